@@ -3,16 +3,35 @@ package main
 import (
 	"context"
 	"log"
+	"net"
+	"os"
+	"time"
 
 	"go-short/internal/handler/admin"
 	"go-short/internal/handler/auth"
+	"go-short/internal/handler/conversion"
+	"go-short/internal/handler/domain"
+	"go-short/internal/handler/folder"
+	"go-short/internal/handler/health"
 	"go-short/internal/handler/link"
+	livehandler "go-short/internal/handler/live"
+	"go-short/internal/handler/qr"
+	"go-short/internal/handler/stats"
+	"go-short/internal/handler/tag"
 	"go-short/internal/handler/user"
 	"go-short/internal/handler/validator"
+	"go-short/internal/handler/workspace"
+	"go-short/internal/healthcheck"
+	"go-short/internal/live"
+	"go-short/internal/mail"
 	"go-short/internal/middleware"
+	"go-short/internal/oidc"
+	"go-short/internal/qrcode"
 	"go-short/internal/repository/impl/postgresql"
 	"go-short/internal/repository/impl/redis"
 	"go-short/internal/service"
+	"go-short/internal/urlcheck"
+	"go-short/internal/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,21 +52,153 @@ func main() {
 	// 延迟队列 worker：消费缓存失效任务，提高可靠性
 	go redisRepo.RunCacheInvalidateWorker(context.Background())
 
+	// 实时点击流：订阅 Redirect 发布的访问事件，扇出给 SSE 客户端
+	liveHub := live.NewHub()
+	go liveHub.Run(context.Background(), redisRepo.SubscribeAccessEvents(context.Background()))
+
+	// 二维码中心 Logo（QR_LOGO_FILE，可选）
+	qrLogo, err := qrcode.LoadLogoFromEnv()
+	if err != nil {
+		log.Printf("⚠️ QR logo load failed: %v (logo embedding disabled)", err)
+	}
+
 	// 2. 初始化 Repository
 	userRepo := postgresql.NewUserRepository(db)
 	linkRepo := postgresql.NewLinkRepository(db)
 	accessLogRepo := postgresql.NewAccessLogRepository(db)
+	linkStatsRepo := postgresql.NewLinkStatsRepository(db)
+	conversionRepo := postgresql.NewConversionRepository(db)
+	tagRepo := postgresql.NewTagRepository(db)
+	folderRepo := postgresql.NewFolderRepository(db)
+	domainRepo := postgresql.NewDomainRepository(db)
+	urlRuleRepo := postgresql.NewURLRuleRepository(db)
+	revisionRepo := postgresql.NewLinkRevisionRepository(db)
+	linkHealthRepo := postgresql.NewLinkHealthRepository(db)
+	refreshTokenRepo := postgresql.NewRefreshTokenRepository(db)
+	sessionRepo := postgresql.NewSessionRepository(db)
+	apiKeyRepo := postgresql.NewAPIKeyRepository(db)
+	workspaceRepo := postgresql.NewWorkspaceRepository(db)
+	roleRepo := postgresql.NewRoleRepository(db)
+	identityRepo := postgresql.NewUserIdentityRepository(db)
+	mfaRepo := postgresql.NewMFARepository(db)
+	authEventRepo := postgresql.NewAuthEventRepository(db)
 
 	// 3. 初始化 Service（redisRepo 用于缓存失效：删除/禁用链接时）
-	userService := service.NewUserService(db, userRepo)
-	linkService := service.NewLinkService(db, linkRepo, userRepo, accessLogRepo, redisRepo)
-	adminService := service.NewAdminService(db, linkRepo, userRepo, accessLogRepo, redisRepo)
+	domainService := service.NewDomainService(db, linkRepo, domainRepo, net.DefaultResolver)
+	// 手动重新检查链接目标地址（定时检查由 Worker 执行）
+	healthConfig := healthcheck.LoadConfigFromEnv()
+	healthService := service.NewHealthService(db, linkRepo, linkHealthRepo, workspaceRepo, healthcheck.NewProber(healthConfig), healthConfig)
+
+	// 目标地址安全检查：协议 → 内网地址 → 跳转循环 → 黑白名单（白名单跳过后续检查）→ 本地恶意网址库
+	urlRules := urlcheck.NewRuleSet()
+	urlRuleService := service.NewURLRuleService(db, urlRuleRepo, urlRules)
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	baseHost := util.BaseHost(baseURL)
+	checkers := []urlcheck.URLChecker{
+		urlcheck.SchemeChecker,
+		urlcheck.PrivateAddressChecker,
+		urlcheck.SelfHostChecker{IsSelf: func(ctx context.Context, host string) bool {
+			return host == baseHost || domainService.IsVerifiedHostname(ctx, host)
+		}},
+		urlRules,
+	}
+	hashList, err := urlcheck.LoadHashPrefixFileFromEnv()
+	if err != nil {
+		log.Printf("⚠️ URL hash prefix list load failed: %v (malware list disabled)", err)
+	} else if hashList != nil {
+		checkers = append(checkers, hashList)
+		log.Printf("✅ URL hash prefix list loaded %d prefixes", hashList.Len())
+	}
+	urlChecker := urlcheck.NewPipeline(checkers...)
+	// 规则变更时本实例立即刷新，其他实例每分钟从数据库同步
+	go func() {
+		for {
+			if err := urlRuleService.ReloadRules(context.Background()); err != nil {
+				log.Printf("⚠️ URL rules refresh failed: %v", err)
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	// JWT 签名密钥：RS256 / EdDSA 私钥文件（JWT_SIGNING_KEYS），按 kid 轮换；生产环境未配置时拒绝启动
+	jwtKeys, err := util.LoadKeySetFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+	util.SetKeySet(jwtKeys)
+	log.Printf("✅ JWT signing key: %s", jwtKeys.Describe())
+	// 点击 ID 签名密钥：校验转化回传的 click_id，须与 Redirect 服务一致
+	if err := util.LoadClickIDSecretFromEnv(); err != nil {
+		log.Fatal("Failed to load click ID secret:", err)
+	}
+	// 邮件验证 / 重置密码链接签名密钥
+	if err := util.LoadEmailTokenSecretFromEnv(); err != nil {
+		log.Fatal("Failed to load email token secret:", err)
+	}
+
+	// 登录会话：每个 refresh token 家族一条记录，修改密码时终止其他会话
+	tokenConfig := service.LoadTokenConfigFromEnv()
+	userService := service.NewUserService(db, userRepo, sessionRepo, refreshTokenRepo, redisRepo, tokenConfig)
+	// access token 吊销列表存于 Redis，认证中间件每次请求检查
+	tokenService := service.NewTokenService(db, userRepo, refreshTokenRepo, sessionRepo, redisRepo, tokenConfig)
+	middleware.SetTokenRevocationStore(redisRepo)
+	// 个人 API Key：在声明了权限范围的路由上代替 JWT
+	apiKeyService := service.NewAPIKeyService(db, userRepo, apiKeyRepo)
+	middleware.SetAPIKeyAuthenticator(apiKeyService)
+	// 角色权限：认证中间件每次请求按用户当前角色刷新权限（Redis 缓存），不信任 token 中的角色
+	roleService := service.NewRoleService(db, roleRepo, userRepo, redisRepo)
+	middleware.SetPermissionResolver(roleService)
+	// SSO：OIDC_PROVIDERS 中配置的每个 IdP，首次登录自动创建本地用户
+	oidcConfigs, oidcErrs := oidc.LoadConfigsFromEnv()
+	for _, err := range oidcErrs {
+		log.Printf("⚠️ %v (provider disabled)", err)
+	}
+	oidcProviders := make([]*oidc.Provider, 0, len(oidcConfigs))
+	for _, cfg := range oidcConfigs {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(cfg, nil))
+		log.Printf("✅ OIDC provider %s configured (issuer %s)", cfg.Name, cfg.Issuer)
+	}
+	oidcService := service.NewOIDCService(db, userRepo, roleRepo, identityRepo, redisRepo, redisRepo, oidcProviders)
+	// 两步验证：密码登录后按用户设置与角色要求（MFA_REQUIRED_ROLES）进行 TOTP 校验
+	mfaService := service.NewMFAService(db, userRepo, mfaRepo, redisRepo, service.LoadMFAConfigFromEnv())
+	// 防暴力破解：按用户名与 IP 统计登录失败（Redis 滑动窗口），渐进延迟后临时锁定，失败写入审计事件
+	loginGuard := service.NewLoginGuardService(db, redisRepo, authEventRepo, service.LoadLoginGuardConfigFromEnv())
+	// 邮件：验证邮箱与找回密码（MAIL_DRIVER 为 smtp 或 file，未配置 SMTP 时写入本地 .eml 文件）
+	mailConfig := mail.LoadConfigFromEnv()
+	mailer, err := mail.New(mailConfig)
+	if err != nil {
+		log.Fatal("Failed to init mailer:", err)
+	}
+	log.Printf("✅ Mailer configured (driver %s)", mailConfig.Driver)
+	emailService := service.NewEmailService(db, userRepo, redisRepo, mailer, service.LoadEmailConfigFromEnv())
+	linkService := service.NewLinkService(db, linkRepo, userRepo, accessLogRepo, domainRepo, revisionRepo, workspaceRepo, redisRepo, urlChecker)
+	adminService := service.NewAdminService(db, linkRepo, userRepo, accessLogRepo, revisionRepo, roleRepo, redisRepo)
+	conversionService := service.NewConversionService(db, linkRepo, workspaceRepo, conversionRepo)
+	bulkService := service.NewBulkService(db, linkRepo, userRepo, domainRepo, revisionRepo, workspaceRepo, redisRepo, urlChecker)
+	tagService := service.NewTagService(db, linkRepo, tagRepo, workspaceRepo)
+	folderService := service.NewFolderService(db, linkRepo, folderRepo, workspaceRepo)
+	statsService := service.NewStatsService(db, linkRepo, linkStatsRepo, conversionRepo, workspaceRepo, redisRepo)
+	// 工作区：团队共享链接，按成员角色控制访问
+	workspaceService := service.NewWorkspaceService(db, workspaceRepo, userRepo, linkRepo)
+	qrService := service.NewQRService(db, linkRepo, workspaceRepo, redisRepo, qrLogo)
 
 	// 4. 初始化 Handler
-	authHandler := auth.NewAuthHandler(userService)
-	linkHandler := link.NewLinkHandler(linkService)
-	adminHandler := admin.NewAdminHandler(adminService)
-	userHandler := user.NewUserHandler(userService)
+	authHandler := auth.NewAuthHandler(userService, tokenService, oidcService, mfaService, loginGuard, emailService)
+	linkHandler := link.NewLinkHandler(linkService, bulkService, tagService, healthService)
+	adminHandler := admin.NewAdminHandler(adminService, urlRuleService, tokenService, roleService, mfaService, loginGuard)
+	userHandler := user.NewUserHandler(userService, statsService, apiKeyService, mfaService, emailService, tokenService)
+	liveHandler := livehandler.NewLiveHandler(linkService, liveHub)
+	conversionHandler := conversion.NewConversionHandler(conversionService)
+	statsHandler := stats.NewStatsHandler(statsService)
+	tagHandler := tag.NewTagHandler(tagService)
+	folderHandler := folder.NewFolderHandler(folderService)
+	qrHandler := qr.NewQRHandler(qrService)
+	domainHandler := domain.NewDomainHandler(domainService)
+	healthHandler := health.NewHealthHandler(healthService)
+	workspaceHandler := workspace.NewWorkspaceHandler(workspaceService)
 
 	// 5. 将自定义验证规则注册到Gin的默认validator
 	// 必须在创建 Gin 引擎之前调用，这样 ShouldBindJSON 等绑定方法才能使用自定义验证规则
@@ -60,11 +211,21 @@ func main() {
 	r.Use(middleware.CORS())
 
 	// 7. 注册路由
+	auth.RegisterWellKnownRoutes(r, authHandler)
 	api := r.Group("/api/v1")
 	auth.RegisterRoutes(api, authHandler)
 	link.RegisterRoutes(api, linkHandler)
 	user.RegisterRoutes(api, userHandler)
 	admin.RegisterRoutes(api, adminHandler)
+	livehandler.RegisterRoutes(api, liveHandler)
+	conversion.RegisterRoutes(api, conversionHandler)
+	stats.RegisterRoutes(api, statsHandler)
+	tag.RegisterRoutes(api, tagHandler)
+	folder.RegisterRoutes(api, folderHandler)
+	qr.RegisterRoutes(api, qrHandler)
+	domain.RegisterRoutes(api, domainHandler)
+	health.RegisterRoutes(api, healthHandler)
+	workspace.RegisterRoutes(api, workspaceHandler)
 
 	log.Println("🚀 API Server running on :8080")
 	r.Run(":8080")
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"go-short/internal/export"
+	"go-short/internal/model"
+	"go-short/internal/repository/impl/postgresql"
+	"go-short/internal/service"
+)
+
+// 访问日志导出工具：按时间范围将 access_logs 流式导出为 CSV / NDJSON / Parquet 文件
+//
+//	go run ./cmd/export -format parquet -from 2026-01-01 -to 2026-01-31 -out clicks.parquet
+func main() {
+	formatFlag := flag.String("format", "csv", "导出格式：csv | ndjson | parquet")
+	fromFlag := flag.String("from", "", "开始时间（2006-01-02 或 RFC3339），默认 to 前 30 天")
+	toFlag := flag.String("to", "", "结束时间（2006-01-02 或 RFC3339，仅日期时包含当天），默认当前时间")
+	linkFlag := flag.Int64("link", 0, "仅导出指定链接 ID（0 表示全部）")
+	outFlag := flag.String("out", "", "输出文件路径，默认按时间范围生成")
+	flag.Parse()
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatalf("Invalid format %q: %v", *formatFlag, err)
+	}
+	from, to, err := export.ParseTimeRange(*fromFlag, *toFlag, time.Now())
+	if err != nil {
+		log.Fatalf("Invalid time range: %v", err)
+	}
+
+	query := service.ExportAccessLogsQuery{From: from, To: to}
+	if *linkFlag > 0 {
+		query.LinkID = linkFlag
+	}
+
+	outPath := *outFlag
+	if outPath == "" {
+		outPath = export.FileName("access_logs", format, from, to)
+	}
+
+	db, err := postgresql.NewPostgresClient()
+	if err != nil {
+		log.Fatal("Failed to connect to DB:", err)
+	}
+	accessLogRepo := postgresql.NewAccessLogRepository(db)
+	adminService := service.NewAdminService(db, nil, nil, accessLogRepo, nil, nil, nil)
+	if err := adminService.CheckExportAccessLogs(query); err != nil {
+		log.Fatalf("Invalid export query: %v", err)
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", outPath, err)
+	}
+	defer f.Close()
+
+	var total int
+	start := time.Now()
+	err = export.Stream(f, format, func(fn func([]model.AccessLog) error) error {
+		return adminService.ExportAccessLogs(context.Background(), query, func(logs []model.AccessLog) error {
+			total += len(logs)
+			return fn(logs)
+		})
+	})
+	if err != nil {
+		log.Fatalf("Export failed after %d rows: %v", total, err)
+	}
+
+	log.Printf("✅ Exported %d rows to %s in %s", total, outPath, time.Since(start).Round(time.Millisecond))
+}
@@ -3,18 +3,24 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"os"
 	_ "net/http/pprof" // 零埋点 pprof，import 即生效
 	"time"
 
 	"go-short/internal/bloom"
 	"go-short/internal/metrics"
+	"go-short/internal/model"
 	"go-short/internal/mq"
+	"go-short/internal/qrcode"
 	"go-short/internal/repository/impl/local"
 	"go-short/internal/repository/impl/postgresql"
 	"go-short/internal/repository/impl/redis"
 	"go-short/internal/service"
+	"go-short/internal/urlcheck"
+	"go-short/internal/util"
 
 	"github.com/gin-gonic/gin"
 	"github.com/segmentio/kafka-go"
@@ -25,6 +31,11 @@ func main() {
 	// 生产模式，减少日志输出提升性能
 	gin.SetMode(gin.ReleaseMode)
 
+	// 点击 ID 签名密钥：生产环境未配置时拒绝启动，避免使用公开的默认密钥
+	if err := util.LoadClickIDSecretFromEnv(); err != nil {
+		log.Fatal("Failed to load click ID secret:", err)
+	}
+
 	// 1. 初始化资源
 	db, err := postgresql.NewPostgresClient()
 	if err != nil {
@@ -43,6 +54,8 @@ func main() {
 	linkRepo := postgresql.NewLinkRepository(db)
 	userRepo := postgresql.NewUserRepository(db)
 	accessLogRepo := postgresql.NewAccessLogRepository(db)
+	domainRepo := postgresql.NewDomainRepository(db)
+	urlRuleRepo := postgresql.NewURLRuleRepository(db)
 	redisRepo := redis.NewRedisRepository(rdb)
 
 	// 初始化本地缓存（TTL 5min + LRU 最多 10000 条）
@@ -62,19 +75,90 @@ func main() {
 		}
 	}()
 
-	// 订阅 Redis 缓存失效通道，删除/禁用链接时删除本地缓存
+	// 订阅 Redis 缓存失效通道，删除/禁用/编辑链接时删除本地缓存
 	go func() {
 		pubsub := rdb.Subscribe(context.Background(), redis.CacheInvalidateChannel)
 		defer pubsub.Close()
 		ch := pubsub.Channel()
 		for msg := range ch {
-			code := msg.Payload
-			localCache.Delete("short:" + code)
+			key := msg.Payload // 短码或 "域名/短码"
+			localCache.Delete("short:" + key)
+			// 失效的短码可能是刚改名/启用的新短码，加入布隆避免被误判为不存在
+			shortCodeBloom.Add(key)
 		}
 	}()
 
 	// 3. 初始化 Service（redirect 只读，无需 cacheInvalidator）
-	linkService := service.NewLinkService(db, linkRepo, userRepo, accessLogRepo, nil)
+	linkService := service.NewLinkService(db, linkRepo, userRepo, accessLogRepo, domainRepo, nil, nil, nil, nil)
+
+	// 跳转时复查目标地址：管理员新拉黑的域名和恶意网址库对已有链接立即生效（规则每分钟同步）
+	urlRules := urlcheck.NewRuleSet()
+	urlRuleService := service.NewURLRuleService(db, urlRuleRepo, urlRules)
+	redirectCheckers := []urlcheck.URLChecker{urlRules}
+	if hashList, err := urlcheck.LoadHashPrefixFileFromEnv(); err != nil {
+		log.Printf("⚠️ URL hash prefix list load failed: %v (malware list disabled)", err)
+	} else if hashList != nil {
+		redirectCheckers = append(redirectCheckers, hashList)
+	}
+	redirectChecker := urlcheck.NewPipeline(redirectCheckers...)
+
+	// 二维码：Redis 缓存多实例共享，本地缓存存放热点图片
+	qrLogo, err := qrcode.LoadLogoFromEnv()
+	if err != nil {
+		log.Printf("⚠️ QR logo load failed: %v (logo embedding disabled)", err)
+	}
+	qrService := service.NewQRService(db, linkRepo, nil, redisRepo, qrLogo)
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	// 已验证的自定义域名与 URL 黑白名单每分钟刷新一次，Host 命中则按该域名查找短码，否则视为默认域名
+	customDomains := local.NewDomainSet()
+	defaultHost := util.BaseHost(baseURL)
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			hosts, err := domainRepo.ListVerifiedHostnames(ctx, nil)
+			if err != nil {
+				log.Printf("⚠️ Custom domain refresh failed: %v", err)
+			} else {
+				customDomains.Replace(hosts)
+			}
+			if err := urlRuleService.ReloadRules(ctx); err != nil {
+				log.Printf("⚠️ URL rules refresh failed: %v", err)
+			}
+			cancel()
+			time.Sleep(time.Minute)
+		}
+	}()
+	resolveDomain := func(c *gin.Context) string {
+		host := util.NormalizeHost(c.Request.Host)
+		if host == defaultHost || !customDomains.Contains(host) {
+			return ""
+		}
+		return host
+	}
+	// linkAvailable 按跳转同样的路径（本地缓存 -> Redis -> PostgreSQL）确认链接启用且未过期；
+	// 跳转缓存在链接停用、删除、修改时已通过广播失效，Redis 缓存不晚于链接过期时间
+	linkAvailable := func(ctx context.Context, domain, code string) bool {
+		key := model.LinkKey(domain, code)
+		if _, ok := localCache.Get("short:" + key); ok {
+			return true
+		}
+		if url, err := redisRepo.GetLinkFromCache(ctx, key); err == nil {
+			localCache.Set("short:"+key, url)
+			return true
+		}
+		link, err := linkService.GetLinkByCodeForRedirect(ctx, domain, code)
+		if err != nil {
+			return false
+		}
+		value := service.NewRedirectTarget(link).Encode()
+		localCache.Set("short:"+key, value)
+		redisRepo.CacheLink(ctx, key, value, service.RedirectCacheTTL(link, time.Hour))
+		return true
+	}
 
 	// 4. 启动 pprof（零埋点，import 即注册，6060 端口）
 	go func() { _ = http.ListenAndServe(":6060", nil) }()
@@ -91,13 +175,15 @@ func main() {
 		}
 
 		ctx := c.Request.Context()
+		domain := resolveDomain(c)
+		key := model.LinkKey(domain, code) // 自定义域名下短码独立，缓存/布隆/锁均以 "域名/短码" 为键
 		var longURL string
 		var found bool
 
 		// 三级缓存查询：本地缓存 -> Redis -> PostgreSQL
 
 		// Step 1: 查本地缓存（最快）
-		cacheKey := "short:" + code
+		cacheKey := "short:" + key
 		startTime := time.Now()
 		if url, ok := localCache.Get(cacheKey); ok {
 			longURL = url
@@ -110,7 +196,7 @@ func main() {
 		}
 
 		// Step 2: 布隆过滤器防穿透（一定不存在则直返 404，不打 Redis/DB）
-		if !found && shortCodeBloom.DefinitelyNotExist(code) {
+		if !found && shortCodeBloom.DefinitelyNotExist(key) {
 			c.String(404, "Link not found or expired")
 			return
 		}
@@ -118,7 +204,7 @@ func main() {
 		// Step 3: 查 Redis
 		if !found {
 			startTime = time.Now()
-			cachedURL, err := redisRepo.GetLinkFromCache(ctx, code)
+			cachedURL, err := redisRepo.GetLinkFromCache(ctx, key)
 			redisDuration := time.Since(startTime)
 
 			if err == nil {
@@ -134,17 +220,17 @@ func main() {
 			} else {
 				// Redis 错误（非未命中），记录日志但继续降级到数据库
 				metrics.RecordRedis(false, redisDuration)
-				log.Printf("Redis error for code %s: %v, falling back to database", code, err)
+				log.Printf("Redis error for code %s: %v, falling back to database", key, err)
 			}
 		}
 
 		// Step 4: Redis 未命中，查 PostgreSQL（回源，分布式锁防缓存击穿）
 		if !found {
-			lockKey := redis.LockKeyForCode(code)
+			lockKey := redis.LockKeyForCode(key)
 			token, gotLock := redisRepo.TryLock(ctx, lockKey, redis.LockTTLSeconds*time.Second)
 			if gotLock {
 				defer func() { _ = redisRepo.Unlock(context.Background(), lockKey, token) }()
-				if url, err := redisRepo.GetLinkFromCache(ctx, code); err == nil {
+				if url, err := redisRepo.GetLinkFromCache(ctx, key); err == nil {
 					longURL = url
 					found = true
 					localCache.Set(cacheKey, longURL)
@@ -153,7 +239,7 @@ func main() {
 			if !found && !gotLock {
 				for i := 0; i < 20; i++ {
 					time.Sleep(50 * time.Millisecond)
-					if url, err := redisRepo.GetLinkFromCache(ctx, code); err == nil {
+					if url, err := redisRepo.GetLinkFromCache(ctx, key); err == nil {
 						longURL = url
 						found = true
 						localCache.Set(cacheKey, longURL)
@@ -163,23 +249,38 @@ func main() {
 			}
 			if !found {
 				startTime := time.Now()
-				link, dbErr := linkService.GetLinkByCodeForRedirect(ctx, code)
+				link, dbErr := linkService.GetLinkByCodeForRedirect(ctx, domain, code)
 				postgresDuration := time.Since(startTime)
 				if dbErr != nil {
 					metrics.RecordPostgres(false, postgresDuration)
 					c.String(404, "Link not found or expired")
 					return
 				}
-				longURL = link.OriginalURL
+				longURL = service.NewRedirectTarget(link).Encode()
 				metrics.RecordPostgres(true, postgresDuration)
-				shortCodeBloom.Add(code) // DB 命中则加入布隆（新建链接首次访问）
+				shortCodeBloom.Add(key) // DB 命中则加入布隆（新建链接首次访问）
 				localCache.Set(cacheKey, longURL)
-				redisRepo.CacheLink(ctx, code, longURL, time.Hour)
+				redisRepo.CacheLink(ctx, key, longURL, service.RedirectCacheTTL(link, time.Hour))
+			}
+		}
+
+		// 缓存值可能带有附加选项（如转化追踪），解析出真正的目标 URL
+		target := service.DecodeRedirectTarget(longURL)
+		if err := urlcheck.CheckString(ctx, redirectChecker, target.URL); err != nil {
+			c.String(http.StatusForbidden, "Link blocked: destination is unsafe")
+			return
+		}
+		redirectURL := target.URL
+		clickID := ""
+		if target.TrackConversions {
+			if id, token, err := util.NewClickToken(key); err == nil {
+				clickID = id.String()
+				redirectURL = util.AppendQueryParam(redirectURL, util.ClickIDParam, token)
 			}
 		}
 
 		// Step 5: 异步发送访问日志到 Kafka
-		go func(code, ip, ua string) {
+		go func(domain, code, ip, ua, clickID string) {
 			bgCtx := context.Background()
 			logData := map[string]any{
 				"code": code,
@@ -187,13 +288,65 @@ func main() {
 				"ua":   ua,
 				"ts":   time.Now().Unix(),
 			}
+			if domain != "" {
+				logData["domain"] = domain
+			}
+			if clickID != "" {
+				logData["cid"] = clickID
+			}
 			dataBytes, _ := json.Marshal(logData)
 			_ = kafkaWriter.WriteMessages(bgCtx, kafka.Message{Value: dataBytes})
-			rdb.Incr(bgCtx, "stats:visits:"+code)
-		}(code, c.ClientIP(), c.Request.UserAgent())
+			_ = redisRepo.PublishAccessEvent(bgCtx, dataBytes) // 实时点击流
+			rdb.Incr(bgCtx, "stats:visits:"+model.LinkKey(domain, code))
+		}(domain, code, c.ClientIP(), c.Request.UserAgent(), clickID)
 
 		// Step 6: 302 重定向
-		c.Redirect(http.StatusFound, longURL)
+		c.Redirect(http.StatusFound, redirectURL)
+	})
+
+	// 公开二维码（印刷物料使用）：先确认链接可用，再按参数哈希缓存：本地缓存 -> Redis -> 生成
+	r.GET("/code/:code/qr", func(c *gin.Context) {
+		code := c.Param("code")
+		domain := resolveDomain(c)
+		if code == "" || shortCodeBloom.DefinitelyNotExist(model.LinkKey(domain, code)) {
+			c.String(404, "Link not found or expired")
+			return
+		}
+		opts, err := qrcode.ParsePublicOptions(c.Request.URL.Query())
+		if err != nil {
+			c.String(400, "Invalid QR code options")
+			return
+		}
+		if !linkAvailable(c.Request.Context(), domain, code) {
+			c.String(404, "Link not found or expired")
+			return
+		}
+
+		key := opts.CacheKey(util.ShortURL(baseURL, domain, code))
+		// 每次使用前回源校验（ETag 命中返回 304），链接停用后不再被中间缓存继续提供
+		c.Header("Cache-Control", "public, no-cache")
+		c.Header("ETag", `"`+key+`"`)
+		if c.GetHeader("If-None-Match") == `"`+key+`"` {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		if data, ok := localCache.Get("qr:" + key); ok {
+			c.Data(200, opts.ContentType(), []byte(data))
+			return
+		}
+
+		data, err := qrService.GetShortURLQRCode(c.Request.Context(), domain, code, baseURL, opts)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrQRLogoUnavailable):
+				c.String(400, "QR logo not configured")
+			default:
+				c.String(500, "Internal Server Error")
+			}
+			return
+		}
+		localCache.Set("qr:"+key, string(data))
+		c.Data(200, opts.ContentType(), data)
 	})
 
 	// 7. 健康检查
@@ -3,25 +3,48 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"log"
 	"os"
+	"strings"
 	"time"
 
+	"go-short/internal/healthcheck"
 	"go-short/internal/model"
 	"go-short/internal/mq"
 	"go-short/internal/repository"
 	"go-short/internal/repository/impl/postgresql"
+	"go-short/internal/service"
+
+	"github.com/google/uuid"
 )
 
+// maintenanceInterval access_logs 分区维护间隔
+const maintenanceInterval = time.Hour
+
+// healthCheckIdle 链接健康检查没有积压时的轮询间隔
+const healthCheckIdle = time.Minute
+
+// maxClockSkew 访问时间超过当前时间该值时视为 Redirect Server 时钟偏差
+const maxClockSkew = 5 * time.Minute
+
 // LogPayload 对应 Redirect Server 发送的 JSON 结构
 type LogPayload struct {
-	Code string `json:"code"`
-	IP   string `json:"ip"`
-	UA   string `json:"ua"`
-	TS   int64  `json:"ts"`
+	Code   string `json:"code"`
+	Domain string `json:"domain,omitempty"` // 自定义域名（默认域名为空）
+	IP     string `json:"ip"`
+	UA     string `json:"ua"`
+	TS     int64  `json:"ts"`
+	CID    string `json:"cid,omitempty"` // 点击 ID（仅开启转化追踪的链接）
 }
 
 func main() {
+	// -maintenance：只执行一轮分区维护后退出（可由 cron 调用）
+	maintenanceOnly := flag.Bool("maintenance", false, "run access_logs partition maintenance once and exit")
+	// -backfill-stats：按全部访问日志重新汇总 link_daily_stats 后退出（升级或修复统计时执行一次）
+	backfillStats := flag.Bool("backfill-stats", false, "re-roll up link_daily_stats from all access logs and exit")
+	flag.Parse()
+
 	db, err := postgresql.NewPostgresClient()
 	if err != nil {
 		log.Fatal("Failed to connect to DB:", err)
@@ -29,9 +52,61 @@ func main() {
 
 	linkRepo := postgresql.NewLinkRepository(db)
 	accessLogRepo := postgresql.NewAccessLogRepository(db)
+	partitionRepo := postgresql.NewAccessLogPartitionRepository(db)
+	linkStatsRepo := postgresql.NewLinkStatsRepository(db)
+	userRepo := postgresql.NewUserRepository(db)
+	refreshTokenRepo := postgresql.NewRefreshTokenRepository(db)
+	sessionRepo := postgresql.NewSessionRepository(db)
+	authEventRepo := postgresql.NewAuthEventRepository(db)
+	maintenanceService := service.NewMaintenanceService(db, partitionRepo, linkStatsRepo, linkRepo, userRepo, refreshTokenRepo, sessionRepo, authEventRepo, service.LoadRetentionConfigFromEnv())
+	healthConfig := healthcheck.LoadConfigFromEnv()
+	healthService := service.NewHealthService(db, linkRepo, postgresql.NewLinkHealthRepository(db), nil, healthcheck.NewProber(healthConfig), healthConfig)
 
 	ctx := context.Background()
 
+	if *backfillStats {
+		if err := maintenanceService.BackfillDailyStats(ctx); err != nil {
+			log.Fatal("Backfill failed:", err)
+		}
+		log.Println("✅ Backfill completed")
+		return
+	}
+
+	if *maintenanceOnly {
+		if err := maintenanceService.RunAccessLogMaintenance(ctx); err != nil {
+			log.Fatal("Maintenance failed:", err)
+		}
+		log.Println("✅ Maintenance completed")
+		return
+	}
+
+	// 定时维护：预建分区、汇总统计、执行保留策略
+	go func() {
+		ticker := time.NewTicker(maintenanceInterval)
+		defer ticker.Stop()
+		for {
+			if err := maintenanceService.RunAccessLogMaintenance(ctx); err != nil {
+				log.Println("Maintenance error:", err)
+			}
+			<-ticker.C
+		}
+	}()
+
+	// 链接健康检查：每轮检查一批到期链接，批次满说明还有积压，立即继续
+	go func() {
+		for {
+			n, err := healthService.RunHealthChecks(ctx)
+			if err != nil {
+				log.Println("Health check error:", err)
+			} else if n > 0 {
+				log.Printf("🩺 Checked %d link destinations", n)
+			}
+			if err != nil || n < healthConfig.BatchSize {
+				time.Sleep(healthCheckIdle)
+			}
+		}
+	}()
+
 	reader := mq.NewAccessLogReader("access_logs_group")
 	defer reader.Close()
 
@@ -61,7 +136,7 @@ func processLog(ctx context.Context, workerID int, data []byte, linkRepo reposit
 		return true // 格式错误无需重试
 	}
 
-	linkID, err := linkRepo.GetLinkIDByCode(ctx, nil, payload.Code)
+	linkID, err := linkRepo.GetLinkIDByCode(ctx, nil, payload.Domain, payload.Code)
 	if err != nil {
 		log.Printf("[worker-%d] Failed to find link: %s, err=%v\n", workerID, payload.Code, err)
 		return true // 链接已删除，无需重试
@@ -74,8 +149,22 @@ func processLog(ctx context.Context, workerID int, data []byte, linkRepo reposit
 		UserAgent: payload.UA,
 		VisitedAt: time.Unix(payload.TS, 0),
 	}
+	// 时钟偏差导致的未来时间修正为当前时间：写入 DEFAULT 分区后会阻止该月分区的创建
+	if now := time.Now(); accessLog.VisitedAt.After(now.Add(maxClockSkew)) {
+		accessLog.VisitedAt = now
+	}
+	if payload.CID != "" {
+		if clickID, err := uuid.Parse(payload.CID); err == nil {
+			accessLog.ClickID = &clickID
+		}
+	}
 
 	if err := accessLogRepo.SaveAccessLog(ctx, nil, &accessLog); err != nil {
+		if isNoPartitionError(err) {
+			log.Printf("[worker-%d] ⚠️ Dropping log for %s: no partition for visited_at %s, err=%v\n",
+				workerID, payload.Code, accessLog.VisitedAt.Format(time.RFC3339), err)
+			return true // 缺少分区，重试不会成功
+		}
 		log.Printf("[worker-%d] Failed to save log: %s, err=%v\n", workerID, payload.Code, err)
 		return false // DB 失败，不提交，稍后重试
 	}
@@ -85,3 +174,8 @@ func processLog(ctx context.Context, workerID int, data []byte, linkRepo reposit
 	}
 	return true
 }
+
+// isNoPartitionError access_logs 没有可容纳该行的分区（DEFAULT 分区缺失时）
+func isNoPartitionError(err error) bool {
+	return strings.Contains(err.Error(), "no partition of relation")
+}
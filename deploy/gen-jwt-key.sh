@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# 生成 JWT 签名密钥（Ed25519，PKCS#8 PEM），用法：./gen-jwt-key.sh [kid]
+# 轮换：生成新 kid 后先加入 JWT_VERIFY_KEYS 发布到 JWKS，再移到 JWT_SIGNING_KEYS 首位，旧密钥在 access token 有效期过后移除
+
+set -e
+
+KID="${1:-k1}"
+KEY_DIR="$(cd "$(dirname "$0")" && pwd)/keys"
+mkdir -p "$KEY_DIR"
+
+if [ -e "$KEY_DIR/$KID.pem" ]; then
+  echo "❌ $KEY_DIR/$KID.pem 已存在，请使用新的 kid"
+  exit 1
+fi
+
+openssl genpkey -algorithm ed25519 -out "$KEY_DIR/$KID.pem"
+chmod 600 "$KEY_DIR/$KID.pem"
+echo "✅ 密钥已生成: $KEY_DIR/$KID.pem"
+echo "   配置: JWT_SIGNING_KEYS=$KID=/etc/goshort/keys/$KID.pem"
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 
+	"go-short/internal/model"
+
 	"github.com/bits-and-blooms/bloom/v3"
 	"gorm.io/gorm"
 )
@@ -40,24 +42,27 @@ func (b *ShortCodeBloom) DefinitelyNotExist(code string) bool {
 	return ready && notIn
 }
 
-// LoadFromDB 从 DB 加载所有有效短码，加载完成后 SetReady
+// LoadFromDB 从 DB 加载所有有效短码（不含回收站，自定义域名下为 "域名/短码"），加载完成后 SetReady
 func (b *ShortCodeBloom) LoadFromDB(ctx context.Context, db *gorm.DB) (int, error) {
-	var codes []string
+	var rows []struct {
+		Domain    string
+		ShortCode string
+	}
 	err := db.WithContext(ctx).Table("links").
-		Select("short_code").
-		Where("status = ?", true).
+		Select("domain, short_code").
+		Where("status = ? AND deleted_at IS NULL", true).
 		Where("expires_at IS NULL OR expires_at > NOW()").
-		Pluck("short_code", &codes).Error
+		Scan(&rows).Error
 	if err != nil {
 		return 0, err
 	}
 	b.mu.Lock()
-	for _, c := range codes {
-		if c != "" {
-			b.filter.AddString(c)
+	for _, r := range rows {
+		if r.ShortCode != "" {
+			b.filter.AddString(model.LinkKey(r.Domain, r.ShortCode))
 		}
 	}
 	b.ready = true
 	b.mu.Unlock()
-	return len(codes), nil
+	return len(rows), nil
 }
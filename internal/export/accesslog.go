@@ -0,0 +1,256 @@
+// Package export 访问日志导出：按批写入 CSV / NDJSON / Parquet，不在内存中攒全量数据。
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-short/internal/model"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format 导出格式
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// parquetRowGroupSize Parquet 每个 RowGroup 的最大行数，决定写出时的内存上限
+const parquetRowGroupSize = 10000
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// ParseFormat 解析导出格式，空字符串默认为 CSV
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON, "jsonl":
+		return FormatNDJSON, nil
+	case FormatParquet:
+		return FormatParquet, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType 返回 HTTP Content-Type
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension 返回文件扩展名
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Row 导出行（字段顺序即 CSV 列顺序）
+type Row struct {
+	ID        int64  `json:"id" parquet:"id"`
+	LinkID    int64  `json:"link_id" parquet:"link_id"`
+	ShortCode string `json:"short_code" parquet:"short_code"`
+	IPAddress string `json:"ip_address" parquet:"ip_address"`
+	UserAgent string `json:"user_agent" parquet:"user_agent"`
+	Referer   string `json:"referer" parquet:"referer"`
+	ClickID   string `json:"click_id,omitempty" parquet:"click_id,optional"`
+	VisitedAt int64  `json:"-" parquet:"visited_at,timestamp(millisecond)"`
+	// VisitedAtISO 仅用于 CSV / NDJSON
+	VisitedAtISO string `json:"visited_at" parquet:"-"`
+}
+
+var csvHeader = []string{"id", "link_id", "short_code", "ip_address", "user_agent", "referer", "click_id", "visited_at"}
+
+// NewRow 由访问日志模型构造导出行
+func NewRow(l *model.AccessLog) Row {
+	clickID := ""
+	if l.ClickID != nil {
+		clickID = l.ClickID.String()
+	}
+	return Row{
+		ID:           l.ID,
+		LinkID:       l.LinkID,
+		ShortCode:    l.ShortCode,
+		IPAddress:    l.IPAddress,
+		UserAgent:    l.UserAgent,
+		Referer:      l.Referer,
+		ClickID:      clickID,
+		VisitedAt:    l.VisitedAt.UnixMilli(),
+		VisitedAtISO: l.VisitedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// Writer 导出写入器
+type Writer interface {
+	// WriteBatch 写入一批访问日志
+	WriteBatch(logs []model.AccessLog) error
+	// Flush 将已写入的数据刷到下游（HTTP 流式响应时每批调用一次）
+	Flush() error
+	// Close 结束写入（Parquet 在此写 footer）
+	Close() error
+}
+
+// NewWriter 按格式创建写入器
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w, parquet.MaxRowsPerRowGroup(parquetRowGroupSize))}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteBatch(logs []model.AccessLog) error {
+	for i := range logs {
+		r := NewRow(&logs[i])
+		record := []string{
+			strconv.FormatInt(r.ID, 10),
+			strconv.FormatInt(r.LinkID, 10),
+			r.ShortCode,
+			r.IPAddress,
+			r.UserAgent,
+			r.Referer,
+			r.ClickID,
+			r.VisitedAtISO,
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteBatch(logs []model.AccessLog) error {
+	for i := range logs {
+		if err := n.enc.Encode(NewRow(&logs[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error { return nil }
+
+func (n *ndjsonWriter) Close() error { return nil }
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Row]
+}
+
+func (p *parquetWriter) WriteBatch(logs []model.AccessLog) error {
+	rows := make([]Row, 0, len(logs))
+	for i := range logs {
+		rows = append(rows, NewRow(&logs[i]))
+	}
+	_, err := p.w.Write(rows)
+	return err
+}
+
+// Flush Parquet 按 RowGroup 写出，这里不强制切分，避免产生大量小 RowGroup
+func (p *parquetWriter) Flush() error { return nil }
+
+func (p *parquetWriter) Close() error {
+	return p.w.Close()
+}
+
+// Stream 创建写入器并执行 run：run 每读到一批数据就回调一次，写入后立即刷到下游
+// w 若实现 http.Flusher（如 gin.ResponseWriter），每批都会触发一次网络刷新
+func Stream(w io.Writer, format Format, run func(fn func([]model.AccessLog) error) error) error {
+	ew, err := NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+	err = run(func(logs []model.AccessLog) error {
+		if err := ew.WriteBatch(logs); err != nil {
+			return err
+		}
+		if err := ew.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// ParseTimeRange 解析导出时间范围，支持 2006-01-02 与 RFC3339；
+// 缺省时 to 为当前时间、from 为 to 前 30 天；仅日期的 to 视为包含当天
+func ParseTimeRange(fromStr, toStr string, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if toStr != "" {
+		t, dateOnly, err := parseTime(toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if fromStr != "" {
+		t, _, err := parseTime(fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+	return from, to, nil
+}
+
+func parseTime(s string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, false, err
+}
+
+// FileName 生成导出文件名，例如 access_logs_20260101_20260201.csv
+func FileName(prefix string, format Format, from, to time.Time) string {
+	return prefix + "_" + from.Format("20060102") + "_" + to.Format("20060102") + "." + format.Extension()
+}
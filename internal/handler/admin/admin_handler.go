@@ -2,19 +2,29 @@ package admin
 
 import (
 	"errors"
+	"go-short/internal/export"
+	"go-short/internal/model"
+	"go-short/internal/repository"
 	"go-short/internal/service"
+	"log"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type AdminHandler struct {
-	adminService *service.AdminService // 通过依赖注入，不用自己连接
+	adminService   *service.AdminService // 通过依赖注入，不用自己连接
+	urlRuleService *service.URLRuleService
+	tokenService   *service.TokenService
+	roleService    *service.RoleService
+	mfaService     *service.MFAService
+	loginGuard     *service.LoginGuardService
 }
 
-func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
-	return &AdminHandler{adminService: adminService}
+func NewAdminHandler(adminService *service.AdminService, urlRuleService *service.URLRuleService, tokenService *service.TokenService, roleService *service.RoleService, mfaService *service.MFAService, loginGuard *service.LoginGuardService) *AdminHandler {
+	return &AdminHandler{adminService: adminService, urlRuleService: urlRuleService, tokenService: tokenService, roleService: roleService, mfaService: mfaService, loginGuard: loginGuard}
 }
 
 func (h *AdminHandler) CreateUser(c *gin.Context) {
@@ -23,12 +33,18 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
 	createUserRequest := service.CreateUserCommand{
 		Username: req.Username,
 		Password: req.Password,
 		Status:   req.Status,
 		Email:    req.Email,
 		Role:     req.Role,
+		ActorID:  adminID,
 	}
 	user, err := h.adminService.CreateUser(c, createUserRequest)
 	if user == nil || err != nil {
@@ -36,6 +52,14 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 			c.JSON(409, ErrUserAlreadyExists)
 			return
 		}
+		if errors.Is(err, service.ErrRoleNotFound) {
+			c.JSON(400, ErrRoleNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrRoleEscalation) {
+			c.JSON(403, ErrRoleEscalation)
+			return
+		}
 		c.JSON(500, ErrDatabase)
 		return
 	}
@@ -54,11 +78,18 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
 	// 使用 AdminService 的事务方法删除用户及其所有数据
-	if err := h.adminService.DeleteUserAndData(c, userID); err != nil {
-		c.JSON(500, ErrDatabase)
+	if err := h.adminService.DeleteUserAndData(c, userID, adminID); err != nil {
+		writeUserActionError(c, err)
 		return
 	}
+	h.revokeUserTokens(c, userID)
 	c.JSON(200, NewDeleteUserResponse(userIDstr))
 }
 
@@ -69,13 +100,26 @@ func (h *AdminHandler) UnactiveUser(c *gin.Context) {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
-	if err := h.adminService.UnactiveUserByUserID(c, userID); err != nil {
-		c.JSON(500, ErrDatabase)
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
 		return
 	}
+	if err := h.adminService.UnactiveUserByUserID(c, userID, adminID); err != nil {
+		writeUserActionError(c, err)
+		return
+	}
+	h.revokeUserTokens(c, userID)
 	c.JSON(200, NewUnactivateUserResponse(userIDstr, false))
 }
 
+// revokeUserTokens 禁用或删除用户后立即吊销其已签发的令牌（失败只记录日志，令牌最长在 access token 有效期内失效）
+func (h *AdminHandler) revokeUserTokens(c *gin.Context, userID uuid.UUID) {
+	if err := h.tokenService.RevokeUserTokens(c, userID); err != nil {
+		log.Printf("⚠️ Revoke tokens for user %s failed: %v", userID, err)
+	}
+}
+
 func (h *AdminHandler) ActiveUser(c *gin.Context) {
 	userIDstr := c.Param("userID")
 	userID, err := uuid.Parse(userIDstr)
@@ -83,8 +127,13 @@ func (h *AdminHandler) ActiveUser(c *gin.Context) {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
-	if err := h.adminService.ActiveUserByUserID(c, userID); err != nil {
-		c.JSON(500, ErrDatabase)
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.adminService.ActiveUserByUserID(c, userID, adminID); err != nil {
+		writeUserActionError(c, err)
 		return
 	}
 	c.JSON(200, NewActivateUserResponse(userIDstr, true))
@@ -105,41 +154,101 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		size = 10
 	}
 
-	users, total, err := h.adminService.GetAllUsers(c, page, size)
+	query := service.ListUsersQuery{Page: page, Size: size, Count: c.Query("count")}
+	// 带 cursor 参数（可为空串表示第一页）即进入游标模式
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		query.Cursor = &cursor
+	}
+	result, err := h.adminService.ListUsers(c, query)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(400, ErrInvalidCursor)
+			return
+		}
 		c.JSON(500, ErrDatabase)
 		return
 	}
-
 	// 转换为响应格式
-	userResponses := make([]UserResponse, 0, len(users))
-	for _, user := range users {
-		email := ""
-		if user.Email != nil {
-			email = *user.Email
-		}
+	userResponses := make([]UserResponse, 0, len(result.Users))
+	for _, user := range result.Users {
+		userResponses = append(userResponses, newUserResponse(user))
+	}
 
-		isActive := user.Status == "active"
-		createdAt := ""
-		updatedAt := ""
-		if !user.CreatedAt.IsZero() {
-			createdAt = user.CreatedAt.Format("2006-01-02T15:04:05Z")
-		}
-		if !user.UpdatedAt.IsZero() {
-			updatedAt = user.UpdatedAt.Format("2006-01-02T15:04:05Z")
-		}
+	c.JSON(200, NewListUsersResponse(userResponses, result, page, size, query.Cursor != nil))
+}
 
-		userResponses = append(userResponses, UserResponse{
-			UserID:    user.ID.String(),
-			Username:  user.Username,
-			Email:     email,
-			IsActive:  &isActive,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-		})
+func newUserResponse(user model.User) UserResponse {
+	email := ""
+	if user.Email != nil {
+		email = *user.Email
+	}
+
+	isActive := user.Status == "active"
+	createdAt := ""
+	updatedAt := ""
+	deletedAt := ""
+	if !user.CreatedAt.IsZero() {
+		createdAt = user.CreatedAt.Format("2006-01-02T15:04:05Z")
+	}
+	if !user.UpdatedAt.IsZero() {
+		updatedAt = user.UpdatedAt.Format("2006-01-02T15:04:05Z")
+	}
+	if user.DeletedAt.Valid {
+		deletedAt = user.DeletedAt.Time.Format("2006-01-02T15:04:05Z")
 	}
 
-	c.JSON(200, NewListUsersResponse(userResponses, int(total), page, size))
+	return UserResponse{
+		UserID:    user.ID.String(),
+		Username:  user.Username,
+		Email:     email,
+		IsActive:  &isActive,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		DeletedAt: deletedAt,
+	}
+}
+
+// ListDeletedUsers 已删除（保留期内可恢复）的用户列表（分页）
+func (h *AdminHandler) ListDeletedUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size < 1 || size > 100 {
+		size = 10
+	}
+
+	users, total, err := h.adminService.ListDeletedUsers(c, page, size)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	userResponses := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, newUserResponse(user))
+	}
+	c.JSON(200, NewListDeletedUsersResponse(userResponses, total, page, size))
+}
+
+// RestoreUser 恢复已删除的用户及随其一起删除的链接
+func (h *AdminHandler) RestoreUser(c *gin.Context) {
+	userIDstr := c.Param("userID")
+	userID, err := uuid.Parse(userIDstr)
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.adminService.RestoreUser(c, userID, adminID); err != nil {
+		writeUserActionError(c, err)
+		return
+	}
+	c.JSON(200, NewRestoreUserResponse(userIDstr))
 }
 
 func (h *AdminHandler) ActiveLink(c *gin.Context) {
@@ -149,7 +258,12 @@ func (h *AdminHandler) ActiveLink(c *gin.Context) {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
-	if err := h.adminService.ActiveLink(c, linkID); err != nil {
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.adminService.ActiveLink(c, linkID, adminID); err != nil {
 		c.JSON(500, ErrDatabase)
 		return
 	}
@@ -163,7 +277,12 @@ func (h *AdminHandler) UnactiveLink(c *gin.Context) {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
-	if err := h.adminService.UnactiveLink(c, linkID); err != nil {
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.adminService.UnactiveLink(c, linkID, adminID); err != nil {
 		c.JSON(500, ErrDatabase)
 		return
 	}
@@ -197,3 +316,394 @@ func (h *AdminHandler) GetRecentAccessLogs(c *gin.Context) {
 
 	c.JSON(200, NewListAccessLogsResponse(resp, len(resp)))
 }
+
+// ExportAccessLogs 流式导出全站访问日志（可用 link_id 过滤）
+func (h *AdminHandler) ExportAccessLogs(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(400, ErrUnsupportedFormat)
+		return
+	}
+	from, to, err := export.ParseTimeRange(c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		c.JSON(400, ErrInvalidTimeRange)
+		return
+	}
+
+	query := service.ExportAccessLogsQuery{From: from, To: to}
+	if linkIDStr := c.Query("link_id"); linkIDStr != "" {
+		linkID, err := strconv.ParseInt(linkIDStr, 10, 64)
+		if err != nil {
+			c.JSON(400, ErrInvalidRequest)
+			return
+		}
+		query.LinkID = &linkID
+	}
+	if err := h.adminService.CheckExportAccessLogs(query); err != nil {
+		c.JSON(400, ErrInvalidTimeRange)
+		return
+	}
+
+	fileName := export.FileName("access_logs", format, from, to)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(200)
+
+	err = export.Stream(c.Writer, format, func(fn func([]model.AccessLog) error) error {
+		return h.adminService.ExportAccessLogs(c, query, fn)
+	})
+	if err != nil {
+		log.Printf("Export access logs failed: %v", err)
+	}
+}
+
+// ListURLRules 目标域名黑白名单
+func (h *AdminHandler) ListURLRules(c *gin.Context) {
+	rules, err := h.urlRuleService.ListRules(c)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListURLRulesResponse(rules))
+}
+
+// CreateURLRule 添加黑白名单规则，pattern 为 example.com 或 *.example.com
+func (h *AdminHandler) CreateURLRule(c *gin.Context) {
+	var req CreateURLRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidURLRule)
+		return
+	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	rule, err := h.urlRuleService.CreateRule(c, service.CreateURLRuleCommand{
+		Pattern:   req.Pattern,
+		Action:    req.Action,
+		Reason:    req.Reason,
+		CreatedBy: adminID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidURLRule):
+			c.JSON(400, ErrInvalidURLRule)
+		case errors.Is(err, service.ErrURLRuleExists):
+			c.JSON(409, ErrURLRuleExists)
+		default:
+			c.JSON(500, ErrDatabase)
+		}
+		return
+	}
+	c.JSON(200, NewURLRuleResponse(rule))
+}
+
+// DeleteURLRule 删除黑白名单规则
+func (h *AdminHandler) DeleteURLRule(c *gin.Context) {
+	ruleID, err := strconv.ParseInt(c.Param("ruleID"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.urlRuleService.DeleteRule(c, ruleID); err != nil {
+		if errors.Is(err, service.ErrURLRuleNotFound) {
+			c.JSON(404, ErrURLRuleNotFound)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("删除规则成功"))
+}
+
+// ListPermissions 全部可授予的权限
+func (h *AdminHandler) ListPermissions(c *gin.Context) {
+	c.JSON(200, NewListPermissionsResponse())
+}
+
+// ListRoles 内置与自定义角色
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListRolesResponse(roles))
+}
+
+// CreateRole 创建自定义角色
+func (h *AdminHandler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRole)
+		return
+	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	role, err := h.roleService.CreateRole(c, adminID, service.CreateRoleCommand{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		writeRoleError(c, err)
+		return
+	}
+	c.JSON(200, NewRoleResponse(role, "创建角色成功"))
+}
+
+// UpdateRole 修改自定义角色的描述或权限，持有该角色的用户立即生效
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	cmd := service.UpdateRoleCommand{Description: req.Description}
+	if req.Permissions != nil {
+		cmd.Permissions = *req.Permissions
+		if cmd.Permissions == nil {
+			cmd.Permissions = []string{}
+		}
+	}
+	role, err := h.roleService.UpdateRole(c, c.Param("name"), adminID, cmd)
+	if err != nil {
+		writeRoleError(c, err)
+		return
+	}
+	c.JSON(200, NewRoleResponse(role, "更新角色成功"))
+}
+
+// DeleteRole 删除没有用户使用的自定义角色
+func (h *AdminHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c, c.Param("name")); err != nil {
+		writeRoleError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("删除角色成功"))
+}
+
+// AssignUserRole 修改用户角色，无需用户重新登录
+func (h *AdminHandler) AssignUserRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRole)
+		return
+	}
+	if err := h.roleService.AssignUserRole(c, userID, adminID, req.Role); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(404, ErrUserNotFound)
+		case errors.Is(err, service.ErrRoleNotFound):
+			c.JSON(400, ErrRoleNotFound)
+		case errors.Is(err, service.ErrChangeOwnRole):
+			c.JSON(400, ErrChangeOwnRole)
+		case errors.Is(err, service.ErrRoleEscalation):
+			c.JSON(403, ErrRoleEscalation)
+		default:
+			c.JSON(500, ErrDatabase)
+		}
+		return
+	}
+	c.JSON(200, NewSuccessResponse("修改用户角色成功"))
+}
+
+// ResetUserMFA 重置用户的两步验证（如丢失设备），角色要求两步验证的用户下次登录时重新绑定
+func (h *AdminHandler) ResetUserMFA(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if !h.authorizeUserAction(c, userID) {
+		return
+	}
+	if err := h.mfaService.Reset(c, userID); err != nil {
+		if errors.Is(err, service.ErrMFANotEnabled) {
+			c.JSON(404, ErrMFANotEnabled)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("已重置用户的两步验证"))
+}
+
+// ListUserSessions 用户当前有效的登录会话，按最近活跃时间倒序
+func (h *AdminHandler) ListUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	sessions, err := h.tokenService.ListSessions(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListSessionsResponse(sessions))
+}
+
+// RevokeUserSession 终止用户的某个会话，该会话的令牌立即失效
+func (h *AdminHandler) RevokeUserSession(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("sessionID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if !h.authorizeUserAction(c, userID) {
+		return
+	}
+	if err := h.tokenService.RevokeSession(c, userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(404, ErrSessionNotFound)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("会话已终止"))
+}
+
+// RevokeUserSessions 终止用户的全部会话（强制在所有设备上重新登录）
+func (h *AdminHandler) RevokeUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if !h.authorizeUserAction(c, userID) {
+		return
+	}
+	if err := h.tokenService.RevokeUserTokens(c, userID); err != nil {
+		c.JSON(500, ErrInternal)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("已终止用户的全部会话"))
+}
+
+// ListLockouts 当前因登录失败次数过多被锁定的用户名与 IP
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	lockouts, err := h.loginGuard.ListLockouts(c)
+	if err != nil {
+		c.JSON(500, ErrInternal)
+		return
+	}
+	c.JSON(200, NewListLockoutsResponse(lockouts))
+}
+
+// ClearLockout 解除锁定（scope 为 user 或 ip），同时清除失败计数
+func (h *AdminHandler) ClearLockout(c *gin.Context) {
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.loginGuard.ClearLockout(c, c.Param("scope"), c.Param("key"), adminID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLockoutScope):
+			c.JSON(400, ErrInvalidLockout)
+		case errors.Is(err, service.ErrLockoutNotFound):
+			c.JSON(404, ErrLockoutNotFound)
+		default:
+			c.JSON(500, ErrInternal)
+		}
+		return
+	}
+	c.JSON(200, NewSuccessResponse("已解除锁定"))
+}
+
+// ListAuthEvents 认证审计事件（登录失败、锁定、解除锁定），按时间倒序
+func (h *AdminHandler) ListAuthEvents(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil || size < 1 || size > 100 {
+		size = 20
+	}
+	filter := repository.AuthEventFilter{
+		Event:    c.Query("event"),
+		Username: c.Query("username"),
+		IP:       c.Query("ip"),
+	}
+	events, total, err := h.loginGuard.ListEvents(c, filter, page, size)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListAuthEventsResponse(events, total, page, size))
+}
+
+// authorizeUserAction 目标用户的角色超出操作者权限时返回 403
+func (h *AdminHandler) authorizeUserAction(c *gin.Context, userID uuid.UUID) bool {
+	adminID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return false
+	}
+	if err := h.adminService.AuthorizeUserAction(c, adminID, userID); err != nil {
+		writeUserActionError(c, err)
+		return false
+	}
+	return true
+}
+
+func writeUserActionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(404, ErrUserNotFound)
+	case errors.Is(err, service.ErrRoleEscalation):
+		c.JSON(403, ErrRoleEscalation)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+func writeRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		c.JSON(404, ErrRoleNotFound)
+	case errors.Is(err, service.ErrRoleExists):
+		c.JSON(409, ErrRoleExists)
+	case errors.Is(err, service.ErrInvalidRole):
+		c.JSON(400, ErrInvalidRole)
+	case errors.Is(err, service.ErrInvalidPermission):
+		c.JSON(400, NewErrorResponse(ErrInvalidPermission.Code, ErrInvalidPermission.Message, err.Error()))
+	case errors.Is(err, service.ErrBuiltinRole):
+		c.JSON(403, ErrBuiltinRole)
+	case errors.Is(err, service.ErrRoleInUse):
+		c.JSON(409, ErrRoleInUse)
+	case errors.Is(err, service.ErrChangeOwnRole):
+		c.JSON(403, ErrChangeOwnRole)
+	case errors.Is(err, service.ErrRoleEscalation):
+		c.JSON(403, ErrRoleEscalation)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
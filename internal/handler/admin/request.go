@@ -69,3 +69,28 @@ type ChangeAdminStatusRequest struct {
 	IDRequest
 	Status int `json:"status" binding:"required,oneof=0 1"`
 }
+
+// CreateURLRuleRequest 添加目标域名黑白名单规则
+type CreateURLRuleRequest struct {
+	Pattern string `json:"pattern" binding:"required,max=255"`
+	Action  string `json:"action" binding:"required,oneof=block allow"`
+	Reason  string `json:"reason" binding:"max=255"`
+}
+
+// CreateRoleRequest 创建自定义角色
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,role"`
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest 修改自定义角色，未传的字段不修改
+type UpdateRoleRequest struct {
+	Description *string   `json:"description" binding:"omitempty,max=200"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// AssignRoleRequest 修改用户角色
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,role"`
+}
@@ -1,5 +1,12 @@
 package admin
 
+import (
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"go-short/internal/service"
+	"time"
+)
+
 // BaseResponse 基础响应结构
 type BaseResponse struct {
 	Success bool   `json:"success"`
@@ -15,6 +22,7 @@ type UserResponse struct {
 	IsActive  *bool  `json:"is_active,omitempty"`
 	CreatedAt string `json:"created_at,omitempty"`
 	UpdatedAt string `json:"updated_at,omitempty"`
+	DeletedAt string `json:"deleted_at,omitempty"` // 仅已删除用户列表返回
 }
 
 // LinkResponse 链接操作响应
@@ -32,10 +40,13 @@ type LinkResponse struct {
 // ListUsersResponse 用户列表响应
 type ListUsersResponse struct {
 	BaseResponse
-	Users []UserResponse `json:"users"`
-	Total int            `json:"total"`
-	Page  int            `json:"page"`
-	Limit int            `json:"limit"`
+	Users          []UserResponse `json:"users"`
+	Total          *int64         `json:"total,omitempty"` // count=none 时省略
+	TotalEstimated bool           `json:"total_estimated,omitempty"`
+	Page           int            `json:"page,omitempty"` // 游标模式下省略
+	Limit          int            `json:"limit"`
+	NextCursor     string         `json:"next_cursor,omitempty"`
+	HasMore        *bool          `json:"has_more,omitempty"` // 仅游标模式返回
 }
 
 // ListLinksResponse 链接列表响应
@@ -97,6 +108,13 @@ func NewDeleteUserResponse(userID string) UserResponse {
 	}
 }
 
+func NewRestoreUserResponse(userID string) UserResponse {
+	return UserResponse{
+		BaseResponse: NewSuccessResponse("用户恢复成功"),
+		UserID:       userID,
+	}
+}
+
 func NewActivateUserResponse(userID string, isActive bool) UserResponse {
 	return UserResponse{
 		BaseResponse: NewSuccessResponse("用户状态更新成功"),
@@ -123,11 +141,34 @@ func NewUnactivateLinkResponse(linkID string, isActive bool) LinkResponse {
 }
 
 // 列表响应构造函数
-func NewListUsersResponse(users []UserResponse, total, page, limit int) ListUsersResponse {
+// NewListUsersResponse total < 0 表示未统计；cursorMode 时返回 next_cursor / has_more 而不是页码
+func NewListUsersResponse(users []UserResponse, p *service.UserPage, page, limit int, cursorMode bool) ListUsersResponse {
+	resp := ListUsersResponse{
+		BaseResponse:   NewSuccessResponse("获取用户列表成功"),
+		Users:          users,
+		TotalEstimated: p.Estimated && p.Total >= 0,
+		Page:           page,
+		Limit:          limit,
+	}
+	if p.Total >= 0 {
+		total := p.Total
+		resp.Total = &total
+	}
+	if cursorMode {
+		hasMore := p.NextCursor != ""
+		resp.Page = 0
+		resp.NextCursor = p.NextCursor
+		resp.HasMore = &hasMore
+	}
+	return resp
+}
+
+// NewListDeletedUsersResponse 已删除用户列表（offset 分页）
+func NewListDeletedUsersResponse(users []UserResponse, total int64, page, limit int) ListUsersResponse {
 	return ListUsersResponse{
-		BaseResponse: NewSuccessResponse("获取用户列表成功"),
+		BaseResponse: NewSuccessResponse("获取已删除用户列表成功"),
 		Users:        users,
-		Total:        total,
+		Total:        &total,
 		Page:         page,
 		Limit:        limit,
 	}
@@ -152,6 +193,232 @@ func NewListAccessLogsResponse(logs []AccessLogResponse, total int) ListAccessLo
 	}
 }
 
+// URLRuleItem 黑白名单规则
+type URLRuleItem struct {
+	ID        int64  `json:"id"`
+	Pattern   string `json:"pattern"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type URLRuleResponse struct {
+	BaseResponse
+	Rule URLRuleItem `json:"rule"`
+}
+
+type ListURLRulesResponse struct {
+	BaseResponse
+	Rules []URLRuleItem `json:"rules"`
+}
+
+func newURLRuleItem(r *model.URLRule) URLRuleItem {
+	return URLRuleItem{
+		ID:        r.ID,
+		Pattern:   r.Pattern,
+		Action:    r.Action,
+		Reason:    r.Reason,
+		CreatedBy: r.CreatedBy.String(),
+		CreatedAt: r.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func NewURLRuleResponse(r *model.URLRule) URLRuleResponse {
+	return URLRuleResponse{
+		BaseResponse: NewSuccessResponse("添加规则成功"),
+		Rule:         newURLRuleItem(r),
+	}
+}
+
+func NewListURLRulesResponse(rules []model.URLRule) ListURLRulesResponse {
+	items := make([]URLRuleItem, 0, len(rules))
+	for i := range rules {
+		items = append(items, newURLRuleItem(&rules[i]))
+	}
+	return ListURLRulesResponse{
+		BaseResponse: NewSuccessResponse("获取规则列表成功"),
+		Rules:        items,
+	}
+}
+
+type RoleItem struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"built_in"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type RoleResponse struct {
+	BaseResponse
+	Role RoleItem `json:"role"`
+}
+
+type ListRolesResponse struct {
+	BaseResponse
+	Roles []RoleItem `json:"roles"`
+}
+
+type ListPermissionsResponse struct {
+	BaseResponse
+	Permissions []string `json:"permissions"`
+}
+
+func newRoleItem(r *model.Role) RoleItem {
+	perms := r.EffectivePermissions()
+	if perms == nil {
+		perms = []string{}
+	}
+	return RoleItem{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: perms,
+		BuiltIn:     r.BuiltIn,
+		CreatedAt:   r.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   r.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func NewRoleResponse(r *model.Role, message string) RoleResponse {
+	return RoleResponse{
+		BaseResponse: NewSuccessResponse(message),
+		Role:         newRoleItem(r),
+	}
+}
+
+func NewListRolesResponse(roles []model.Role) ListRolesResponse {
+	items := make([]RoleItem, 0, len(roles))
+	for i := range roles {
+		items = append(items, newRoleItem(&roles[i]))
+	}
+	return ListRolesResponse{
+		BaseResponse: NewSuccessResponse("获取角色列表成功"),
+		Roles:        items,
+	}
+}
+
+func NewListPermissionsResponse() ListPermissionsResponse {
+	return ListPermissionsResponse{
+		BaseResponse: NewSuccessResponse("获取权限列表成功"),
+		Permissions:  model.Permissions,
+	}
+}
+
+// LockoutItem 被临时锁定的用户名或 IP
+type LockoutItem struct {
+	Scope       string `json:"scope"` // user | ip
+	Key         string `json:"key"`
+	ExpiresIn   int    `json:"expires_in"` // 剩余秒数
+	LockedUntil string `json:"locked_until"`
+}
+
+type ListLockoutsResponse struct {
+	BaseResponse
+	Lockouts []LockoutItem `json:"lockouts"`
+}
+
+func NewListLockoutsResponse(lockouts []repository.LoginLockout) ListLockoutsResponse {
+	items := make([]LockoutItem, 0, len(lockouts))
+	now := time.Now().UTC()
+	for _, l := range lockouts {
+		items = append(items, LockoutItem{
+			Scope:       l.Scope,
+			Key:         l.Key,
+			ExpiresIn:   int(l.ExpiresIn.Seconds()),
+			LockedUntil: now.Add(l.ExpiresIn).Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return ListLockoutsResponse{
+		BaseResponse: NewSuccessResponse("获取锁定列表成功"),
+		Lockouts:     items,
+	}
+}
+
+// SessionItem 用户的登录会话
+type SessionItem struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type ListSessionsResponse struct {
+	BaseResponse
+	Sessions []SessionItem `json:"sessions"`
+}
+
+func NewListSessionsResponse(sessions []model.Session) ListSessionsResponse {
+	items := make([]SessionItem, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, SessionItem{
+			ID:         s.ID.String(),
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+			LastSeenAt: s.LastSeenAt.UTC().Format("2006-01-02T15:04:05Z"),
+			ExpiresAt:  s.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return ListSessionsResponse{
+		BaseResponse: NewSuccessResponse("获取会话列表成功"),
+		Sessions:     items,
+	}
+}
+
+// AuthEventItem 认证审计事件
+type AuthEventItem struct {
+	ID        int64  `json:"id"`
+	Event     string `json:"event"`
+	Username  string `json:"username,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	ActorID   string `json:"actor_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListAuthEventsResponse struct {
+	BaseResponse
+	Events []AuthEventItem `json:"events"`
+	Total  int64           `json:"total"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+}
+
+func NewListAuthEventsResponse(events []model.AuthEvent, total int64, page, limit int) ListAuthEventsResponse {
+	items := make([]AuthEventItem, 0, len(events))
+	for _, e := range events {
+		item := AuthEventItem{
+			ID:        e.ID,
+			Event:     e.Event,
+			Username:  e.Username,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Reason:    e.Reason,
+			CreatedAt: e.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		if e.UserID != nil {
+			item.UserID = e.UserID.String()
+		}
+		if e.ActorID != nil {
+			item.ActorID = e.ActorID.String()
+		}
+		items = append(items, item)
+	}
+	return ListAuthEventsResponse{
+		BaseResponse: NewSuccessResponse("获取审计事件成功"),
+		Events:       items,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}
+}
+
 // 错误响应构造函数
 func NewErrorResponse(code, message, details string) ErrorResponse {
 	return ErrorResponse{
@@ -175,6 +442,24 @@ var (
 	ErrUserAlreadyExists = NewErrorResponse("USER_EXISTS", "用户已存在", "")
 	ErrForbidden         = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
 	ErrUnauthorized      = NewErrorResponse("UNAUTHORIZED", "未授权访问", "")
+	ErrUnsupportedFormat = NewErrorResponse("UNSUPPORTED_FORMAT", "不支持的导出格式", "")
+	ErrInvalidTimeRange  = NewErrorResponse("INVALID_TIME_RANGE", "时间范围无效", "")
+	ErrInvalidCursor     = NewErrorResponse("INVALID_CURSOR", "分页游标无效", "")
+	ErrInvalidURLRule    = NewErrorResponse("INVALID_URL_RULE", "规则格式无效", "pattern 为域名或 *.域名，action 为 block / allow")
+	ErrURLRuleExists     = NewErrorResponse("URL_RULE_EXISTS", "规则已存在", "")
+	ErrURLRuleNotFound   = NewErrorResponse("URL_RULE_NOT_FOUND", "规则不存在", "")
+	ErrRoleNotFound      = NewErrorResponse("ROLE_NOT_FOUND", "角色不存在", "")
+	ErrRoleExists        = NewErrorResponse("ROLE_EXISTS", "角色已存在", "")
+	ErrInvalidRole       = NewErrorResponse("INVALID_ROLE", "角色名无效", "小写字母开头，2-20 位小写字母、数字、- 或 _")
+	ErrBuiltinRole       = NewErrorResponse("BUILTIN_ROLE", "内置角色不能修改或删除", "")
+	ErrRoleInUse         = NewErrorResponse("ROLE_IN_USE", "角色仍有用户使用", "请先修改这些用户的角色")
+	ErrInvalidPermission = NewErrorResponse("INVALID_PERMISSION", "权限无效", "")
+	ErrChangeOwnRole     = NewErrorResponse("CHANGE_OWN_ROLE", "不能修改自己的角色", "")
+	ErrRoleEscalation    = NewErrorResponse("ROLE_ESCALATION", "不能授予或修改超出自己权限的角色", "admin 角色只能由 admin 授予")
+	ErrMFANotEnabled     = NewErrorResponse("MFA_NOT_ENABLED", "该用户未启用两步验证", "")
+	ErrLockoutNotFound   = NewErrorResponse("LOCKOUT_NOT_FOUND", "锁定不存在或已过期", "")
+	ErrInvalidLockout    = NewErrorResponse("INVALID_LOCKOUT_SCOPE", "锁定类型无效", "可选 user、ip")
+	ErrSessionNotFound   = NewErrorResponse("SESSION_NOT_FOUND", "会话不存在", "")
 
 	// 服务器错误 (5xx) - 系统错误
 	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
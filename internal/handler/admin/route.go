@@ -2,22 +2,51 @@ package admin
 
 import (
 	"go-short/internal/middleware"
+	"go-short/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RegisterRoutes 注册管理员相关路由
+// RegisterRoutes 注册管理员相关路由，每个路由按所需权限鉴权（权限来自用户当前角色）
 func RegisterRoutes(router *gin.RouterGroup, handler *AdminHandler) {
 	adminGroup := router.Group("/admin")
-	adminGroup.Use(middleware.AdminMiddleware())
+	adminGroup.Use(middleware.AuthMiddleware())
 	{
-		adminGroup.POST("/createUser", handler.CreateUser)
-		adminGroup.GET("/getUserList", handler.GetUsers)
-		adminGroup.DELETE("/delete/:userID", handler.DeleteUser)
-		adminGroup.PUT("/unactivateUser/:userID", handler.UnactiveUser)
-		adminGroup.PUT("/activateUser/:userID", handler.ActiveUser)
-		adminGroup.PUT("/activateLink/:linkID", handler.ActiveLink)
-		adminGroup.PUT("/unactivateLink/:linkID", handler.UnactiveLink)
-		adminGroup.GET("/recentLogs", handler.GetRecentAccessLogs)
+		users := middleware.RequirePermission(model.PermUsersManage)
+		adminGroup.POST("/createUser", users, handler.CreateUser)
+		adminGroup.GET("/getUserList", users, handler.GetUsers)
+		adminGroup.DELETE("/delete/:userID", users, handler.DeleteUser)
+		adminGroup.PUT("/unactivateUser/:userID", users, handler.UnactiveUser)
+		adminGroup.PUT("/activateUser/:userID", users, handler.ActiveUser)
+		adminGroup.GET("/deletedUsers", users, handler.ListDeletedUsers)
+		adminGroup.PUT("/restoreUser/:userID", users, handler.RestoreUser)
+		adminGroup.PUT("/users/:userID/role", users, handler.AssignUserRole)
+		adminGroup.DELETE("/users/:userID/mfa", users, handler.ResetUserMFA)
+		adminGroup.GET("/users/:userID/sessions", users, handler.ListUserSessions)
+		adminGroup.DELETE("/users/:userID/sessions", users, handler.RevokeUserSessions)
+		adminGroup.DELETE("/users/:userID/sessions/:sessionID", users, handler.RevokeUserSession)
+		adminGroup.GET("/lockouts", users, handler.ListLockouts)
+		adminGroup.DELETE("/lockouts/:scope/:key", users, handler.ClearLockout)
+
+		links := middleware.RequirePermission(model.PermLinksModerate)
+		adminGroup.PUT("/activateLink/:linkID", links, handler.ActiveLink)
+		adminGroup.PUT("/unactivateLink/:linkID", links, handler.UnactiveLink)
+
+		logs := middleware.RequirePermission(model.PermLogsRead)
+		adminGroup.GET("/recentLogs", logs, handler.GetRecentAccessLogs)
+		adminGroup.GET("/export", logs, handler.ExportAccessLogs)
+		adminGroup.GET("/authEvents", logs, handler.ListAuthEvents)
+
+		urlRules := middleware.RequirePermission(model.PermURLRulesManage)
+		adminGroup.GET("/urlRules", urlRules, handler.ListURLRules)
+		adminGroup.POST("/urlRules", urlRules, handler.CreateURLRule)
+		adminGroup.DELETE("/urlRules/:ruleID", urlRules, handler.DeleteURLRule)
+
+		roles := middleware.RequirePermission(model.PermRolesManage)
+		adminGroup.GET("/permissions", roles, handler.ListPermissions)
+		adminGroup.GET("/roles", roles, handler.ListRoles)
+		adminGroup.POST("/roles", roles, handler.CreateRole)
+		adminGroup.PATCH("/roles/:name", roles, handler.UpdateRole)
+		adminGroup.DELETE("/roles/:name", roles, handler.DeleteRole)
 	}
 }
@@ -2,21 +2,32 @@ package auth
 
 import (
 	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"go-short/internal/model"
 	"go-short/internal/service"
 	"go-short/internal/util"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// AuthHandler 负责认证相关接口：注册、登录
+// AuthHandler 负责认证相关接口：注册、登录、SSO 登录、邮箱验证与找回密码、刷新令牌、登出
 type AuthHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
+	oidcService  *service.OIDCService
+	mfaService   *service.MFAService
+	loginGuard   *service.LoginGuardService
+	emailService *service.EmailService
 }
 
-func NewAuthHandler(userService *service.UserService) *AuthHandler {
-	return &AuthHandler{userService: userService}
+func NewAuthHandler(userService *service.UserService, tokenService *service.TokenService, oidcService *service.OIDCService, mfaService *service.MFAService, loginGuard *service.LoginGuardService, emailService *service.EmailService) *AuthHandler {
+	return &AuthHandler{userService: userService, tokenService: tokenService, oidcService: oidcService, mfaService: mfaService, loginGuard: loginGuard, emailService: emailService}
 }
 
 // Register 用户注册
@@ -41,6 +52,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(500, ErrDatabase)
 		return
 	}
+	h.emailService.QueueVerification(user.ID, h.emailService.Language(c.GetHeader("Accept-Language")))
 	email := ""
 	if user.Email != nil {
 		email = *user.Email
@@ -48,29 +60,354 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(200, NewRegisterResponse(user.ID, user.Username, email))
 }
 
-// Login 用户登录
+// Login 用户登录。用户名与 IP 的失败次数按滑动窗口计数，超过阈值后渐进延迟直至临时锁定（429）
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, ErrInvalidRequest)
 		return
 	}
+	reservation, ok := h.checkLoginGuard(c, req.Username)
+	if !ok {
+		return
+	}
+	attempt := service.LoginAttempt{Username: req.Username, IP: c.ClientIP(), UserAgent: c.Request.UserAgent(), ReservationID: reservation}
+
 	user, err := h.userService.GetUserByUsername(c, req.Username)
 	if err != nil {
+		attempt.Reason = "unknown_user"
+		h.loginGuard.RecordFailure(c, attempt)
 		c.JSON(401, ErrInvalidCredentials)
 		return
 	}
+	attempt.UserID = &user.ID
 
 	if !util.VerifyPassword(req.Password, user.PasswordHash) {
+		attempt.Reason = "invalid_password"
+		h.loginGuard.RecordFailure(c, attempt)
 		c.JSON(401, ErrInvalidCredentials)
 		return
 	}
-	// 生成 JWT
-	token, err := util.GenerateToken(user.ID, user.Username, user.Role, 24*time.Hour)
+	// 密码正确后才提示已禁用，避免暴露账号状态
+	if user.Status != "active" {
+		attempt.Reason = "user_disabled"
+		h.loginGuard.RecordFailure(c, attempt)
+		c.JSON(403, ErrUserDisabled)
+		return
+	}
+	// 密码正确，撤销本次尝试的预占，不计入失败
+	h.loginGuard.Release(c, req.Username, attempt.IP, reservation)
+	// 已启用两步验证（或角色要求两步验证）时只返回挑战令牌，通过 /auth/mfa/* 完成登录
+	challenge, err := h.mfaService.BeginLogin(c, user)
 	if err != nil {
+		if errors.Is(err, service.ErrUserDisabled) {
+			c.JSON(403, ErrUserDisabled)
+			return
+		}
+		c.JSON(500, ErrInternal)
+		return
+	}
+	if challenge != nil {
+		c.JSON(200, NewMFAChallengeResponse(challenge))
+		return
+	}
+	h.issueTokens(c, user)
+}
+
+// checkLoginGuard 用户名或 IP 被锁定、处于延迟期时返回 429 与 Retry-After；Redis 不可用时返回 503。
+// 通过时返回本次尝试的预占 id
+func (h *AuthHandler) checkLoginGuard(c *gin.Context, username string) (string, bool) {
+	reservation, wait, err := h.loginGuard.Check(c, username, c.ClientIP())
+	if err == nil {
+		return reservation, true
+	}
+	writeLoginGuardError(c, wait, err)
+	return "", false
+}
+
+// checkMFALoginGuard 两步验证前检查挑战对应的用户名与 IP 是否已被锁定（不预占）：
+// 锁定期间不允许继续用已拿到的挑战穷举验证码；挑战无效时按两步验证错误返回
+func (h *AuthHandler) checkMFALoginGuard(c *gin.Context, challengeToken string) bool {
+	user, err := h.mfaService.ChallengeUser(c, challengeToken)
+	if err != nil {
+		writeMFAError(c, err)
+		return false
+	}
+	wait, err := h.loginGuard.CheckBlocked(c, user.Username, c.ClientIP())
+	if err != nil {
+		writeLoginGuardError(c, wait, err)
+		return false
+	}
+	return true
+}
+
+func writeLoginGuardError(c *gin.Context, wait time.Duration, err error) {
+	switch {
+	case errors.Is(err, service.ErrLoginLocked):
+		c.Header("Retry-After", retryAfter(wait))
+		c.JSON(429, ErrLoginLocked)
+	case errors.Is(err, service.ErrLoginThrottled):
+		c.Header("Retry-After", retryAfter(wait))
+		c.JSON(429, ErrLoginThrottled)
+	default:
+		log.Printf("⚠️ Login guard unavailable: %v", err)
+		c.JSON(503, ErrServiceUnavailable)
+	}
+}
+
+// retryAfter 向上取整到秒
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int((d + time.Second - 1) / time.Second))
+}
+
+// issueTokens 签发短期 access token 与 refresh token，成功后清除该用户名的登录失败计数
+func (h *AuthHandler) issueTokens(c *gin.Context, user *model.User) {
+	pair, err := h.tokenService.IssueTokens(c, user, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrUserDisabled) {
+			c.JSON(403, ErrUserDisabled)
+			return
+		}
 		c.JSON(500, ErrGenerateJWT)
 		return
 	}
+	h.loginGuard.RecordSuccess(c, user.Username)
+	c.JSON(200, NewLoginResponse(pair))
+}
+
+// MFAVerify 登录第二步：提交验证码或恢复码换取令牌
+func (h *AuthHandler) MFAVerify(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if !h.checkMFALoginGuard(c, req.MFAToken) {
+		return
+	}
+	user, err := h.mfaService.VerifyLogin(c, req.MFAToken, req.Code)
+	if err != nil {
+		// 验证码错误计入该用户名的失败次数，防止拿到密码后无限次重新登录来穷举验证码
+		if user != nil && (errors.Is(err, service.ErrMFAInvalidCode) || errors.Is(err, service.ErrMFAInvalidChallenge)) {
+			h.loginGuard.RecordFailure(c, service.LoginAttempt{
+				Username:  user.Username,
+				UserID:    &user.ID,
+				IP:        c.ClientIP(),
+				UserAgent: c.Request.UserAgent(),
+				Reason:    "invalid_mfa_code",
+			})
+		}
+		writeMFAError(c, err)
+		return
+	}
+	h.issueTokens(c, user)
+}
+
+// MFAEnroll 角色要求两步验证但尚未绑定时，在登录过程中生成密钥
+func (h *AuthHandler) MFAEnroll(c *gin.Context) {
+	var req MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	enrollment, err := h.mfaService.EnrollWithChallenge(c, req.MFAToken)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewMFAEnrollmentResponse(enrollment))
+}
+
+// MFAConfirm 登录过程中确认绑定，启用两步验证后签发令牌并返回恢复码
+func (h *AuthHandler) MFAConfirm(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if !h.checkMFALoginGuard(c, req.MFAToken) {
+		return
+	}
+	user, codes, err := h.mfaService.ConfirmWithChallenge(c, req.MFAToken, req.Code)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	pair, err := h.tokenService.IssueTokens(c, user, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrUserDisabled) {
+			c.JSON(403, ErrUserDisabled)
+			return
+		}
+		c.JSON(500, ErrGenerateJWT)
+		return
+	}
+	h.loginGuard.RecordSuccess(c, user.Username)
+	resp := NewLoginResponse(pair)
+	resp.RecoveryCodes = codes
+	c.JSON(200, resp)
+}
+
+func writeMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFAInvalidChallenge):
+		c.JSON(401, ErrMFAInvalidChallenge)
+	case errors.Is(err, service.ErrMFAInvalidCode):
+		c.JSON(401, ErrMFAInvalidCode)
+	case errors.Is(err, service.ErrMFAEnrollmentPending):
+		c.JSON(400, ErrMFAEnrollmentPending)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		c.JSON(409, ErrMFAAlreadyEnabled)
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		c.JSON(400, ErrMFANotEnrolled)
+	case errors.Is(err, service.ErrUserDisabled), errors.Is(err, service.ErrUserNotFound):
+		c.JSON(403, ErrUserDisabled)
+	default:
+		c.JSON(500, ErrInternal)
+	}
+}
+
+// SSOProviders 已配置的 SSO 提供方
+func (h *AuthHandler) SSOProviders(c *gin.Context) {
+	c.JSON(200, NewSSOProvidersResponse(h.oidcService.ListProviders()))
+}
+
+// ssoStateCookie 发起 SSO 登录的浏览器保存 state，回调时必须一致
+const ssoStateCookie = "goshort_sso_state"
+
+// setSSOStateCookie 只发往该提供方的回调路径；SameSite=Lax 保证从 IdP 跳回（顶级 GET 导航）时会携带
+func setSSOStateCookie(c *gin.Context, value string, maxAge int) {
+	path := strings.TrimSuffix(c.Request.URL.Path, "/login")
+	path = strings.TrimSuffix(path, "/callback")
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, value, maxAge, path, "", secure, true)
+}
+
+// SSOLogin 跳转到 IdP 授权页（授权码 + PKCE），state 同时写入 Cookie 与发起登录的浏览器绑定
+func (h *AuthHandler) SSOLogin(c *gin.Context) {
+	authURL, state, err := h.oidcService.StartLogin(c, c.Param("provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCProviderNotFound):
+			c.JSON(404, ErrSSOProviderNotFound)
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			log.Printf("⚠️ SSO login start failed: %v", err)
+			c.JSON(502, ErrSSOUnavailable)
+		default:
+			c.JSON(500, ErrInternal)
+		}
+		return
+	}
+	setSSOStateCookie(c, state, int(service.OIDCStateTTL/time.Second))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// SSOCallback IdP 回调：校验 state 与 ID Token，找到或创建本地用户后按需进行两步验证，再签发令牌
+func (h *AuthHandler) SSOCallback(c *gin.Context) {
+	cookieState, _ := c.Cookie(ssoStateCookie)
+	setSSOStateCookie(c, "", -1) // state 一次性，无论成功与否都清除
+	if idpErr := c.Query("error"); idpErr != "" {
+		c.JSON(401, NewErrorResponse(ErrSSOLoginFailed.Code, ErrSSOLoginFailed.Message, idpErr+": "+c.Query("error_description")))
+		return
+	}
+	user, err := h.oidcService.CompleteLogin(c, c.Param("provider"), c.Query("state"), cookieState, c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCProviderNotFound):
+			c.JSON(404, ErrSSOProviderNotFound)
+		case errors.Is(err, service.ErrOIDCInvalidState):
+			c.JSON(400, ErrSSOInvalidState)
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			log.Printf("⚠️ SSO login failed: %v", err)
+			c.JSON(401, ErrSSOLoginFailed)
+		case errors.Is(err, service.ErrUserDisabled):
+			c.JSON(403, ErrUserDisabled)
+		default:
+			c.JSON(500, ErrInternal)
+		}
+		return
+	}
+	// 与密码登录相同：已启用两步验证（或角色要求两步验证）时只返回挑战令牌
+	challenge, err := h.mfaService.BeginLogin(c, user)
+	if err != nil {
+		if errors.Is(err, service.ErrUserDisabled) {
+			c.JSON(403, ErrUserDisabled)
+			return
+		}
+		c.JSON(500, ErrInternal)
+		return
+	}
+	if challenge != nil {
+		c.JSON(200, NewMFAChallengeResponse(challenge))
+		return
+	}
+	h.issueTokens(c, user)
+}
+
+// Refresh 用 refresh token 换发新的 access token 与 refresh token（旧 refresh token 立即失效）
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	pair, err := h.tokenService.Refresh(c, req.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			c.JSON(401, ErrRefreshTokenReused)
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			c.JSON(401, ErrInvalidRefreshToken)
+		case errors.Is(err, service.ErrUserDisabled):
+			c.JSON(403, ErrUserDisabled)
+		default:
+			c.JSON(500, ErrGenerateJWT)
+		}
+		return
+	}
+	resp := NewLoginResponse(pair)
+	resp.Message = "Token refreshed"
+	c.JSON(200, resp)
+}
+
+// Logout 吊销当前 access token 及请求体中的 refresh token；all=true 时退出全部设备
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, ErrInvalidRequest)
+			return
+		}
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	cmd := service.LogoutCommand{
+		UserID:       userID,
+		JTI:          c.GetString("jti"),
+		SessionID:    c.GetString("sid"),
+		RefreshToken: req.RefreshToken,
+		All:          req.All,
+	}
+	if exp, ok := c.Get("token_exp"); ok {
+		cmd.ExpiresAt, _ = exp.(time.Time)
+	}
+	if err := h.tokenService.Logout(c, cmd); err != nil {
+		c.JSON(500, ErrInternal)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("Logged out"))
+}
+
+// JWKS 签发 access token 的公钥，供其他服务按 kid 验签；仅配置了 HS256 密钥时 keys 为空
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, h.tokenService.JWKS())
+}
 
-	c.JSON(200, NewLoginResponse(token, 24*3600))
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
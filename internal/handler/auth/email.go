@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"log"
+
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// VerifyEmail 提交邮件中的验证链接令牌。链接指向前端页面，由前端 POST 提交，避免邮件安全扫描预取链接时消耗令牌
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.emailService.VerifyEmail(c, req.Token); err != nil {
+		writeEmailError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("Email verified"))
+}
+
+// ResendVerification 重新发送验证邮件（同步发送，失败时返回错误）
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.emailService.SendVerification(c, userID, h.emailService.Language(c.GetHeader("Accept-Language"))); err != nil {
+		writeEmailError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("Verification email sent"))
+}
+
+// ForgotPassword 发送重置密码邮件。无论邮箱是否注册都返回相同响应
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	if err := h.emailService.ForgotPassword(c, req.Email, h.emailService.Language(c.GetHeader("Accept-Language"))); err != nil {
+		c.JSON(500, ErrInternal)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("If the email is registered, a password reset link has been sent"))
+}
+
+// ResetPassword 凭重置链接令牌设置新密码，成功后吊销该用户已签发的全部令牌
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := h.emailService.ResetPassword(c, req.Token, req.NewPassword)
+	if err != nil {
+		writeEmailError(c, err)
+		return
+	}
+	if err := h.tokenService.RevokeUserTokens(c, userID); err != nil {
+		log.Printf("⚠️ Revoke tokens after password reset for user %s failed: %v", userID, err)
+	}
+	c.JSON(200, NewSuccessResponse("Password has been reset, please log in again"))
+}
+
+func writeEmailError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidEmailToken):
+		c.JSON(400, ErrInvalidEmailToken)
+	case errors.Is(err, service.ErrEmailTokenExpired):
+		c.JSON(400, ErrEmailTokenExpired)
+	case errors.Is(err, service.ErrEmailNotSet):
+		c.JSON(400, ErrEmailNotSet)
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		c.JSON(409, ErrEmailAlreadyVerified)
+	case errors.Is(err, service.ErrMailTooFrequent):
+		c.JSON(429, ErrMailTooFrequent)
+	case errors.Is(err, service.ErrUserDisabled):
+		c.JSON(403, ErrUserDisabled)
+	default:
+		c.JSON(500, ErrInternal)
+	}
+}
@@ -9,3 +9,35 @@ type LoginRequest struct {
 	Username string `json:"username" binding:"required,username"`
 	Password string `json:"password" binding:"required"`
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required,max=128"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"max=128"` // 可选，同时吊销该登录会话
+	All          bool   `json:"all"`                             // 退出全部设备
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required,max=128"`
+	Code     string `json:"code" binding:"required,max=32"` // 6 位验证码或恢复码
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required,max=128"`
+}
+
+// TokenRequest 邮件链接中的令牌
+type TokenRequest struct {
+	Token string `json:"token" binding:"required,max=1024"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=128"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required,max=1024"`
+	NewPassword string `json:"new_password" binding:"required,new_password"`
+}
@@ -1,6 +1,11 @@
 package auth
 
-import "github.com/google/uuid"
+import (
+	"encoding/base64"
+	"go-short/internal/service"
+
+	"github.com/google/uuid"
+)
 
 // BaseResponse 基础响应结构
 type BaseResponse struct {
@@ -23,6 +28,31 @@ type LoginResponse struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	ExpiresIn    int    `json:"expires_in"`
 	TokenType    string `json:"token_type"`
+	// RecoveryCodes 登录过程中完成两步验证绑定时返回，只返回这一次
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse 需要两步验证时 Login 返回的挑战（不含令牌）
+type MFAChallengeResponse struct {
+	BaseResponse
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+// MFAEnrollmentResponse 两步验证绑定信息
+type MFAEnrollmentResponse struct {
+	BaseResponse
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // data:image/png;base64,...
+}
+
+// SSOProvidersResponse 可用的 SSO 提供方
+type SSOProvidersResponse struct {
+	BaseResponse
+	Providers []string `json:"providers"`
 }
 
 type ErrorResponse struct {
@@ -49,12 +79,43 @@ func NewRegisterResponse(userID uuid.UUID, username, email string) RegisterRespo
 	return resp
 }
 
-func NewLoginResponse(accessToken string, expiresIn int) LoginResponse {
+func NewSSOProvidersResponse(providers []string) SSOProvidersResponse {
+	return SSOProvidersResponse{
+		BaseResponse: NewSuccessResponse("OK"),
+		Providers:    providers,
+	}
+}
+
+func NewMFAChallengeResponse(challenge *service.MFAChallenge) MFAChallengeResponse {
+	message := "MFA code required"
+	if challenge.EnrollmentRequired {
+		message = "MFA enrollment required"
+	}
+	return MFAChallengeResponse{
+		BaseResponse:       NewSuccessResponse(message),
+		MFARequired:        true,
+		EnrollmentRequired: challenge.EnrollmentRequired,
+		MFAToken:           challenge.Token,
+		ExpiresIn:          challenge.ExpiresIn,
+	}
+}
+
+func NewMFAEnrollmentResponse(enrollment *service.MFAEnrollment) MFAEnrollmentResponse {
+	return MFAEnrollmentResponse{
+		BaseResponse: NewSuccessResponse("Scan the QR code with an authenticator app, then confirm with a code"),
+		Secret:       enrollment.Secret,
+		OTPAuthURI:   enrollment.OTPAuthURI,
+		QRCode:       "data:image/png;base64," + base64.StdEncoding.EncodeToString(enrollment.QRCode),
+	}
+}
+
+func NewLoginResponse(pair *service.TokenPair) LoginResponse {
 	resp := LoginResponse{
 		BaseResponse: NewSuccessResponse("Login successful"),
 	}
-	resp.AccessToken = accessToken
-	resp.ExpiresIn = expiresIn
+	resp.AccessToken = pair.AccessToken
+	resp.RefreshToken = pair.RefreshToken
+	resp.ExpiresIn = pair.ExpiresIn
 	resp.TokenType = "Bearer"
 	return resp
 }
@@ -74,15 +135,36 @@ type ErrorCode string
 
 var (
 	// 客户端错误 (4xx) - 业务逻辑错误
-	ErrInvalidRequest     = NewErrorResponse("BAD_REQUEST", "请求无效", "")
-	ErrUserExists         = NewErrorResponse("USER_EXISTS", "用户已存在", "")
-	ErrInvalidCredentials = NewErrorResponse("INVALID_CREDENTIALS", "用户名或密码错误", "")
+	ErrInvalidRequest       = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrUserExists           = NewErrorResponse("USER_EXISTS", "用户已存在", "")
+	ErrInvalidCredentials   = NewErrorResponse("INVALID_CREDENTIALS", "用户名或密码错误", "")
+	ErrUserDisabled         = NewErrorResponse("USER_DISABLED", "用户已被禁用", "")
+	ErrInvalidRefreshToken  = NewErrorResponse("INVALID_REFRESH_TOKEN", "刷新令牌无效或已过期", "")
+	ErrRefreshTokenReused   = NewErrorResponse("REFRESH_TOKEN_REUSED", "刷新令牌已被使用，该登录会话已全部失效，请重新登录", "")
+	ErrMFAInvalidChallenge  = NewErrorResponse("MFA_CHALLENGE_INVALID", "两步验证已过期或失败次数过多，请重新登录", "")
+	ErrMFAInvalidCode       = NewErrorResponse("MFA_INVALID_CODE", "验证码错误", "")
+	ErrMFAEnrollmentPending = NewErrorResponse("MFA_ENROLLMENT_REQUIRED", "需要先完成两步验证绑定", "")
+	ErrMFAAlreadyEnabled    = NewErrorResponse("MFA_ALREADY_ENABLED", "已启用两步验证", "")
+	ErrMFANotEnrolled       = NewErrorResponse("MFA_NOT_ENROLLED", "请先生成两步验证密钥", "")
+	ErrSSOProviderNotFound  = NewErrorResponse("SSO_PROVIDER_NOT_FOUND", "SSO 提供方不存在", "")
+	ErrSSOInvalidState      = NewErrorResponse("SSO_INVALID_STATE", "SSO 登录状态无效或已过期，请重新登录", "")
+	ErrSSOLoginFailed       = NewErrorResponse("SSO_LOGIN_FAILED", "SSO 登录失败", "")
+	ErrSSOUnavailable       = NewErrorResponse("SSO_UNAVAILABLE", "无法连接 SSO 提供方", "")
+	ErrLoginLocked          = NewErrorResponse("LOGIN_LOCKED", "登录失败次数过多，已被临时锁定", "请在 Retry-After 秒后重试或联系管理员解除")
+	ErrLoginThrottled       = NewErrorResponse("TOO_MANY_ATTEMPTS", "登录尝试过于频繁", "请在 Retry-After 秒后重试")
+	ErrInvalidEmailToken    = NewErrorResponse("INVALID_TOKEN", "链接无效或已使用", "")
+	ErrEmailTokenExpired    = NewErrorResponse("TOKEN_EXPIRED", "链接已过期，请重新获取", "")
+	ErrEmailNotSet          = NewErrorResponse("EMAIL_NOT_SET", "未设置邮箱", "")
+	ErrEmailAlreadyVerified = NewErrorResponse("EMAIL_ALREADY_VERIFIED", "邮箱已验证", "")
+	ErrMailTooFrequent      = NewErrorResponse("TOO_MANY_REQUESTS", "邮件发送过于频繁，请稍后再试", "")
 
 	// 服务器错误 (5xx) - 系统错误
 	ErrPasswordHash = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
 	ErrDatabase     = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
 	ErrInternal     = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
 	ErrGenerateJWT  = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+
+	ErrServiceUnavailable = NewErrorResponse("SERVICE_UNAVAILABLE", "服务暂时不可用，请稍后重试", "")
 )
 
 func NewError(code ErrorCode, message string) ErrorResponse {
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"go-short/internal/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,5 +12,22 @@ func RegisterRoutes(router *gin.RouterGroup, handler *AuthHandler) {
 	{
 		authGroup.POST("/register", handler.Register)
 		authGroup.POST("/login", handler.Login)
+		authGroup.POST("/mfa/verify", handler.MFAVerify)
+		authGroup.POST("/mfa/enroll", handler.MFAEnroll)
+		authGroup.POST("/mfa/confirm", handler.MFAConfirm)
+		authGroup.POST("/verify-email", handler.VerifyEmail)
+		authGroup.POST("/verify-email/resend", middleware.AuthMiddleware(), handler.ResendVerification)
+		authGroup.POST("/forgot-password", handler.ForgotPassword)
+		authGroup.POST("/reset-password", handler.ResetPassword)
+		authGroup.GET("/sso/providers", handler.SSOProviders)
+		authGroup.GET("/sso/:provider/login", handler.SSOLogin)
+		authGroup.GET("/sso/:provider/callback", handler.SSOCallback)
+		authGroup.POST("/refresh", handler.Refresh)
+		authGroup.POST("/logout", middleware.AuthMiddleware(), handler.Logout)
 	}
 }
+
+// RegisterWellKnownRoutes 注册根路径下的公开端点（不在 /api/v1 下）
+func RegisterWellKnownRoutes(router gin.IRoutes, handler *AuthHandler) {
+	router.GET("/.well-known/jwks.json", handler.JWKS)
+}
@@ -0,0 +1,64 @@
+package conversion
+
+import (
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConversionHandler struct {
+	conversionService *service.ConversionService
+}
+
+func NewConversionHandler(conversionService *service.ConversionService) *ConversionHandler {
+	return &ConversionHandler{conversionService: conversionService}
+}
+
+// Record 上报转化事件（click_id 为重定向时追加到目标 URL 的 gs_cid 参数），只能上报自己有编辑权限的链接
+func (h *ConversionHandler) Record(c *gin.Context) {
+	var req RecordConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	conversion, err := h.conversionService.RecordConversion(c, service.RecordConversionCommand{
+		UserID:     userID,
+		IsAdmin:    middleware.HasPermission(c, model.PermLinksModerate),
+		ClickToken: req.ClickID,
+		Event:      req.Event,
+		Value:      req.Value,
+		Currency:   req.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidClickID) {
+			c.JSON(400, ErrInvalidClickID)
+			return
+		}
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		if errors.Is(err, service.ErrConversionDuplicate) {
+			c.JSON(409, ErrConversionDuplicate)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	c.JSON(200, NewConversionResponse(conversion))
+}
@@ -0,0 +1,9 @@
+package conversion
+
+// RecordConversionRequest 转化上报请求
+type RecordConversionRequest struct {
+	ClickID  string  `json:"click_id" binding:"required,max=256"`
+	Event    string  `json:"event" binding:"omitempty,max=64"`
+	Value    float64 `json:"value" binding:"omitempty,min=0"`
+	Currency string  `json:"currency" binding:"omitempty,len=3"`
+}
@@ -0,0 +1,71 @@
+package conversion
+
+import (
+	"go-short/internal/model"
+	"time"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// ConversionResponse 转化上报响应
+type ConversionResponse struct {
+	BaseResponse
+	ConversionID int64     `json:"conversion_id"`
+	LinkID       int64     `json:"link_id"`
+	Event        string    `json:"event"`
+	ClickedAt    time.Time `json:"clicked_at"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func NewConversionResponse(conversion *model.Conversion) ConversionResponse {
+	return ConversionResponse{
+		BaseResponse: NewSuccessResponse("转化记录成功"),
+		ConversionID: conversion.ID,
+		LinkID:       conversion.LinkID,
+		Event:        conversion.Event,
+		ClickedAt:    conversion.ClickedAt,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest      = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidClickID      = NewErrorResponse("INVALID_CLICK_ID", "点击 ID 无效", "")
+	ErrLinkNotFound        = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden           = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+	ErrConversionDuplicate = NewErrorResponse("CONVERSION_EXISTS", "转化事件已记录", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
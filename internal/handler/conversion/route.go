@@ -0,0 +1,13 @@
+package conversion
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册转化上报路由：落地页服务端使用带 conversions:write 范围的个人 API Key（也接受 JWT）
+func RegisterRoutes(r *gin.RouterGroup, handler *ConversionHandler) {
+	r.POST("/conversions", middleware.AuthMiddleware(model.ScopeConversionsWrite), handler.Record)
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DomainHandler struct {
+	domainService *service.DomainService
+}
+
+func NewDomainHandler(domainService *service.DomainService) *DomainHandler {
+	return &DomainHandler{domainService: domainService}
+}
+
+// writeError 将 Service 错误映射为响应
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDomain):
+		c.JSON(400, ErrInvalidDomain)
+	case errors.Is(err, service.ErrDomainExists):
+		c.JSON(409, ErrDomainExists)
+	case errors.Is(err, service.ErrDomainNotFound):
+		c.JSON(404, ErrDomainNotFound)
+	case errors.Is(err, service.ErrDomainVerifyFailed):
+		c.JSON(422, NewErrorResponse("DOMAIN_VERIFY_FAILED", "未找到匹配的 TXT 验证记录", err.Error()))
+	case errors.Is(err, service.ErrDomainInUse):
+		c.JSON(409, ErrDomainInUse)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// List 获取当前用户的自定义域名
+func (h *DomainHandler) List(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	domains, err := h.domainService.ListDomains(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListDomainsResponse(domains))
+}
+
+// Create 添加自定义域名，返回需要配置的 TXT 记录
+func (h *DomainHandler) Create(c *gin.Context) {
+	var req CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	domain, err := h.domainService.AddDomain(c, userID, req.Hostname)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewDomainResponse(domain, "域名已添加，请配置 TXT 记录后验证"))
+}
+
+// Verify 查询 DNS TXT 记录验证域名所有权
+func (h *DomainHandler) Verify(c *gin.Context) {
+	domainID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || domainID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	domain, err := h.domainService.VerifyDomain(c, domainID, userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewDomainResponse(domain, "域名验证成功"))
+}
+
+// Delete 删除自定义域名（域名下仍有链接时拒绝）
+func (h *DomainHandler) Delete(c *gin.Context) {
+	domainID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || domainID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	if err := h.domainService.DeleteDomain(c, domainID, userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("域名删除成功"))
+}
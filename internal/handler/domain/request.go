@@ -0,0 +1,6 @@
+package domain
+
+// CreateDomainRequest 添加自定义域名请求
+type CreateDomainRequest struct {
+	Hostname string `json:"hostname" binding:"required,max=253"`
+}
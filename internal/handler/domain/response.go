@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"go-short/internal/model"
+	"go-short/internal/service"
+	"time"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// DomainItem 自定义域名
+type DomainItem struct {
+	ID         int64      `json:"id"`
+	Hostname   string     `json:"hostname"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	TXTName    string     `json:"txt_name"`  // 需要添加的 TXT 记录名
+	TXTValue   string     `json:"txt_value"` // TXT 记录值
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// DomainResponse 单个域名响应
+type DomainResponse struct {
+	BaseResponse
+	DomainItem
+}
+
+// ListDomainsResponse 域名列表响应
+type ListDomainsResponse struct {
+	BaseResponse
+	Domains []DomainItem `json:"domains"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func newDomainItem(domain *model.Domain) DomainItem {
+	record := service.VerificationRecord(domain)
+	return DomainItem{
+		ID:         domain.ID,
+		Hostname:   domain.Hostname,
+		Verified:   domain.VerifiedAt != nil,
+		VerifiedAt: domain.VerifiedAt,
+		TXTName:    record.RecordName,
+		TXTValue:   record.RecordValue,
+		CreatedAt:  domain.CreatedAt,
+	}
+}
+
+func NewDomainResponse(domain *model.Domain, message string) DomainResponse {
+	return DomainResponse{
+		BaseResponse: NewSuccessResponse(message),
+		DomainItem:   newDomainItem(domain),
+	}
+}
+
+func NewListDomainsResponse(domains []model.Domain) ListDomainsResponse {
+	items := make([]DomainItem, 0, len(domains))
+	for i := range domains {
+		items = append(items, newDomainItem(&domains[i]))
+	}
+	return ListDomainsResponse{
+		BaseResponse: NewSuccessResponse("获取域名列表成功"),
+		Domains:      items,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID  = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrInvalidDomain  = NewErrorResponse("INVALID_DOMAIN", "域名格式无效", "")
+	ErrDomainExists   = NewErrorResponse("DOMAIN_EXISTS", "域名已被绑定", "")
+	ErrDomainNotFound = NewErrorResponse("DOMAIN_NOT_FOUND", "域名不存在", "")
+	ErrDomainInUse    = NewErrorResponse("DOMAIN_IN_USE", "域名下仍有短链接，无法删除", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"go-short/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册自定义域名相关路由
+func RegisterRoutes(r *gin.RouterGroup, handler *DomainHandler) {
+	domainsGroup := r.Group("/domains")
+	domainsGroup.Use(middleware.AuthMiddleware())
+	{
+		domainsGroup.GET("", handler.List)
+		domainsGroup.POST("", handler.Create)
+		domainsGroup.POST("/:id/verify", handler.Verify)
+		domainsGroup.DELETE("/:id", handler.Delete)
+	}
+}
@@ -0,0 +1,147 @@
+package folder
+
+import (
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FolderHandler struct {
+	folderService *service.FolderService
+}
+
+func NewFolderHandler(folderService *service.FolderService) *FolderHandler {
+	return &FolderHandler{folderService: folderService}
+}
+
+// writeError 将 Service 错误映射为响应
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFolderName):
+		c.JSON(400, ErrInvalidFolderName)
+	case errors.Is(err, service.ErrFolderExists):
+		c.JSON(409, ErrFolderExists)
+	case errors.Is(err, service.ErrFolderNotFound):
+		c.JSON(404, ErrFolderNotFound)
+	case errors.Is(err, service.ErrLinkNotFound):
+		c.JSON(404, ErrLinkNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(403, ErrForbidden)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// List 获取当前用户的文件夹
+func (h *FolderHandler) List(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	folders, err := h.folderService.ListFolders(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListFoldersResponse(folders))
+}
+
+// Create 创建文件夹
+func (h *FolderHandler) Create(c *gin.Context) {
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(c, userID, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewFolderResponse(folder, "文件夹创建成功"))
+}
+
+// Rename 重命名文件夹
+func (h *FolderHandler) Rename(c *gin.Context) {
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || folderID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	folder, err := h.folderService.RenameFolder(c, folderID, userID, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewFolderResponse(folder, "文件夹已重命名"))
+}
+
+// Delete 删除文件夹（链接保留，移出文件夹）
+func (h *FolderHandler) Delete(c *gin.Context) {
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || folderID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(c, folderID, userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("文件夹删除成功"))
+}
+
+// MoveLink 将链接移入/移出文件夹
+func (h *FolderHandler) MoveLink(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	var req MoveLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	if err := h.folderService.MoveLink(c, linkID, userID, isAdmin, req.FolderID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("链接已移动"))
+}
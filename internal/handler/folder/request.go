@@ -0,0 +1,11 @@
+package folder
+
+// FolderRequest 创建/重命名文件夹请求
+type FolderRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// MoveLinkRequest 移动链接请求（folder_id 为 null 表示移出文件夹）
+type MoveLinkRequest struct {
+	FolderID *int64 `json:"folder_id" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,95 @@
+package folder
+
+import (
+	"go-short/internal/model"
+	"time"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// FolderItem 文件夹
+type FolderItem struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FolderResponse 单个文件夹响应
+type FolderResponse struct {
+	BaseResponse
+	FolderItem
+}
+
+// ListFoldersResponse 文件夹列表响应
+type ListFoldersResponse struct {
+	BaseResponse
+	Folders []FolderItem `json:"folders"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func newFolderItem(folder *model.Folder) FolderItem {
+	return FolderItem{ID: folder.ID, Name: folder.Name, CreatedAt: folder.CreatedAt}
+}
+
+func NewFolderResponse(folder *model.Folder, message string) FolderResponse {
+	return FolderResponse{
+		BaseResponse: NewSuccessResponse(message),
+		FolderItem:   newFolderItem(folder),
+	}
+}
+
+func NewListFoldersResponse(folders []model.Folder) ListFoldersResponse {
+	items := make([]FolderItem, 0, len(folders))
+	for i := range folders {
+		items = append(items, newFolderItem(&folders[i]))
+	}
+	return ListFoldersResponse{
+		BaseResponse: NewSuccessResponse("获取文件夹列表成功"),
+		Folders:      items,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest    = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID     = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrInvalidFolderName = NewErrorResponse("INVALID_FOLDER_NAME", "文件夹名无效", "")
+	ErrFolderExists      = NewErrorResponse("FOLDER_EXISTS", "文件夹已存在", "")
+	ErrFolderNotFound    = NewErrorResponse("FOLDER_NOT_FOUND", "文件夹不存在", "")
+	ErrLinkNotFound      = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden         = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
@@ -0,0 +1,21 @@
+package folder
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册文件夹相关路由
+func RegisterRoutes(r *gin.RouterGroup, handler *FolderHandler) {
+	foldersGroup := r.Group("/folders")
+	foldersGroup.Use(middleware.AuthMiddleware())
+	{
+		foldersGroup.GET("", handler.List)
+		foldersGroup.POST("", handler.Create)
+		foldersGroup.PATCH("/:id", handler.Rename)
+		foldersGroup.DELETE("/:id", handler.Delete)
+	}
+	r.PUT("/links/:id/folder", middleware.AuthMiddleware(model.ScopeLinksWrite), handler.MoveLink)
+}
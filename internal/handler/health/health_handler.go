@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// GetHealth 链接最近一次健康检查结果
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	h.handle(c, h.healthService.GetLinkHealth, "获取健康检查结果成功")
+}
+
+// Recheck 立即重新检查链接目标地址（同步返回结果，一分钟内重复调用返回上次结果）
+func (h *HealthHandler) Recheck(c *gin.Context) {
+	h.handle(c, h.healthService.CheckLink, "检查完成")
+}
+
+func (h *HealthHandler) handle(c *gin.Context, fn func(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.LinkHealth, error), message string) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	health, err := fn(c, linkID, userID, isAdmin)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLinkNotFound):
+			c.JSON(404, ErrLinkNotFound)
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(403, ErrForbidden)
+		default:
+			c.JSON(500, ErrInternal)
+		}
+		return
+	}
+	c.JSON(200, NewHealthResponse(linkID, health, message))
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"go-short/internal/model"
+	"time"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthItem 链接目标地址健康检查结果
+type HealthItem struct {
+	StatusCode          int       `json:"status_code"` // 0 表示请求失败
+	FinalURL            string    `json:"final_url,omitempty"`
+	LatencyMs           int64     `json:"latency_ms"`
+	Error               string    `json:"error,omitempty"`
+	Broken              bool      `json:"broken"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CheckedAt           time.Time `json:"checked_at"`
+}
+
+// HealthResponse 未检查过时 health 为 null
+type HealthResponse struct {
+	BaseResponse
+	LinkID int64       `json:"link_id"`
+	Health *HealthItem `json:"health"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// NewHealthItem 转换检查结果，h 为 nil 时返回 nil
+func NewHealthItem(h *model.LinkHealth) *HealthItem {
+	if h == nil {
+		return nil
+	}
+	return &HealthItem{
+		StatusCode:          h.StatusCode,
+		FinalURL:            h.FinalURL,
+		LatencyMs:           h.LatencyMs,
+		Error:               h.Error,
+		Broken:              h.Broken,
+		ConsecutiveFailures: h.ConsecutiveFailures,
+		CheckedAt:           h.CheckedAt,
+	}
+}
+
+func NewHealthResponse(linkID int64, h *model.LinkHealth, message string) HealthResponse {
+	return HealthResponse{
+		BaseResponse: BaseResponse{Success: true, Message: message},
+		LinkID:       linkID,
+		Health:       NewHealthItem(h),
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID  = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrLinkNotFound   = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden      = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrInternal = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
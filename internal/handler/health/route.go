@@ -0,0 +1,14 @@
+package health
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册链接健康检查路由
+func RegisterRoutes(r *gin.RouterGroup, handler *HealthHandler) {
+	r.GET("/links/:id/health", middleware.AuthMiddleware(model.ScopeLinksRead), handler.GetHealth)
+	r.POST("/links/:id/health", middleware.AuthMiddleware(model.ScopeLinksWrite), handler.Recheck)
+}
@@ -0,0 +1,159 @@
+package link
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxBulkBodySize 批量导入请求体上限
+const maxBulkBodySize = 32 << 20
+
+// bulkRow 批量导入中的一行，err 非空表示该行解析失败（不影响其他行）
+type bulkRow struct {
+	req CreateLinkRequest
+	err error
+}
+
+// readBulkRows 按 Content-Type 读取 JSON 或 CSV
+func readBulkRows(c *gin.Context) ([]bulkRow, error) {
+	contentType := c.ContentType()
+	switch {
+	case contentType == "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parseBulkCSV(f)
+	case contentType == "text/csv":
+		return parseBulkCSV(c.Request.Body)
+	default:
+		return parseBulkJSON(c.Request.Body)
+	}
+}
+
+// parseBulkJSON 支持 [...] 和 {"links": [...]} 两种格式
+func parseBulkJSON(r io.Reader) ([]bulkRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var reqs []CreateLinkRequest
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &reqs)
+	} else {
+		var body BulkCreateLinksRequest
+		err = json.Unmarshal(data, &body)
+		reqs = body.Links
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]bulkRow, len(reqs))
+	for i := range reqs {
+		rows[i].req = reqs[i]
+	}
+	return rows, nil
+}
+
+// parseBulkCSV 首行为表头，必须包含 url 列；可选列 alias、short_code、expires_at（RFC3339）、status、track_conversions
+func parseBulkCSV(r io.Reader) ([]bulkRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("missing url column")
+	}
+
+	var rows []bulkRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, parseBulkCSVRecord(get))
+	}
+	return rows, nil
+}
+
+func parseBulkCSVRecord(get func(string) string) bulkRow {
+	var row bulkRow
+	row.req.URL = get("url")
+	if v := get("alias"); v != "" {
+		row.req.Alias = &v
+	}
+	if v := get("short_code"); v != "" {
+		row.req.ShortCode = &v
+	}
+	if v := get("expires_at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			row.err = errors.New("expires_at 格式错误（需 RFC3339）")
+			return row
+		}
+		row.req.ExpiresAt = &t
+	}
+	if v := get("status"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			row.err = errors.New("status 格式错误")
+			return row
+		}
+		row.req.Status = &b
+	}
+	if v := get("track_conversions"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			row.err = errors.New("track_conversions 格式错误")
+			return row
+		}
+		row.req.TrackConversions = &b
+	}
+	return row
+}
+
+// describeValidationError 将校验错误转换为可读的行级错误
+func describeValidationError(err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return errors.New("参数校验失败")
+	}
+	fields := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, fmt.Sprintf("%s(%s)", fe.Field(), fe.Tag()))
+	}
+	return errors.New("参数校验失败: " + strings.Join(fields, ", "))
+}
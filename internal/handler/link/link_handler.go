@@ -2,22 +2,38 @@ package link
 
 import (
 	"errors"
+	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"go-short/internal/export"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
 	"go-short/internal/service"
+	"go-short/internal/urlcheck"
+	"go-short/internal/util"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 )
 
 type LinkHandler struct {
-	linkService *service.LinkService
+	linkService   *service.LinkService
+	bulkService   *service.BulkService
+	tagService    *service.TagService
+	healthService *service.HealthService
 }
 
-func NewLinkHandler(linkService *service.LinkService) *LinkHandler {
+func NewLinkHandler(linkService *service.LinkService, bulkService *service.BulkService, tagService *service.TagService, healthService *service.HealthService) *LinkHandler {
 	return &LinkHandler{
-		linkService: linkService,
+		linkService:   linkService,
+		bulkService:   bulkService,
+		tagService:    tagService,
+		healthService: healthService,
 	}
 }
 
@@ -38,15 +54,8 @@ func (h *LinkHandler) Create(c *gin.Context) {
 	}
 
 	// 调用 Service 层处理业务逻辑
-	cmd := service.CreateLinkCommand{
-		OriginalURL: req.URL,
-		Alias:       req.Alias,
-		ShortCode:   req.ShortCode,
-		Status:      req.Status,
-		ExpiresAt:   req.ExpiresAt,
-		UserID:      userID,
-	}
-
+	cmd := newCreateLinkCommand(&req, userID)
+	cmd.WorkspaceID = activeWorkspace(c)
 	link, err := h.linkService.CreateLink(c, cmd)
 	if err != nil {
 		// 检查是否是已存在链接
@@ -55,7 +64,7 @@ func (h *LinkHandler) Create(c *gin.Context) {
 			if baseURL == "" {
 				baseURL = "http://localhost:8080"
 			}
-			shortURL := baseURL + "/code/" + link.ShortCode
+			shortURL := util.ShortURL(baseURL, link.Domain, link.ShortCode)
 			c.JSON(200, NewLinkExistsResponse(link, shortURL))
 			return
 		}
@@ -64,6 +73,21 @@ func (h *LinkHandler) Create(c *gin.Context) {
 			c.JSON(400, ErrShortCodeDuplicate)
 			return
 		}
+		if writeWorkspaceError(c, err) {
+			return
+		}
+		if resp, ok := domainErrorResponse(err); ok {
+			c.JSON(400, resp)
+			return
+		}
+		if resp, ok := unsafeURLResponse(err); ok {
+			c.JSON(422, resp)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidURL) {
+			c.JSON(400, ErrInvalidRequest)
+			return
+		}
 		c.JSON(500, ErrDatabase)
 		return
 	}
@@ -72,12 +96,181 @@ func (h *LinkHandler) Create(c *gin.Context) {
 	if baseURL == "" {
 		baseURL = "http://localhost:8080"
 	}
-	shortURL := baseURL + "/code/" + link.ShortCode
+	shortURL := util.ShortURL(baseURL, link.Domain, link.ShortCode)
 
 	c.JSON(200, NewCreateLinkResponse(link, shortURL))
 }
 
-// GetLinks 获取用户的链接列表
+// newCreateLinkCommand 将创建请求转换为 Service 命令（单条与批量共用）
+func newCreateLinkCommand(req *CreateLinkRequest, userID uuid.UUID) service.CreateLinkCommand {
+	cmd := service.CreateLinkCommand{
+		OriginalURL: req.URL,
+		Alias:       req.Alias,
+		ShortCode:   req.ShortCode,
+		Status:      req.Status,
+		ExpiresAt:   req.ExpiresAt,
+		UserID:      userID,
+		Domain:      req.Domain,
+	}
+	if req.TrackConversions != nil {
+		cmd.TrackConversions = *req.TrackConversions
+	}
+	return cmd
+}
+
+// activeWorkspace 当前工作区（X-Workspace-ID 请求头，由认证中间件解析），nil 表示个人链接
+func activeWorkspace(c *gin.Context) *int64 {
+	if id := c.GetInt64("workspace_id"); id > 0 {
+		return &id
+	}
+	return nil
+}
+
+// writeWorkspaceError 非工作区成员返回 404，角色权限不足返回 403
+func writeWorkspaceError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.JSON(404, ErrWorkspaceNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(403, ErrForbidden)
+	default:
+		return false
+	}
+	return true
+}
+
+// domainErrorResponse 自定义域名校验失败对应的响应
+func domainErrorResponse(err error) (ErrorResponse, bool) {
+	switch {
+	case errors.Is(err, service.ErrDomainNotFound):
+		return ErrDomainNotFound, true
+	case errors.Is(err, service.ErrDomainNotVerified):
+		return ErrDomainNotVerified, true
+	}
+	return ErrorResponse{}, false
+}
+
+// unsafeURLResponse 目标地址未通过安全检查，details 中返回拒绝原因
+func unsafeURLResponse(err error) (ErrorResponse, bool) {
+	if !errors.Is(err, service.ErrUnsafeURL) {
+		return ErrorResponse{}, false
+	}
+	details := ""
+	var blocked *urlcheck.BlockedError
+	if errors.As(err, &blocked) {
+		details = blocked.Reason
+	}
+	return NewErrorResponse("UNSAFE_URL", "目标地址不安全", details), true
+}
+
+// BulkCreate 批量创建短链接：JSON 数组 / {"links": [...]}，或上传 CSV（multipart 字段 file，或 text/csv 请求体）
+// 不超过 service.BulkSyncLimit 行同步返回逐行结果，超过（或 async=true）则转为后台任务返回 202
+// 所有行使用同一个域名（查询参数 domain，默认域名为空）
+func (h *LinkHandler) BulkCreate(c *gin.Context) {
+	uidStr := c.GetString("uid")
+	userID, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkBodySize)
+	rows, err := readBulkRows(c)
+	if err != nil {
+		c.JSON(400, ErrInvalidBulkFile)
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(400, ErrBulkEmpty)
+		return
+	}
+	if len(rows) > service.BulkMaxRows {
+		c.JSON(400, ErrBulkTooLarge)
+		return
+	}
+
+	// 逐行使用与单条创建相同的校验规则
+	items := make([]service.BulkLinkItem, len(rows))
+	for i := range rows {
+		if rows[i].err != nil {
+			items[i].Invalid = rows[i].err
+			continue
+		}
+		if err := binding.Validator.ValidateStruct(&rows[i].req); err != nil {
+			items[i].Invalid = describeValidationError(err)
+			continue
+		}
+		items[i].Command = newCreateLinkCommand(&rows[i].req, userID)
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	domain := c.Query("domain")
+	if len(items) > service.BulkSyncLimit || c.Query("async") == "true" {
+		job, err := h.bulkService.StartBulkJob(c, userID, activeWorkspace(c), domain, items)
+		if err != nil {
+			if writeWorkspaceError(c, err) {
+				return
+			}
+			if resp, ok := domainErrorResponse(err); ok {
+				c.JSON(400, resp)
+				return
+			}
+			c.JSON(500, ErrDatabase)
+			return
+		}
+		resp := NewBulkJobResponse(job, baseURL)
+		resp.Message = "导入任务已创建"
+		c.Header("Location", "/api/v1/links/bulk/jobs/"+job.ID)
+		c.JSON(202, resp)
+		return
+	}
+
+	results, err := h.bulkService.BulkCreateLinks(c, userID, activeWorkspace(c), domain, items)
+	if err != nil {
+		if writeWorkspaceError(c, err) {
+			return
+		}
+		if resp, ok := domainErrorResponse(err); ok {
+			c.JSON(400, resp)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewBulkCreateResponse(results, util.NormalizeHost(domain), baseURL))
+}
+
+// GetBulkJob 查询后台导入任务进度（完成后包含逐行结果）
+func (h *LinkHandler) GetBulkJob(c *gin.Context) {
+	uidStr := c.GetString("uid")
+	userID, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	job, err := h.bulkService.GetBulkJob(c, c.Param("jobID"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkJobNotFound) {
+			c.JSON(404, ErrBulkJobNotFound)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	c.JSON(200, NewBulkJobResponse(job, baseURL))
+}
+
+// GetLinks 获取用户的链接列表（支持标签/文件夹/状态/过期/创建时间/关键字筛选，排序与分页大小）
 func (h *LinkHandler) GetLinks(c *gin.Context) {
 	uidStr := c.GetString("uid")
 	userID, err := uuid.Parse(uidStr)
@@ -91,7 +284,67 @@ func (h *LinkHandler) GetLinks(c *gin.Context) {
 		page = 1 // 容错：非法值重置为第一页
 	}
 
-	list, total, err := h.linkService.GetLinksByUser(c, userID, page, 10)
+	var req ListLinksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	query := service.ListLinksQuery{
+		UserID:      userID,
+		WorkspaceID: activeWorkspace(c),
+		Domain:      req.Domain,
+		Tag:         req.Tag,
+		Expired:     req.Expired,
+		Query:       req.Q,
+		CreatedFrom: req.CreatedFrom,
+		Sort:        req.Sort,
+		Asc:         req.Order == "asc",
+		Page:        page,
+		Size:        req.PageSize,
+	}
+	if req.FolderID != nil {
+		if *req.FolderID == 0 {
+			query.NoFolder = true
+		} else {
+			query.FolderID = req.FolderID
+		}
+	}
+	if req.Status != "" {
+		active := req.Status == "active"
+		query.Status = &active
+	}
+	if req.CreatedTo != nil {
+		createdTo := req.CreatedTo.AddDate(0, 0, 1)
+		query.CreatedTo = &createdTo
+	}
+	if query.Size == 0 {
+		query.Size = service.DefaultLinkPageSize
+	}
+	query.Count = req.Count
+	// 带 cursor 参数（可为空串表示第一页）即进入游标模式，否则保持原有 page 分页
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		query.Cursor = &cursor
+	}
+
+	result, err := h.linkService.ListLinks(c, query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(400, ErrInvalidCursor)
+			return
+		}
+		if writeWorkspaceError(c, err) {
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	tags, err := h.tagService.GetTagsByLinks(c, result.Links)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	health, err := h.healthService.GetHealthByLinks(c, result.Links)
 	if err != nil {
 		c.JSON(500, ErrDatabase)
 		return
@@ -101,7 +354,7 @@ func (h *LinkHandler) GetLinks(c *gin.Context) {
 	if baseURL == "" {
 		baseURL = "http://localhost:8080"
 	}
-	c.JSON(200, NewListLinksResponse(list, total, page, 10, baseURL))
+	c.JSON(200, NewLinkPageResponse(result, tags, health, page, query.Size, query.Cursor != nil, baseURL))
 }
 
 // GetLinksByAlias 根据用户短链接别名获取链接列表
@@ -123,7 +376,23 @@ func (h *LinkHandler) GetLinksByAlias(c *gin.Context) {
 		page = 1 // 容错：非法值重置为第一页
 	}
 
-	list, total, err := h.linkService.GetLinksByUserAlias(c, userID, alias, page, 10)
+	query := service.ListLinksQuery{UserID: userID, WorkspaceID: activeWorkspace(c), Alias: alias, Page: page, Size: 10, Count: c.Query("count")}
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		query.Cursor = &cursor
+	}
+	result, err := h.linkService.ListLinks(c, query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(400, ErrInvalidCursor)
+			return
+		}
+		if writeWorkspaceError(c, err) {
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	health, err := h.healthService.GetHealthByLinks(c, result.Links)
 	if err != nil {
 		c.JSON(500, ErrDatabase)
 		return
@@ -133,7 +402,186 @@ func (h *LinkHandler) GetLinksByAlias(c *gin.Context) {
 	if baseURL == "" {
 		baseURL = "http://localhost:8080"
 	}
-	c.JSON(200, NewListLinksResponse(list, total, page, 10, baseURL))
+	c.JSON(200, NewLinkPageResponse(result, nil, health, page, 10, query.Cursor != nil, baseURL))
+}
+
+// Update 编辑短链接（PATCH，乐观锁：If-Match 头或请求体 version 必须与当前版本一致）
+func (h *LinkHandler) Update(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	var req UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	version, ok := parseIfMatch(c.GetHeader("If-Match"))
+	if !ok && req.Version != nil {
+		version, ok = *req.Version, true
+	}
+	if !ok {
+		c.JSON(428, ErrVersionRequired)
+		return
+	}
+
+	uidStr := c.GetString("uid")
+	userID, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	cmd := service.UpdateLinkCommand{
+		LinkID:           linkID,
+		UserID:           userID,
+		IsAdmin:          isAdmin,
+		Version:          version,
+		OriginalURL:      req.URL,
+		Alias:            req.Alias,
+		ShortCode:        req.ShortCode,
+		Status:           req.Status,
+		ExpiresAt:        req.ExpiresAt,
+		ClearExpiresAt:   req.ClearExpiresAt,
+		TrackConversions: req.TrackConversions,
+	}
+	link, err := h.linkService.UpdateLink(c, cmd)
+	if err != nil {
+		writeUpdateError(c, err)
+		return
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	shortURL := util.ShortURL(baseURL, link.Domain, link.ShortCode)
+
+	c.Header("ETag", `"`+strconv.FormatInt(link.Version, 10)+`"`)
+	c.JSON(200, NewUpdateLinkResponse(link, shortURL))
+}
+
+// writeUpdateError 编辑 / 回滚失败时的错误响应
+func writeUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrLinkNotFound):
+		c.JSON(404, ErrLinkNotFound)
+	case errors.Is(err, service.ErrRevisionNotFound):
+		c.JSON(404, ErrRevisionNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(403, ErrForbidden)
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(412, ErrVersionConflict)
+	case errors.Is(err, service.ErrShortCodeExists):
+		c.JSON(400, ErrShortCodeDuplicate)
+	case errors.Is(err, service.ErrInvalidURL):
+		c.JSON(400, ErrInvalidRequest)
+	case errors.Is(err, service.ErrUnsafeURL):
+		resp, _ := unsafeURLResponse(err)
+		c.JSON(422, resp)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// History 链接变更记录（按时间倒序，分页）
+func (h *LinkHandler) History(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || size < 1 || size > 100 {
+		size = 20
+	}
+
+	revisions, total, err := h.linkService.GetLinkHistory(c, linkID, userID, middleware.HasPermission(c, model.PermLinksModerate), page, size)
+	if err != nil {
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewLinkHistoryResponse(revisions, total, page, size))
+}
+
+// Rollback 回滚到指定版本（目标地址、别名、启停、过期时间、转化追踪；短码不回滚），If-Match 或 version 可选
+func (h *LinkHandler) Rollback(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	var req RollbackLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	cmd := service.RollbackLinkCommand{
+		LinkID:     linkID,
+		RevisionID: req.RevisionID,
+		UserID:     userID,
+		IsAdmin:    middleware.HasPermission(c, model.PermLinksModerate),
+		Version:    req.Version,
+	}
+	if version, ok := parseIfMatch(c.GetHeader("If-Match")); ok {
+		cmd.Version = &version
+	}
+	link, err := h.linkService.RollbackLink(c, cmd)
+	if err != nil {
+		writeUpdateError(c, err)
+		return
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	resp := NewUpdateLinkResponse(link, util.ShortURL(baseURL, link.Domain, link.ShortCode))
+	resp.Message = "短链接已回滚"
+	c.Header("ETag", `"`+strconv.FormatInt(link.Version, 10)+`"`)
+	c.JSON(200, resp)
+}
+
+// parseIfMatch 解析 If-Match 头中的版本号，支持 "3"、W/"3" 和 3
+func parseIfMatch(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+	header = strings.TrimPrefix(header, "W/")
+	header = strings.Trim(header, `"`)
+	version, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
 }
 
 // Delete 根据ID删除短链接
@@ -151,7 +599,7 @@ func (h *LinkHandler) Delete(c *gin.Context) {
 		c.JSON(400, ErrInvalidUserID)
 		return
 	}
-	isAdmin := c.GetString("role") == "admin"
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
 	err = h.linkService.DeleteLink(c, linkID, userID, isAdmin)
 	if err != nil {
 		if errors.Is(err, service.ErrLinkNotFound) {
@@ -168,3 +616,146 @@ func (h *LinkHandler) Delete(c *gin.Context) {
 
 	c.JSON(200, NewDeleteLinkResponse())
 }
+
+// ListTrash 回收站中的链接（按删除时间倒序）
+func (h *LinkHandler) ListTrash(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || size < 1 || size > 100 {
+		size = 10
+	}
+
+	links, total, err := h.linkService.ListTrash(c, userID, activeWorkspace(c), page, size)
+	if err != nil {
+		if writeWorkspaceError(c, err) {
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	c.JSON(200, NewTrashResponse(links, total, page, size, baseURL))
+}
+
+// Restore 从回收站恢复链接
+func (h *LinkHandler) Restore(c *gin.Context) {
+	h.trashAction(c, func(linkID int64, userID uuid.UUID, isAdmin bool) error {
+		link, err := h.linkService.RestoreLink(c, linkID, userID, isAdmin)
+		if err != nil {
+			return err
+		}
+		baseURL := os.Getenv("BASE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+		c.JSON(200, NewRestoreLinkResponse(link, util.ShortURL(baseURL, link.Domain, link.ShortCode)))
+		return nil
+	})
+}
+
+// Purge 彻底删除回收站中的链接（不可恢复，短码释放）
+func (h *LinkHandler) Purge(c *gin.Context) {
+	h.trashAction(c, func(linkID int64, userID uuid.UUID, isAdmin bool) error {
+		if err := h.linkService.PurgeLink(c, linkID, userID, isAdmin); err != nil {
+			return err
+		}
+		c.JSON(200, NewPurgeLinkResponse())
+		return nil
+	})
+}
+
+// trashAction 解析链接ID与当前用户后执行回收站操作，统一处理错误响应
+func (h *LinkHandler) trashAction(c *gin.Context, fn func(linkID int64, userID uuid.UUID, isAdmin bool) error) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	if err := fn(linkID, userID, middleware.HasPermission(c, model.PermLinksModerate)); err != nil {
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// Export 流式导出链接的访问日志（format=csv|ndjson|parquet，from/to 为日期或 RFC3339）
+func (h *LinkHandler) Export(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	uidStr := c.GetString("uid")
+	userID, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(400, ErrUnsupportedFormat)
+		return
+	}
+	from, to, err := export.ParseTimeRange(c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		c.JSON(400, ErrInvalidTimeRange)
+		return
+	}
+
+	query := service.ExportAccessLogsQuery{LinkID: &linkID, From: from, To: to}
+	if err := h.linkService.CheckExportLinkAccessLogs(c, userID, isAdmin, query); err != nil {
+		if errors.Is(err, service.ErrInvalidTimeRange) {
+			c.JSON(400, ErrInvalidTimeRange)
+			return
+		}
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	fileName := export.FileName("access_logs_"+strconv.FormatInt(linkID, 10), format, from, to)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(200)
+
+	// 响应头已写出，中途失败只能记录日志并中断连接
+	err = export.Stream(c.Writer, format, func(fn func([]model.AccessLog) error) error {
+		return h.linkService.ExportLinkAccessLogs(c, query, fn)
+	})
+	if err != nil {
+		log.Printf("Export access logs failed: link=%d, err=%v", linkID, err)
+	}
+}
@@ -4,9 +4,50 @@ import "time"
 
 // CreateLinkRequest 创建短链接请求
 type CreateLinkRequest struct {
-	URL       string     `json:"url" binding:"required,url"`
-	Alias     *string    `json:"alias"`
-	ExpiresAt *time.Time `json:"expires_at" binding:"omitempty,expiry_time"`
-	Status    *bool      `json:"status"`
-	ShortCode *string    `json:"short_code" binding:"omitempty,short_code"`
+	URL              string     `json:"url" binding:"required,url"`
+	Alias            *string    `json:"alias"`
+	ExpiresAt        *time.Time `json:"expires_at" binding:"omitempty,expiry_time"`
+	Status           *bool      `json:"status"`
+	ShortCode        *string    `json:"short_code" binding:"omitempty,short_code"`
+	TrackConversions *bool      `json:"track_conversions"`                  // 重定向时追加签名点击 ID（gs_cid）
+	Domain           string     `json:"domain" binding:"omitempty,max=253"` // 已验证的自定义域名，空表示默认域名
+}
+
+// UpdateLinkRequest 编辑短链接请求（字段为空表示不修改）
+type UpdateLinkRequest struct {
+	URL              *string    `json:"url" binding:"omitempty,url"`
+	Alias            *string    `json:"alias" binding:"omitempty,max=100"`
+	ExpiresAt        *time.Time `json:"expires_at" binding:"omitempty,expiry_time"`
+	ClearExpiresAt   bool       `json:"clear_expires_at"` // 取消过期时间
+	Status           *bool      `json:"status"`
+	ShortCode        *string    `json:"short_code" binding:"omitempty,short_code"`
+	TrackConversions *bool      `json:"track_conversions"`
+	Version          *int64     `json:"version"` // 未携带 If-Match 时使用
+}
+
+// RollbackLinkRequest 回滚到指定版本
+type RollbackLinkRequest struct {
+	RevisionID int64  `json:"revision_id" binding:"required,min=1"`
+	Version    *int64 `json:"version"` // 可选，未携带 If-Match 时使用
+}
+
+// BulkCreateLinksRequest 批量创建请求（JSON 也可以直接传数组）
+type BulkCreateLinksRequest struct {
+	Links []CreateLinkRequest `json:"links"`
+}
+
+// ListLinksRequest 链接列表查询参数
+type ListLinksRequest struct {
+	PageSize    int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Domain      *string    `form:"domain" binding:"omitempty,max=253"` // 传空值（domain=）表示默认域名
+	Tag         string     `form:"tag" binding:"omitempty,max=50"`
+	FolderID    *int64     `form:"folder_id" binding:"omitempty,min=0"` // 0 表示未归入文件夹的链接
+	Status      string     `form:"status" binding:"omitempty,oneof=active disabled"`
+	Expired     *bool      `form:"expired"`
+	Q           string     `form:"q" binding:"omitempty,max=200"` // 别名 / URL 子串搜索
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02" time_utc:"1"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02" time_utc:"1"` // 包含当天
+	Sort        string     `form:"sort" binding:"omitempty,oneof=created clicks alias"`
+	Order       string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Count       string     `form:"count" binding:"omitempty,oneof=exact estimated none"` // 总数统计方式
 }
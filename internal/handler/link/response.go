@@ -2,6 +2,8 @@ package link
 
 import (
 	"go-short/internal/model"
+	"go-short/internal/service"
+	"go-short/internal/util"
 	"time"
 )
 
@@ -14,23 +16,103 @@ type BaseResponse struct {
 // LinkResponse 链接操作响应
 type LinkResponse struct {
 	BaseResponse
-	LinkID      int64      `json:"link_id,omitempty"`
-	ShortCode   string     `json:"short_code,omitempty"`
-	OriginalURL string     `json:"original_url,omitempty"`
-	ShortURL    string     `json:"short_url,omitempty"`
-	Alias       string     `json:"alias,omitempty"`
-	IsActive    *bool      `json:"is_active,omitempty"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	LinkID           int64              `json:"link_id,omitempty"`
+	Domain           string             `json:"domain,omitempty"` // 自定义域名，默认域名时省略
+	ShortCode        string             `json:"short_code,omitempty"`
+	OriginalURL      string             `json:"original_url,omitempty"`
+	ShortURL         string             `json:"short_url,omitempty"`
+	Alias            string             `json:"alias,omitempty"`
+	IsActive         *bool              `json:"is_active,omitempty"`
+	ExpiresAt        *time.Time         `json:"expires_at,omitempty"`
+	CreatedAt        time.Time          `json:"created_at,omitempty"`
+	TrackConversions bool               `json:"track_conversions"`
+	Version          int64              `json:"version,omitempty"`
+	Clicks           int64              `json:"clicks"`
+	FolderID         *int64             `json:"folder_id,omitempty"`
+	WorkspaceID      *int64             `json:"workspace_id,omitempty"` // 所属工作区，个人链接时省略
+	Tags             []string           `json:"tags,omitempty"`
+	Health           *LinkHealthSummary `json:"health,omitempty"`     // 仅列表返回，未检查过时省略
+	DeletedAt        *time.Time         `json:"deleted_at,omitempty"` // 仅回收站列表返回
+}
+
+// LinkHealthSummary 目标地址最近一次健康检查结果摘要
+type LinkHealthSummary struct {
+	Broken     bool      `json:"broken"`
+	StatusCode int       `json:"status_code"` // 0 表示请求失败
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// RevisionResponse 一条变更记录
+type RevisionResponse struct {
+	RevisionID       int64                          `json:"revision_id"`
+	Action           string                         `json:"action"` // create | update | delete | restore | rollback
+	ActorID          string                         `json:"actor_id"`
+	Changes          map[string]service.FieldChange `json:"changes"`
+	RollbackOf       *int64                         `json:"rollback_of,omitempty"`
+	OriginalURL      string                         `json:"original_url"`
+	Alias            string                         `json:"alias"`
+	ShortCode        string                         `json:"short_code"`
+	IsActive         bool                           `json:"is_active"`
+	ExpiresAt        *time.Time                     `json:"expires_at"`
+	TrackConversions bool                           `json:"track_conversions"`
+	CreatedAt        time.Time                      `json:"created_at"`
+}
+
+// LinkHistoryResponse 变更记录列表响应
+type LinkHistoryResponse struct {
+	BaseResponse
+	Revisions []RevisionResponse `json:"revisions"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
 }
 
 // ListLinksResponse 链接列表响应
 type ListLinksResponse struct {
 	BaseResponse
-	Links []LinkResponse `json:"links"`
-	Total int64          `json:"total"`
-	Page  int            `json:"page"`
-	Limit int            `json:"limit"`
+	Links          []LinkResponse `json:"links"`
+	Total          *int64         `json:"total,omitempty"` // count=none 时省略
+	TotalEstimated bool           `json:"total_estimated,omitempty"`
+	Page           int            `json:"page,omitempty"` // 游标模式下省略
+	Limit          int            `json:"limit"`
+	NextCursor     string         `json:"next_cursor,omitempty"`
+	HasMore        *bool          `json:"has_more,omitempty"` // 仅游标模式返回
+}
+
+// BulkLinkResultResponse 批量创建的单行结果
+type BulkLinkResultResponse struct {
+	Row       int    `json:"row"`
+	Status    string `json:"status"` // created | exists | error
+	LinkID    int64  `json:"link_id,omitempty"`
+	ShortCode string `json:"short_code,omitempty"`
+	ShortURL  string `json:"short_url,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkCreateResponse 同步批量创建响应
+type BulkCreateResponse struct {
+	BaseResponse
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Exists  int                      `json:"exists"`
+	Failed  int                      `json:"failed"`
+	Results []BulkLinkResultResponse `json:"results"`
+}
+
+// BulkJobResponse 后台导入任务响应
+type BulkJobResponse struct {
+	BaseResponse
+	JobID      string                   `json:"job_id"`
+	Status     string                   `json:"status"` // running | completed | failed
+	Total      int                      `json:"total"`
+	Processed  int                      `json:"processed"`
+	Created    int                      `json:"created"`
+	Exists     int                      `json:"exists"`
+	Failed     int                      `json:"failed"`
+	Error      string                   `json:"error,omitempty"`
+	Results    []BulkLinkResultResponse `json:"results,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
 }
 
 // ErrorResponse 错误响应
@@ -53,63 +135,244 @@ func NewSuccessResponse(message string) BaseResponse {
 func NewCreateLinkResponse(link *model.Link, shortURL string) LinkResponse {
 	isActive := link.Status
 	return LinkResponse{
-		BaseResponse: NewSuccessResponse("短链接创建成功"),
-		LinkID:       link.ID,
-		ShortCode:    link.ShortCode,
-		OriginalURL:  link.OriginalURL,
-		ShortURL:     shortURL,
-		Alias:        link.Alias,
-		IsActive:     &isActive,
-		ExpiresAt:    link.ExpiresAt,
-		CreatedAt:    link.CreatedAt,
+		BaseResponse:     NewSuccessResponse("短链接创建成功"),
+		LinkID:           link.ID,
+		Domain:           link.Domain,
+		ShortCode:        link.ShortCode,
+		OriginalURL:      link.OriginalURL,
+		ShortURL:         shortURL,
+		Alias:            link.Alias,
+		IsActive:         &isActive,
+		ExpiresAt:        link.ExpiresAt,
+		CreatedAt:        link.CreatedAt,
+		TrackConversions: link.TrackConversions,
+		WorkspaceID:      link.WorkspaceID,
+		Version:          link.Version,
 	}
 }
 
 func NewLinkExistsResponse(link *model.Link, shortURL string) LinkResponse {
 	isActive := link.Status
 	return LinkResponse{
-		BaseResponse: NewSuccessResponse("链接已存在"),
-		LinkID:       link.ID,
-		ShortCode:    link.ShortCode,
-		OriginalURL:  link.OriginalURL,
-		ShortURL:     shortURL,
-		Alias:        link.Alias,
-		IsActive:     &isActive,
-		ExpiresAt:    link.ExpiresAt,
-		CreatedAt:    link.CreatedAt,
+		BaseResponse:     NewSuccessResponse("链接已存在"),
+		LinkID:           link.ID,
+		Domain:           link.Domain,
+		ShortCode:        link.ShortCode,
+		OriginalURL:      link.OriginalURL,
+		ShortURL:         shortURL,
+		Alias:            link.Alias,
+		IsActive:         &isActive,
+		ExpiresAt:        link.ExpiresAt,
+		CreatedAt:        link.CreatedAt,
+		TrackConversions: link.TrackConversions,
+		WorkspaceID:      link.WorkspaceID,
+		Version:          link.Version,
 	}
 }
 
-func NewListLinksResponse(links []model.Link, total int64, page, limit int, baseURL string) ListLinksResponse {
+// NewListLinksResponse tags、health 为链接ID到标签 / 健康检查结果的映射，可为 nil
+func NewListLinksResponse(links []model.Link, tags map[int64][]model.Tag, health map[int64]model.LinkHealth, total int64, page, limit int, baseURL string) ListLinksResponse {
 	linkResponses := make([]LinkResponse, 0, len(links))
 	for _, link := range links {
 		isActive := link.Status
 		shortURL := ""
 		if link.ShortCode != "" {
-			shortURL = baseURL + "/code/" + link.ShortCode
+			shortURL = util.ShortURL(baseURL, link.Domain, link.ShortCode)
 		}
 		linkResponses = append(linkResponses, LinkResponse{
-			LinkID:      link.ID,
-			ShortCode:   link.ShortCode,
-			OriginalURL: link.OriginalURL,
-			ShortURL:    shortURL,
-			Alias:       link.Alias,
-			IsActive:    &isActive,
-			ExpiresAt:   link.ExpiresAt,
-			CreatedAt:   link.CreatedAt,
+			LinkID:           link.ID,
+			Domain:           link.Domain,
+			ShortCode:        link.ShortCode,
+			OriginalURL:      link.OriginalURL,
+			ShortURL:         shortURL,
+			Alias:            link.Alias,
+			IsActive:         &isActive,
+			ExpiresAt:        link.ExpiresAt,
+			CreatedAt:        link.CreatedAt,
+			TrackConversions: link.TrackConversions,
+			WorkspaceID:      link.WorkspaceID,
+			Version:          link.Version,
+			Clicks:           link.VisitCount,
+			FolderID:         link.FolderID,
+			Tags:             tagNames(tags[link.ID]),
+			Health:           newLinkHealthSummary(health, link.ID),
+			DeletedAt:        deletedAt(link),
 		})
 	}
-	return ListLinksResponse{
+	resp := ListLinksResponse{
 		BaseResponse: NewSuccessResponse("获取链接列表成功"),
 		Links:        linkResponses,
-		Total:        total,
 		Page:         page,
 		Limit:        limit,
 	}
+	if total >= 0 {
+		resp.Total = &total
+	}
+	return resp
+}
+
+func deletedAt(link model.Link) *time.Time {
+	if !link.DeletedAt.Valid {
+		return nil
+	}
+	t := link.DeletedAt.Time
+	return &t
+}
+
+func newLinkHealthSummary(health map[int64]model.LinkHealth, linkID int64) *LinkHealthSummary {
+	h, ok := health[linkID]
+	if !ok {
+		return nil
+	}
+	return &LinkHealthSummary{Broken: h.Broken, StatusCode: h.StatusCode, CheckedAt: h.CheckedAt}
+}
+
+// NewLinkPageResponse 分页结果响应，cursorMode 时返回 next_cursor / has_more 而不是页码
+func NewLinkPageResponse(p *service.LinkPage, tags map[int64][]model.Tag, health map[int64]model.LinkHealth, page, limit int, cursorMode bool, baseURL string) ListLinksResponse {
+	if cursorMode {
+		page = 0
+	}
+	resp := NewListLinksResponse(p.Links, tags, health, p.Total, page, limit, baseURL)
+	resp.TotalEstimated = p.Estimated && p.Total >= 0
+	if cursorMode {
+		hasMore := p.NextCursor != ""
+		resp.NextCursor = p.NextCursor
+		resp.HasMore = &hasMore
+	}
+	return resp
+}
+
+func NewUpdateLinkResponse(link *model.Link, shortURL string) LinkResponse {
+	isActive := link.Status
+	return LinkResponse{
+		BaseResponse:     NewSuccessResponse("短链接更新成功"),
+		LinkID:           link.ID,
+		Domain:           link.Domain,
+		ShortCode:        link.ShortCode,
+		OriginalURL:      link.OriginalURL,
+		ShortURL:         shortURL,
+		Alias:            link.Alias,
+		IsActive:         &isActive,
+		ExpiresAt:        link.ExpiresAt,
+		CreatedAt:        link.CreatedAt,
+		TrackConversions: link.TrackConversions,
+		WorkspaceID:      link.WorkspaceID,
+		Version:          link.Version,
+	}
+}
+
+func tagNames(tags []model.Tag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func newBulkLinkResults(results []service.BulkLinkResult, domain, baseURL string) []BulkLinkResultResponse {
+	items := make([]BulkLinkResultResponse, 0, len(results))
+	for _, r := range results {
+		item := BulkLinkResultResponse{
+			Row:       r.Row,
+			Status:    r.Status,
+			LinkID:    r.LinkID,
+			ShortCode: r.ShortCode,
+			Error:     r.Error,
+		}
+		if r.ShortCode != "" {
+			item.ShortURL = util.ShortURL(baseURL, domain, r.ShortCode)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func NewBulkCreateResponse(results []service.BulkLinkResult, domain, baseURL string) BulkCreateResponse {
+	resp := BulkCreateResponse{
+		BaseResponse: NewSuccessResponse("批量创建完成"),
+		Total:        len(results),
+		Results:      newBulkLinkResults(results, domain, baseURL),
+	}
+	for _, r := range results {
+		switch r.Status {
+		case service.BulkResultCreated:
+			resp.Created++
+		case service.BulkResultExists:
+			resp.Exists++
+		default:
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+func NewBulkJobResponse(job *service.BulkJob, baseURL string) BulkJobResponse {
+	return BulkJobResponse{
+		BaseResponse: NewSuccessResponse("获取导入任务成功"),
+		JobID:        job.ID,
+		Status:       job.Status,
+		Total:        job.Total,
+		Processed:    job.Processed,
+		Created:      job.Created,
+		Exists:       job.Exists,
+		Failed:       job.Failed,
+		Error:        job.Error,
+		Results:      newBulkLinkResults(job.Results, job.Domain, baseURL),
+		CreatedAt:    job.CreatedAt,
+		FinishedAt:   job.FinishedAt,
+	}
 }
 
 func NewDeleteLinkResponse() BaseResponse {
-	return NewSuccessResponse("短链接删除成功")
+	return NewSuccessResponse("短链接已移入回收站")
+}
+
+// NewTrashResponse 回收站列表
+func NewTrashResponse(links []model.Link, total int64, page, limit int, baseURL string) ListLinksResponse {
+	resp := NewListLinksResponse(links, nil, nil, total, page, limit, baseURL)
+	resp.Message = "获取回收站成功"
+	return resp
+}
+
+func NewRestoreLinkResponse(link *model.Link, shortURL string) LinkResponse {
+	resp := NewUpdateLinkResponse(link, shortURL)
+	resp.Message = "短链接已恢复"
+	return resp
+}
+
+func NewLinkHistoryResponse(revisions []model.LinkRevision, total int64, page, limit int) LinkHistoryResponse {
+	items := make([]RevisionResponse, 0, len(revisions))
+	for i := range revisions {
+		rev := &revisions[i]
+		items = append(items, RevisionResponse{
+			RevisionID:       rev.ID,
+			Action:           rev.Action,
+			ActorID:          rev.ActorID.String(),
+			Changes:          service.ParseRevisionChanges(rev),
+			RollbackOf:       rev.RollbackOf,
+			OriginalURL:      rev.OriginalURL,
+			Alias:            rev.Alias,
+			ShortCode:        rev.ShortCode,
+			IsActive:         rev.Status,
+			ExpiresAt:        rev.ExpiresAt,
+			TrackConversions: rev.TrackConversions,
+			CreatedAt:        rev.CreatedAt,
+		})
+	}
+	return LinkHistoryResponse{
+		BaseResponse: NewSuccessResponse("获取变更记录成功"),
+		Revisions:    items,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}
+}
+
+func NewPurgeLinkResponse() BaseResponse {
+	return NewSuccessResponse("短链接已彻底删除")
 }
 
 // 错误响应构造函数
@@ -138,6 +401,19 @@ var (
 	ErrForbidden          = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
 	ErrUnauthorized       = NewErrorResponse("UNAUTHORIZED", "未授权访问", "")
 	ErrUserNotFound       = NewErrorResponse("USER_NOT_FOUND", "用户不存在", "")
+	ErrUnsupportedFormat  = NewErrorResponse("UNSUPPORTED_FORMAT", "不支持的导出格式", "")
+	ErrInvalidTimeRange   = NewErrorResponse("INVALID_TIME_RANGE", "时间范围无效", "")
+	ErrVersionRequired    = NewErrorResponse("PRECONDITION_REQUIRED", "缺少版本号（If-Match 或 version）", "")
+	ErrVersionConflict    = NewErrorResponse("VERSION_CONFLICT", "链接已被修改，请刷新后重试", "")
+	ErrInvalidBulkFile    = NewErrorResponse("INVALID_FILE", "导入文件格式错误", "")
+	ErrBulkEmpty          = NewErrorResponse("BULK_EMPTY", "没有可导入的链接", "")
+	ErrBulkTooLarge       = NewErrorResponse("BULK_TOO_LARGE", "导入行数超过上限", "")
+	ErrBulkJobNotFound    = NewErrorResponse("JOB_NOT_FOUND", "导入任务不存在", "")
+	ErrInvalidCursor      = NewErrorResponse("INVALID_CURSOR", "分页游标无效", "")
+	ErrDomainNotFound     = NewErrorResponse("DOMAIN_NOT_FOUND", "域名不存在", "")
+	ErrDomainNotVerified  = NewErrorResponse("DOMAIN_NOT_VERIFIED", "域名尚未验证", "")
+	ErrRevisionNotFound   = NewErrorResponse("REVISION_NOT_FOUND", "版本不存在", "")
+	ErrWorkspaceNotFound  = NewErrorResponse("WORKSPACE_NOT_FOUND", "工作区不存在", "")
 	// 服务器错误 (5xx) - 系统错误
 	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
 	ErrInternal = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
@@ -2,18 +2,32 @@ package link
 
 import (
 	"go-short/internal/middleware"
+	"go-short/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterRoutes 注册链接相关路由
 func RegisterRoutes(r *gin.RouterGroup, handler *LinkHandler) {
+	// 各路由声明 API Key 所需的权限范围（JWT 不受限制）
+	read := middleware.AuthMiddleware(model.ScopeLinksRead)
+	write := middleware.AuthMiddleware(model.ScopeLinksWrite)
+	stats := middleware.AuthMiddleware(model.ScopeStatsRead)
+
 	linksGroup := r.Group("/links")
-	linksGroup.Use(middleware.AuthMiddleware())
 	{
-		linksGroup.POST("", handler.Create)
-		linksGroup.GET("", handler.GetLinks)
-		linksGroup.GET("/GetLinksByAlias", handler.GetLinksByAlias)
-		linksGroup.DELETE("/:id", handler.Delete)
+		linksGroup.POST("", write, handler.Create)
+		linksGroup.POST("/bulk", write, handler.BulkCreate)
+		linksGroup.GET("/bulk/jobs/:jobID", read, handler.GetBulkJob)
+		linksGroup.GET("", read, handler.GetLinks)
+		linksGroup.GET("/GetLinksByAlias", read, handler.GetLinksByAlias)
+		linksGroup.PATCH("/:id", write, handler.Update)
+		linksGroup.DELETE("/:id", write, handler.Delete)
+		linksGroup.GET("/trash", read, handler.ListTrash)
+		linksGroup.POST("/:id/restore", write, handler.Restore)
+		linksGroup.DELETE("/trash/:id", write, handler.Purge)
+		linksGroup.GET("/:id/history", read, handler.History)
+		linksGroup.POST("/:id/rollback", write, handler.Rollback)
+		linksGroup.GET("/:id/export", stats, handler.Export)
 	}
 }
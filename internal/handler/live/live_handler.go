@@ -0,0 +1,142 @@
+package live
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-short/internal/live"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	minHeartbeat     = 5 * time.Second
+	maxHeartbeat     = 60 * time.Second
+)
+
+// LiveHandler 实时点击流（SSE）
+type LiveHandler struct {
+	linkService *service.LinkService
+	hub         *live.Hub
+}
+
+func NewLiveHandler(linkService *service.LinkService, hub *live.Hub) *LiveHandler {
+	return &LiveHandler{
+		linkService: linkService,
+		hub:         hub,
+	}
+}
+
+// LinkLive 链接所有者订阅单个链接的实时点击
+func (h *LiveHandler) LinkLive(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	link, err := h.linkService.GetOwnedLink(c, linkID, userID, isAdmin)
+	if err != nil {
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	filter := parseFilter(c)
+	filter.Codes = []string{model.LinkKey(link.Domain, link.ShortCode)} // 所有者只能看到自己的链接
+	h.stream(c, filter)
+}
+
+// AdminLive 管理员订阅全站实时点击，可用 codes 参数按短码过滤
+func (h *LiveHandler) AdminLive(c *gin.Context) {
+	filter := parseFilter(c)
+	if codes := strings.TrimSpace(c.Query("codes")); codes != "" {
+		for _, code := range strings.Split(codes, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				filter.Codes = append(filter.Codes, code)
+			}
+		}
+	}
+	h.stream(c, filter)
+}
+
+// parseFilter 解析客户端过滤参数：ip（前缀）、ua（关键字）
+func parseFilter(c *gin.Context) live.Filter {
+	return live.Filter{
+		IPPrefix:  strings.TrimSpace(c.Query("ip")),
+		UAKeyword: strings.TrimSpace(c.Query("ua")),
+	}
+}
+
+// parseHeartbeat 解析心跳间隔（秒），越界时取默认值
+func parseHeartbeat(c *gin.Context) time.Duration {
+	sec, err := strconv.Atoi(c.Query("heartbeat"))
+	if err != nil {
+		return defaultHeartbeat
+	}
+	d := time.Duration(sec) * time.Second
+	if d < minHeartbeat || d > maxHeartbeat {
+		return defaultHeartbeat
+	}
+	return d
+}
+
+// stream 持续推送事件，直到客户端断开或被判定为慢消费者
+func (h *LiveHandler) stream(c *gin.Context, filter live.Filter) {
+	sub := h.hub.Subscribe(filter, live.DefaultBufferSize)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 缓冲
+	c.Status(200)
+
+	ticker := time.NewTicker(parseHeartbeat(c))
+	defer ticker.Stop()
+
+	c.SSEvent("ready", gin.H{"codes": filter.Codes})
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Done:
+			c.SSEvent("close", gin.H{"reason": "slow consumer"})
+			c.Writer.Flush()
+			return
+		case <-ticker.C:
+			c.SSEvent("heartbeat", gin.H{"ts": time.Now().Unix()})
+			c.Writer.Flush()
+		case evt := <-sub.C:
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			c.SSEvent("click", NewClickEvent(evt))
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package live
+
+import "go-short/internal/live"
+
+// ClickEvent 推送给客户端的点击事件
+type ClickEvent struct {
+	ShortCode string `json:"short_code"`
+	Domain    string `json:"domain,omitempty"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	VisitedAt int64  `json:"visited_at"`
+}
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+func NewClickEvent(evt *live.AccessEvent) ClickEvent {
+	return ClickEvent{
+		ShortCode: evt.Code,
+		Domain:    evt.Domain,
+		IPAddress: evt.IP,
+		UserAgent: evt.UA,
+		VisitedAt: evt.TS,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID  = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrLinkNotFound   = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden      = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
@@ -0,0 +1,14 @@
+package live
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册实时点击流路由
+func RegisterRoutes(r *gin.RouterGroup, handler *LiveHandler) {
+	r.GET("/links/:id/live", middleware.AuthMiddleware(model.ScopeStatsRead), handler.LinkLive)
+	r.GET("/admin/live", middleware.AuthMiddleware(), middleware.RequirePermission(model.PermLogsRead), handler.AdminLive)
+}
@@ -0,0 +1,66 @@
+package qr
+
+import (
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/qrcode"
+	"go-short/internal/service"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type QRHandler struct {
+	qrService *service.QRService
+}
+
+func NewQRHandler(qrService *service.QRService) *QRHandler {
+	return &QRHandler{qrService: qrService}
+}
+
+// LinkQRCode 生成链接二维码（PNG / SVG），参数见 qrcode.ParseOptions
+func (h *QRHandler) LinkQRCode(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	opts, err := qrcode.ParseOptions(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(400, ErrInvalidOptions)
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	data, err := h.qrService.GetLinkQRCode(c, linkID, userID, isAdmin, baseURL, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLinkNotFound):
+			c.JSON(404, ErrLinkNotFound)
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(403, ErrForbidden)
+		case errors.Is(err, service.ErrQRLogoUnavailable):
+			c.JSON(400, ErrLogoUnavailable)
+		default:
+			c.JSON(500, ErrInternal)
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(200, opts.ContentType(), data)
+}
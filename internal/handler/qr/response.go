@@ -0,0 +1,40 @@
+package qr
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest  = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidOptions  = NewErrorResponse("INVALID_QR_OPTIONS", "二维码参数无效", "format: png|svg, size: 64-2048, level: L|M|Q|H, margin: 0-16, fg/bg: #RRGGBB")
+	ErrInvalidUserID   = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrLinkNotFound    = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden       = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+	ErrLogoUnavailable = NewErrorResponse("QR_LOGO_UNAVAILABLE", "未配置二维码 Logo", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrInternal = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
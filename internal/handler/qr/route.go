@@ -0,0 +1,13 @@
+package qr
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册二维码相关路由
+func RegisterRoutes(r *gin.RouterGroup, handler *QRHandler) {
+	r.GET("/links/:id/qr", middleware.AuthMiddleware(model.ScopeLinksRead), handler.LinkQRCode)
+}
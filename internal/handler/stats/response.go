@@ -0,0 +1,73 @@
+package stats
+
+import "go-short/internal/service"
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// LinkStatsResponse 链接统计响应
+type LinkStatsResponse struct {
+	BaseResponse
+	LinkID          int64   `json:"link_id"`
+	Clicks          int64   `json:"clicks"`
+	Conversions     int64   `json:"conversions"`
+	TrackedClicks   int64   `json:"tracked_clicks"`
+	ConvertedClicks int64   `json:"converted_clicks"`
+	ConversionValue float64 `json:"conversion_value"`
+	ConversionRate  float64 `json:"conversion_rate"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func NewLinkStatsResponse(stats *service.LinkStats) LinkStatsResponse {
+	return LinkStatsResponse{
+		BaseResponse:    NewSuccessResponse("获取链接统计成功"),
+		LinkID:          stats.LinkID,
+		Clicks:          stats.Clicks,
+		Conversions:     stats.Conversions,
+		TrackedClicks:   stats.TrackedClicks,
+		ConvertedClicks: stats.ConvertedClicks,
+		ConversionValue: stats.ConversionValue,
+		ConversionRate:  stats.ConversionRate,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID  = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrLinkNotFound   = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden      = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
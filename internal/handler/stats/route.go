@@ -0,0 +1,13 @@
+package stats
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册统计相关路由
+func RegisterRoutes(r *gin.RouterGroup, handler *StatsHandler) {
+	r.GET("/links/:id/stats", middleware.AuthMiddleware(model.ScopeStatsRead), handler.LinkStats)
+}
@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{statsService: statsService}
+}
+
+// LinkStats 获取链接点击与转化统计
+func (h *StatsHandler) LinkStats(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	stats, err := h.statsService.GetLinkStats(c, linkID, userID, isAdmin)
+	if err != nil {
+		if errors.Is(err, service.ErrLinkNotFound) {
+			c.JSON(404, ErrLinkNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(403, ErrForbidden)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+
+	c.JSON(200, NewLinkStatsResponse(stats))
+}
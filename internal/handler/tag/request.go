@@ -0,0 +1,11 @@
+package tag
+
+// CreateTagRequest 创建标签请求
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// SetLinkTagsRequest 设置链接标签请求（整体替换，空数组表示清空）
+type SetLinkTagsRequest struct {
+	Tags []string `json:"tags" binding:"max=20"`
+}
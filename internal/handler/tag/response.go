@@ -0,0 +1,118 @@
+package tag
+
+import (
+	"go-short/internal/model"
+	"time"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// TagItem 标签
+type TagItem struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagResponse 单个标签响应
+type TagResponse struct {
+	BaseResponse
+	TagItem
+}
+
+// ListTagsResponse 标签列表响应
+type ListTagsResponse struct {
+	BaseResponse
+	Tags []TagItem `json:"tags"`
+}
+
+// LinkTagsResponse 链接标签响应
+type LinkTagsResponse struct {
+	BaseResponse
+	LinkID int64    `json:"link_id"`
+	Tags   []string `json:"tags"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func newTagItem(tag *model.Tag) TagItem {
+	return TagItem{ID: tag.ID, Name: tag.Name, CreatedAt: tag.CreatedAt}
+}
+
+func NewTagResponse(tag *model.Tag) TagResponse {
+	return TagResponse{
+		BaseResponse: NewSuccessResponse("标签创建成功"),
+		TagItem:      newTagItem(tag),
+	}
+}
+
+func NewListTagsResponse(tags []model.Tag) ListTagsResponse {
+	items := make([]TagItem, 0, len(tags))
+	for i := range tags {
+		items = append(items, newTagItem(&tags[i]))
+	}
+	return ListTagsResponse{
+		BaseResponse: NewSuccessResponse("获取标签列表成功"),
+		Tags:         items,
+	}
+}
+
+func NewLinkTagsResponse(linkID int64, tags []model.Tag) LinkTagsResponse {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return LinkTagsResponse{
+		BaseResponse: NewSuccessResponse("链接标签已更新"),
+		LinkID:       linkID,
+		Tags:         names,
+	}
+}
+
+func NewDeleteTagResponse() BaseResponse {
+	return NewSuccessResponse("标签删除成功")
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID  = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrInvalidTagName = NewErrorResponse("INVALID_TAG_NAME", "标签名无效", "")
+	ErrTooManyTags    = NewErrorResponse("TOO_MANY_TAGS", "标签数量超过上限", "")
+	ErrTagNotFound    = NewErrorResponse("TAG_NOT_FOUND", "标签不存在", "")
+	ErrLinkNotFound   = NewErrorResponse("LINK_NOT_FOUND", "链接不存在", "")
+	ErrForbidden      = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
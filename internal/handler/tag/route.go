@@ -0,0 +1,20 @@
+package tag
+
+import (
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册标签相关路由
+func RegisterRoutes(r *gin.RouterGroup, handler *TagHandler) {
+	tagsGroup := r.Group("/tags")
+	tagsGroup.Use(middleware.AuthMiddleware())
+	{
+		tagsGroup.GET("", handler.List)
+		tagsGroup.POST("", handler.Create)
+		tagsGroup.DELETE("/:id", handler.Delete)
+	}
+	r.PUT("/links/:id/tags", middleware.AuthMiddleware(model.ScopeLinksWrite), handler.SetLinkTags)
+}
@@ -0,0 +1,123 @@
+package tag
+
+import (
+	"errors"
+	"go-short/internal/middleware"
+	"go-short/internal/model"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// List 获取当前用户的标签
+func (h *TagHandler) List(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	tags, err := h.tagService.ListTags(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListTagsResponse(tags))
+}
+
+// Create 创建标签
+func (h *TagHandler) Create(c *gin.Context) {
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(c, userID, req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTagName) {
+			c.JSON(400, ErrInvalidTagName)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewTagResponse(tag))
+}
+
+// Delete 删除标签
+func (h *TagHandler) Delete(c *gin.Context) {
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || tagID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	if err := h.tagService.DeleteTag(c, userID, tagID); err != nil {
+		if errors.Is(err, service.ErrTagNotFound) {
+			c.JSON(404, ErrTagNotFound)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewDeleteTagResponse())
+}
+
+// SetLinkTags 替换链接的标签
+func (h *TagHandler) SetLinkTags(c *gin.Context) {
+	linkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || linkID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	var req SetLinkTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	isAdmin := middleware.HasPermission(c, model.PermLinksModerate)
+
+	tags, err := h.tagService.SetLinkTags(c, linkID, userID, isAdmin, req.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLinkNotFound):
+			c.JSON(404, ErrLinkNotFound)
+		case errors.Is(err, service.ErrForbidden):
+			c.JSON(403, ErrForbidden)
+		case errors.Is(err, service.ErrInvalidTagName):
+			c.JSON(400, ErrInvalidTagName)
+		case errors.Is(err, service.ErrTooManyTags):
+			c.JSON(400, ErrTooManyTags)
+		default:
+			c.JSON(500, ErrDatabase)
+		}
+		return
+	}
+	c.JSON(200, NewLinkTagsResponse(linkID, tags))
+}
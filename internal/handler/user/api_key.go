@@ -0,0 +1,142 @@
+package user
+
+import (
+	"errors"
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// writeAPIKeyError 将 API Key 相关的 Service 错误映射为响应
+func writeAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		c.JSON(404, ErrAPIKeyNotFound)
+	case errors.Is(err, service.ErrAPIKeyLimit):
+		c.JSON(409, ErrAPIKeyLimit)
+	case errors.Is(err, service.ErrInvalidAPIKeyScope):
+		c.JSON(400, ErrInvalidScope)
+	case errors.Is(err, service.ErrInvalidAPIKeyIP):
+		c.JSON(400, ErrInvalidIP)
+	case errors.Is(err, service.ErrInvalidAPIKeyExpiry):
+		c.JSON(400, ErrInvalidExpiry)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// parseAPIKeyParams 解析当前用户与路径中的 API Key ID
+func parseAPIKeyParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return uuid.Nil, uuid.Nil, false
+	}
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, keyID, true
+}
+
+// ListAPIKeys 获取当前用户的 API Key
+func (h *UserHandler) ListAPIKeys(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListAPIKeysResponse(keys))
+}
+
+// CreateAPIKey 创建 API Key，完整密钥只在响应中返回这一次
+func (h *UserHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.CreateAPIKey(c, service.CreateAPIKeyCommand{
+		UserID:     userID,
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	c.JSON(201, NewAPIKeyResponse(key, rawKey, "API Key 创建成功，请立即保存密钥，之后将无法再次查看"))
+}
+
+// GetAPIKey 获取单个 API Key
+func (h *UserHandler) GetAPIKey(c *gin.Context) {
+	userID, keyID, ok := parseAPIKeyParams(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.GetAPIKey(c, userID, keyID)
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	c.JSON(200, NewAPIKeyResponse(key, "", "获取 API Key 成功"))
+}
+
+// UpdateAPIKey 修改 API Key 的名称、权限范围、IP 白名单或过期时间
+func (h *UserHandler) UpdateAPIKey(c *gin.Context) {
+	var req UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, keyID, ok := parseAPIKeyParams(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.UpdateAPIKey(c, service.UpdateAPIKeyCommand{
+		UserID:         userID,
+		KeyID:          keyID,
+		Name:           req.Name,
+		Scopes:         req.Scopes,
+		AllowedIPs:     req.AllowedIPs,
+		ExpiresAt:      req.ExpiresAt,
+		ClearExpiresAt: req.ClearExpiresAt,
+	})
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	c.JSON(200, NewAPIKeyResponse(key, "", "API Key 更新成功"))
+}
+
+// DeleteAPIKey 删除 API Key，立即失效
+func (h *UserHandler) DeleteAPIKey(c *gin.Context) {
+	userID, keyID, ok := parseAPIKeyParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.DeleteAPIKey(c, userID, keyID); err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("API Key 删除成功"))
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"errors"
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// writeMFAError 将两步验证相关的 Service 错误映射为响应
+func writeMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFAInvalidCode):
+		c.JSON(400, ErrMFAInvalidCode)
+	case errors.Is(err, service.ErrMFANotEnabled):
+		c.JSON(400, ErrMFANotEnabled)
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		c.JSON(400, ErrMFANotEnrolled)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		c.JSON(409, ErrMFAAlreadyEnabled)
+	case errors.Is(err, service.ErrMFARequired):
+		c.JSON(403, ErrMFARequired)
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(404, ErrUserNotFound)
+	case errors.Is(err, service.ErrUserDisabled):
+		c.JSON(403, ErrForbidden)
+	default:
+		c.JSON(500, ErrInternal)
+	}
+}
+
+// GetMFAStatus 当前用户的两步验证状态
+func (h *UserHandler) GetMFAStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	status, err := h.mfaService.Status(c, userID, c.GetString("role"))
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewMFAStatusResponse(status))
+}
+
+// EnrollMFA 生成两步验证密钥与二维码，需调用 ConfirmMFA 后才生效
+func (h *UserHandler) EnrollMFA(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	enrollment, err := h.mfaService.Enroll(c, userID)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewMFAEnrollmentResponse(enrollment))
+}
+
+// ConfirmMFA 提交验证码启用两步验证，恢复码只在响应中返回这一次
+func (h *UserHandler) ConfirmMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	codes, err := h.mfaService.Confirm(c, userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewRecoveryCodesResponse("两步验证已启用，请妥善保存恢复码", codes))
+}
+
+// RegenerateRecoveryCodes 凭验证码重新生成恢复码，旧恢复码全部失效
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c, userID, req.Code)
+	if err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewRecoveryCodesResponse("恢复码已重新生成，旧恢复码已失效", codes))
+}
+
+// DisableMFA 凭验证码或恢复码关闭两步验证
+func (h *UserHandler) DisableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	if err := h.mfaService.Disable(c, userID, c.GetString("role"), req.Code); err != nil {
+		writeMFAError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("两步验证已关闭"))
+}
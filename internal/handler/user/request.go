@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 // UpdateUserRequest 更新用户信息请求
 type UpdateUserRequest struct {
 	Email *string `json:"email" binding:"omitempty,email"`
@@ -10,3 +12,25 @@ type UpdatePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,new_password"`
 }
+
+// CreateAPIKeyRequest 创建 API Key 请求
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"` // links:read / links:write / stats:read
+	AllowedIPs []string   `json:"allowed_ips"`                     // IP 或 CIDR，为空不限制
+	ExpiresAt  *time.Time `json:"expires_at" binding:"omitempty,expiry_time"`
+}
+
+// UpdateAPIKeyRequest 修改 API Key 请求，未传的字段保持不变
+type UpdateAPIKeyRequest struct {
+	Name           *string    `json:"name" binding:"omitempty,min=1,max=100"`
+	Scopes         []string   `json:"scopes" binding:"omitempty,min=1"`
+	AllowedIPs     []string   `json:"allowed_ips"` // 传空数组取消限制
+	ExpiresAt      *time.Time `json:"expires_at" binding:"omitempty,expiry_time"`
+	ClearExpiresAt bool       `json:"clear_expires_at"` // 取消过期时间
+}
+
+// MFACodeRequest 两步验证操作请求，code 为 6 位验证码（关闭时也可用恢复码）
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
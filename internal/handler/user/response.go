@@ -1,7 +1,10 @@
 package user
 
 import (
+	"encoding/base64"
 	"go-short/internal/model"
+	"go-short/internal/service"
+	"go-short/internal/util"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,14 +19,82 @@ type BaseResponse struct {
 // UserResponse 用户信息响应
 type UserResponse struct {
 	BaseResponse
-	UserID    uuid.UUID  `json:"user_id,omitempty"`
-	Username  string     `json:"username,omitempty"`
-	Email     *string    `json:"email,omitempty"`
-	Role      string     `json:"role,omitempty"`
-	Status    string     `json:"status,omitempty"`
-	LinkCount int64      `json:"link_count,omitempty"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UserID        uuid.UUID  `json:"user_id,omitempty"`
+	Username      string     `json:"username,omitempty"`
+	Email         *string    `json:"email,omitempty"`
+	EmailVerified bool       `json:"email_verified"` // 邮箱变更后重置为 false
+	Role          string     `json:"role,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	LinkCount     int64      `json:"link_count,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// DashboardResponse 用户仪表盘响应
+type DashboardResponse struct {
+	BaseResponse
+	TotalLinks    int64               `json:"total_links"`
+	ActiveLinks   int64               `json:"active_links"`
+	ExpiredLinks  int64               `json:"expired_links"`
+	DisabledLinks int64               `json:"disabled_links"`
+	TotalClicks   int64               `json:"total_clicks"`
+	Clicks7d      int64               `json:"clicks_7d"`
+	Clicks30d     int64               `json:"clicks_30d"`
+	TopLinks      []DashboardLinkItem `json:"top_links"`
+	RecentLinks   []DashboardLinkItem `json:"recent_links"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+}
+
+// DashboardLinkItem 仪表盘中的链接条目
+type DashboardLinkItem struct {
+	ID          int64      `json:"id"`
+	ShortCode   string     `json:"short_code"`
+	Alias       string     `json:"alias"`
+	OriginalURL string     `json:"original_url"`
+	Clicks      *int64     `json:"clicks,omitempty"`     // 仅热门链接
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // 仅最新链接
+}
+
+// APIKeyItem API Key 信息（不含密钥）
+type APIKeyItem struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // 完整密钥的开头 gsk_<prefix>_，用于辨认
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyResponse 单个 API Key 响应，创建时附带完整密钥
+type APIKeyResponse struct {
+	BaseResponse
+	APIKeyItem
+	Key string `json:"key,omitempty"` // 仅创建时返回一次
+}
+
+// ListAPIKeysResponse API Key 列表响应
+type ListAPIKeysResponse struct {
+	BaseResponse
+	APIKeys []APIKeyItem `json:"api_keys"`
+}
+
+// SessionItem 登录会话信息
+type SessionItem struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"` // 最近一次登录或刷新令牌的时间
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为发起本次请求的会话
+}
+
+// ListSessionsResponse 会话列表响应
+type ListSessionsResponse struct {
+	BaseResponse
+	Sessions []SessionItem `json:"sessions"`
 }
 
 // ErrorResponse 错误响应
@@ -56,15 +127,16 @@ func NewUserResponse(user *model.User) UserResponse {
 	}
 
 	return UserResponse{
-		BaseResponse: NewSuccessResponse("获取用户信息成功"),
-		UserID:       user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		Role:         user.Role,
-		Status:       user.Status,
-		LinkCount:    user.LinkCount,
-		CreatedAt:    createdAt,
-		UpdatedAt:    updatedAt,
+		BaseResponse:  NewSuccessResponse("获取用户信息成功"),
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		Status:        user.Status,
+		LinkCount:     user.LinkCount,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
 	}
 }
 
@@ -81,15 +153,157 @@ func NewUpdateUserResponse(user *model.User) UserResponse {
 	}
 
 	return UserResponse{
-		BaseResponse: NewSuccessResponse("用户信息更新成功"),
-		UserID:       user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		Role:         user.Role,
-		Status:       user.Status,
-		LinkCount:    user.LinkCount,
-		CreatedAt:    createdAt,
-		UpdatedAt:    updatedAt,
+		BaseResponse:  NewSuccessResponse("用户信息更新成功"),
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		Status:        user.Status,
+		LinkCount:     user.LinkCount,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+	}
+}
+
+func NewDashboardResponse(d *service.Dashboard) DashboardResponse {
+	topLinks := make([]DashboardLinkItem, 0, len(d.TopLinks))
+	for _, l := range d.TopLinks {
+		clicks := l.Clicks
+		topLinks = append(topLinks, DashboardLinkItem{
+			ID:          l.LinkID,
+			ShortCode:   l.ShortCode,
+			Alias:       l.Alias,
+			OriginalURL: l.OriginalURL,
+			Clicks:      &clicks,
+		})
+	}
+	recentLinks := make([]DashboardLinkItem, 0, len(d.RecentLinks))
+	for _, l := range d.RecentLinks {
+		createdAt := l.CreatedAt
+		recentLinks = append(recentLinks, DashboardLinkItem{
+			ID:          l.ID,
+			ShortCode:   l.ShortCode,
+			Alias:       l.Alias,
+			OriginalURL: l.OriginalURL,
+			CreatedAt:   &createdAt,
+		})
+	}
+
+	return DashboardResponse{
+		BaseResponse:  NewSuccessResponse("获取仪表盘成功"),
+		TotalLinks:    d.Links.Total,
+		ActiveLinks:   d.Links.Active,
+		ExpiredLinks:  d.Links.Expired,
+		DisabledLinks: d.Links.Disabled,
+		TotalClicks:   d.Clicks.Total,
+		Clicks7d:      d.Clicks.Last7Days,
+		Clicks30d:     d.Clicks.Last30Days,
+		TopLinks:      topLinks,
+		RecentLinks:   recentLinks,
+		GeneratedAt:   d.GeneratedAt,
+	}
+}
+
+func newAPIKeyItem(key *model.APIKey) APIKeyItem {
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+	return APIKeyItem{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     util.APIKeyPrefix + key.Prefix,
+		Scopes:     key.Scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func NewAPIKeyResponse(key *model.APIKey, rawKey, message string) APIKeyResponse {
+	return APIKeyResponse{
+		BaseResponse: NewSuccessResponse(message),
+		APIKeyItem:   newAPIKeyItem(key),
+		Key:          rawKey,
+	}
+}
+
+func NewListAPIKeysResponse(keys []model.APIKey) ListAPIKeysResponse {
+	items := make([]APIKeyItem, 0, len(keys))
+	for i := range keys {
+		items = append(items, newAPIKeyItem(&keys[i]))
+	}
+	return ListAPIKeysResponse{
+		BaseResponse: NewSuccessResponse("获取 API Key 列表成功"),
+		APIKeys:      items,
+	}
+}
+
+func NewListSessionsResponse(sessions []model.Session, currentSessionID string) ListSessionsResponse {
+	items := make([]SessionItem, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, SessionItem{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID.String() == currentSessionID,
+		})
+	}
+	return ListSessionsResponse{
+		BaseResponse: NewSuccessResponse("获取会话列表成功"),
+		Sessions:     items,
+	}
+}
+
+// MFAStatusResponse 两步验证状态
+type MFAStatusResponse struct {
+	BaseResponse
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"` // 当前角色要求两步验证，不能关闭
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollmentResponse 两步验证绑定信息
+type MFAEnrollmentResponse struct {
+	BaseResponse
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // data:image/png;base64,...
+}
+
+// RecoveryCodesResponse 恢复码只在生成时返回一次
+type RecoveryCodesResponse struct {
+	BaseResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func NewMFAStatusResponse(status *service.MFAStatus) MFAStatusResponse {
+	return MFAStatusResponse{
+		BaseResponse:           NewSuccessResponse("获取两步验证状态成功"),
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	}
+}
+
+func NewMFAEnrollmentResponse(enrollment *service.MFAEnrollment) MFAEnrollmentResponse {
+	return MFAEnrollmentResponse{
+		BaseResponse: NewSuccessResponse("请使用验证器 App 扫描二维码，然后提交验证码确认"),
+		Secret:       enrollment.Secret,
+		OTPAuthURI:   enrollment.OTPAuthURI,
+		QRCode:       "data:image/png;base64," + base64.StdEncoding.EncodeToString(enrollment.QRCode),
+	}
+}
+
+func NewRecoveryCodesResponse(message string, codes []string) RecoveryCodesResponse {
+	return RecoveryCodesResponse{
+		BaseResponse:  NewSuccessResponse(message),
+		RecoveryCodes: codes,
 	}
 }
 
@@ -114,12 +328,24 @@ type ErrorCode string
 
 var (
 	// 客户端错误 (4xx) - 业务逻辑错误
-	ErrInvalidRequest   = NewErrorResponse("BAD_REQUEST", "请求无效", "")
-	ErrUserNotFound     = NewErrorResponse("USER_NOT_FOUND", "用户不存在", "")
-	ErrInvalidPassword  = NewErrorResponse("INVALID_PASSWORD", "密码错误", "")
-	ErrPasswordTooShort = NewErrorResponse("PASSWORD_TOO_SHORT", "密码长度不足", "")
-	ErrForbidden        = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
-	ErrUnauthorized     = NewErrorResponse("UNAUTHORIZED", "未授权访问", "")
+	ErrInvalidRequest    = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrUserNotFound      = NewErrorResponse("USER_NOT_FOUND", "用户不存在", "")
+	ErrInvalidPassword   = NewErrorResponse("INVALID_PASSWORD", "密码错误", "")
+	ErrPasswordTooShort  = NewErrorResponse("PASSWORD_TOO_SHORT", "密码长度不足", "")
+	ErrForbidden         = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+	ErrUnauthorized      = NewErrorResponse("UNAUTHORIZED", "未授权访问", "")
+	ErrAPIKeyNotFound    = NewErrorResponse("API_KEY_NOT_FOUND", "API Key 不存在", "")
+	ErrAPIKeyLimit       = NewErrorResponse("API_KEY_LIMIT", "API Key 数量已达上限", "")
+	ErrInvalidScope      = NewErrorResponse("INVALID_SCOPE", "权限范围无效", "可选 links:read、links:write、stats:read")
+	ErrInvalidIP         = NewErrorResponse("INVALID_IP_ALLOWLIST", "IP 白名单格式无效", "")
+	ErrInvalidExpiry     = NewErrorResponse("INVALID_EXPIRES_AT", "过期时间必须晚于当前时间", "")
+	ErrWorkspaceNotFound = NewErrorResponse("WORKSPACE_NOT_FOUND", "工作区不存在", "")
+	ErrMFAInvalidCode    = NewErrorResponse("MFA_INVALID_CODE", "验证码错误", "")
+	ErrMFANotEnabled     = NewErrorResponse("MFA_NOT_ENABLED", "未启用两步验证", "")
+	ErrMFANotEnrolled    = NewErrorResponse("MFA_NOT_ENROLLED", "请先生成两步验证密钥", "")
+	ErrMFAAlreadyEnabled = NewErrorResponse("MFA_ALREADY_ENABLED", "已启用两步验证", "")
+	ErrMFARequired       = NewErrorResponse("MFA_REQUIRED", "当前角色要求启用两步验证，不能关闭", "")
+	ErrSessionNotFound   = NewErrorResponse("SESSION_NOT_FOUND", "会话不存在", "")
 
 	// 服务器错误 (5xx) - 系统错误
 	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
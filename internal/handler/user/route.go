@@ -2,17 +2,34 @@ package user
 
 import (
 	"go-short/internal/middleware"
+	"go-short/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterRoutes 注册用户相关路由
 func RegisterRoutes(r *gin.RouterGroup, handler *UserHandler) {
+	// 个人资料、密码、会话、API Key 和两步验证管理只接受 JWT；仪表盘可用具备 stats:read 的 API Key 访问
+	auth := middleware.AuthMiddleware()
 	userGroup := r.Group("/user")
-	userGroup.Use(middleware.AuthMiddleware())
 	{
-		userGroup.GET("/profile", handler.GetProfile)
-		userGroup.PUT("/profile", handler.UpdateProfile)
-		userGroup.PUT("/password", handler.UpdatePassword)
+		userGroup.GET("/profile", auth, handler.GetProfile)
+		userGroup.PUT("/profile", auth, handler.UpdateProfile)
+		userGroup.PUT("/password", auth, handler.UpdatePassword)
+		userGroup.GET("/sessions", auth, handler.ListSessions)
+		userGroup.DELETE("/sessions/:id", auth, handler.RevokeSession)
+		userGroup.GET("/dashboard", middleware.AuthMiddleware(model.ScopeStatsRead), handler.Dashboard)
+
+		userGroup.GET("/api-keys", auth, handler.ListAPIKeys)
+		userGroup.POST("/api-keys", auth, handler.CreateAPIKey)
+		userGroup.GET("/api-keys/:id", auth, handler.GetAPIKey)
+		userGroup.PATCH("/api-keys/:id", auth, handler.UpdateAPIKey)
+		userGroup.DELETE("/api-keys/:id", auth, handler.DeleteAPIKey)
+
+		userGroup.GET("/mfa", auth, handler.GetMFAStatus)
+		userGroup.POST("/mfa/enroll", auth, handler.EnrollMFA)
+		userGroup.POST("/mfa/confirm", auth, handler.ConfirmMFA)
+		userGroup.POST("/mfa/recovery-codes", auth, handler.RegenerateRecoveryCodes)
+		userGroup.DELETE("/mfa", auth, handler.DisableMFA)
 	}
 }
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"errors"
+	"go-short/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListSessions 获取当前用户的登录会话（设备、IP、创建与最近活跃时间），current 标记本次请求所在的会话
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+
+	sessions, err := h.tokenService.ListSessions(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListSessionsResponse(sessions, c.GetString("sid")))
+}
+
+// RevokeSession 终止当前用户的某个会话，该会话的 refresh token 与 access token 立即失效（可终止当前会话，相当于登出）
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+
+	if err := h.tokenService.RevokeSession(c, userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(404, ErrSessionNotFound)
+			return
+		}
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("会话已终止"))
+}
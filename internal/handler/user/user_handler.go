@@ -5,15 +5,26 @@ import (
 	"go-short/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService   *service.UserService
+	statsService  *service.StatsService
+	apiKeyService *service.APIKeyService
+	mfaService    *service.MFAService
+	emailService  *service.EmailService
+	tokenService  *service.TokenService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, statsService *service.StatsService, apiKeyService *service.APIKeyService, mfaService *service.MFAService, emailService *service.EmailService, tokenService *service.TokenService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:   userService,
+		statsService:  statsService,
+		apiKeyService: apiKeyService,
+		mfaService:    mfaService,
+		emailService:  emailService,
+		tokenService:  tokenService,
 	}
 }
 
@@ -70,6 +81,10 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		c.JSON(500, ErrDatabase)
 		return
 	}
+	// 提交了未验证的邮箱（含变更）时发送验证邮件
+	if req.Email != nil && user.EmailVerifiedAt == nil {
+		h.emailService.QueueVerification(user.ID, h.emailService.Language(c.GetHeader("Accept-Language")))
+	}
 
 	c.JSON(200, NewUpdateUserResponse(user))
 }
@@ -94,7 +109,7 @@ func (h *UserHandler) UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.UpdatePassword(c, usernameStr, req.OldPassword, req.NewPassword)
+	err := h.userService.UpdatePassword(c, usernameStr, req.OldPassword, req.NewPassword, c.GetString("sid"))
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidPassword) {
 			c.JSON(400, ErrInvalidPassword)
@@ -106,3 +121,30 @@ func (h *UserHandler) UpdatePassword(c *gin.Context) {
 
 	c.JSON(200, NewUpdatePasswordResponse())
 }
+
+// Dashboard 获取当前用户的仪表盘统计
+func (h *UserHandler) Dashboard(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, ErrUnauthorized)
+		return
+	}
+
+	// X-Workspace-ID 指定时统计该工作区的链接
+	var workspaceID *int64
+	if id := c.GetInt64("workspace_id"); id > 0 {
+		workspaceID = &id
+	}
+	dashboard, err := h.statsService.GetUserDashboard(c, userID, workspaceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWorkspaceNotFound):
+			c.JSON(404, ErrWorkspaceNotFound)
+		default:
+			c.JSON(500, ErrDatabase)
+		}
+		return
+	}
+
+	c.JSON(200, NewDashboardResponse(dashboard))
+}
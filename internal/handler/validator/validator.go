@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"go-short/internal/model"
 	"log"
 	"regexp"
 	"slices"
@@ -171,12 +172,10 @@ func validateShortCode(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+// validateRole 验证角色名格式（内置或自定义角色，是否存在由 service 层检查）
 func validateRole(fl validator.FieldLevel) bool {
-	code := fl.Field().String()
-	if code == "admin" || code == "user" {
-		return true
-	}
-	return false
+	return model.ValidRoleName(fl.Field().String())
 }
 
 // validateURL 验证 URL 格式（允许不带协议的 URL，service 层会处理）
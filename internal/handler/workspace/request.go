@@ -0,0 +1,23 @@
+package workspace
+
+// CreateWorkspaceRequest 创建工作区请求
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// RenameWorkspaceRequest 修改工作区名称请求
+type RenameWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// UpdateMemberRequest 修改成员角色请求
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// InviteMemberRequest 邀请成员请求（用户名与邮箱二选一）
+type InviteMemberRequest struct {
+	Username string `json:"username" binding:"required_without=Email,max=50"`
+	Email    string `json:"email" binding:"required_without=Username,omitempty,email"`
+	Role     string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
@@ -0,0 +1,204 @@
+package workspace
+
+import (
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BaseResponse 基础响应结构
+type BaseResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// WorkspaceItem 工作区及当前用户的角色
+type WorkspaceItem struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"` // owner | editor | viewer
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspaceResponse 单个工作区响应
+type WorkspaceResponse struct {
+	BaseResponse
+	WorkspaceItem
+}
+
+// ListWorkspacesResponse 工作区列表响应
+type ListWorkspacesResponse struct {
+	BaseResponse
+	Workspaces []WorkspaceItem `json:"workspaces"`
+}
+
+// MemberItem 工作区成员
+type MemberItem struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    *string   `json:"email,omitempty"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ListMembersResponse 成员列表响应
+type ListMembersResponse struct {
+	BaseResponse
+	Members []MemberItem `json:"members"`
+}
+
+// InvitationItem 工作区邀请
+type InvitationItem struct {
+	ID              int64     `json:"id"`
+	WorkspaceID     int64     `json:"workspace_id"`
+	WorkspaceName   string    `json:"workspace_name,omitempty"`
+	InviterID       uuid.UUID `json:"inviter_id"`
+	InviterUsername string    `json:"inviter_username,omitempty"`
+	InviteeID       uuid.UUID `json:"invitee_id"`
+	InviteeUsername string    `json:"invitee_username,omitempty"`
+	Role            string    `json:"role"`
+	Status          string    `json:"status"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// InvitationResponse 单个邀请响应
+type InvitationResponse struct {
+	BaseResponse
+	InvitationItem
+}
+
+// ListInvitationsResponse 邀请列表响应
+type ListInvitationsResponse struct {
+	BaseResponse
+	Invitations []InvitationItem `json:"invitations"`
+}
+
+// ErrorResponse 错误响应
+type ErrorResponse struct {
+	BaseResponse
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// 成功响应构造函数
+func NewSuccessResponse(message string) BaseResponse {
+	return BaseResponse{
+		Success: true,
+		Message: message,
+	}
+}
+
+func newWorkspaceItem(w *repository.UserWorkspace) WorkspaceItem {
+	return WorkspaceItem{
+		ID:        w.ID,
+		Name:      w.Name,
+		Role:      w.Role,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func NewWorkspaceResponse(w *repository.UserWorkspace, message string) WorkspaceResponse {
+	return WorkspaceResponse{
+		BaseResponse:  NewSuccessResponse(message),
+		WorkspaceItem: newWorkspaceItem(w),
+	}
+}
+
+func NewListWorkspacesResponse(workspaces []repository.UserWorkspace) ListWorkspacesResponse {
+	items := make([]WorkspaceItem, 0, len(workspaces))
+	for i := range workspaces {
+		items = append(items, newWorkspaceItem(&workspaces[i]))
+	}
+	return ListWorkspacesResponse{
+		BaseResponse: NewSuccessResponse("获取工作区列表成功"),
+		Workspaces:   items,
+	}
+}
+
+func NewListMembersResponse(members []repository.WorkspaceMemberInfo) ListMembersResponse {
+	items := make([]MemberItem, 0, len(members))
+	for _, m := range members {
+		items = append(items, MemberItem{
+			UserID:   m.UserID,
+			Username: m.Username,
+			Email:    m.Email,
+			Role:     m.Role,
+			JoinedAt: m.CreatedAt,
+		})
+	}
+	return ListMembersResponse{
+		BaseResponse: NewSuccessResponse("获取成员列表成功"),
+		Members:      items,
+	}
+}
+
+func newInvitationItem(inv *model.WorkspaceInvitation) InvitationItem {
+	return InvitationItem{
+		ID:          inv.ID,
+		WorkspaceID: inv.WorkspaceID,
+		InviterID:   inv.InviterID,
+		InviteeID:   inv.InviteeID,
+		Role:        inv.Role,
+		Status:      inv.Status,
+		ExpiresAt:   inv.ExpiresAt,
+		CreatedAt:   inv.CreatedAt,
+	}
+}
+
+func NewInvitationResponse(inv *model.WorkspaceInvitation) InvitationResponse {
+	return InvitationResponse{
+		BaseResponse:   NewSuccessResponse("邀请已发送"),
+		InvitationItem: newInvitationItem(inv),
+	}
+}
+
+func NewListInvitationsResponse(invitations []repository.WorkspaceInvitationInfo) ListInvitationsResponse {
+	items := make([]InvitationItem, 0, len(invitations))
+	for i := range invitations {
+		item := newInvitationItem(&invitations[i].WorkspaceInvitation)
+		item.WorkspaceName = invitations[i].WorkspaceName
+		item.InviterUsername = invitations[i].InviterUsername
+		item.InviteeUsername = invitations[i].InviteeUsername
+		items = append(items, item)
+	}
+	return ListInvitationsResponse{
+		BaseResponse: NewSuccessResponse("获取邀请列表成功"),
+		Invitations:  items,
+	}
+}
+
+// 错误响应构造函数
+func NewErrorResponse(code, message, details string) ErrorResponse {
+	return ErrorResponse{
+		BaseResponse: BaseResponse{
+			Success: false,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+var (
+	// 客户端错误 (4xx) - 业务逻辑错误
+	ErrInvalidRequest     = NewErrorResponse("BAD_REQUEST", "请求无效", "")
+	ErrInvalidUserID      = NewErrorResponse("INVALID_USER_ID", "无效的用户ID", "")
+	ErrForbidden          = NewErrorResponse("FORBIDDEN", "没有操作权限", "")
+	ErrUserNotFound       = NewErrorResponse("USER_NOT_FOUND", "用户不存在", "")
+	ErrWorkspaceNotFound  = NewErrorResponse("WORKSPACE_NOT_FOUND", "工作区不存在", "")
+	ErrWorkspaceNotEmpty  = NewErrorResponse("WORKSPACE_NOT_EMPTY", "工作区下仍有链接，无法删除", "请先删除或彻底清除回收站中的链接")
+	ErrInvalidRole        = NewErrorResponse("INVALID_ROLE", "工作区角色无效", "可选 owner、editor、viewer")
+	ErrLastOwner          = NewErrorResponse("LAST_OWNER", "工作区至少需要保留一位所有者", "")
+	ErrAlreadyMember      = NewErrorResponse("ALREADY_MEMBER", "该用户已是工作区成员", "")
+	ErrInvitationExists   = NewErrorResponse("INVITATION_EXISTS", "已向该用户发出邀请", "")
+	ErrInvitationNotFound = NewErrorResponse("INVITATION_NOT_FOUND", "邀请不存在或已失效", "")
+
+	// 服务器错误 (5xx) - 系统错误
+	ErrDatabase = NewErrorResponse("INTERNAL_ERROR", "系统错误", "")
+)
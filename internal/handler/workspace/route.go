@@ -0,0 +1,29 @@
+package workspace
+
+import (
+	"go-short/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes 注册工作区相关路由（成员管理只接受 JWT）
+func RegisterRoutes(r *gin.RouterGroup, handler *WorkspaceHandler) {
+	workspacesGroup := r.Group("/workspaces")
+	workspacesGroup.Use(middleware.AuthMiddleware())
+	{
+		workspacesGroup.GET("", handler.List)
+		workspacesGroup.POST("", handler.Create)
+		workspacesGroup.GET("/invitations", handler.ListMyInvitations)
+		workspacesGroup.POST("/invitations/:invitationID/accept", handler.AcceptInvitation)
+		workspacesGroup.POST("/invitations/:invitationID/decline", handler.DeclineInvitation)
+		workspacesGroup.GET("/:id", handler.Get)
+		workspacesGroup.PATCH("/:id", handler.Rename)
+		workspacesGroup.DELETE("/:id", handler.Delete)
+		workspacesGroup.GET("/:id/members", handler.ListMembers)
+		workspacesGroup.PUT("/:id/members/:userID", handler.UpdateMember)
+		workspacesGroup.DELETE("/:id/members/:userID", handler.RemoveMember)
+		workspacesGroup.GET("/:id/invitations", handler.ListInvitations)
+		workspacesGroup.POST("/:id/invitations", handler.Invite)
+		workspacesGroup.DELETE("/:id/invitations/:invitationID", handler.RevokeInvitation)
+	}
+}
@@ -0,0 +1,327 @@
+package workspace
+
+import (
+	"errors"
+	"go-short/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WorkspaceHandler struct {
+	workspaceService *service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// writeError 将 Service 错误映射为响应
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.JSON(404, ErrWorkspaceNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(403, ErrForbidden)
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(404, ErrUserNotFound)
+	case errors.Is(err, service.ErrWorkspaceNotEmpty):
+		c.JSON(409, ErrWorkspaceNotEmpty)
+	case errors.Is(err, service.ErrInvalidWorkspaceRole):
+		c.JSON(400, ErrInvalidRole)
+	case errors.Is(err, service.ErrLastWorkspaceOwner):
+		c.JSON(409, ErrLastOwner)
+	case errors.Is(err, service.ErrAlreadyMember):
+		c.JSON(409, ErrAlreadyMember)
+	case errors.Is(err, service.ErrInvitationExists):
+		c.JSON(409, ErrInvitationExists)
+	case errors.Is(err, service.ErrInvitationNotFound):
+		c.JSON(404, ErrInvitationNotFound)
+	default:
+		c.JSON(500, ErrDatabase)
+	}
+}
+
+// parseWorkspaceParams 解析当前用户与路径中的工作区ID
+func parseWorkspaceParams(c *gin.Context) (uuid.UUID, int64, bool) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return uuid.Nil, 0, false
+	}
+	workspaceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || workspaceID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return uuid.Nil, 0, false
+	}
+	return userID, workspaceID, true
+}
+
+// parseInvitationID 解析路径中的邀请ID
+func parseInvitationID(c *gin.Context) (int64, bool) {
+	invitationID, err := strconv.ParseInt(c.Param("invitationID"), 10, 64)
+	if err != nil || invitationID <= 0 {
+		c.JSON(400, ErrInvalidRequest)
+		return 0, false
+	}
+	return invitationID, true
+}
+
+// List 当前用户所在的工作区
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	workspaces, err := h.workspaceService.ListWorkspaces(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListWorkspacesResponse(workspaces))
+}
+
+// Create 创建工作区，创建者成为所有者
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(c, userID, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(201, NewWorkspaceResponse(workspace, "工作区创建成功"))
+}
+
+// Get 获取工作区（需为成员）
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(c, workspaceID, userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewWorkspaceResponse(workspace, "获取工作区成功"))
+}
+
+// Rename 修改工作区名称（所有者）
+func (h *WorkspaceHandler) Rename(c *gin.Context) {
+	var req RenameWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.RenameWorkspace(c, workspaceID, userID, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewWorkspaceResponse(workspace, "工作区更新成功"))
+}
+
+// Delete 删除工作区（所有者，工作区下仍有链接时拒绝）
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(c, workspaceID, userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("工作区删除成功"))
+}
+
+// ListMembers 工作区成员列表
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.ListMembers(c, workspaceID, userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewListMembersResponse(members))
+}
+
+// UpdateMember 修改成员角色（所有者）
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	if err := h.workspaceService.UpdateMemberRole(c, workspaceID, userID, memberID, req.Role); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("成员角色已更新"))
+}
+
+// RemoveMember 移除成员（所有者），或成员自己退出工作区
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(c, workspaceID, userID, memberID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("成员已移除"))
+}
+
+// ListInvitations 工作区待处理的邀请（所有者）
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.workspaceService.ListWorkspaceInvitations(c, workspaceID, userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewListInvitationsResponse(invitations))
+}
+
+// Invite 按用户名或邮箱邀请已注册用户（所有者）
+func (h *WorkspaceHandler) Invite(c *gin.Context) {
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrInvalidRequest)
+		return
+	}
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.workspaceService.InviteMember(c, service.InviteMemberCommand{
+		WorkspaceID: workspaceID,
+		InviterID:   userID,
+		Username:    req.Username,
+		Email:       req.Email,
+		Role:        req.Role,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(201, NewInvitationResponse(invitation))
+}
+
+// RevokeInvitation 撤回邀请（所有者）
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	userID, workspaceID, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.RevokeInvitation(c, workspaceID, invitationID, userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("邀请已撤回"))
+}
+
+// ListMyInvitations 发给当前用户的待处理邀请
+func (h *WorkspaceHandler) ListMyInvitations(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+
+	invitations, err := h.workspaceService.ListMyInvitations(c, userID)
+	if err != nil {
+		c.JSON(500, ErrDatabase)
+		return
+	}
+	c.JSON(200, NewListInvitationsResponse(invitations))
+}
+
+// AcceptInvitation 接受邀请并加入工作区
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.AcceptInvitation(c, invitationID, userID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewWorkspaceResponse(workspace, "已加入工作区"))
+}
+
+// DeclineInvitation 拒绝邀请
+func (h *WorkspaceHandler) DeclineInvitation(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(400, ErrInvalidUserID)
+		return
+	}
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.DeclineInvitation(c, invitationID, userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(200, NewSuccessResponse("已拒绝邀请"))
+}
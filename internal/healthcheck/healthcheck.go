@@ -0,0 +1,245 @@
+// Package healthcheck 链接目标地址健康检查：HEAD（必要时回退 GET）探测，跟随重定向，按主机限速并限制并发。
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-short/internal/urlcheck"
+)
+
+const (
+	maxRedirects = 10
+	maxBodyRead  = 64 << 10 // GET 回退时最多读取的响应体字节数
+	userAgent    = "GoShort-LinkChecker/1.0"
+)
+
+// ErrInternalAddress 目标解析到内网地址，拒绝连接（防止借健康检查探测内网）
+var ErrInternalAddress = errors.New("destination resolves to an internal address")
+
+// Config 健康检查任务配置
+type Config struct {
+	Interval     time.Duration // 同一链接两次检查的最小间隔
+	Concurrency  int           // 同时进行的请求数
+	HostInterval time.Duration // 同一主机两次请求的最小间隔
+	Timeout      time.Duration // 单个链接的总超时（含重定向）
+	BatchSize    int           // 每轮最多检查的链接数
+}
+
+// LoadConfigFromEnv 从环境变量读取配置：
+// HEALTH_CHECK_INTERVAL（默认 24h）、HEALTH_CHECK_CONCURRENCY（默认 8）、HEALTH_CHECK_HOST_INTERVAL（默认 1s）、
+// HEALTH_CHECK_TIMEOUT（默认 10s）、HEALTH_CHECK_BATCH（默认 500）
+func LoadConfigFromEnv() Config {
+	cfg := Config{
+		Interval:     24 * time.Hour,
+		Concurrency:  8,
+		HostInterval: time.Second,
+		Timeout:      10 * time.Second,
+		BatchSize:    500,
+	}
+	if d, err := time.ParseDuration(os.Getenv("HEALTH_CHECK_INTERVAL")); err == nil && d > 0 {
+		cfg.Interval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("HEALTH_CHECK_CONCURRENCY")); err == nil && n > 0 {
+		cfg.Concurrency = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("HEALTH_CHECK_HOST_INTERVAL")); err == nil && d >= 0 {
+		cfg.HostInterval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("HEALTH_CHECK_TIMEOUT")); err == nil && d > 0 {
+		cfg.Timeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("HEALTH_CHECK_BATCH")); err == nil && n > 0 {
+		cfg.BatchSize = n
+	}
+	return cfg
+}
+
+// Result 单次检查结果
+type Result struct {
+	StatusCode int // 请求失败时为 0
+	FinalURL   string
+	Latency    time.Duration
+	Err        error
+}
+
+// Broken 请求失败，或返回 4xx/5xx（401、403、429 通常是鉴权或反爬限制，页面本身未必失效，不算）
+func (r Result) Broken() bool {
+	if r.Err != nil {
+		return true
+	}
+	switch r.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return false
+	}
+	return r.StatusCode >= 400
+}
+
+// Prober 发起探测请求，可被多个 goroutine 共享
+type Prober struct {
+	client  *http.Client
+	timeout time.Duration
+	limiter *hostLimiter
+}
+
+// NewProber 使用拒绝内网地址的 Transport（在拨号时按实际解析出的 IP 校验，重定向同样生效）
+func NewProber(cfg Config) *Prober {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || urlcheck.IsInternalIP(ip) {
+				return ErrInternalAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // 不走代理，否则拨号校验的是代理地址
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return NewProberWithClient(&http.Client{Transport: transport}, cfg)
+}
+
+// NewProberWithClient 使用自定义 http.Client（如 httptest 服务器的客户端），重定向策略会被覆盖
+func NewProberWithClient(client *http.Client, cfg Config) *Prober {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("too many redirects")
+		}
+		return nil
+	}
+	return &Prober{client: &c, timeout: cfg.Timeout, limiter: newHostLimiter(cfg.HostInterval)}
+}
+
+// Probe 先发 HEAD，失败或返回错误状态码时回退 GET（不少服务器不支持 HEAD）
+func (p *Prober) Probe(ctx context.Context, rawURL string) Result {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if err := p.limiter.wait(ctx, u.Hostname()); err != nil {
+		return Result{Err: err}
+	}
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	res := p.do(ctx, http.MethodHead, rawURL)
+	if !res.Broken() || errors.Is(res.Err, ErrInternalAddress) || ctx.Err() != nil {
+		return res
+	}
+	return p.do(ctx, http.MethodGet, rawURL)
+}
+
+func (p *Prober) do(ctx context.Context, method, rawURL string) Result {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return Result{Err: err}
+	}
+	req.Header.Set("User-Agent", userAgent)
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Result{Latency: time.Since(start), Err: err}
+	}
+	defer resp.Body.Close()
+	if method == http.MethodGet {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodyRead))
+	}
+	return Result{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+		Latency:    time.Since(start),
+	}
+}
+
+// ProbeAll 以 concurrency 个 goroutine 并发检查，结果与 urls 一一对应
+func (p *Prober) ProbeAll(ctx context.Context, urls []string, concurrency int) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = p.Probe(ctx, urls[i])
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// hostLimiter 按主机分配请求时间槽，同一主机相邻两次请求至少间隔 interval
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if len(l.next) > 10000 {
+		// 清理早已过去的时间槽，避免长期运行时无限增长
+		for h, t := range l.next {
+			if t.Before(now) {
+				delete(l.next, h)
+			}
+		}
+	}
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	d := time.Until(slot)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,242 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{Timeout: 5 * time.Second}
+}
+
+func TestProbeStatusFinalURLAndLatency(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final?x=1", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewProberWithClient(srv.Client(), testConfig())
+
+	res := p.Probe(context.Background(), srv.URL+"/start")
+	if res.Err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("Probe = %+v", res)
+	}
+	if res.FinalURL != srv.URL+"/final?x=1" {
+		t.Errorf("FinalURL = %q", res.FinalURL)
+	}
+	if res.Latency < 20*time.Millisecond {
+		t.Errorf("Latency = %v, want >= 20ms", res.Latency)
+	}
+	if res.Broken() {
+		t.Error("200 should not be broken")
+	}
+
+	res = p.Probe(context.Background(), srv.URL+"/gone")
+	if res.StatusCode != http.StatusGone || !res.Broken() {
+		t.Errorf("Probe(/gone) = %+v, want broken 410", res)
+	}
+}
+
+func TestProbeFallsBackToGet(t *testing.T) {
+	var methods []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	res := NewProberWithClient(srv.Client(), testConfig()).Probe(context.Background(), srv.URL)
+	if res.Err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("Probe = %+v", res)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Fatalf("methods = %v, want [HEAD GET]", methods)
+	}
+}
+
+func TestProbeTooManyRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// 即使传入的客户端禁止跟随重定向，也会被覆盖为统一策略
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	res := NewProberWithClient(client, testConfig()).Probe(context.Background(), srv.URL+"/loop")
+	if res.Err == nil || !res.Broken() {
+		t.Fatalf("Probe = %+v, want redirect error", res)
+	}
+}
+
+func TestBroken(t *testing.T) {
+	cases := []struct {
+		res  Result
+		want bool
+	}{
+		{Result{StatusCode: 200}, false},
+		{Result{StatusCode: 301}, false},
+		{Result{StatusCode: 401}, false},
+		{Result{StatusCode: 403}, false},
+		{Result{StatusCode: 429}, false},
+		{Result{StatusCode: 404}, true},
+		{Result{StatusCode: 500}, true},
+		{Result{Err: errors.New("timeout")}, true},
+	}
+	for _, c := range cases {
+		if got := c.res.Broken(); got != c.want {
+			t.Errorf("%+v.Broken() = %v, want %v", c.res, got, c.want)
+		}
+	}
+}
+
+func TestProbeAllHostInterval(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	const interval = 50 * time.Millisecond
+	cfg := testConfig()
+	cfg.HostInterval = interval
+	p := NewProberWithClient(srv.Client(), cfg)
+
+	urls := []string{srv.URL + "/a", srv.URL + "/b", srv.URL + "/c", srv.URL + "/d"}
+	start := time.Now()
+	results := p.ProbeAll(context.Background(), urls, len(urls))
+	elapsed := time.Since(start)
+
+	for i, res := range results {
+		if res.Err != nil || res.StatusCode != http.StatusOK {
+			t.Fatalf("results[%d] = %+v", i, res)
+		}
+		if res.FinalURL != urls[i] {
+			t.Errorf("results[%d].FinalURL = %q, want %q", i, res.FinalURL, urls[i])
+		}
+	}
+	if elapsed < 3*interval {
+		t.Errorf("4 requests to one host took %v, want >= %v", elapsed, 3*interval)
+	}
+	for i := 1; i < len(times); i++ {
+		// 允许少量调度误差
+		if gap := times[i].Sub(times[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("gap between request %d and %d = %v, want >= %v", i-1, i, gap, interval)
+		}
+	}
+}
+
+func TestHostLimiterPerHost(t *testing.T) {
+	l := newHostLimiter(time.Hour)
+	ctx := context.Background()
+	// 不同主机各自的第一次请求不等待
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		if err := l.wait(ctx, host); err != nil {
+			t.Fatalf("wait(%s): %v", host, err)
+		}
+	}
+	// 同一主机的第二次请求需等待一个间隔，ctx 取消时返回
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx, "a.example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait on busy host = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestNewProberBlocksInternalAddressAtDial(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewProber(testConfig())
+	// httptest 监听 127.0.0.1，拨号时被拒绝；主机名解析到回环地址同样被拒绝
+	for _, target := range []string{srv.URL, "http://localhost:" + port} {
+		res := p.Probe(context.Background(), target)
+		if !errors.Is(res.Err, ErrInternalAddress) {
+			t.Errorf("Probe(%s).Err = %v, want ErrInternalAddress", target, res.Err)
+		}
+		if !res.Broken() {
+			t.Errorf("Probe(%s) should be broken", target)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("internal server received %d requests", n)
+	}
+}
+
+func TestNewProberBlocksRedirectToInternalAddress(t *testing.T) {
+	var hits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer internal.Close()
+
+	// 测试中无法启动公网地址的服务器，用 RoundTripper 模拟一个返回重定向的公网站点，
+	// 重定向目标交给 NewProber 的 Transport 拨号
+	p := NewProber(testConfig())
+	blocking := p.client.Transport
+	p.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "public.example.com" {
+			return &http.Response{
+				StatusCode: http.StatusFound,
+				Header:     http.Header{"Location": []string{internal.URL + "/admin"}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		return blocking.RoundTrip(req)
+	})
+
+	res := p.Probe(context.Background(), "http://public.example.com/")
+	if !errors.Is(res.Err, ErrInternalAddress) {
+		t.Fatalf("Probe.Err = %v, want ErrInternalAddress", res.Err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("internal server received %d requests", n)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,167 @@
+// Package live 实时点击流：将 Redirect 发布的访问事件扇出给 SSE 订阅者。
+// 每个订阅者持有独立的有界缓冲区，慢消费者只会丢自己的事件，不会拖慢其他订阅者。
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go-short/internal/model"
+)
+
+const (
+	// DefaultBufferSize 单个订阅者的事件缓冲区大小
+	DefaultBufferSize = 256
+	// MaxConsecutiveDrops 连续丢弃超过该数量则判定为慢消费者，主动断开
+	MaxConsecutiveDrops = 1024
+)
+
+// AccessEvent 访问事件，与 Kafka 访问日志消息结构一致
+type AccessEvent struct {
+	Code   string `json:"code"`
+	Domain string `json:"domain,omitempty"`
+	IP     string `json:"ip"`
+	UA     string `json:"ua"`
+	TS     int64  `json:"ts"`
+}
+
+// Filter 订阅过滤条件，零值表示不过滤
+type Filter struct {
+	Codes     []string // 短码白名单（为空表示全部），自定义域名下为 "域名/短码"
+	IPPrefix  string   // IP 前缀匹配
+	UAKeyword string   // UA 子串匹配（不区分大小写）
+}
+
+// Match 判断事件是否满足过滤条件
+func (f Filter) Match(evt *AccessEvent) bool {
+	if len(f.Codes) > 0 {
+		key := model.LinkKey(evt.Domain, evt.Code)
+		matched := false
+		for _, code := range f.Codes {
+			if code == key {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.IPPrefix != "" && !strings.HasPrefix(evt.IP, f.IPPrefix) {
+		return false
+	}
+	if f.UAKeyword != "" && !strings.Contains(strings.ToLower(evt.UA), strings.ToLower(f.UAKeyword)) {
+		return false
+	}
+	return true
+}
+
+// Subscriber 单个订阅者
+type Subscriber struct {
+	C      chan *AccessEvent
+	Done   chan struct{} // 被 Hub 判定为慢消费者而断开时关闭
+	filter Filter
+
+	dropped     atomic.Uint64 // 累计丢弃数
+	consecutive atomic.Uint64 // 连续丢弃数，成功投递后清零
+	closeOnce   sync.Once
+}
+
+// TakeDropped 读取并清零累计丢弃数，用于向客户端报告
+func (s *Subscriber) TakeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscriber) close() {
+	s.closeOnce.Do(func() { close(s.Done) })
+}
+
+// Hub 订阅中心
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[*Subscriber]struct{}
+}
+
+// NewHub 创建订阅中心
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscriber]struct{})}
+}
+
+// Subscribe 注册订阅者，bufferSize <= 0 时使用默认值
+func (h *Hub) Subscribe(filter Filter, bufferSize int) *Subscriber {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	sub := &Subscriber{
+		C:      make(chan *AccessEvent, bufferSize),
+		Done:   make(chan struct{}),
+		filter: filter,
+	}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe 注销订阅者（连接断开时调用）
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+	sub.close()
+}
+
+// Publish 向所有匹配的订阅者投递事件，缓冲区满则丢弃（不阻塞发布方）
+func (h *Hub) Publish(evt *AccessEvent) {
+	var slow []*Subscriber
+
+	h.mu.RLock()
+	for sub := range h.subs {
+		if !sub.filter.Match(evt) {
+			continue
+		}
+		select {
+		case sub.C <- evt:
+			sub.consecutive.Store(0)
+		default:
+			sub.dropped.Add(1)
+			if sub.consecutive.Add(1) >= MaxConsecutiveDrops {
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		h.Unsubscribe(sub)
+	}
+}
+
+// SubscriberCount 当前订阅者数量
+func (h *Hub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Run 从消息源（Redis pub/sub 或 Kafka）读取 JSON 事件并扇出，直到 ctx 取消或消息源关闭
+func (h *Hub) Run(ctx context.Context, source <-chan []byte) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-source:
+			if !ok {
+				return
+			}
+			var evt AccessEvent
+			if err := json.Unmarshal(data, &evt); err != nil || evt.Code == "" {
+				continue
+			}
+			h.Publish(&evt)
+		}
+	}
+}
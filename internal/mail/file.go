@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer 把邮件写成 .eml 文件，用于本地开发（没有 SMTP 服务器时查看邮件内容）
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+// NewFileMailer 目录不存在时创建
+func NewFileMailer(from, dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("mail: create dir %s: %w", dir, err)
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+// Send 文件名为 <时间戳>-<随机串>.eml；邮件含一次性链接，文件仅属主可读
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := build(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), randomHex(4))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o600)
+}
+
+// MemoryMailer 保存在内存中，用于测试断言
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send 校验后保存
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages 已发送邮件的副本
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Reset 清空已发送邮件
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
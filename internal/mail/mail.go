@@ -0,0 +1,175 @@
+// Package mail 邮件发送：Mailer 接口及 SMTP、文件（.eml）、内存三种实现，以及多语言邮件模板。
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("mail: invalid message")
+
+// 发送方式
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+// Message 一封邮件，Text 与 HTML 至少一项非空
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer 发送邮件，实现需可并发使用
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config 发送配置
+type Config struct {
+	Driver string // smtp | file | memory
+	From   string // 如 "GoShort <no-reply@example.com>"
+	SMTP   SMTPConfig
+	Dir    string // file 方式的输出目录
+}
+
+// LoadConfigFromEnv MAIL_DRIVER（配置了 SMTP_HOST 时默认 smtp，否则 file）、MAIL_FROM、MAIL_DIR（默认 mail-outbox），
+// SMTP_HOST、SMTP_PORT（默认 587）、SMTP_USERNAME、SMTP_PASSWORD、SMTP_TLS（starttls / tls / none，默认 starttls）
+func LoadConfigFromEnv() Config {
+	cfg := Config{
+		Driver: os.Getenv("MAIL_DRIVER"),
+		From:   os.Getenv("MAIL_FROM"),
+		Dir:    os.Getenv("MAIL_DIR"),
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     587,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			TLS:      os.Getenv("SMTP_TLS"),
+		},
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverFile
+		if cfg.SMTP.Host != "" {
+			cfg.Driver = DriverSMTP
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = "GoShort <no-reply@localhost>"
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "mail-outbox"
+	}
+	if n, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && n > 0 {
+		cfg.SMTP.Port = n
+	}
+	if cfg.SMTP.TLS == "" {
+		cfg.SMTP.TLS = TLSStartTLS
+	}
+	return cfg
+}
+
+// New 按配置创建 Mailer
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.From, cfg.SMTP)
+	case DriverFile:
+		return NewFileMailer(cfg.From, cfg.Dir)
+	case DriverMemory:
+		return NewMemoryMailer(), nil
+	}
+	return nil, fmt.Errorf("mail: unknown driver %q", cfg.Driver)
+}
+
+// validate 拒绝可导致头部注入的换行
+func (m Message) validate() error {
+	if m.To == "" || (m.Text == "" && m.HTML == "") {
+		return ErrInvalidMessage
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return fmt.Errorf("%w: header contains newline", ErrInvalidMessage)
+	}
+	return nil
+}
+
+// build 生成 RFC 5322 邮件：multipart/alternative（纯文本 + HTML），正文 base64 编码
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	if err := msg.validate(); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		if part.content == "" {
+			continue
+		}
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64Lines(pw, []byte(part.content))
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+w.Boundary()+`"`)
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeBase64Lines base64 编码并按 76 字符换行（RFC 2045）
+func writeBase64Lines(w io.Writer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		w.Write([]byte(enc[:76] + "\r\n"))
+		enc = enc[76:]
+	}
+	w.Write([]byte(enc + "\r\n"))
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimRight(from[i+1:], ">")
+	}
+	return "<" + randomHex(12) + "@" + domain + ">"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
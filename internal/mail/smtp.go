@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP 加密方式
+const (
+	TLSStartTLS = "starttls" // 明文连接后升级（587）
+	TLSImplicit = "tls"      // 直接 TLS 连接（465）
+	TLSNone     = "none"     // 不加密，仅用于本地测试服务器
+)
+
+// smtpTimeout 单封邮件从连接到发送完成的最长时间
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	TLS      string // starttls | tls | none
+}
+
+// SMTPMailer 每封邮件建立一次连接发送
+type SMTPMailer struct {
+	from     string
+	fromAddr string
+	config   SMTPConfig
+}
+
+// NewSMTPMailer from 为 RFC 5322 地址，如 "GoShort <no-reply@example.com>"
+func NewSMTPMailer(from string, config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("mail: SMTP_HOST is required")
+	}
+	addr, err := netmail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid from address %q: %w", from, err)
+	}
+	switch config.TLS {
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("mail: invalid SMTP_TLS %q", config.TLS)
+	}
+	return &SMTPMailer{from: addr.String(), fromAddr: addr.Address, config: config}, nil
+}
+
+// Send 发送邮件；starttls 模式下服务器不支持 STARTTLS 时拒绝发送，不降级为明文
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	data, err := build(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("mail: connect smtp: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if m.config.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("mail: smtp server does not support STARTTLS")
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return fmt.Errorf("mail: starttls: %w", err)
+		}
+	}
+	if m.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return fmt.Errorf("mail: smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(m.fromAddr); err != nil {
+		return fmt.Errorf("mail: MAIL FROM: %w", err)
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("mail: RCPT TO: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("mail: DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mail: write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: send message: %w", err)
+	}
+	return c.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	if m.config.TLS == TLSImplicit {
+		d := &tls.Dialer{Config: &tls.Config{ServerName: m.config.Host}}
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
@@ -0,0 +1,122 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// 模板名称
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+)
+
+// Languages 支持的语言，第一个为默认语言
+var Languages = []string{"zh", "en"}
+
+// TemplateData 模板变量
+type TemplateData struct {
+	Username   string
+	Link       string // 一次性链接
+	ValidHours int    // 链接有效小时数
+}
+
+type templateSource struct {
+	subject string
+	text    string
+	html    string
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+const htmlLayout = `<!DOCTYPE html><html><body style="font-family:sans-serif;line-height:1.6;color:#222">%s</body></html>`
+
+var templateSources = map[string]map[string]templateSource{
+	"zh": {
+		TemplateVerifyEmail: {
+			subject: "验证你的 GoShort 邮箱",
+			text:    "{{.Username}}，你好：\n\n请打开下面的链接验证邮箱（{{.ValidHours}} 小时内有效）：\n{{.Link}}\n\n如果这不是你的操作，请忽略此邮件。\n",
+			html:    `<p>{{.Username}}，你好：</p><p>请点击下面的按钮验证邮箱（{{.ValidHours}} 小时内有效）：</p><p><a href="{{.Link}}">验证邮箱</a></p><p>如果这不是你的操作，请忽略此邮件。</p>`,
+		},
+		TemplateResetPassword: {
+			subject: "重置你的 GoShort 密码",
+			text:    "{{.Username}}，你好：\n\n我们收到了重置密码的请求。请打开下面的链接设置新密码（{{.ValidHours}} 小时内有效，只能使用一次）：\n{{.Link}}\n\n如果这不是你的操作，请忽略此邮件，你的密码不会改变。\n",
+			html:    `<p>{{.Username}}，你好：</p><p>我们收到了重置密码的请求。请点击下面的按钮设置新密码（{{.ValidHours}} 小时内有效，只能使用一次）：</p><p><a href="{{.Link}}">重置密码</a></p><p>如果这不是你的操作，请忽略此邮件，你的密码不会改变。</p>`,
+		},
+	},
+	"en": {
+		TemplateVerifyEmail: {
+			subject: "Verify your GoShort email address",
+			text:    "Hi {{.Username}},\n\nOpen the link below to verify your email address (valid for {{.ValidHours}} hours):\n{{.Link}}\n\nIf you didn't request this, you can ignore this email.\n",
+			html:    `<p>Hi {{.Username}},</p><p>Click the button below to verify your email address (valid for {{.ValidHours}} hours):</p><p><a href="{{.Link}}">Verify email</a></p><p>If you didn't request this, you can ignore this email.</p>`,
+		},
+		TemplateResetPassword: {
+			subject: "Reset your GoShort password",
+			text:    "Hi {{.Username}},\n\nWe received a request to reset your password. Open the link below to choose a new one (valid for {{.ValidHours}} hours, single use):\n{{.Link}}\n\nIf you didn't request this, you can ignore this email and your password will stay the same.\n",
+			html:    `<p>Hi {{.Username}},</p><p>We received a request to reset your password. Click the button below to choose a new one (valid for {{.ValidHours}} hours, single use):</p><p><a href="{{.Link}}">Reset password</a></p><p>If you didn't request this, you can ignore this email and your password will stay the same.</p>`,
+		},
+	},
+}
+
+// templates 启动时编译，模板有误直接 panic
+var templates = compileTemplates()
+
+func compileTemplates() map[string]map[string]compiledTemplate {
+	out := make(map[string]map[string]compiledTemplate, len(templateSources))
+	for lang, byName := range templateSources {
+		out[lang] = make(map[string]compiledTemplate, len(byName))
+		for name, src := range byName {
+			id := lang + "/" + name
+			out[lang][name] = compiledTemplate{
+				subject: texttemplate.Must(texttemplate.New(id + ".subject").Parse(src.subject)),
+				text:    texttemplate.Must(texttemplate.New(id + ".text").Parse(src.text)),
+				html:    htmltemplate.Must(htmltemplate.New(id + ".html").Parse(fmt.Sprintf(htmlLayout, src.html))),
+			}
+		}
+	}
+	return out
+}
+
+// Render 按语言渲染模板，不支持的语言使用默认语言
+func Render(name, lang, to string, data TemplateData) (Message, error) {
+	byName, ok := templates[lang]
+	if !ok {
+		byName = templates[Languages[0]]
+	}
+	t, ok := byName[name]
+	if !ok {
+		return Message{}, fmt.Errorf("mail: unknown template %q", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject.String(), Text: text.String(), HTML: html.String()}, nil
+}
+
+// MatchLanguage 从 Accept-Language 中选出第一个支持的语言（按出现顺序，忽略权重与地区），都不支持时返回 fallback
+func MatchLanguage(acceptLanguage, fallback string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		for _, lang := range Languages {
+			if base == lang {
+				return lang
+			}
+		}
+	}
+	return fallback
+}
@@ -2,46 +2,224 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"go-short/internal/service"
 	"go-short/internal/util"
+	"log"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// AuthMiddleware 通用认证中间件：解析 token 并设置用户信息（不检查角色）
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		tokenStr := c.GetHeader("Authorization")
+// revocationStore access token 吊销列表，由 SetTokenRevocationStore 在启动时设置；为 nil 时不检查吊销
+var revocationStore repository.TokenRevocationStore
+
+// SetTokenRevocationStore 设置吊销列表（API 服务启动时调用）
+func SetTokenRevocationStore(store repository.TokenRevocationStore) {
+	revocationStore = store
+}
+
+// APIKeyAuthenticator 校验个人 API Key（由 APIKeyService 实现）
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey, clientIP string) (*model.APIKey, *model.User, error)
+}
+
+// apiKeyAuthenticator 由 SetAPIKeyAuthenticator 在启动时设置；为 nil 时不接受 API Key
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator 设置 API Key 校验器（API 服务启动时调用）
+func SetAPIKeyAuthenticator(auth APIKeyAuthenticator) {
+	apiKeyAuthenticator = auth
+}
 
-		// 检查 Authorization header 格式
-		if tokenStr == "" {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header required"})
+// PermissionResolver 查询用户当前角色与权限（由 RoleService 实现，带缓存）
+type PermissionResolver interface {
+	ResolvePermissions(ctx context.Context, userID uuid.UUID) (string, []string, error)
+}
+
+// permissionResolver 由 SetPermissionResolver 在启动时设置；为 nil 时按 token 中的角色使用内置权限
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver 设置权限查询（API 服务启动时调用）
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// AuthMiddleware 通用认证中间件：解析 token 并设置用户信息与当前权限（不检查权限，见 RequirePermission）。
+// 传入 scopes 的路由同时接受 API Key（Authorization: Bearer gsk_... 或 X-API-Key），且 Key 必须具备全部 scopes；
+// 未声明 scopes 的路由只接受 JWT
+func AuthMiddleware(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rawKey, ok := apiKeyFromRequest(c); ok {
+			authenticateAPIKey(c, rawKey, scopes)
 			return
 		}
-
-		// 提取Token (Bearer <token>)
-		if len(tokenStr) <= 7 || !strings.HasPrefix(tokenStr, "Bearer ") {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid authorization header format"})
+		claims, ok := authenticate(c)
+		if !ok {
 			return
 		}
-
-		token := tokenStr[7:]
-		claims, err := util.ParseToken(token)
-		if err != nil {
-			// token 解析失败（可能是过期、签名错误等）
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired token"})
+		setUserContext(c, claims)
+		if !setPermissions(c, claims) {
+			return
+		}
+		if !setActiveWorkspace(c) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		if claims == nil {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token claims"})
+// setPermissions 按用户当前角色设置 role 与 permissions（token 有效期内角色可能已变更），
+// 用户已删除时写入 401，无法查询时写入 503
+func setPermissions(c *gin.Context, claims *util.UserClaims) bool {
+	if permissionResolver == nil {
+		var perms []string
+		if claims.Role == model.RoleAdmin {
+			perms = slices.Clone(model.Permissions)
+		}
+		c.Set("permissions", perms)
+		return true
+	}
+	role, perms, err := permissionResolver.ResolvePermissions(c, claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.AbortWithStatusJSON(401, gin.H{"error": "User no longer exists"})
+			return false
+		}
+		log.Printf("⚠️ Permission lookup failed: %v", err)
+		c.AbortWithStatusJSON(503, gin.H{"error": "Authentication temporarily unavailable"})
+		return false
+	}
+	c.Set("role", role)
+	c.Set("permissions", perms)
+	return true
+}
+
+// setActiveWorkspace 解析 X-Workspace-ID 请求头（当前工作区），格式错误时写入 400 并中止请求；
+// 成员身份由 Service 校验
+func setActiveWorkspace(c *gin.Context) bool {
+	raw := c.GetHeader("X-Workspace-ID")
+	if raw == "" {
+		return true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		c.AbortWithStatusJSON(400, gin.H{"error": "Invalid X-Workspace-ID header"})
+		return false
+	}
+	c.Set("workspace_id", id)
+	return true
+}
+
+// apiKeyFromRequest 提取请求中的 API Key
+func apiKeyFromRequest(c *gin.Context) (string, bool) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key, true
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && strings.HasPrefix(token, util.APIKeyPrefix) {
+		return token, true
+	}
+	return "", false
+}
+
+// authenticateAPIKey 校验 API Key 与路由所需权限范围，通过后设置用户信息并继续
+func authenticateAPIKey(c *gin.Context, rawKey string, scopes []string) {
+	if len(scopes) == 0 || apiKeyAuthenticator == nil {
+		c.AbortWithStatusJSON(403, gin.H{"error": "API key not allowed for this endpoint"})
+		return
+	}
+	key, user, err := apiKeyAuthenticator.AuthenticateAPIKey(c, rawKey, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKey):
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired API key"})
+		case errors.Is(err, service.ErrAPIKeyIPNotAllowed):
+			c.AbortWithStatusJSON(403, gin.H{"error": "IP address not allowed for this API key"})
+		case errors.Is(err, service.ErrUserDisabled):
+			c.AbortWithStatusJSON(403, gin.H{"error": "User is disabled"})
+		default:
+			log.Printf("⚠️ API key authentication failed: %v", err)
+			c.AbortWithStatusJSON(503, gin.H{"error": "Authentication temporarily unavailable"})
+		}
+		return
+	}
+	for _, scope := range scopes {
+		if !key.HasScope(scope) {
+			c.AbortWithStatusJSON(403, gin.H{"error": "API key missing required scope: " + scope})
 			return
 		}
+	}
 
-		// 设置用户信息到上下文
-		c.Set("uid", claims.UserID.String())
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Next()
+	c.Set("uid", user.ID.String())
+	c.Set("username", user.Username)
+	c.Set("role", model.RoleUser) // API Key 不继承角色权限，只能操作本人资源
+	c.Set("permissions", []string(nil))
+	c.Set("api_key_id", key.ID.String())
+	if !setActiveWorkspace(c) {
+		return
+	}
+	c.Next()
+}
+
+// authenticate 解析 Bearer token 并检查是否已吊销，失败时写入 401 并中止请求
+func authenticate(c *gin.Context) (*util.UserClaims, bool) {
+	tokenStr := c.GetHeader("Authorization")
+
+	// 检查 Authorization header 格式
+	if tokenStr == "" {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header required"})
+		return nil, false
+	}
+
+	// 提取Token (Bearer <token>)
+	if len(tokenStr) <= 7 || !strings.HasPrefix(tokenStr, "Bearer ") {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid authorization header format"})
+		return nil, false
+	}
+
+	token := tokenStr[7:]
+	claims, err := util.ParseToken(token)
+	if err != nil {
+		// token 解析失败（可能是过期、签名错误等）
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired token"})
+		return nil, false
+	}
+
+	if claims == nil || claims.IssuedAt == nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token claims"})
+		return nil, false
+	}
+
+	// 检查吊销列表（登出、禁用用户、refresh token 重放）；无法确认时拒绝而不是放行
+	if revocationStore != nil {
+		revoked, err := revocationStore.IsTokenRevoked(c, claims.ID, claims.SessionID, claims.UserID, claims.IssuedTime())
+		if err != nil {
+			log.Printf("⚠️ Token revocation check failed: %v", err)
+			c.AbortWithStatusJSON(503, gin.H{"error": "Authentication temporarily unavailable"})
+			return nil, false
+		}
+		if revoked {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Token has been revoked"})
+			return nil, false
+		}
+	}
+	return claims, true
+}
+
+// setUserContext 设置用户信息到上下文（jti、过期时间供登出使用）
+func setUserContext(c *gin.Context, claims *util.UserClaims) {
+	c.Set("uid", claims.UserID.String())
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("jti", claims.ID)
+	c.Set("sid", claims.SessionID)
+	if claims.ExpiresAt != nil {
+		c.Set("token_exp", claims.ExpiresAt.Time)
 	}
 }
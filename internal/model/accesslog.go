@@ -1,15 +1,21 @@
 package model
 
-import "time"
+import (
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// AccessLog 访问日志，按 visited_at 月分区（分区键必须包含在主键中）
 type AccessLog struct {
-	ID        int64     `gorm:"primaryKey"`
-	LinkID    int64     `gorm:"index:idx_access_logs_link_id"`
-	ShortCode string    `gorm:"not null;size:20"`
-	IPAddress string    `gorm:"size:45"` // 支持IPv6
-	UserAgent string    `gorm:"type:text"`
-	Referer   string    `gorm:"type:text"`
-	VisitedAt time.Time `gorm:"column:visited_at;not null;index:,sort:desc"`
+	ID        int64      `gorm:"primaryKey;autoIncrement"`
+	LinkID    int64      `gorm:"index:idx_access_logs_link_id;index:idx_access_logs_link_time,priority:1"`
+	ShortCode string     `gorm:"not null;size:20"`
+	IPAddress string     `gorm:"size:45"` // 支持IPv6
+	UserAgent string     `gorm:"type:text"`
+	Referer   string     `gorm:"type:text"`
+	ClickID   *uuid.UUID `gorm:"type:uuid"` // 开启转化追踪的链接才有
+	VisitedAt time.Time  `gorm:"column:visited_at;primaryKey;not null;index:,sort:desc;index:idx_access_logs_link_time,priority:2"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// AccessLogPartition access_logs 月分区元数据（汇总、归档状态）
+type AccessLogPartition struct {
+	Name       string     `gorm:"primaryKey;size:64"`
+	RangeStart time.Time  `gorm:"not null"`
+	RangeEnd   time.Time  `gorm:"not null"`
+	RolledUpAt *time.Time // 已汇总进 link_daily_stats 的时间
+	ArchivedAt *time.Time // 已归档（移出主表）的时间
+	DroppedAt  *time.Time // 已删除的时间
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+func (AccessLogPartition) TableName() string {
+	return "access_log_partitions"
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Key 权限范围
+const (
+	ScopeLinksRead  = "links:read"  // 查询链接、历史、二维码、健康状态
+	ScopeLinksWrite = "links:write" // 创建、编辑、删除、恢复链接
+	ScopeStatsRead  = "stats:read"  // 访问统计、仪表盘、访问日志导出
+
+	ScopeConversionsWrite = "conversions:write" // 落地页服务端上报转化
+)
+
+// APIKeyScopes 全部可授予的权限范围
+var APIKeyScopes = []string{ScopeLinksRead, ScopeLinksWrite, ScopeStatsRead, ScopeConversionsWrite}
+
+// APIKey 用户的个人 API Key，完整密钥为 gsk_<Prefix>_<secret>，只存 secret 的哈希
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_api_keys_user_id"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16;not null;uniqueIndex:idx_api_keys_prefix"` // 明文前缀，用于查找和在列表中辨认
+	SecretHash string     `gorm:"size:64;not null"`                                 // SHA-256 十六进制
+	Scopes     []string   `gorm:"type:jsonb;serializer:json;not null"`
+	AllowedIPs []string   `gorm:"type:jsonb;serializer:json;not null"` // IP 或 CIDR，为空不限制
+	ExpiresAt  *time.Time // 为空永不过期
+	LastUsedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope 是否授予了指定权限范围
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 认证审计事件类型
+const (
+	AuthEventLoginFailed    = "login_failed"    // 用户名或密码错误、用户已禁用
+	AuthEventLockout        = "lockout"         // 失败次数过多，用户名或 IP 被临时锁定
+	AuthEventLockoutCleared = "lockout_cleared" // 管理员解除锁定
+)
+
+// AuthEvent 认证审计事件。登录被节流或锁定期间的请求不写入，避免攻击期间表无限增长
+type AuthEvent struct {
+	ID        int64      `gorm:"primaryKey"`
+	Event     string     `gorm:"size:32;not null;index:idx_auth_events_event_created,priority:1"`
+	Username  string     `gorm:"size:64;not null;default:'';index:idx_auth_events_username"`
+	UserID    *uuid.UUID `gorm:"type:uuid"` // 用户存在时记录
+	IP        string     `gorm:"size:45;not null;default:''"`
+	UserAgent string     `gorm:"size:255;not null;default:''"`
+	Reason    string     `gorm:"size:64;not null;default:''"` // 如 invalid_password、unknown_user、user_disabled
+	ActorID   *uuid.UUID `gorm:"type:uuid"`                   // 解除锁定的管理员
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_auth_events_event_created,priority:2;index:idx_auth_events_created_at"`
+}
+
+func (AuthEvent) TableName() string {
+	return "auth_events"
+}
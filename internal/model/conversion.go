@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversion 转化事件，通过点击 ID 归因到某次短链接点击
+type Conversion struct {
+	ID        int64     `gorm:"primaryKey"`
+	ClickID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_conversions_click_event,priority:1"`
+	Event     string    `gorm:"size:64;not null;default:'conversion';uniqueIndex:idx_conversions_click_event,priority:2"`
+	LinkID    int64     `gorm:"not null;index:idx_conversions_link_id"`
+	ShortCode string    `gorm:"not null;size:20"`
+	Value     float64   `gorm:"type:numeric(18,4);default:0"`
+	Currency  string    `gorm:"size:3;default:''"`
+	ClickedAt time.Time `gorm:"not null"` // 由 UUIDv7 还原，用于关联 access_logs 时做分区裁剪
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (Conversion) TableName() string {
+	return "conversions"
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain 用户绑定的自定义短链域名，需通过 DNS TXT 记录验证所有权后才能使用
+type Domain struct {
+	ID          int64      `gorm:"primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_domains_user_id"`
+	Hostname    string     `gorm:"size:253;not null;uniqueIndex:idx_domains_hostname"` // 小写，不含端口
+	VerifyToken string     `gorm:"size:64;not null"`
+	VerifiedAt  *time.Time // 为空表示尚未验证
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+func (Domain) TableName() string {
+	return "domains"
+}
+
+// LinkKey 短链在缓存、布隆过滤器中的唯一键：默认域名下为短码本身，自定义域名下为 "域名/短码"
+func LinkKey(domain, code string) string {
+	if domain == "" {
+		return code
+	}
+	return domain + "/" + code
+}
+
+// ParseLinkKey LinkKey 的逆操作
+func ParseLinkKey(key string) (domain, code string) {
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
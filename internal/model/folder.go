@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Folder 链接文件夹，一个链接最多属于一个文件夹
+type Folder struct {
+	ID        int64     `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_folders_user_name,priority:1"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_folders_user_name,priority:2"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (Folder) TableName() string {
+	return "folders"
+}
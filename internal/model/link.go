@@ -4,19 +4,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Link struct {
-	ID          int64      `gorm:"primaryKey"`
-	ShortCode   string     `gorm:"not null;unique;size:20;default:'';index:idx_links_short_code"`
-	OriginalURL string     `gorm:"not null;type:text"`
-	Alias       string     `gorm:"size:100;default:''"`
-	UserID      uuid.UUID  `gorm:"type:uuid;index:idx_links_user_id;index:idx_links_user_created,priority:1"`
-	IsCustom    bool       `gorm:"default:false"`
-	VisitCount  int64      `gorm:"default:0"`
-	ExpiresAt   *time.Time `gorm:"index:idx_links_expires_at"`
-	Status      bool       `gorm:"default:true"`
-	CreatedAt   time.Time  `gorm:"autoCreateTime;index:idx_links_user_created,priority:2"`
+	ID               int64          `gorm:"primaryKey"`
+	Domain           string         `gorm:"size:253;not null;default:'';uniqueIndex:idx_links_domain_code,priority:1"` // 自定义域名，空表示默认域名（BASE_URL）
+	ShortCode        string         `gorm:"not null;size:20;default:'';uniqueIndex:idx_links_domain_code,priority:2;index:idx_links_short_code"`
+	OriginalURL      string         `gorm:"not null;type:text"`
+	Alias            string         `gorm:"size:100;default:''"`
+	UserID           uuid.UUID      `gorm:"type:uuid;index:idx_links_user_id;index:idx_links_user_created,priority:1"`
+	IsCustom         bool           `gorm:"default:false"`
+	VisitCount       int64          `gorm:"default:0"` // 累计点击，由维护任务从 link_daily_stats 同步
+	ExpiresAt        *time.Time     `gorm:"index:idx_links_expires_at"`
+	Status           bool           `gorm:"default:true"`
+	TrackConversions bool           `gorm:"default:false"`      // 重定向时追加签名点击 ID，用于转化归因
+	Version          int64          `gorm:"not null;default:1"` // 乐观锁版本号，每次编辑 +1
+	FolderID         *int64         `gorm:"index:idx_links_folder_id"`
+	WorkspaceID      *int64         `gorm:"index:idx_links_workspace_id"` // 所属工作区，空表示创建者的个人链接
+	CreatedAt        time.Time      `gorm:"autoCreateTime;index:idx_links_user_created,priority:2"`
+	DeletedAt        gorm.DeletedAt `gorm:"index:idx_links_deleted_at"` // 软删除（回收站），期间短码仍被占用
 }
 
 // TableName 指定表名
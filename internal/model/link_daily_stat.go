@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// LinkDailyStat 链接按天汇总的访问统计（由 access_logs 汇总而来，原始日志过期后仍保留）
+type LinkDailyStat struct {
+	LinkID         int64     `gorm:"primaryKey;autoIncrement:false"`
+	Day            time.Time `gorm:"primaryKey;type:date;index:idx_link_daily_stats_day"`
+	Clicks         int64     `gorm:"not null;default:0"`
+	UniqueVisitors int64     `gorm:"not null;default:0"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (LinkDailyStat) TableName() string {
+	return "link_daily_stats"
+}
+
+// RollupCheckpoint 汇总任务进度：RolledUpTo 之前的整天已汇总完成（不含 RolledUpTo 当天）
+type RollupCheckpoint struct {
+	Name       string    `gorm:"primaryKey;size:64"`
+	RolledUpTo time.Time `gorm:"type:date;not null"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (RollupCheckpoint) TableName() string {
+	return "rollup_checkpoints"
+}
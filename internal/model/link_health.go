@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// LinkHealth 链接目标地址的最近一次健康检查结果（每个链接一行）
+type LinkHealth struct {
+	LinkID              int64     `gorm:"primaryKey;autoIncrement:false"`
+	CheckedURL          string    `gorm:"not null;type:text"`            // 检查时的 original_url，链接改了目标地址后结果作废
+	StatusCode          int       `gorm:"not null;default:0"`            // 0 表示请求失败（DNS、超时、连接被拒等）
+	FinalURL            string    `gorm:"not null;type:text;default:''"` // 跟随重定向后的最终地址
+	LatencyMs           int64     `gorm:"not null;default:0"`
+	Error               string    `gorm:"size:255;not null;default:''"`
+	Broken              bool      `gorm:"not null;default:false"`
+	ConsecutiveFailures int       `gorm:"not null;default:0"`
+	CheckedAt           time.Time `gorm:"not null;index:idx_link_health_checked_at"`
+}
+
+func (LinkHealth) TableName() string {
+	return "link_health"
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 链接变更类型
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update" // 编辑目标地址、别名、短码、过期时间、启停等
+	RevisionDelete   = "delete" // 移入回收站
+	RevisionRestore  = "restore"
+	RevisionRollback = "rollback"
+)
+
+// LinkRevision 链接的一次变更记录：操作人、时间、字段差异，以及变更后的状态快照（用于回滚）
+type LinkRevision struct {
+	ID      int64     `gorm:"primaryKey"`
+	LinkID  int64     `gorm:"not null;index:idx_link_revisions_link,priority:1"`
+	Action  string    `gorm:"size:20;not null"`
+	ActorID uuid.UUID `gorm:"type:uuid;not null"`
+	Changes []byte    `gorm:"type:jsonb;not null"` // {"字段": {"from": 旧值, "to": 新值}}，创建时只有 to
+
+	// 变更后的快照
+	OriginalURL      string `gorm:"not null;type:text"`
+	Alias            string `gorm:"size:100;not null;default:''"`
+	ShortCode        string `gorm:"size:20;not null;default:''"`
+	Status           bool   `gorm:"not null"`
+	ExpiresAt        *time.Time
+	TrackConversions bool      `gorm:"not null;default:false"`
+	RollbackOf       *int64    // 回滚时指向目标版本
+	CreatedAt        time.Time `gorm:"not null;index:idx_link_revisions_link,priority:2"`
+}
+
+func (LinkRevision) TableName() string {
+	return "link_revisions"
+}
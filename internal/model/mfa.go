@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA 用户的 TOTP 两步验证，开始绑定时写入，确认验证码后启用
+type UserMFA struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Secret      string    `gorm:"size:64;not null"` // base32 TOTP 密钥
+	Enabled     bool      `gorm:"not null;default:false"`
+	LastCounter int64     `gorm:"not null;default:0"` // 最近一次使用的时间步，同一验证码不能重复使用
+	EnabledAt   *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode 一次性恢复码，只存 SHA-256 哈希
+type MFARecoveryCode struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_mfa_recovery_codes_user_id"`
+	CodeHash  string    `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
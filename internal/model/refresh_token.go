@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken 刷新令牌（只存哈希）。每次刷新都轮换出新令牌，同一次登录产生的令牌属于同一个 FamilyID；
+// 已轮换的令牌再次出现即视为泄露，整个家族随之吊销
+type RefreshToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_refresh_tokens_user_id"`
+	FamilyID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_refresh_tokens_family_id"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex:idx_refresh_tokens_hash"` // SHA-256 十六进制
+	ExpiresAt  time.Time  `gorm:"not null;index:idx_refresh_tokens_expires_at"`
+	RotatedAt  *time.Time // 已换发新令牌
+	ReplacedBy *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt  *time.Time // 登出、禁用用户或检测到重放
+	UserAgent  string     `gorm:"size:255;not null;default:''"`
+	IP         string     `gorm:"size:45;not null;default:''"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
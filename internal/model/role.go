@@ -0,0 +1,65 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// 系统权限
+const (
+	PermUsersManage    = "users.manage"     // 创建、删除、启停、恢复用户，分配角色
+	PermLinksModerate  = "links.moderate"   // 查看和操作任意用户的链接，启停链接
+	PermLogsRead       = "logs.read"        // 查看和导出访问日志
+	PermURLRulesManage = "url_rules.manage" // 管理目标域名黑白名单
+	PermRolesManage    = "roles.manage"     // 管理自定义角色
+)
+
+// Permissions 全部可授予的权限
+var Permissions = []string{PermUsersManage, PermLinksModerate, PermLogsRead, PermURLRulesManage, PermRolesManage}
+
+// 内置角色，不能修改或删除
+const (
+	RoleAdmin = "admin" // 始终拥有全部权限（包括以后新增的）
+	RoleUser  = "user"  // 普通用户，没有系统权限
+)
+
+// Role 角色及其权限，users.role 保存角色名
+type Role struct {
+	Name        string    `gorm:"primaryKey;size:20"`
+	Description string    `gorm:"size:200;not null;default:''"`
+	Permissions []string  `gorm:"type:jsonb;serializer:json;not null"`
+	BuiltIn     bool      `gorm:"not null;default:false"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,19}$`)
+
+// ValidRoleName 角色名：小写字母开头，2-20 位小写字母、数字、- 或 _
+func ValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name)
+}
+
+// IsPermission 是否为已定义的权限
+func IsPermission(perm string) bool {
+	return slices.Contains(Permissions, perm)
+}
+
+// IsBuiltinRole 是否为内置角色
+func IsBuiltinRole(name string) bool {
+	return name == RoleAdmin || name == RoleUser
+}
+
+// EffectivePermissions 角色实际拥有的权限：admin 始终为全部权限
+func (r *Role) EffectivePermissions() []string {
+	if r.Name == RoleAdmin {
+		return slices.Clone(Permissions)
+	}
+	return r.Permissions
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session 登录会话：一次登录对应一个 refresh token 家族（ID 即 FamilyID），每次刷新令牌时更新最近活跃时间与客户端信息；
+// access token 通过 sid 声明关联会话，终止会话时一并吊销
+type Session struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_sessions_user_id"`
+	UserAgent  string     `gorm:"size:255;not null;default:''"`
+	IP         string     `gorm:"size:45;not null;default:''"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	LastSeenAt time.Time  `gorm:"not null"`
+	ExpiresAt  time.Time  `gorm:"not null;index:idx_sessions_expires_at"` // 当前 refresh token 的过期时间
+	RevokedAt  *time.Time // 登出、终止会话、修改密码或禁用用户
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
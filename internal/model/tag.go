@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag 用户自定义标签，与链接多对多（link_tags）
+type Tag struct {
+	ID        int64     `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name,priority:1"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name,priority:2"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// LinkTag 链接与标签的关联
+type LinkTag struct {
+	LinkID int64 `gorm:"primaryKey;autoIncrement:false"`
+	TagID  int64 `gorm:"primaryKey;autoIncrement:false;index:idx_link_tags_tag_id"`
+}
+
+func (LinkTag) TableName() string {
+	return "link_tags"
+}
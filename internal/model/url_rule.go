@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// URL 规则类型
+const (
+	URLRuleBlock = "block"
+	URLRuleAllow = "allow"
+)
+
+// URLRule 管理员维护的目标域名黑白名单，Pattern 为域名或 "*.域名"（匹配该域名及其所有子域名）
+type URLRule struct {
+	ID        int64     `gorm:"primaryKey"`
+	Pattern   string    `gorm:"size:255;not null;uniqueIndex:idx_url_rules_pattern"` // 小写
+	Action    string    `gorm:"size:10;not null"`                                    // block | allow
+	Reason    string    `gorm:"size:255;not null;default:''"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (URLRule) TableName() string {
+	return "url_rules"
+}
@@ -4,18 +4,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primary_key"`
-	Username     string    `gorm:"not null;unique;size:64"`
-	PasswordHash string    `gorm:"not null;column:password_hash"`
-	Email        *string   `gorm:"size:128"`
-	Role         string    `gorm:"size:20;default:'user'"`
-	Status       string    `gorm:"size:20;default:'active'"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
-	LinkCount    int64     `gorm:"default:0"`
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primary_key;index:idx_users_created_id,priority:2"`
+	Username        string         `gorm:"not null;unique;size:64"`
+	PasswordHash    string         `gorm:"not null;column:password_hash"`
+	Email           *string        `gorm:"size:128"`
+	EmailVerifiedAt *time.Time     `gorm:"column:email_verified_at"` // 邮箱变更后清空
+	Role            string         `gorm:"size:20;default:'user'"`
+	Status          string         `gorm:"size:20;default:'active'"`
+	CreatedAt       time.Time      `gorm:"column:created_at;autoCreateTime;index:idx_users_created_id,priority:1"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	LinkCount       int64          `gorm:"default:0"`
+	DeletedAt       gorm.DeletedAt `gorm:"index:idx_users_deleted_at"` // 软删除，期间用户名仍被占用
 }
 
 func (User) TableName() string {
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity 外部 IdP 账号（OIDC 的 iss 对应的提供方 + sub）与本地用户的关联，一个用户可关联多个
+type UserIdentity struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index:idx_user_identities_user_id"`
+	Provider    string    `gorm:"size:32;not null;uniqueIndex:idx_user_identities_provider_subject,priority:1"`
+	Subject     string    `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject,priority:2"`
+	Email       string    `gorm:"size:128;not null;default:''"` // 最近一次登录时 IdP 返回的邮箱
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	LastLoginAt time.Time
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
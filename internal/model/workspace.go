@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 工作区成员角色
+const (
+	WorkspaceRoleOwner  = "owner"  // 管理成员、邀请，编辑与删除工作区
+	WorkspaceRoleEditor = "editor" // 创建、编辑、删除工作区链接
+	WorkspaceRoleViewer = "viewer" // 查看工作区链接与统计
+)
+
+// 邀请状态
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+var workspaceRoleRank = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// IsValidWorkspaceRole 是否为有效的工作区角色
+func IsValidWorkspaceRole(role string) bool {
+	return workspaceRoleRank[role] > 0
+}
+
+// WorkspaceRoleAllows 角色 role 是否具备 need 所需的权限（owner ⊇ editor ⊇ viewer）
+func WorkspaceRoleAllows(role, need string) bool {
+	return workspaceRoleRank[role] > 0 && workspaceRoleRank[role] >= workspaceRoleRank[need]
+}
+
+// Workspace 团队工作区，链接可归属于工作区并由成员按角色共同管理
+type Workspace struct {
+	ID        int64     `gorm:"primaryKey"`
+	Name      string    `gorm:"size:100;not null"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceMember 工作区成员
+type WorkspaceMember struct {
+	WorkspaceID int64     `gorm:"primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_workspace_members_user_id"`
+	Role        string    `gorm:"size:20;not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// WorkspaceInvitation 工作区邀请（按用户名或邮箱邀请已注册用户，被邀请人接受后成为成员）
+type WorkspaceInvitation struct {
+	ID          int64     `gorm:"primaryKey"`
+	WorkspaceID int64     `gorm:"not null;index:idx_workspace_invitations_workspace_id"`
+	InviterID   uuid.UUID `gorm:"type:uuid;not null"`
+	InviteeID   uuid.UUID `gorm:"type:uuid;not null;index:idx_workspace_invitations_invitee_id"`
+	Role        string    `gorm:"size:20;not null"`
+	Status      string    `gorm:"size:20;not null;default:'pending'"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	RespondedAt *time.Time
+}
+
+func (WorkspaceInvitation) TableName() string {
+	return "workspace_invitations"
+}
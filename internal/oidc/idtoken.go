@@ -0,0 +1,249 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 接受的 ID Token 签名算法（不接受 HS*，对称密钥即 client_secret，不适合多方场景）
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims 校验通过的 ID Token 声明
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	Raw               map[string]any
+}
+
+// Values 取声明的字符串值，name 支持 a.b 路径；值可以是字符串或字符串数组
+func (c *Claims) Values(name string) []string {
+	var v any = map[string]any(c.Raw)
+	for _, part := range strings.Split(name, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// MapRole 按 RoleClaim 与映射规则得到本地角色，未配置 RoleClaim 或未匹配时返回 DefaultRole（可能为空）
+func (p *Provider) MapRole(claims *Claims) string {
+	if p.config.RoleClaim != "" {
+		values := claims.Values(p.config.RoleClaim)
+		for _, m := range p.config.RoleMappings {
+			if slices.Contains(values, m.Value) {
+				return m.Role
+			}
+		}
+	}
+	return p.config.DefaultRole
+}
+
+// VerifyIDToken 校验签名（JWKS）、iss、aud、azp、exp、iat 与 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*Claims, error) {
+	mc := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, mc, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	// 多个 aud 时 azp 必须为本客户端
+	if aud, _ := mc.GetAudience(); len(aud) > 1 {
+		if azp, _ := mc["azp"].(string); azp != p.config.ClientID {
+			return nil, fmt.Errorf("%w: azp mismatch", ErrInvalidToken)
+		}
+	}
+	if got, _ := mc["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+	sub, _ := mc.GetSubject()
+	if sub == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+
+	claims := &Claims{Subject: sub, Raw: mc}
+	claims.Email, _ = mc["email"].(string)
+	claims.PreferredUsername, _ = mc["preferred_username"].(string)
+	claims.Name, _ = mc["name"].(string)
+	// 部分 IdP 以字符串 "true" 返回 email_verified
+	switch v := mc["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	return claims, nil
+}
+
+// key 按 kid 查找公钥，未知 kid 时重新拉取 JWKS（IdP 轮换密钥），两次拉取至少间隔一分钟
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetched) < jwksMinRefresh {
+		return nil, errors.New("unknown key id")
+	}
+	p.mu.Unlock()
+	keys, err := p.fetchKeys(ctx)
+	p.mu.Lock()
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysFetched = keys, time.Now()
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, errors.New("unknown key id")
+}
+
+// lookupKey 调用方持有锁；token 未带 kid 且 JWKS 只有一个密钥时使用该密钥
+func (p *Provider) lookupKey(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		k, err := jwk.publicKey()
+		if err != nil {
+			continue // 跳过不支持的密钥类型
+		}
+		keys[jwk.Kid] = k
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey 解析 RSA、EC（P-256/384/521）与 OKP（Ed25519）公钥
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
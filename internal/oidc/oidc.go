@@ -0,0 +1,291 @@
+// Package oidc OpenID Connect 依赖方（RP）：发现文档、授权码 + PKCE、按 JWKS 校验 ID Token。
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxResponseSize  = 1 << 20          // 发现文档、JWKS、令牌响应的最大字节数
+	discoveryTTL     = time.Hour        // 发现文档缓存时间
+	jwksMinRefresh   = time.Minute      // 遇到未知 kid 时两次拉取 JWKS 的最小间隔
+	httpTimeout      = 10 * time.Second // 与 IdP 通信的超时
+	defaultScopes    = "openid email profile"
+	providerNameExpr = `^[a-z][a-z0-9_-]{0,31}$`
+)
+
+var (
+	ErrDiscovery     = errors.New("oidc: discovery failed")
+	ErrTokenExchange = errors.New("oidc: token exchange failed")
+	ErrInvalidToken  = errors.New("oidc: invalid id token")
+)
+
+var providerNameRegex = regexp.MustCompile(providerNameExpr)
+
+// RoleMapping 声明值到本地角色的映射，如 groups 中包含 Value 时授予 Role
+type RoleMapping struct {
+	Value string
+	Role  string
+}
+
+// Config 单个 IdP 的配置
+type Config struct {
+	Name         string // 路由中的提供方名称，如 corp
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 为空时按公共客户端处理（只靠 PKCE）
+	RedirectURL  string
+	Scopes       []string
+	RoleClaim    string        // 用于映射角色的声明，支持 a.b 路径；为空时不同步角色
+	RoleMappings []RoleMapping // 按配置顺序匹配，先匹配的优先
+	DefaultRole  string        // 未匹配任何映射时的角色
+	LinkByEmail  bool          // 按已验证邮箱关联已有本地账号（仅对可信 IdP 开启）
+}
+
+// LoadConfigsFromEnv 从环境变量读取 IdP 配置：OIDC_PROVIDERS=corp,okta，每个提供方读取
+// OIDC_<NAME>_ISSUER、_CLIENT_ID、_CLIENT_SECRET、_REDIRECT_URL（必填前两项与 REDIRECT_URL）、
+// _SCOPES（空格分隔，默认 openid email profile）、_ROLE_CLAIM、_ROLE_MAPPING（group=role,group2=role2）、
+// _DEFAULT_ROLE（默认 user）、_LINK_BY_EMAIL（true/false）；缺少必填项的提供方被跳过
+func LoadConfigsFromEnv() ([]Config, []error) {
+	var configs []Config
+	var errs []error
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !providerNameRegex.MatchString(name) {
+			errs = append(errs, fmt.Errorf("oidc provider %q: invalid name", name))
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		cfg := Config{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			RoleClaim:    os.Getenv(prefix + "ROLE_CLAIM"),
+			DefaultRole:  os.Getenv(prefix + "DEFAULT_ROLE"),
+			LinkByEmail:  os.Getenv(prefix+"LINK_BY_EMAIL") == "true",
+		}
+		if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+			errs = append(errs, fmt.Errorf("oidc provider %q: %sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", name, prefix, prefix, prefix))
+			continue
+		}
+		if len(cfg.Scopes) == 0 {
+			cfg.Scopes = strings.Fields(defaultScopes)
+		}
+		if !slices.Contains(cfg.Scopes, "openid") {
+			cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+		}
+		for _, pair := range strings.Split(os.Getenv(prefix+"ROLE_MAPPING"), ",") {
+			value, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || value == "" || role == "" {
+				continue
+			}
+			cfg.RoleMappings = append(cfg.RoleMappings, RoleMapping{Value: value, Role: role})
+		}
+		configs = append(configs, cfg)
+	}
+	return configs, errs
+}
+
+// Discovery 发现文档中用到的字段
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Provider 单个 IdP 的客户端，发现文档与 JWKS 按需拉取并缓存，可并发使用
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         map[string]any // kid → 公钥
+	keysFetched  time.Time
+}
+
+// NewProvider client 为 nil 时使用带超时的默认客户端
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: httpTimeout}
+	}
+	return &Provider{config: config, client: client}
+}
+
+// Config 提供方配置
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+// Discover 获取发现文档（缓存一小时），issuer 必须与配置一致
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		d := p.discovery
+		p.mu.Unlock()
+		return d, nil
+	}
+	p.mu.Unlock()
+
+	var d Discovery
+	if err := p.getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch %q", ErrDiscovery, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+
+	p.mu.Lock()
+	p.discovery, p.discoveredAt = &d, time.Now()
+	p.mu.Unlock()
+	return &d, nil
+}
+
+// AuthCodeURL 授权请求地址（code + PKCE S256）
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", ErrDiscovery)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// TokenResponse 令牌端点响应
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Exchange 用授权码和 PKCE verifier 换取令牌
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.config.ClientID},
+	}
+	// 默认 client_secret_basic；IdP 只声明支持 client_secret_post 时放在表单中
+	useBasic := p.config.ClientSecret != "" &&
+		(len(d.TokenAuthMethods) == 0 || slices.Contains(d.TokenAuthMethods, "client_secret_basic"))
+	if p.config.ClientSecret != "" && !useBasic {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, fmt.Errorf("%w: status %d %s %s", ErrTokenExchange, resp.StatusCode, e.Error, e.Description)
+	}
+	var tr TokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrTokenExchange)
+	}
+	return &tr, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", rawURL, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// NewPKCE 生成 code_verifier 与对应的 S256 code_challenge
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString 32 字节随机数的 base64url 编码，用于 state、nonce、code_verifier
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
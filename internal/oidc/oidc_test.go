@@ -0,0 +1,463 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "goshort"
+	testClientSecret = "s3cret"
+	testRedirectURL  = "https://short.example.com/api/v1/auth/sso/corp/callback"
+)
+
+// mockIdP 最小化的 OpenID Provider：发现文档、JWKS、令牌端点（校验授权码、PKCE 与客户端凭据）
+type mockIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu                 sync.Mutex
+	authMethods        []string                 // token_endpoint_auth_methods_supported
+	issuer             string                   // 发现文档中的 issuer，为空时使用服务器地址
+	keys               map[string]crypto.Signer // kid → 私钥，按 JWKS 发布
+	codes              map[string]authorization // 授权码 → 授权请求
+	discoveryHits      int
+	jwksHits           int
+	lastTokenForm      url.Values
+	lastBasicAuth      [2]string
+	signingKeyID       string
+	signingMethod      jwt.SigningMethod
+	signingKeyOverride crypto.Signer // 非 nil 时用该密钥签名（模拟伪造的 token）
+}
+
+type authorization struct {
+	nonce         string
+	codeChallenge string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIdP{
+		t:             t,
+		keys:          map[string]crypto.Signer{"rsa-1": rsaKey},
+		codes:         map[string]authorization{},
+		signingKeyID:  "rsa-1",
+		signingMethod: jwt.SigningMethodRS256,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// claims 一组有效的 ID Token 声明
+func (m *mockIdP) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            m.srv.URL,
+		"aud":            testClientID,
+		"sub":            "user-123",
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []string{"staff", "short-admins"},
+	}
+}
+
+func (m *mockIdP) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "corp",
+		Issuer:       m.srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email"},
+	}, m.srv.Client())
+}
+
+// authorize 模拟用户在 IdP 登录并同意：记录授权请求，返回授权码
+func (m *mockIdP) authorize(authURL string) string {
+	m.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	q := u.Query()
+	code, _ := RandomString()
+	m.mu.Lock()
+	m.codes[code] = authorization{nonce: q.Get("nonce"), codeChallenge: q.Get("code_challenge")}
+	m.mu.Unlock()
+	return code
+}
+
+func (m *mockIdP) sign(claims jwt.MapClaims) string {
+	m.t.Helper()
+	m.mu.Lock()
+	key := m.keys[m.signingKeyID]
+	if m.signingKeyOverride != nil {
+		key = m.signingKeyOverride
+	}
+	token := jwt.NewWithClaims(m.signingMethod, claims)
+	token.Header["kid"] = m.signingKeyID
+	m.mu.Unlock()
+	raw, err := token.SignedString(key)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	return raw
+}
+
+func (m *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.discoveryHits++
+	issuer := m.issuer
+	methods := m.authMethods
+	m.mu.Unlock()
+	if issuer == "" {
+		issuer = m.srv.URL
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                m.srv.URL + "/authorize",
+		"token_endpoint":                        m.srv.URL + "/token",
+		"jwks_uri":                              m.srv.URL + "/jwks",
+		"token_endpoint_auth_methods_supported": methods,
+	})
+}
+
+func (m *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jwksHits++
+	var keys []map[string]string
+	for kid, key := range m.keys {
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": b64(pub.N.Bytes()),
+				"e": b64(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": b64(pub)})
+		}
+	}
+	// 加密用途的密钥应被忽略
+	keys = append(keys, map[string]string{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"})
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func (m *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user, pass, _ := r.BasicAuth()
+	m.mu.Lock()
+	m.lastTokenForm = r.PostForm
+	m.lastBasicAuth = [2]string{user, pass}
+	a, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code")) // 授权码一次性
+	m.mu.Unlock()
+
+	tokenError := func(code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": "rejected by mock idp"})
+	}
+	secret := pass
+	if user == "" {
+		secret = r.PostForm.Get("client_secret")
+	}
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError("unsupported_grant_type")
+	case r.PostForm.Get("client_id") != testClientID || secret != testClientSecret:
+		tokenError("invalid_client")
+	case !ok || r.PostForm.Get("redirect_uri") != testRedirectURL:
+		tokenError("invalid_grant")
+	case s256(r.PostForm.Get("code_verifier")) != a.codeChallenge:
+		tokenError("invalid_grant")
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     m.sign(m.claims(a.nonce)),
+		})
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return b64(sum[:])
+}
+
+func TestDiscover(t *testing.T) {
+	m := newMockIdP(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	d, err := p.Discover(ctx)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if d.TokenEndpoint != m.srv.URL+"/token" || d.JWKSURI != m.srv.URL+"/jwks" {
+		t.Fatalf("unexpected discovery: %+v", d)
+	}
+	if _, err := p.Discover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if m.discoveryHits != 1 {
+		t.Errorf("discovery fetched %d times, want 1 (cached)", m.discoveryHits)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	m := newMockIdP(t)
+	m.issuer = "https://evil.example.com"
+	_, err := m.provider().Discover(context.Background())
+	if !errors.Is(err, ErrDiscovery) {
+		t.Fatalf("Discover = %v, want ErrDiscovery", err)
+	}
+}
+
+func TestLoginFlowWithPKCE(t *testing.T) {
+	m := newMockIdP(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s256(verifier) != challenge {
+		t.Fatal("challenge must be S256(verifier)")
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("auth url %s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if u.Path != "/authorize" {
+		t.Errorf("auth url path = %q", u.Path)
+	}
+
+	code := m.authorize(authURL)
+	tokens, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if m.lastBasicAuth != [2]string{testClientID, testClientSecret} || m.lastTokenForm.Has("client_secret") {
+		t.Errorf("expected client_secret_basic, got basic=%v form=%v", m.lastBasicAuth, m.lastTokenForm)
+	}
+
+	claims, err := p.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if groups := claims.Values("groups"); !slices.Contains(groups, "short-admins") {
+		t.Errorf("groups = %v", groups)
+	}
+
+	// 授权码只能用一次
+	if _, err := p.Exchange(ctx, code, verifier); !errors.Is(err, ErrTokenExchange) {
+		t.Errorf("reusing code = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	m := newMockIdP(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	_, challenge, _ := NewPKCE()
+	authURL, err := p.AuthCodeURL(ctx, "s", "n", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, _ := NewPKCE()
+	if _, err := p.Exchange(ctx, m.authorize(authURL), other); !errors.Is(err, ErrTokenExchange) {
+		t.Fatalf("Exchange with wrong verifier = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestExchangeClientSecretPost(t *testing.T) {
+	m := newMockIdP(t)
+	m.authMethods = []string{"client_secret_post"}
+	p := m.provider()
+	ctx := context.Background()
+
+	verifier, challenge, _ := NewPKCE()
+	authURL, err := p.AuthCodeURL(ctx, "s", "n", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(ctx, m.authorize(authURL), verifier); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if m.lastBasicAuth[0] != "" || m.lastTokenForm.Get("client_secret") != testClientSecret {
+		t.Errorf("expected client_secret_post, got basic=%v form=%v", m.lastBasicAuth, m.lastTokenForm)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	m := newMockIdP(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		mutate func(c jwt.MapClaims)
+		forge  bool
+	}{
+		{name: "wrong nonce", mutate: func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{name: "missing nonce", mutate: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "wrong aud", mutate: func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{name: "multiple aud without azp", mutate: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "another-client"} }},
+		{name: "wrong iss", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-5 * time.Minute).Unix() }},
+		{name: "missing exp", mutate: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "issued in the future", mutate: func(c jwt.MapClaims) { c["iat"] = time.Now().Add(10 * time.Minute).Unix() }},
+		{name: "missing sub", mutate: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "forged signature", mutate: func(jwt.MapClaims) {}, forge: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := m.claims("nonce-1")
+			tc.mutate(claims)
+			if tc.forge {
+				m.signingKeyOverride = forged
+				defer func() { m.signingKeyOverride = nil }()
+			}
+			_, err := p.VerifyIDToken(ctx, m.sign(claims), "nonce-1")
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("VerifyIDToken = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	// 多个 aud 时 azp 为本客户端则接受
+	claims := m.claims("nonce-1")
+	claims["aud"] = []string{testClientID, "another-client"}
+	claims["azp"] = testClientID
+	if _, err := p.VerifyIDToken(ctx, m.sign(claims), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken with azp: %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsSymmetricAlgorithm(t *testing.T) {
+	m := newMockIdP(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, m.claims("n"))
+	token.Header["kid"] = "rsa-1"
+	raw, err := token.SignedString([]byte(testClientSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.provider().VerifyIDToken(context.Background(), raw, "n"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("VerifyIDToken(HS256) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	m := newMockIdP(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, m.sign(m.claims("n")), "n"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if _, err := p.VerifyIDToken(ctx, m.sign(m.claims("n")), "n"); err != nil {
+		t.Fatal(err)
+	}
+	if m.jwksHits != 1 {
+		t.Fatalf("jwks fetched %d times, want 1 (cached)", m.jwksHits)
+	}
+
+	// IdP 轮换到新的 Ed25519 密钥
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.keys["ed-2"] = edKey
+	m.signingKeyID, m.signingMethod = "ed-2", jwt.SigningMethodEdDSA
+	m.mu.Unlock()
+
+	// 刚拉取过 JWKS，未知 kid 不会立即重新拉取
+	if _, err := p.VerifyIDToken(ctx, m.sign(m.claims("n")), "n"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("VerifyIDToken within refresh interval = %v, want ErrInvalidToken", err)
+	}
+	if m.jwksHits != 1 {
+		t.Fatalf("jwks fetched %d times, want 1 (rate limited)", m.jwksHits)
+	}
+
+	p.mu.Lock()
+	p.keysFetched = time.Now().Add(-2 * jwksMinRefresh)
+	p.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, m.sign(m.claims("n")), "n"); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+	if m.jwksHits != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", m.jwksHits)
+	}
+}
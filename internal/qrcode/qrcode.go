@@ -0,0 +1,308 @@
+// Package qrcode 短链接二维码：基于 rsc.io/qr 生成矩阵，纯 Go 渲染 PNG / SVG，支持尺寸、纠错级别、边距、颜色和中心 Logo。
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Logo 支持 JPEG
+	"image/png"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// Format 输出格式
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+// 参数范围
+const (
+	DefaultSize   = 256
+	MinSize       = 64
+	MaxSize       = 2048
+	DefaultMargin = 4 // 静区宽度（模块数），规范建议至少 4
+	MaxMargin     = 16
+	logoRatio     = 0.22 // Logo 占二维码内容区宽度的比例，H 级纠错下可安全遮挡
+)
+
+// PublicSizes 公开二维码接口允许的尺寸，限制参数组合以控制渲染开销和缓存条目数
+var PublicSizes = []int{128, 256, 512, 1024}
+
+var ErrInvalidOptions = errors.New("invalid qr code options")
+
+// Options 渲染参数，零值不可直接使用，请通过 ParseOptions 或 DefaultOptions 构造
+type Options struct {
+	Format     Format
+	Size       int    // PNG 边长像素，SVG 为 viewBox 宽高
+	Level      string // L | M | Q | H
+	Margin     int    // 静区宽度（模块数）
+	Foreground color.RGBA
+	Background color.RGBA
+	Logo       bool // 是否在中心嵌入服务端配置的 Logo
+}
+
+// DefaultOptions 默认参数：256px PNG，M 级纠错，黑码白底
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Level:      "M",
+		Margin:     DefaultMargin,
+		Foreground: color.RGBA{A: 0xFF},
+		Background: color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+	}
+}
+
+// ParseOptions 解析查询参数：format、size、level、margin、fg、bg（#RRGGBB 或 RRGGBB）、logo
+// 开启 logo 且未指定 level 时使用 H 级纠错，保证遮挡后仍可识别
+func ParseOptions(q url.Values) (Options, error) {
+	opts := DefaultOptions()
+
+	switch Format(strings.ToLower(q.Get("format"))) {
+	case "", FormatPNG:
+	case FormatSVG:
+		opts.Format = FormatSVG
+	default:
+		return opts, ErrInvalidOptions
+	}
+	if s := q.Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < MinSize || n > MaxSize {
+			return opts, ErrInvalidOptions
+		}
+		opts.Size = n
+	}
+	if s := q.Get("margin"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > MaxMargin {
+			return opts, ErrInvalidOptions
+		}
+		opts.Margin = n
+	}
+	if s := q.Get("logo"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return opts, ErrInvalidOptions
+		}
+		opts.Logo = b
+	}
+	if s := strings.ToUpper(q.Get("level")); s != "" {
+		if _, ok := levels[s]; !ok {
+			return opts, ErrInvalidOptions
+		}
+		opts.Level = s
+	} else if opts.Logo {
+		opts.Level = "H"
+	}
+
+	var err error
+	if s := q.Get("fg"); s != "" {
+		if opts.Foreground, err = parseHexColor(s); err != nil {
+			return opts, ErrInvalidOptions
+		}
+	}
+	if s := q.Get("bg"); s != "" {
+		if opts.Background, err = parseHexColor(s); err != nil {
+			return opts, ErrInvalidOptions
+		}
+	}
+	return opts, nil
+}
+
+// ParsePublicOptions 解析公开接口的参数：size 只能取 PublicSizes，不支持 fg / bg（自定义颜色请使用需登录的链接二维码接口）
+func ParsePublicOptions(q url.Values) (Options, error) {
+	if q.Get("fg") != "" || q.Get("bg") != "" {
+		return DefaultOptions(), ErrInvalidOptions
+	}
+	opts, err := ParseOptions(q)
+	if err != nil {
+		return opts, err
+	}
+	if !slices.Contains(PublicSizes, opts.Size) {
+		return opts, ErrInvalidOptions
+	}
+	return opts, nil
+}
+
+var levels = map[string]qr.Level{"L": qr.L, "M": qr.M, "Q": qr.Q, "H": qr.H}
+
+// parseHexColor 解析 #RRGGBB / RRGGBB
+func parseHexColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, ErrInvalidOptions
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return color.RGBA{}, ErrInvalidOptions
+	}
+	return color.RGBA{R: b[0], G: b[1], B: b[2], A: 0xFF}, nil
+}
+
+// ContentType 输出格式对应的 Content-Type
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// CacheKey 内容与规范化参数的哈希，用作本地缓存 / Redis 键和 ETag
+func (o Options) CacheKey(content string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%d|%x|%x|%t",
+		content, o.Format, o.Size, o.Level, o.Margin, o.Foreground, o.Background, o.Logo)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Render 将 content 编码为二维码图片；logo 为 nil 时忽略 Options.Logo
+func Render(content string, opts Options, logo image.Image) ([]byte, error) {
+	code, err := qr.Encode(content, levels[opts.Level])
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Logo {
+		logo = nil
+	}
+	if opts.Format == FormatSVG {
+		return renderSVG(code, opts, logo)
+	}
+	return renderPNG(code, opts, logo)
+}
+
+// renderPNG 按整数倍模块尺寸绘制，剩余像素平均分给四周，避免缩放产生模糊边缘
+func renderPNG(code *qr.Code, opts Options, logo image.Image) ([]byte, error) {
+	modules := code.Size + 2*opts.Margin
+	scale := opts.Size / modules
+	if scale < 1 {
+		scale = 1
+	}
+	size := opts.Size
+	if modules*scale > size {
+		size = modules * scale
+	}
+	offset := (size-modules*scale)/2 + opts.Margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
+	fg := &image.Uniform{C: opts.Foreground}
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				r := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, r, fg, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if logo != nil {
+		box := logoBox(code.Size*scale, offset, scale)
+		draw.Draw(img, box, &image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
+		pad := scale
+		inner := box.Inset(pad)
+		draw.Draw(img, inner, scaleImage(logo, inner.Dx(), inner.Dy()), image.Point{}, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// logoBox 中心 Logo 区域（含背景留白），边长对齐到模块
+func logoBox(codePixels, offset, scale int) image.Rectangle {
+	side := int(float64(codePixels)*logoRatio) / scale * scale
+	if side < 3*scale {
+		side = 3 * scale
+	}
+	start := offset + (codePixels-side)/2/scale*scale
+	return image.Rect(start, start, start+side, start+side)
+}
+
+// scaleImage 最近邻缩放到 w×h（Logo 很小，无需高质量插值）
+func scaleImage(src image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return dst
+}
+
+// renderSVG 以模块为单位绘制矢量图，同一行连续的深色模块合并为一段路径
+func renderSVG(code *qr.Code, opts Options, logo image.Image) ([]byte, error) {
+	modules := code.Size + 2*opts.Margin
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, modules, modules, hexColor(opts.Background))
+
+	buf.WriteString(`<path fill="` + hexColor(opts.Foreground) + `" d="`)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; {
+			if !code.Black(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < code.Size && code.Black(x, y) {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if logo != nil {
+		box := logoBox(code.Size, opts.Margin, 1)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+			box.Min.X, box.Min.Y, box.Dx(), box.Dy(), hexColor(opts.Background))
+		var logoPNG bytes.Buffer
+		if err := png.Encode(&logoPNG, logo); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`,
+			box.Min.X+1, box.Min.Y+1, box.Dx()-2, box.Dy()-2, base64.StdEncoding.EncodeToString(logoPNG.Bytes()))
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// LoadLogoFromEnv 读取 QR_LOGO_FILE 指定的 PNG / JPEG Logo，未配置返回 nil
+func LoadLogoFromEnv() (image.Image, error) {
+	path := os.Getenv("QR_LOGO_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode qr logo %s: %w", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,35 @@
+package local
+
+import (
+	"sync"
+)
+
+// DomainSet 已验证自定义域名的内存快照，redirect 按 Host 头判断短码所属域名
+type DomainSet struct {
+	mu    sync.RWMutex
+	hosts map[string]struct{}
+}
+
+// NewDomainSet 创建空集合，需定期调用 Replace 刷新
+func NewDomainSet() *DomainSet {
+	return &DomainSet{hosts: make(map[string]struct{})}
+}
+
+// Replace 用最新的域名列表整体替换
+func (s *DomainSet) Replace(hosts []string) {
+	m := make(map[string]struct{}, len(hosts))
+	for _, h := range hosts {
+		m[h] = struct{}{}
+	}
+	s.mu.Lock()
+	s.hosts = m
+	s.mu.Unlock()
+}
+
+// Contains host 需已规范化（小写、无端口）
+func (s *DomainSet) Contains(host string) bool {
+	s.mu.RLock()
+	_, ok := s.hosts[host]
+	s.mu.RUnlock()
+	return ok
+}
@@ -0,0 +1,262 @@
+package postgresql
+
+// ==========================================
+// access_logs 月分区管理（Worker 定时维护）
+// ==========================================
+
+import (
+	"context"
+	"fmt"
+	"go-short/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	accessLogTable         = "access_logs"
+	accessLogPartitionFmt  = "access_logs_p200601"
+	accessLogDefaultTable  = "access_logs_default" // 兜底分区：接收不属于任何月分区的行（如保留期已清理月份的重放日志）
+	AccessLogArchiveSchema = "archive"
+	initialPartitionsAhead = 3 // 启动时至少保证未来 3 个月的分区存在
+)
+
+type accessLogPartitionRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewAccessLogPartitionRepository 创建 AccessLogPartitionRepository 实例
+func NewAccessLogPartitionRepository(db *gorm.DB) *accessLogPartitionRepoImpl {
+	return &accessLogPartitionRepoImpl{db: db}
+}
+
+// MonthStart 返回 t 所在月份的第一天零点（UTC），分区边界统一使用 UTC
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName 月分区表名，例如 access_logs_p202601
+func partitionName(month time.Time) string {
+	return month.Format(accessLogPartitionFmt)
+}
+
+// EnsureMonthlyPartitions 创建 [from, to] 覆盖的所有月分区（已存在则跳过），返回新建的分区名
+func (d *accessLogPartitionRepoImpl) EnsureMonthlyPartitions(ctx context.Context, from, to time.Time) ([]string, error) {
+	return ensureMonthlyPartitions(d.db.WithContext(ctx), from, to)
+}
+
+func ensureMonthlyPartitions(db *gorm.DB, from, to time.Time) ([]string, error) {
+	existing, err := attachedPartitions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	for month := MonthStart(from); !month.After(MonthStart(to)); month = month.AddDate(0, 1, 0) {
+		name := partitionName(month)
+		if existing[name] {
+			continue
+		}
+		end := month.AddDate(0, 1, 0)
+		sql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			name, accessLogTable, month.Format(time.RFC3339), end.Format(time.RFC3339))
+		if err := db.Exec(sql).Error; err != nil {
+			return created, fmt.Errorf("create partition %s: %w", name, err)
+		}
+		partition := model.AccessLogPartition{Name: name, RangeStart: month, RangeEnd: end}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&partition).Error; err != nil {
+			return created, err
+		}
+		created = append(created, name)
+	}
+	return created, nil
+}
+
+// attachedPartitions 当前挂载在 access_logs 上的分区
+func attachedPartitions(db *gorm.DB) (map[string]bool, error) {
+	var names []string
+	err := db.Raw(`
+		SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ? AND p.relnamespace = current_schema()::regnamespace`, accessLogTable).
+		Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(names))
+	for _, n := range names {
+		result[n] = true
+	}
+	return result, nil
+}
+
+// ListPartitions 列出仍挂载在主表上的分区（按时间升序）
+func (d *accessLogPartitionRepoImpl) ListPartitions(ctx context.Context) ([]model.AccessLogPartition, error) {
+	var partitions []model.AccessLogPartition
+	err := d.db.WithContext(ctx).
+		Where("archived_at IS NULL AND dropped_at IS NULL").
+		Order("range_start ASC").
+		Find(&partitions).Error
+	return partitions, err
+}
+
+// MarkRolledUp 标记分区已汇总
+func (d *accessLogPartitionRepoImpl) MarkRolledUp(ctx context.Context, name string) error {
+	return d.db.WithContext(ctx).Model(&model.AccessLogPartition{}).
+		Where("name = ?", name).
+		Update("rolled_up_at", time.Now()).Error
+}
+
+// DropPartition 删除分区（数据不可恢复，调用方需确保已汇总）
+func (d *accessLogPartitionRepoImpl) DropPartition(ctx context.Context, name string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.AccessLogPartition{}).Where("name = ?", name).Update("dropped_at", time.Now()).Error
+	})
+}
+
+// ArchivePartition 将分区从主表摘下并移动到 archive schema（数据保留，查询不再扫描）
+func (d *accessLogPartitionRepoImpl) ArchivePartition(ctx context.Context, name string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %s`, AccessLogArchiveSchema),
+			fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, accessLogTable, name),
+			fmt.Sprintf(`ALTER TABLE %s SET SCHEMA %s`, name, AccessLogArchiveSchema),
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.AccessLogPartition{}).Where("name = ?", name).Update("archived_at", time.Now()).Error
+	})
+}
+
+// ==========================================
+// 建表与迁移（NewPostgresClient 启动时调用）
+// ==========================================
+
+// migrateAccessLogPartitioning 确保 access_logs 为按月范围分区表；
+// 若已存在旧的普通表，则一次性迁移为分区表（数据复制后删除旧表）
+func migrateAccessLogPartitioning(db *gorm.DB) error {
+	var relkind string
+	err := db.Raw(`SELECT relkind::text FROM pg_class WHERE relname = ? AND relnamespace = current_schema()::regnamespace`, accessLogTable).
+		Scan(&relkind).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch relkind {
+	case "p":
+		// 已是分区表：补齐新增列与索引，再补齐分区
+		if err := db.Exec(`ALTER TABLE access_logs ADD COLUMN IF NOT EXISTS click_id uuid`).Error; err != nil {
+			return err
+		}
+		if err := createAccessLogIndexes(db); err != nil {
+			return err
+		}
+		if err := createDefaultAccessLogPartition(db); err != nil {
+			return err
+		}
+		_, err := ensureMonthlyPartitions(db, now, now.AddDate(0, initialPartitionsAhead, 0))
+		return err
+	case "":
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := createPartitionedAccessLogs(tx); err != nil {
+				return err
+			}
+			if _, err := ensureMonthlyPartitions(tx, now, now.AddDate(0, initialPartitionsAhead, 0)); err != nil {
+				return err
+			}
+			return createAccessLogIndexes(tx)
+		})
+	}
+
+	log.Println("🔧 Converting access_logs to a monthly partitioned table...")
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE access_logs RENAME TO access_logs_legacy`).Error; err != nil {
+			return err
+		}
+		if err := createPartitionedAccessLogs(tx); err != nil {
+			return err
+		}
+
+		var minVisited *time.Time
+		if err := tx.Raw(`SELECT MIN(visited_at) FROM access_logs_legacy`).Scan(&minVisited).Error; err != nil {
+			return err
+		}
+		from := now
+		if minVisited != nil && minVisited.Before(now) {
+			from = *minVisited
+		}
+		if _, err := ensureMonthlyPartitions(tx, from, now.AddDate(0, initialPartitionsAhead, 0)); err != nil {
+			return err
+		}
+
+		stmts := []string{
+			`INSERT INTO access_logs (id, link_id, short_code, ip_address, user_agent, referer, visited_at)
+			 SELECT id, link_id, short_code, ip_address, user_agent, referer, visited_at FROM access_logs_legacy`,
+			`SELECT setval(pg_get_serial_sequence('access_logs', 'id'), COALESCE((SELECT MAX(id) FROM access_logs), 0) + 1, false)`,
+			`DROP TABLE access_logs_legacy`,
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if err := createAccessLogIndexes(tx); err != nil {
+			return err
+		}
+		log.Println("✅ access_logs converted to partitioned table")
+		return nil
+	})
+}
+
+func createPartitionedAccessLogs(tx *gorm.DB) error {
+	err := tx.Exec(`
+		CREATE TABLE access_logs (
+			id         bigserial    NOT NULL,
+			link_id    bigint,
+			short_code varchar(20)  NOT NULL,
+			ip_address varchar(45),
+			user_agent text,
+			referer    text,
+			click_id   uuid,
+			visited_at timestamptz  NOT NULL,
+			PRIMARY KEY (id, visited_at)
+		) PARTITION BY RANGE (visited_at)`).Error
+	if err != nil {
+		return err
+	}
+	return createDefaultAccessLogPartition(tx)
+}
+
+// createDefaultAccessLogPartition 创建 DEFAULT 分区，visited_at 超出已有月分区的日志写入这里而不是插入失败。
+// 不记录在 access_log_partitions 中，不参与汇总与保留策略；未来月份的分区须在数据到达前创建（Worker 会把未来时间修正为当前时间）
+func createDefaultAccessLogPartition(tx *gorm.DB) error {
+	return tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s DEFAULT`, accessLogDefaultTable, accessLogTable)).Error
+}
+
+// createAccessLogIndexes 在主表上建索引，PostgreSQL 会自动同步到每个分区
+func createAccessLogIndexes(tx *gorm.DB) error {
+	stmts := []string{
+		`CREATE INDEX IF NOT EXISTS idx_access_logs_link_id ON access_logs (link_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_access_logs_link_time ON access_logs (link_id, visited_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_access_logs_visited_at ON access_logs (visited_at DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_logs_code_time ON access_logs (short_code, visited_at DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_access_logs_click_id ON access_logs (click_id) WHERE click_id IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ package postgresql
 import (
 	"context"
 	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -29,14 +31,44 @@ func (d *accessLogRepoImpl) SaveAccessLog(ctx context.Context, tx *gorm.DB, logE
 }
 
 // GetRecentAccessLogs 获取最近 N 条访问日志（按 VisitedAt 倒序）
+// 先只查本月和上月分区（分区裁剪），不足 N 条时再放开时间条件
 func (d *accessLogRepoImpl) GetRecentAccessLogs(ctx context.Context, tx *gorm.DB, limit int) ([]model.AccessLog, error) {
 	if tx == nil {
 		tx = d.db
 	}
+	since := MonthStart(time.Now()).AddDate(0, -1, 0)
 	var logs []model.AccessLog
 	err := tx.WithContext(ctx).Model(&model.AccessLog{}).
+		Where("visited_at >= ?", since).
 		Order("visited_at DESC").
 		Limit(limit).
 		Find(&logs).Error
+	if err != nil || len(logs) >= limit {
+		return logs, err
+	}
+
+	logs = logs[:0]
+	err = tx.WithContext(ctx).Model(&model.AccessLog{}).
+		Order("visited_at DESC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// GetAccessLogsAfter 按 (visited_at, id) 升序读取游标之后的一页访问日志（keyset 分页，用于流式导出）
+func (d *accessLogRepoImpl) GetAccessLogsAfter(ctx context.Context, tx *gorm.DB, filter repository.AccessLogFilter, after *repository.AccessLogCursor, limit int) ([]model.AccessLog, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	query := tx.WithContext(ctx).Model(&model.AccessLog{}).
+		Where("visited_at >= ? AND visited_at < ?", filter.From, filter.To)
+	if filter.LinkID != nil {
+		query = query.Where("link_id = ?", *filter.LinkID)
+	}
+	if after != nil {
+		query = query.Where("(visited_at, id) > (?, ?)", after.VisitedAt, after.ID)
+	}
+	var logs []model.AccessLog
+	err := query.Order("visited_at ASC, id ASC").Limit(limit).Find(&logs).Error
 	return logs, err
 }
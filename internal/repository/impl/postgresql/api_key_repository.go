@@ -0,0 +1,96 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建 APIKeyRepository 实例
+func NewAPIKeyRepository(db *gorm.DB) *apiKeyRepoImpl {
+	return &apiKeyRepoImpl{db: db}
+}
+
+// ==========================================
+// APIKey 相关操作
+// ==========================================
+
+// Create 保存 API Key
+func (d *apiKeyRepoImpl) Create(ctx context.Context, tx *gorm.DB, key *model.APIKey) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(key).Error
+}
+
+// ListByUser 用户的全部 API Key（按创建时间倒序）
+func (d *apiKeyRepoImpl) ListByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.APIKey, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var keys []model.APIKey
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// GetByID 查询用户的某个 API Key
+func (d *apiKeyRepoImpl) GetByID(ctx context.Context, tx *gorm.DB, userID, keyID uuid.UUID) (*model.APIKey, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var key model.APIKey
+	err := tx.WithContext(ctx).Where("id = ? AND user_id = ?", keyID, userID).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByPrefix 按前缀查询 API Key（鉴权时使用）
+func (d *apiKeyRepoImpl) GetByPrefix(ctx context.Context, tx *gorm.DB, prefix string) (*model.APIKey, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var key model.APIKey
+	err := tx.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Update 更新名称、权限范围、IP 白名单和过期时间
+func (d *apiKeyRepoImpl) Update(ctx context.Context, tx *gorm.DB, key *model.APIKey) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(key).
+		Select("name", "scopes", "allowed_ips", "expires_at", "updated_at").
+		Updates(key).Error
+}
+
+// Delete 删除用户的 API Key，返回是否存在
+func (d *apiKeyRepoImpl) Delete(ctx context.Context, tx *gorm.DB, userID, keyID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("id = ? AND user_id = ?", keyID, userID).Delete(&model.APIKey{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// TouchLastUsed 更新最近使用时间（条件更新，interval 内只写一次）
+func (d *apiKeyRepoImpl) TouchLastUsed(ctx context.Context, tx *gorm.DB, keyID uuid.UUID, at time.Time, interval time.Duration) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, at.Add(-interval)).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,67 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type authEventRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewAuthEventRepository 创建 AuthEventRepository 实例
+func NewAuthEventRepository(db *gorm.DB) *authEventRepoImpl {
+	return &authEventRepoImpl{db: db}
+}
+
+// ==========================================
+// AuthEvent 相关操作
+// ==========================================
+
+// Create 写入审计事件
+func (d *authEventRepoImpl) Create(ctx context.Context, tx *gorm.DB, event *model.AuthEvent) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(event).Error
+}
+
+// List 按时间倒序分页查询审计事件
+func (d *authEventRepoImpl) List(ctx context.Context, tx *gorm.DB, filter repository.AuthEventFilter, page, size int) ([]model.AuthEvent, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	query := tx.WithContext(ctx).Model(&model.AuthEvent{})
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []model.AuthEvent
+	err := query.Order("created_at DESC, id DESC").Offset((page - 1) * size).Limit(size).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// DeleteBefore 删除 before 之前的审计事件
+func (d *authEventRepoImpl) DeleteBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("created_at < ?", before).Delete(&model.AuthEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -8,6 +8,7 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -55,11 +56,32 @@ func NewPostgresClient() (*gorm.DB, error) {
 	// 5. 运行自动迁移
 	log.Println("🔧 Running database auto migrations...")
 
-	// 要迁移的模型列表
+	// 要迁移的模型列表（access_logs 为分区表，GORM 无法建分区表，单独处理）
 	err = db.AutoMigrate(
 		&model.User{},
 		&model.Link{},
-		&model.AccessLog{},
+		&model.AccessLogPartition{},
+		&model.LinkDailyStat{},
+		&model.RollupCheckpoint{},
+		&model.Conversion{},
+		&model.Tag{},
+		&model.LinkTag{},
+		&model.Folder{},
+		&model.Domain{},
+		&model.URLRule{},
+		&model.LinkHealth{},
+		&model.LinkRevision{},
+		&model.RefreshToken{},
+		&model.Session{},
+		&model.APIKey{},
+		&model.Workspace{},
+		&model.WorkspaceMember{},
+		&model.WorkspaceInvitation{},
+		&model.Role{},
+		&model.UserIdentity{},
+		&model.UserMFA{},
+		&model.MFARecoveryCode{},
+		&model.AuthEvent{},
 	)
 
 	if err != nil {
@@ -69,5 +91,61 @@ func NewPostgresClient() (*gorm.DB, error) {
 		log.Println("✅ Database migrations completed")
 	}
 
+	if err := migrateAccessLogPartitioning(db); err != nil {
+		log.Printf("⚠️  access_logs partitioning warning: %v", err)
+	}
+
+	if err := migrateLinkDomainScope(db); err != nil {
+		log.Printf("⚠️  link domain scope warning: %v", err)
+	}
+
+	if err := seedBuiltinRoles(db); err != nil {
+		log.Printf("⚠️  builtin roles warning: %v", err)
+	}
+
+	if err := createLinkSearchIndexes(db); err != nil {
+		log.Printf("⚠️  link search indexes warning: %v (search falls back to sequential scan)", err)
+	}
+
 	return db, nil
 }
+
+// seedBuiltinRoles 写入内置角色 admin / user（已存在时不覆盖）
+func seedBuiltinRoles(db *gorm.DB) error {
+	roles := []model.Role{
+		{Name: model.RoleAdmin, Description: "管理员，拥有全部权限", Permissions: model.Permissions, BuiltIn: true},
+		{Name: model.RoleUser, Description: "普通用户", Permissions: []string{}, BuiltIn: true},
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&roles).Error
+}
+
+// createLinkSearchIndexes 为别名/URL 子串搜索创建 pg_trgm GIN 索引，使 ILIKE '%q%' 可走索引
+func createLinkSearchIndexes(db *gorm.DB) error {
+	stmts := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_links_alias_trgm ON links USING gin (alias gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_links_original_url_trgm ON links USING gin (original_url gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_links_user_visit_count ON links (user_id, visit_count DESC)`,
+	}
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLinkDomainScope 短码唯一性改为 (domain, short_code) 范围：删除旧的全局唯一约束
+// 新的联合唯一索引 idx_links_domain_code 由 AutoMigrate 创建
+func migrateLinkDomainScope(db *gorm.DB) error {
+	stmts := []string{
+		`ALTER TABLE links DROP CONSTRAINT IF EXISTS uni_links_short_code`,
+		`ALTER TABLE links DROP CONSTRAINT IF EXISTS links_short_code_key`,
+	}
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type domainRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewDomainRepository 创建 DomainRepository 实例
+func NewDomainRepository(db *gorm.DB) *domainRepoImpl {
+	return &domainRepoImpl{db: db}
+}
+
+// ==========================================
+// Domain 相关操作
+// ==========================================
+
+// Create 添加自定义域名
+func (d *domainRepoImpl) Create(ctx context.Context, tx *gorm.DB, domain *model.Domain) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(domain).Error
+}
+
+// GetDomainsByUser 获取用户的全部域名（按域名排序）
+func (d *domainRepoImpl) GetDomainsByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Domain, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var domains []model.Domain
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("hostname").Find(&domains).Error
+	return domains, err
+}
+
+// GetDomainByID 根据ID查询域名
+func (d *domainRepoImpl) GetDomainByID(ctx context.Context, tx *gorm.DB, domainID int64) (*model.Domain, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var domain model.Domain
+	err := tx.WithContext(ctx).Where("id = ?", domainID).First(&domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetDomainByHostname 根据域名查询
+func (d *domainRepoImpl) GetDomainByHostname(ctx context.Context, tx *gorm.DB, hostname string) (*model.Domain, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var domain model.Domain
+	err := tx.WithContext(ctx).Where("hostname = ?", hostname).First(&domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// MarkVerified 标记域名已通过所有权验证
+func (d *domainRepoImpl) MarkVerified(ctx context.Context, tx *gorm.DB, domainID int64, at time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.Domain{}).Where("id = ?", domainID).Update("verified_at", at).Error
+}
+
+// Delete 删除域名
+func (d *domainRepoImpl) Delete(ctx context.Context, tx *gorm.DB, domainID int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Where("id = ?", domainID).Delete(&model.Domain{}).Error
+}
+
+// ListVerifiedHostnames 所有已验证的域名（Redirect 按 Host 路由使用）
+func (d *domainRepoImpl) ListVerifiedHostnames(ctx context.Context, tx *gorm.DB) ([]string, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var hostnames []string
+	err := tx.WithContext(ctx).Model(&model.Domain{}).
+		Where("verified_at IS NOT NULL").
+		Pluck("hostname", &hostnames).Error
+	return hostnames, err
+}
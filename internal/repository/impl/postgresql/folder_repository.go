@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type folderRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewFolderRepository 创建 FolderRepository 实例
+func NewFolderRepository(db *gorm.DB) *folderRepoImpl {
+	return &folderRepoImpl{db: db}
+}
+
+// ==========================================
+// Folder 相关操作
+// ==========================================
+
+// Create 创建文件夹
+func (d *folderRepoImpl) Create(ctx context.Context, tx *gorm.DB, folder *model.Folder) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(folder).Error
+}
+
+// GetFoldersByUser 获取用户的全部文件夹（按名称排序）
+func (d *folderRepoImpl) GetFoldersByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Folder, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var folders []model.Folder
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&folders).Error
+	return folders, err
+}
+
+// GetFolderByID 根据ID查询文件夹
+func (d *folderRepoImpl) GetFolderByID(ctx context.Context, tx *gorm.DB, folderID int64) (*model.Folder, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var folder model.Folder
+	err := tx.WithContext(ctx).Where("id = ?", folderID).First(&folder).Error
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// Rename 重命名文件夹
+func (d *folderRepoImpl) Rename(ctx context.Context, tx *gorm.DB, folderID int64, name string) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.Folder{}).Where("id = ?", folderID).Update("name", name).Error
+}
+
+// Delete 删除文件夹，其中的链接移出文件夹（不删除链接）
+func (d *folderRepoImpl) Delete(ctx context.Context, tx *gorm.DB, folderID int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 含回收站中的链接，避免恢复后指向不存在的文件夹
+		if err := tx.Unscoped().Model(&model.Link{}).Where("folder_id = ?", folderID).Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", folderID).Delete(&model.Folder{}).Error
+	})
+}
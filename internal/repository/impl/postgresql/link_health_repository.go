@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type linkHealthRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewLinkHealthRepository 创建 LinkHealthRepository 实例
+func NewLinkHealthRepository(db *gorm.DB) *linkHealthRepoImpl {
+	return &linkHealthRepoImpl{db: db}
+}
+
+// ==========================================
+// LinkHealth 相关操作
+// ==========================================
+
+// SaveHealth 写入检查结果（存在则覆盖），连续失败次数在库内累加，成功时清零
+func (d *linkHealthRepoImpl) SaveHealth(ctx context.Context, tx *gorm.DB, health *model.LinkHealth) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "link_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"checked_url": health.CheckedURL,
+			"status_code": health.StatusCode,
+			"final_url":   health.FinalURL,
+			"latency_ms":  health.LatencyMs,
+			"error":       health.Error,
+			"broken":      health.Broken,
+			"checked_at":  health.CheckedAt,
+			"consecutive_failures": gorm.Expr(
+				"CASE WHEN ? THEN link_health.consecutive_failures + 1 ELSE 0 END", health.Broken),
+		}),
+	}).Create(health).Error
+}
+
+// GetHealth 查询单个链接的检查结果
+func (d *linkHealthRepoImpl) GetHealth(ctx context.Context, tx *gorm.DB, linkID int64) (*model.LinkHealth, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var health model.LinkHealth
+	err := tx.WithContext(ctx).Where("link_id = ?", linkID).First(&health).Error
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// GetHealthByLinkIDs 批量查询检查结果，用于列表展示
+func (d *linkHealthRepoImpl) GetHealthByLinkIDs(ctx context.Context, tx *gorm.DB, linkIDs []int64) (map[int64]model.LinkHealth, error) {
+	result := make(map[int64]model.LinkHealth)
+	if len(linkIDs) == 0 {
+		return result, nil
+	}
+	if tx == nil {
+		tx = d.db
+	}
+	var rows []model.LinkHealth
+	if err := tx.WithContext(ctx).Where("link_id IN ?", linkIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.LinkID] = r
+	}
+	return result, nil
+}
+
+// ListLinksDueForCheck 启用且未过期、从未检查、上次检查早于 before 或目标地址已变更的链接，从未检查的优先
+func (d *linkHealthRepoImpl) ListLinksDueForCheck(ctx context.Context, tx *gorm.DB, before time.Time, limit int) ([]model.Link, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var links []model.Link
+	err := tx.WithContext(ctx).Table("links l").
+		Select("l.id, l.original_url").
+		Joins("LEFT JOIN link_health h ON h.link_id = l.id").
+		Where("l.status AND l.deleted_at IS NULL AND (l.expires_at IS NULL OR l.expires_at > NOW())").
+		Where("h.link_id IS NULL OR h.checked_at < ? OR h.checked_url <> l.original_url", before).
+		Order("h.checked_at NULLS FIRST, l.id").
+		Limit(limit).
+		Scan(&links).Error
+	return links, err
+}
+
+// PruneOrphans 删除已删除链接的检查结果
+func (d *linkHealthRepoImpl) PruneOrphans(ctx context.Context, tx *gorm.DB) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Exec(`DELETE FROM link_health h WHERE NOT EXISTS (SELECT 1 FROM links l WHERE l.id = h.link_id)`)
+	return res.RowsAffected, res.Error
+}
@@ -3,11 +3,18 @@ package postgresql
 import (
 	"context"
 	"go-short/internal/model"
+	"go-short/internal/repository"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// linkListColumns 列表查询返回的列
+const linkListColumns = "id, domain, short_code, original_url, alias, user_id, is_custom, visit_count, expires_at, status, track_conversions, version, folder_id, workspace_id, created_at, deleted_at"
+
 type linkRepoImpl struct {
 	db *gorm.DB
 }
@@ -37,15 +44,100 @@ func (d *linkRepoImpl) Update(ctx context.Context, tx *gorm.DB, link *model.Link
 	return tx.WithContext(ctx).Save(link).Error
 }
 
-// GetLinkByCode 根据短码查询链接 (用于重定向服务，通常这里会有 DB 级的 Fallback)
-func (d *linkRepoImpl) GetLinkByCode(ctx context.Context, tx *gorm.DB, code string) (*model.Link, error) {
+// CreateBatch 批量插入链接（调用方需预先分配 ID 和短码，避免多行空短码触发唯一约束）
+func (d *linkRepoImpl) CreateBatch(ctx context.Context, tx *gorm.DB, links []model.Link) error {
+	if tx == nil {
+		tx = d.db
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&links).Error
+}
+
+// NextLinkIDs 从 links 主键序列预取 n 个 ID，用于插入前生成短码
+func (d *linkRepoImpl) NextLinkIDs(ctx context.Context, tx *gorm.DB, n int) ([]int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var ids []int64
+	err := tx.WithContext(ctx).
+		Raw("SELECT nextval(pg_get_serial_sequence('links', 'id')) FROM generate_series(1, ?)", n).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// GetLinksByScopeAndURLs 批量查询个人或工作区在某域名下已创建的启用链接（与 GetLinkByScopeAndURL 条件一致）
+func (d *linkRepoImpl) GetLinksByScopeAndURLs(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, domain string, originalURLs []string) ([]model.Link, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var links []model.Link
+	if len(originalURLs) == 0 {
+		return links, nil
+	}
+	err := applyLinkScope(tx.WithContext(ctx), "", scope).
+		Where("domain = ? AND original_url IN ? AND status = ?", domain, originalURLs, true).
+		Find(&links).Error
+	return links, err
+}
+
+// applyLinkScope 限定链接归属范围；table 为查询中 links 表的别名前缀（如 "l."），可为空
+func applyLinkScope(query *gorm.DB, table string, scope repository.LinkScope) *gorm.DB {
+	if scope.WorkspaceID != nil {
+		return query.Where(table+"workspace_id = ?", *scope.WorkspaceID)
+	}
+	return query.Where(table+"user_id = ? AND "+table+"workspace_id IS NULL", scope.UserID)
+}
+
+// GetExistingShortCodes 返回 codes 中在该域名下已被占用的短码（含回收站中的链接）
+func (d *linkRepoImpl) GetExistingShortCodes(ctx context.Context, tx *gorm.DB, domain string, codes []string) ([]string, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var existing []string
+	if len(codes) == 0 {
+		return existing, nil
+	}
+	err := tx.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Where("domain = ? AND short_code IN ?", domain, codes).
+		Pluck("short_code", &existing).Error
+	return existing, err
+}
+
+// UpdateWithVersion 仅当数据库中的版本号仍为 expectedVersion 时更新可编辑字段（乐观锁）
+// 返回 false 表示版本已变化（被其他请求修改或已删除）
+func (d *linkRepoImpl) UpdateWithVersion(ctx context.Context, tx *gorm.DB, link *model.Link, expectedVersion int64) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	result := tx.WithContext(ctx).Model(&model.Link{}).
+		Where("id = ? AND version = ?", link.ID, expectedVersion).
+		Updates(map[string]any{
+			"original_url":      link.OriginalURL,
+			"alias":             link.Alias,
+			"short_code":        link.ShortCode,
+			"is_custom":         link.IsCustom,
+			"expires_at":        link.ExpiresAt,
+			"status":            link.Status,
+			"track_conversions": link.TrackConversions,
+			"version":           link.Version,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetLinkByCode 根据域名和短码查询链接 (用于重定向服务，通常这里会有 DB 级的 Fallback)
+func (d *linkRepoImpl) GetLinkByCode(ctx context.Context, tx *gorm.DB, domain, code string) (*model.Link, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var link model.Link
-	// 查询条件：短码匹配且状态为启用
+	// 查询条件：域名、短码匹配且状态为启用
 	err := tx.WithContext(ctx).
-		Where("short_code = ? AND status = ?", code, true).
+		Where("domain = ? AND short_code = ? AND status = ?", domain, code, true).
 		First(&link).Error
 
 	if err != nil {
@@ -54,14 +146,14 @@ func (d *linkRepoImpl) GetLinkByCode(ctx context.Context, tx *gorm.DB, code stri
 	return &link, nil
 }
 
-// CheckShortCodeExists 检查短码是否已被占用 (用于自定义短码)
-func (d *linkRepoImpl) CheckShortCodeExists(ctx context.Context, tx *gorm.DB, code string) (bool, error) {
+// CheckShortCodeExists 检查短码在该域名下是否已被占用 (用于自定义短码，回收站中的链接仍占用短码)
+func (d *linkRepoImpl) CheckShortCodeExists(ctx context.Context, tx *gorm.DB, domain, code string) (bool, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var count int64
-	err := tx.WithContext(ctx).Model(&model.Link{}).
-		Where("short_code = ?", code).
+	err := tx.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Where("domain = ? AND short_code = ?", domain, code).
 		Count(&count).Error
 	if err != nil {
 		return false, err
@@ -69,14 +161,14 @@ func (d *linkRepoImpl) CheckShortCodeExists(ctx context.Context, tx *gorm.DB, co
 	return count > 0, nil
 }
 
-// GetLinkByUserAndURL 根据用户ID和原始URL查询链接（用于检查是否已存在）
-func (d *linkRepoImpl) GetLinkByUserAndURL(ctx context.Context, tx *gorm.DB, userID uuid.UUID, originalURL string) (*model.Link, error) {
+// GetLinkByScopeAndURL 在个人或工作区范围内根据域名和原始URL查询链接（用于检查是否已存在）
+func (d *linkRepoImpl) GetLinkByScopeAndURL(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, domain, originalURL string) (*model.Link, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var link model.Link
-	err := tx.WithContext(ctx).
-		Where("user_id = ? AND original_url = ? AND status = ?", userID, originalURL, true).
+	err := applyLinkScope(tx.WithContext(ctx), "", scope).
+		Where("domain = ? AND original_url = ? AND status = ?", domain, originalURL, true).
 		First(&link).Error
 	if err != nil {
 		return nil, err
@@ -84,45 +176,16 @@ func (d *linkRepoImpl) GetLinkByUserAndURL(ctx context.Context, tx *gorm.DB, use
 	return &link, nil
 }
 
-// GetLinksByUser 获取用户的链接列表 (分页)
-func (d *linkRepoImpl) GetLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, page, size int) ([]model.Link, int64, error) {
-	if tx == nil {
-		tx = d.db
-	}
-	var links []model.Link
-	var total int64
-	offset := (page - 1) * size
-
-	baseQuery := tx.WithContext(ctx).Model(&model.Link{}).Where("user_id = ?", userID)
-
-	if err := baseQuery.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if err := tx.WithContext(ctx).
-		Model(&model.Link{}).
-		Where("user_id = ?", userID).
-		Select("id, short_code, original_url, alias, user_id, is_custom, visit_count, expires_at, status, created_at").
-		Offset(offset).
-		Limit(size).
-		Order("created_at DESC").
-		Find(&links).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return links, total, nil
-}
-
 // GetLinkIDByCode 根据短码查询链接ID (用于日志查询服务)
-func (d *linkRepoImpl) GetLinkIDByCode(ctx context.Context, tx *gorm.DB, code string) (int64, error) {
+func (d *linkRepoImpl) GetLinkIDByCode(ctx context.Context, tx *gorm.DB, domain, code string) (int64, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var linkID int64
-	// 查询条件：短码匹配且状态为启用
+	// 查询条件：域名、短码匹配且状态为启用
 	err := tx.WithContext(ctx).
 		Model(&model.Link{}).
-		Where("short_code = ? AND status = ?", code, true).
+		Where("domain = ? AND short_code = ? AND status = ?", domain, code, true).
 		Select("id").
 		First(&linkID).Error
 
@@ -132,29 +195,121 @@ func (d *linkRepoImpl) GetLinkIDByCode(ctx context.Context, tx *gorm.DB, code st
 	return linkID, nil
 }
 
-func (d *linkRepoImpl) GetLinksByUserAlias(ctx context.Context, tx *gorm.DB, userID uuid.UUID, alias string, page, size int) ([]model.Link, int64, error) {
+// DeleteLinksByUser 将用户的全部链接移入回收站（deleted_at 统一为 at，恢复用户时据此恢复），返回被删除的链接（完整字段）
+func (d *linkRepoImpl) DeleteLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) ([]model.Link, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var links []model.Link
-	var total int64
-	offset := (page - 1) * size
+	err := tx.WithContext(ctx).Model(&links).
+		Clauses(clause.Returning{}).
+		Where("user_id = ?", userID).
+		Update("deleted_at", at).Error
+	return links, err
+}
 
-	query := tx.WithContext(ctx).Model(&model.Link{}).Where("alias = ? AND user_id = ?", alias, userID)
+// RestoreLinksByUser 恢复用户在 at 时刻随用户一起删除的链接，返回被恢复的链接
+func (d *linkRepoImpl) RestoreLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) ([]model.Link, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var links []model.Link
+	err := tx.WithContext(ctx).Unscoped().Model(&links).
+		Clauses(clause.Returning{}).
+		Where("user_id = ? AND deleted_at = ?", userID, at).
+		Update("deleted_at", nil).Error
+	return links, err
+}
+
+// ListTrashedLinks 个人或工作区回收站中的链接（按删除时间倒序，分页）
+func (d *linkRepoImpl) ListTrashedLinks(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, page, size int) ([]model.Link, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var links []model.Link
+	var total int64
+	query := applyLinkScope(tx.WithContext(ctx).Unscoped().Model(&model.Link{}), "", scope).
+		Where("deleted_at IS NOT NULL")
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := query.Offset(offset).Limit(size).Order("created_at DESC").Find(&links).Error; err != nil {
+	err := query.Select(linkListColumns).
+		Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&links).Error
+	if err != nil {
 		return nil, 0, err
 	}
 	return links, total, nil
 }
 
-func (d *linkRepoImpl) DeleteLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error {
+// GetTrashedLinkByID 查询回收站中的链接
+func (d *linkRepoImpl) GetTrashedLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) (*model.Link, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var link model.Link
+	err := tx.WithContext(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", linkID).
+		First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// RestoreLinkByID 从回收站恢复链接
+func (d *linkRepoImpl) RestoreLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Unscoped().Model(&model.Link{}).
+		Where("id = ? AND deleted_at IS NOT NULL", linkID).
+		Update("deleted_at", nil).Error
+}
+
+// PurgeLinkByID 彻底删除回收站中的链接及其全部关联数据（标签关联、变更记录、健康检查、统计、转化、访问日志）
+func (d *linkRepoImpl) PurgeLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := purgeLinkData(tx, "id = ? AND deleted_at IS NOT NULL", linkID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", linkID).Delete(&model.Link{}).Error
+	})
+}
+
+// PurgeDeletedLinks 彻底删除 before 之前移入回收站的链接及其全部关联数据，返回删除的链接数
+func (d *linkRepoImpl) PurgeDeletedLinks(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
 	if tx == nil {
 		tx = d.db
 	}
-	return tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.Link{}).Error
+	var purged int64
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := purgeLinkData(tx, "deleted_at < ?", before); err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("deleted_at < ?", before).Delete(&model.Link{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// linkDataTables 以 link_id 引用链接的表，彻底删除链接时一并清理
+var linkDataTables = []string{"link_tags", "link_revisions", "link_health", "link_daily_stats", "conversions", "access_logs"}
+
+// purgeLinkData 删除满足 where 条件的链接在各关联表中的数据
+func purgeLinkData(tx *gorm.DB, where string, args ...any) error {
+	for _, table := range linkDataTables {
+		if err := tx.Exec(`DELETE FROM `+table+` WHERE link_id IN (SELECT id FROM links WHERE `+where+`)`, args...).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (d *linkRepoImpl) ActiveLink(ctx context.Context, tx *gorm.DB, LinkID int64) error {
@@ -183,14 +338,14 @@ func (d *linkRepoImpl) GetNumOfLinksByUser(ctx context.Context, tx *gorm.DB, use
 	return count, err
 }
 
-func (d *linkRepoImpl) CheckShortCodeDuplicate(ctx context.Context, tx *gorm.DB, short_code string) (bool, error) {
+func (d *linkRepoImpl) CheckShortCodeDuplicate(ctx context.Context, tx *gorm.DB, domain, short_code string) (bool, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var count int64
-	err := tx.WithContext(ctx).
+	err := tx.WithContext(ctx).Unscoped().
 		Model(&model.Link{}).
-		Where("short_code = ?", short_code).
+		Where("domain = ? AND short_code = ?", domain, short_code).
 		Count(&count).Error
 
 	if err != nil {
@@ -222,3 +377,150 @@ func (d *linkRepoImpl) DeleteLinkByID(ctx context.Context, tx *gorm.DB, linkID i
 	}
 	return tx.WithContext(ctx).Where("id = ?", linkID).Delete(&model.Link{}).Error
 }
+
+// GetLinkStatusCounts 统计个人或工作区链接的启用/过期/禁用数量（单次扫描，走 user_id / workspace_id 索引）
+func (d *linkRepoImpl) GetLinkStatusCounts(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, now time.Time) (*repository.LinkStatusCounts, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var counts repository.LinkStatusCounts
+	err := applyLinkScope(tx.WithContext(ctx).Model(&model.Link{}), "", scope).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status AND (expires_at IS NULL OR expires_at > ?)) AS active,
+			COUNT(*) FILTER (WHERE status AND expires_at <= ?) AS expired,
+			COUNT(*) FILTER (WHERE NOT status) AS disabled`, now, now).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// ListLinks 按条件筛选、排序、分页查询用户链接（offset 或 keyset 游标），总数按 filter.Count 统计
+func (d *linkRepoImpl) ListLinks(ctx context.Context, tx *gorm.DB, filter repository.LinkListFilter) ([]model.Link, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	build := func(db *gorm.DB) *gorm.DB {
+		return applyLinkListFilter(db.Model(&model.Link{}), filter)
+	}
+	total, err := countRows(ctx, tx, filter.Count, build, &[]model.Link{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	direction, cmp := " DESC", "<"
+	if filter.Asc {
+		direction, cmp = " ASC", ">"
+	}
+	var sortColumn string
+	var sortValue any
+	switch filter.Sort {
+	case repository.LinkSortClicks:
+		sortColumn = "links.visit_count"
+		if filter.After != nil {
+			sortValue = filter.After.VisitCount
+		}
+	case repository.LinkSortAlias:
+		sortColumn = "links.alias"
+		if filter.After != nil {
+			sortValue = filter.After.Alias
+		}
+	default:
+		sortColumn = "links.created_at"
+		if filter.After != nil {
+			sortValue = filter.After.CreatedAt
+		}
+	}
+
+	query := build(tx.WithContext(ctx)).Select(linkListColumns).
+		Order(sortColumn + direction + ", links.id" + direction).
+		Limit(filter.Size)
+	if filter.After != nil {
+		query = query.Where("("+sortColumn+", links.id) "+cmp+" (?, ?)", sortValue, filter.After.ID)
+	} else {
+		query = query.Offset((filter.Page - 1) * filter.Size)
+	}
+
+	var links []model.Link
+	if err := query.Find(&links).Error; err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// applyLinkListFilter 应用列表筛选条件（不含排序与分页）
+func applyLinkListFilter(query *gorm.DB, filter repository.LinkListFilter) *gorm.DB {
+	query = applyLinkScope(query, "links.", filter.Scope)
+	if filter.Alias != "" {
+		query = query.Where("links.alias = ?", filter.Alias)
+	}
+	if filter.Domain != nil {
+		query = query.Where("links.domain = ?", *filter.Domain)
+	}
+	if filter.Tag != "" {
+		// 标签属于链接所有者，工作区内按名称匹配各成员创建的同名标签
+		query = query.Where(`EXISTS (SELECT 1 FROM link_tags lt JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.link_id = links.id AND t.user_id = links.user_id AND t.name = ?)`, filter.Tag)
+	}
+	if filter.FolderID != nil {
+		query = query.Where("links.folder_id = ?", *filter.FolderID)
+	} else if filter.NoFolder {
+		query = query.Where("links.folder_id IS NULL")
+	}
+	if filter.Status != nil {
+		query = query.Where("links.status = ?", *filter.Status)
+	}
+	if filter.Expired != nil {
+		if *filter.Expired {
+			query = query.Where("links.expires_at <= NOW()")
+		} else {
+			query = query.Where("links.expires_at IS NULL OR links.expires_at > NOW()")
+		}
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("links.alias ILIKE ? OR links.original_url ILIKE ?", pattern, pattern)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("links.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("links.created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+// CountLinksByDomain 统计使用该域名的链接数（含回收站）
+func (d *linkRepoImpl) CountLinksByDomain(ctx context.Context, tx *gorm.DB, domain string) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var count int64
+	// 回收站中的链接同样占用该域名下的短码
+	err := tx.WithContext(ctx).Unscoped().Model(&model.Link{}).Where("domain = ?", domain).Count(&count).Error
+	return count, err
+}
+
+// CountWorkspaceLinks 统计工作区的链接数（含回收站）
+func (d *linkRepoImpl) CountWorkspaceLinks(ctx context.Context, tx *gorm.DB, workspaceID int64) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var count int64
+	err := tx.WithContext(ctx).Unscoped().Model(&model.Link{}).Where("workspace_id = ?", workspaceID).Count(&count).Error
+	return count, err
+}
+
+// escapeLike 转义 LIKE 通配符，搜索词按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// SetLinkFolder 设置链接所属文件夹（nil 表示移出文件夹）
+func (d *linkRepoImpl) SetLinkFolder(ctx context.Context, tx *gorm.DB, linkID int64, folderID *int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.Link{}).Where("id = ?", linkID).Update("folder_id", folderID).Error
+}
@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type linkRevisionRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewLinkRevisionRepository 创建 LinkRevisionRepository 实例
+func NewLinkRevisionRepository(db *gorm.DB) *linkRevisionRepoImpl {
+	return &linkRevisionRepoImpl{db: db}
+}
+
+// ==========================================
+// LinkRevision 相关操作
+// ==========================================
+
+// CreateRevisions 写入变更记录（应与链接变更在同一事务内）
+func (d *linkRevisionRepoImpl) CreateRevisions(ctx context.Context, tx *gorm.DB, revisions []model.LinkRevision) error {
+	if tx == nil {
+		tx = d.db
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).CreateInBatches(revisions, 500).Error
+}
+
+// ListByLink 链接的变更记录（按时间倒序，分页）
+func (d *linkRevisionRepoImpl) ListByLink(ctx context.Context, tx *gorm.DB, linkID int64, page, size int) ([]model.LinkRevision, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var revisions []model.LinkRevision
+	var total int64
+	query := tx.WithContext(ctx).Model(&model.LinkRevision{}).Where("link_id = ?", linkID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&revisions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// GetRevision 查询链接的某条变更记录
+func (d *linkRevisionRepoImpl) GetRevision(ctx context.Context, tx *gorm.DB, linkID, revisionID int64) (*model.LinkRevision, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var revision model.LinkRevision
+	err := tx.WithContext(ctx).Where("id = ? AND link_id = ?", revisionID, linkID).First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
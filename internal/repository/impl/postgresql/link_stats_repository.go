@@ -0,0 +1,155 @@
+package postgresql
+
+// ==========================================
+// 链接访问统计汇总（link_daily_stats）
+// ==========================================
+
+import (
+	"context"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dailyStatsCheckpoint link_daily_stats 汇总进度在 rollup_checkpoints 中的名称
+const dailyStatsCheckpoint = "link_daily_stats"
+
+type linkStatsRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewLinkStatsRepository 创建 LinkStatsRepository 实例
+func NewLinkStatsRepository(db *gorm.DB) *linkStatsRepoImpl {
+	return &linkStatsRepoImpl{db: db}
+}
+
+// RollupDailyStats 将 [from, to) 内的访问日志按 (link_id, 天) 汇总写入 link_daily_stats（幂等，可重复执行）
+// from/to 应对齐到 UTC 零点，避免同一天被拆成两段互相覆盖
+func (d *linkStatsRepoImpl) RollupDailyStats(ctx context.Context, tx *gorm.DB, from, to time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Exec(`
+		INSERT INTO link_daily_stats (link_id, day, clicks, unique_visitors, updated_at)
+		SELECT link_id, (visited_at AT TIME ZONE 'UTC')::date, COUNT(*), COUNT(DISTINCT ip_address), NOW()
+		FROM access_logs
+		WHERE visited_at >= ? AND visited_at < ? AND link_id IS NOT NULL
+		GROUP BY 1, 2
+		ON CONFLICT (link_id, day) DO UPDATE
+		SET clicks = EXCLUDED.clicks, unique_visitors = EXCLUDED.unique_visitors, updated_at = EXCLUDED.updated_at`,
+		from, to).Error
+}
+
+// GetRollupCheckpoint 已汇总完成的截止日期（不含），从未汇总过时返回 nil
+func (d *linkStatsRepoImpl) GetRollupCheckpoint(ctx context.Context, tx *gorm.DB) (*time.Time, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var checkpoints []model.RollupCheckpoint
+	if err := tx.WithContext(ctx).Where("name = ?", dailyStatsCheckpoint).Limit(1).Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	day := checkpoints[0].RolledUpTo.UTC()
+	return &day, nil
+}
+
+// SetRollupCheckpoint 记录汇总进度
+func (d *linkStatsRepoImpl) SetRollupCheckpoint(ctx context.Context, tx *gorm.DB, rolledUpTo time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rolled_up_to", "updated_at"}),
+	}).Create(&model.RollupCheckpoint{Name: dailyStatsCheckpoint, RolledUpTo: rolledUpTo}).Error
+}
+
+// EarliestAccessLogDay 主表（含已挂载的分区）中最早的访问日期，用于首次回填
+func (d *linkStatsRepoImpl) EarliestAccessLogDay(ctx context.Context, tx *gorm.DB) (*time.Time, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var earliest *time.Time
+	err := tx.WithContext(ctx).Table("access_logs").
+		Select("MIN(visited_at)").
+		Scan(&earliest).Error
+	if err != nil || earliest == nil {
+		return nil, err
+	}
+	t := earliest.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return &day, nil
+}
+
+// GetTotalClicks 链接累计点击数（来自汇总表，最多滞后一个维护周期）
+func (d *linkStatsRepoImpl) GetTotalClicks(ctx context.Context, tx *gorm.DB, linkID int64) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var total int64
+	err := tx.WithContext(ctx).Table("link_daily_stats").
+		Where("link_id = ?", linkID).
+		Select("COALESCE(SUM(clicks), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetClickSummary 个人或工作区所有链接的累计点击及近 7/30 天点击（since 为 UTC 零点）
+func (d *linkStatsRepoImpl) GetClickSummary(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, since7d, since30d time.Time) (*repository.UserClickSummary, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var summary repository.UserClickSummary
+	query := tx.WithContext(ctx).Table("link_daily_stats s").
+		Joins("JOIN links l ON l.id = s.link_id AND l.deleted_at IS NULL")
+	err := applyLinkScope(query, "l.", scope).
+		Select(`COALESCE(SUM(s.clicks), 0) AS total,
+			COALESCE(SUM(s.clicks) FILTER (WHERE s.day >= ?), 0) AS last7_days,
+			COALESCE(SUM(s.clicks) FILTER (WHERE s.day >= ?), 0) AS last30_days`, since7d, since30d).
+		Scan(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetTopLinksByScope 个人或工作区在 since 之后点击数最多的链接
+func (d *linkStatsRepoImpl) GetTopLinksByScope(ctx context.Context, tx *gorm.DB, scope repository.LinkScope, since time.Time, limit int) ([]repository.LinkClicks, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var links []repository.LinkClicks
+	query := tx.WithContext(ctx).Table("link_daily_stats s").
+		Joins("JOIN links l ON l.id = s.link_id AND l.deleted_at IS NULL")
+	err := applyLinkScope(query, "l.", scope).
+		Where("s.day >= ?", since).
+		Select("l.id AS link_id, l.short_code, l.alias, l.original_url, SUM(s.clicks) AS clicks").
+		Group("l.id, l.short_code, l.alias, l.original_url").
+		Order("clicks DESC, l.id").
+		Limit(limit).
+		Scan(&links).Error
+	return links, err
+}
+
+// SyncVisitCounts 将 since 之后有点击的链接的累计点击同步到 links.visit_count（用于列表按点击排序）
+func (d *linkStatsRepoImpl) SyncVisitCounts(ctx context.Context, tx *gorm.DB, since time.Time) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	result := tx.WithContext(ctx).Exec(`
+		UPDATE links l SET visit_count = t.total
+		FROM (
+			SELECT link_id, SUM(clicks) AS total
+			FROM link_daily_stats
+			WHERE link_id IN (SELECT DISTINCT link_id FROM link_daily_stats WHERE day >= ?)
+			GROUP BY link_id
+		) t
+		WHERE l.id = t.link_id AND l.visit_count IS DISTINCT FROM t.total`, since)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,126 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewMFARepository 创建 MFARepository 实例
+func NewMFARepository(db *gorm.DB) *mfaRepoImpl {
+	return &mfaRepoImpl{db: db}
+}
+
+// ==========================================
+// MFA 相关操作
+// ==========================================
+
+// GetByUserID 获取用户的两步验证设置
+func (d *mfaRepoImpl) GetByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.UserMFA, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var mfa model.UserMFA
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SaveSecret 写入新密钥；已有未启用的密钥时覆盖，已启用时不修改
+func (d *mfaRepoImpl) SaveSecret(ctx context.Context, tx *gorm.DB, userID uuid.UUID, secret string) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	mfa := model.UserMFA{UserID: userID, Secret: secret}
+	res := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"secret": secret, "last_counter": 0, "updated_at": time.Now()}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.enabled = false"}}},
+	}).Create(&mfa)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Enable 确认绑定后启用，同时记录确认时使用的时间步
+func (d *mfaRepoImpl) Enable(ctx context.Context, tx *gorm.DB, userID uuid.UUID, counter int64, at time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.UserMFA{}).Where("user_id = ?", userID).
+		Updates(map[string]any{"enabled": true, "enabled_at": at, "last_counter": counter}).Error
+}
+
+// UseCounter 条件更新保证并发请求中同一时间步只有一个成功
+func (d *mfaRepoImpl) UseCounter(ctx context.Context, tx *gorm.DB, userID uuid.UUID, counter int64) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.UserMFA{}).
+		Where("user_id = ? AND last_counter < ?", userID, counter).
+		Update("last_counter", counter)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Delete 删除两步验证设置及全部恢复码
+func (d *mfaRepoImpl) Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var deleted bool
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("user_id = ?", userID).Delete(&model.UserMFA{})
+		deleted = res.RowsAffected > 0
+		return res.Error
+	})
+	return deleted, err
+}
+
+// ReplaceRecoveryCodes 用新的一组恢复码替换旧的（旧码全部失效）
+func (d *mfaRepoImpl) ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hashes []string) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]model.MFARecoveryCode, 0, len(hashes))
+		for _, h := range hashes {
+			codes = append(codes, model.MFARecoveryCode{UserID: userID, CodeHash: h})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode 条件更新保证每个恢复码只能使用一次
+func (d *mfaRepoImpl) UseRecoveryCode(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hash string, at time.Time) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountRecoveryCodes 未使用的恢复码数量
+func (d *mfaRepoImpl) CountRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var count int64
+	err := tx.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,57 @@
+package postgresql
+
+// ==========================================
+// 列表总数统计（精确 / 估算 / 不统计）
+// ==========================================
+
+import (
+	"context"
+	"encoding/json"
+	"go-short/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// countRows 按模式统计 build 构造的查询的行数
+// exact 执行 COUNT；estimated 读取 EXPLAIN 的估算行数（不扫描数据）；none 返回 -1
+func countRows(ctx context.Context, tx *gorm.DB, mode string, build func(*gorm.DB) *gorm.DB, dest any) (int64, error) {
+	switch mode {
+	case repository.CountNone:
+		return -1, nil
+	case repository.CountEstimated:
+		return estimateRows(ctx, tx, build, dest)
+	default:
+		var total int64
+		err := build(tx.WithContext(ctx)).Count(&total).Error
+		return total, err
+	}
+}
+
+// estimateRows 用 EXPLAIN (FORMAT JSON) 获取查询计划的估算行数
+func estimateRows(ctx context.Context, tx *gorm.DB, build func(*gorm.DB) *gorm.DB, dest any) (int64, error) {
+	stmt := build(tx.Session(&gorm.Session{DryRun: true, Context: ctx})).Find(dest).Statement
+	rows, err := tx.Statement.ConnPool.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var raw []byte
+	if rows.Next() {
+		if err := rows.Scan(&raw); err != nil {
+			return 0, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	var plans []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil || len(plans) == 0 {
+		return 0, err
+	}
+	return int64(plans[0].Plan.PlanRows), nil
+}
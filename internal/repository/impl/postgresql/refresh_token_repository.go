@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 创建 RefreshTokenRepository 实例
+func NewRefreshTokenRepository(db *gorm.DB) *refreshTokenRepoImpl {
+	return &refreshTokenRepoImpl{db: db}
+}
+
+// ==========================================
+// RefreshToken 相关操作
+// ==========================================
+
+// Create 保存刷新令牌
+func (d *refreshTokenRepoImpl) Create(ctx context.Context, tx *gorm.DB, token *model.RefreshToken) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(token).Error
+}
+
+// GetByHash 按哈希查询刷新令牌（含已轮换、已吊销的，用于重放检测）
+func (d *refreshTokenRepoImpl) GetByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (*model.RefreshToken, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var token model.RefreshToken
+	err := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRotated 标记已轮换（条件更新，保证同一令牌只能换发一次）
+func (d *refreshTokenRepoImpl) MarkRotated(ctx context.Context, tx *gorm.DB, id, replacedBy uuid.UUID, at time.Time) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]any{"rotated_at": at, "replaced_by": replacedBy})
+	return res.RowsAffected == 1, res.Error
+}
+
+// RevokeFamily 吊销同一次登录产生的全部令牌
+func (d *refreshTokenRepoImpl) RevokeFamily(ctx context.Context, tx *gorm.DB, familyID uuid.UUID, at time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeByUser 吊销用户的全部令牌，exceptFamily 非空时保留该登录（修改密码时保留当前会话）
+func (d *refreshTokenRepoImpl) RevokeByUser(ctx context.Context, tx *gorm.DB, userID, exceptFamily uuid.UUID, at time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, exceptFamily).
+		Update("revoked_at", at).Error
+}
+
+// DeleteExpired 删除 before 之前已过期的令牌
+func (d *refreshTokenRepoImpl) DeleteExpired(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.RefreshToken{})
+	return res.RowsAffected, res.Error
+}
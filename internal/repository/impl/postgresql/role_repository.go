@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type roleRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建 RoleRepository 实例
+func NewRoleRepository(db *gorm.DB) *roleRepoImpl {
+	return &roleRepoImpl{db: db}
+}
+
+// ==========================================
+// Role 相关操作
+// ==========================================
+
+// Create 创建角色
+func (d *roleRepoImpl) Create(ctx context.Context, tx *gorm.DB, role *model.Role) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(role).Error
+}
+
+// GetByName 按名称获取角色
+func (d *roleRepoImpl) GetByName(ctx context.Context, tx *gorm.DB, name string) (*model.Role, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var role model.Role
+	if err := tx.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List 获取全部角色（内置角色在前）
+func (d *roleRepoImpl) List(ctx context.Context, tx *gorm.DB) ([]model.Role, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var roles []model.Role
+	err := tx.WithContext(ctx).Order("built_in DESC, name").Find(&roles).Error
+	return roles, err
+}
+
+// Update 更新角色描述与权限
+func (d *roleRepoImpl) Update(ctx context.Context, tx *gorm.DB, role *model.Role) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(role).Select("description", "permissions", "updated_at").Updates(role).Error
+}
+
+// Delete 删除角色，返回是否存在
+func (d *roleRepoImpl) Delete(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("name = ?", name).Delete(&model.Role{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountUsers 使用该角色的用户数（含已软删除的用户）
+func (d *roleRepoImpl) CountUsers(ctx context.Context, tx *gorm.DB, name string) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var count int64
+	err := tx.WithContext(ctx).Unscoped().Model(&model.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sessionRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository 创建 SessionRepository 实例
+func NewSessionRepository(db *gorm.DB) *sessionRepoImpl {
+	return &sessionRepoImpl{db: db}
+}
+
+// ==========================================
+// Session 相关操作
+// ==========================================
+
+// Create 登录时创建会话
+func (d *sessionRepoImpl) Create(ctx context.Context, tx *gorm.DB, session *model.Session) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(session).Error
+}
+
+// Touch 刷新令牌时更新客户端信息、最近活跃时间与过期时间，会话不存在或已终止时返回 false
+func (d *sessionRepoImpl) Touch(ctx context.Context, tx *gorm.DB, session *model.Session) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Updates(map[string]any{
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+// GetByID 查询会话（含已终止的）
+func (d *sessionRepoImpl) GetByID(ctx context.Context, tx *gorm.DB, id uuid.UUID) (*model.Session, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var session model.Session
+	if err := tx.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveByUser 用户未终止、未过期的会话（按最近活跃时间倒序）
+func (d *sessionRepoImpl) ListActiveByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, now time.Time) ([]model.Session, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var sessions []model.Session
+	err := tx.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC, id").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke 终止会话，返回是否终止（已终止的返回 false）
+func (d *sessionRepoImpl) Revoke(ctx context.Context, tx *gorm.DB, id uuid.UUID, at time.Time) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+// RevokeByUser 终止用户除 except 外（uuid.Nil 表示全部）的会话，返回被终止的会话 ID
+func (d *sessionRepoImpl) RevokeByUser(ctx context.Context, tx *gorm.DB, userID, except uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var sessions []model.Session
+	err := tx.WithContext(ctx).Model(&sessions).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, except).
+		Update("revoked_at", at).Error
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids, nil
+}
+
+// DeleteExpired 删除 before 之前已过期或已终止的会话
+func (d *sessionRepoImpl) DeleteExpired(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&model.Session{})
+	return res.RowsAffected, res.Error
+}
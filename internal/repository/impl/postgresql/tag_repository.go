@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tagRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建 TagRepository 实例
+func NewTagRepository(db *gorm.DB) *tagRepoImpl {
+	return &tagRepoImpl{db: db}
+}
+
+// ==========================================
+// Tag 相关操作
+// ==========================================
+
+// GetTagsByUser 获取用户的全部标签（按名称排序）
+func (d *tagRepoImpl) GetTagsByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Tag, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var tags []model.Tag
+	err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// GetTagByName 根据名称查询用户标签
+func (d *tagRepoImpl) GetTagByName(ctx context.Context, tx *gorm.DB, userID uuid.UUID, name string) (*model.Tag, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var tag model.Tag
+	err := tx.WithContext(ctx).Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetOrCreateTags 按名称获取标签，不存在的自动创建（并发创建同名标签时依赖唯一索引去重）
+func (d *tagRepoImpl) GetOrCreateTags(ctx context.Context, tx *gorm.DB, userID uuid.UUID, names []string) ([]model.Tag, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var tags []model.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	newTags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		newTags = append(newTags, model.Tag{UserID: userID, Name: name})
+	}
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+	err := tx.WithContext(ctx).Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+// DeleteTag 删除用户标签及其关联，返回是否删除了标签
+func (d *tagRepoImpl) DeleteTag(ctx context.Context, tx *gorm.DB, userID uuid.UUID, tagID int64) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var deleted bool
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", tagID, userID).Delete(&model.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected > 0
+		if !deleted {
+			return nil
+		}
+		return tx.Where("tag_id = ?", tagID).Delete(&model.LinkTag{}).Error
+	})
+	return deleted, err
+}
+
+// SetLinkTags 替换链接的标签集合
+func (d *tagRepoImpl) SetLinkTags(ctx context.Context, tx *gorm.DB, linkID int64, tagIDs []int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", linkID).Delete(&model.LinkTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		linkTags := make([]model.LinkTag, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			linkTags = append(linkTags, model.LinkTag{LinkID: linkID, TagID: tagID})
+		}
+		return tx.Create(&linkTags).Error
+	})
+}
+
+// GetTagsByLinkIDs 批量查询链接的标签，用于列表展示（一次查询，避免 N+1）
+func (d *tagRepoImpl) GetTagsByLinkIDs(ctx context.Context, tx *gorm.DB, linkIDs []int64) (map[int64][]model.Tag, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	result := make(map[int64][]model.Tag)
+	if len(linkIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		LinkID int64
+		model.Tag
+	}
+	err := tx.WithContext(ctx).Table("link_tags lt").
+		Joins("JOIN tags t ON t.id = lt.tag_id").
+		Where("lt.link_id IN ?", linkIDs).
+		Select("lt.link_id, t.id, t.user_id, t.name, t.created_at").
+		Order("t.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.LinkID] = append(result[row.LinkID], row.Tag)
+	}
+	return result, nil
+}
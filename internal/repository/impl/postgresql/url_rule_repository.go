@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type urlRuleRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewURLRuleRepository 创建 URLRuleRepository 实例
+func NewURLRuleRepository(db *gorm.DB) *urlRuleRepoImpl {
+	return &urlRuleRepoImpl{db: db}
+}
+
+// ==========================================
+// URLRule 相关操作
+// ==========================================
+
+// Create 添加黑白名单规则
+func (d *urlRuleRepoImpl) Create(ctx context.Context, tx *gorm.DB, rule *model.URLRule) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(rule).Error
+}
+
+// ListRules 获取全部规则（按模式排序）
+func (d *urlRuleRepoImpl) ListRules(ctx context.Context, tx *gorm.DB) ([]model.URLRule, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var rules []model.URLRule
+	err := tx.WithContext(ctx).Order("pattern").Find(&rules).Error
+	return rules, err
+}
+
+// Delete 删除规则，返回是否存在
+func (d *urlRuleRepoImpl) Delete(ctx context.Context, tx *gorm.DB, ruleID int64) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Where("id = ?", ruleID).Delete(&model.URLRule{})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,52 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userIdentityRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository 创建 UserIdentityRepository 实例
+func NewUserIdentityRepository(db *gorm.DB) *userIdentityRepoImpl {
+	return &userIdentityRepoImpl{db: db}
+}
+
+// ==========================================
+// UserIdentity 相关操作
+// ==========================================
+
+// Create 关联外部账号
+func (d *userIdentityRepoImpl) Create(ctx context.Context, tx *gorm.DB, identity *model.UserIdentity) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(identity).Error
+}
+
+// GetByProviderSubject 按提供方与 sub 查找关联
+func (d *userIdentityRepoImpl) GetByProviderSubject(ctx context.Context, tx *gorm.DB, provider, subject string) (*model.UserIdentity, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var identity model.UserIdentity
+	err := tx.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// UpdateLogin 记录最近一次登录时间与邮箱
+func (d *userIdentityRepoImpl) UpdateLogin(ctx context.Context, tx *gorm.DB, id int64, email string, at time.Time) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.UserIdentity{}).Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_login_at": at}).Error
+}
@@ -3,6 +3,8 @@ package postgresql
 import (
 	"context"
 	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -38,11 +40,61 @@ func (d *userRepoImpl) Update(ctx context.Context, tx *gorm.DB, user *model.User
 }
 
 // Delete 删除用户
-func (d *userRepoImpl) DeleteUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error {
+func (d *userRepoImpl) DeleteUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) error {
 	if tx == nil {
 		tx = d.db
 	}
-	return tx.WithContext(ctx).Where("id = ?", userID).Delete(&model.User{}).Error
+	return tx.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("deleted_at", at).Error
+}
+
+// GetDeletedUserByID 查询已软删除的用户
+func (d *userRepoImpl) GetDeletedUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.User, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var user model.User
+	err := tx.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", userID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListDeletedUsers 已软删除的用户（按删除时间倒序，分页）
+func (d *userRepoImpl) ListDeletedUsers(ctx context.Context, tx *gorm.DB, page, size int) ([]model.User, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var users []model.User
+	var total int64
+	query := tx.WithContext(ctx).Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("deleted_at DESC, id").Offset((page - 1) * size).Limit(size).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// RestoreUserByID 恢复软删除的用户
+func (d *userRepoImpl) RestoreUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Unscoped().Model(&model.User{}).Where("id = ?", userID).Update("deleted_at", nil).Error
+}
+
+// PurgeDeletedUsers 彻底删除 before 之前软删除、且已没有任何链接（含回收站）的用户
+func (d *userRepoImpl) PurgeDeletedUsers(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Unscoped().
+		Where("deleted_at < ? AND NOT EXISTS (SELECT 1 FROM links l WHERE l.user_id = users.id)", before).
+		Delete(&model.User{})
+	return res.RowsAffected, res.Error
 }
 
 // GetUserByUserID 根据用户ID查找用户
@@ -72,13 +124,29 @@ func (d *userRepoImpl) GetUserByUsername(ctx context.Context, tx *gorm.DB, usern
 	return &user, nil
 }
 
+// GetUserByVerifiedEmail 根据已验证的邮箱查找用户（多个时取最早注册的一个），未验证邮箱的用户不会返回
+func (d *userRepoImpl) GetUserByVerifiedEmail(ctx context.Context, tx *gorm.DB, email string) (*model.User, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var user model.User
+	err := tx.WithContext(ctx).
+		Where("LOWER(email) = LOWER(?) AND email_verified_at IS NOT NULL", email).
+		Order("created_at").First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // CheckUsernameExists 检查用户名是否已存在
 func (d *userRepoImpl) CheckUsernameExists(ctx context.Context, tx *gorm.DB, username string) (bool, error) {
 	if tx == nil {
 		tx = d.db
 	}
 	var count int64
-	err := tx.WithContext(ctx).Model(&model.User{}).
+	// 含已软删除的用户：删除期间用户名保留，避免恢复时冲突
+	err := tx.WithContext(ctx).Unscoped().Model(&model.User{}).
 		Where("username = ?", username).
 		Count(&count).Error
 	if err != nil {
@@ -115,6 +183,33 @@ func (d *userRepoImpl) GetAllUsers(ctx context.Context, tx *gorm.DB, page, size
 	return users, total, nil
 }
 
+// ListUsers 用户列表（按 created_at DESC, id DESC），支持 offset 或 keyset 游标，总数按 filter.Count 统计
+func (d *userRepoImpl) ListUsers(ctx context.Context, tx *gorm.DB, filter repository.UserListFilter) ([]model.User, int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	build := func(db *gorm.DB) *gorm.DB {
+		return db.Model(&model.User{})
+	}
+	total, err := countRows(ctx, tx, filter.Count, build, &[]model.User{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := build(tx.WithContext(ctx)).Order("created_at DESC, id DESC").Limit(filter.Size)
+	if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	} else {
+		query = query.Offset((filter.Page - 1) * filter.Size)
+	}
+
+	var users []model.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
 // UnactiveUserByUserID 禁用用户
 func (d *userRepoImpl) UnactiveUserByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error {
 	if tx == nil {
@@ -142,3 +237,48 @@ func (d *userRepoImpl) UpdatePasswordByUserID(ctx context.Context, tx *gorm.DB,
 	}
 	return tx.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("password_hash", password).Error
 }
+
+// MarkEmailVerified 邮箱仍为 email 时标记为已验证，返回是否更新（邮箱已变更时为 false）
+func (d *userRepoImpl) MarkEmailVerified(ctx context.Context, tx *gorm.DB, userID uuid.UUID, email string, at time.Time) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+// UpdateRoleByUserID 修改用户角色
+func (d *userRepoImpl) UpdateRoleByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, role string) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// IncrLinkCount 增减用户链接计数（不会减到负数）
+func (d *userRepoImpl) IncrLinkCount(ctx context.Context, tx *gorm.DB, userID uuid.UUID, delta int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("link_count", gorm.Expr("GREATEST(link_count + ?, 0)", delta)).Error
+}
+
+// ReconcileLinkCounts 按 links 表校准 link_count，只更新有偏差的用户，返回校准的行数
+func (d *userRepoImpl) ReconcileLinkCounts(ctx context.Context, tx *gorm.DB) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	result := tx.WithContext(ctx).Exec(`
+		UPDATE users u SET link_count = c.cnt
+		FROM (
+			SELECT u2.id, COUNT(l.id) AS cnt
+			FROM users u2 LEFT JOIN links l ON l.user_id = u2.id AND l.deleted_at IS NULL
+			GROUP BY u2.id
+		) c
+		WHERE u.id = c.id AND u.link_count IS DISTINCT FROM c.cnt`)
+	return result.RowsAffected, result.Error
+}
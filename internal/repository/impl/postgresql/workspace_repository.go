@@ -0,0 +1,242 @@
+package postgresql
+
+import (
+	"context"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type workspaceRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository 创建 WorkspaceRepository 实例
+func NewWorkspaceRepository(db *gorm.DB) *workspaceRepoImpl {
+	return &workspaceRepoImpl{db: db}
+}
+
+// ==========================================
+// Workspace 相关操作
+// ==========================================
+
+// Create 创建工作区
+func (d *workspaceRepoImpl) Create(ctx context.Context, tx *gorm.DB, workspace *model.Workspace) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(workspace).Error
+}
+
+// GetByID 根据ID查询工作区
+func (d *workspaceRepoImpl) GetByID(ctx context.Context, tx *gorm.DB, workspaceID int64) (*model.Workspace, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var workspace model.Workspace
+	if err := tx.WithContext(ctx).Where("id = ?", workspaceID).First(&workspace).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// ListByUser 用户所在的工作区及其角色（按加入时间排序）
+func (d *workspaceRepoImpl) ListByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]repository.UserWorkspace, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var workspaces []repository.UserWorkspace
+	err := tx.WithContext(ctx).Table("workspaces w").
+		Joins("JOIN workspace_members m ON m.workspace_id = w.id").
+		Where("m.user_id = ?", userID).
+		Select("w.*, m.role").
+		Order("m.created_at, w.id").
+		Scan(&workspaces).Error
+	return workspaces, err
+}
+
+// Rename 修改工作区名称
+func (d *workspaceRepoImpl) Rename(ctx context.Context, tx *gorm.DB, workspaceID int64, name string) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.Workspace{}).Where("id = ?", workspaceID).
+		Updates(map[string]any{"name": name, "updated_at": time.Now()}).Error
+}
+
+// Delete 删除工作区及其成员和邀请（调用方需保证工作区下已没有链接）
+func (d *workspaceRepoImpl) Delete(ctx context.Context, tx *gorm.DB, workspaceID int64) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&model.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&model.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", workspaceID).Delete(&model.Workspace{}).Error
+	})
+}
+
+// ==========================================
+// WorkspaceMember 相关操作
+// ==========================================
+
+// AddMember 添加成员
+func (d *workspaceRepoImpl) AddMember(ctx context.Context, tx *gorm.DB, member *model.WorkspaceMember) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(member).Error
+}
+
+// GetMember 查询用户在工作区中的成员记录
+func (d *workspaceRepoImpl) GetMember(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var member model.WorkspaceMember
+	err := tx.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembers 工作区成员列表（不含已删除的用户）
+func (d *workspaceRepoImpl) ListMembers(ctx context.Context, tx *gorm.DB, workspaceID int64) ([]repository.WorkspaceMemberInfo, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var members []repository.WorkspaceMemberInfo
+	err := tx.WithContext(ctx).Table("workspace_members m").
+		Joins("JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL").
+		Where("m.workspace_id = ?", workspaceID).
+		Select("m.user_id, u.username, u.email, m.role, m.created_at").
+		Order("m.created_at, u.username").
+		Scan(&members).Error
+	return members, err
+}
+
+// UpdateMemberRole 修改成员角色
+func (d *workspaceRepoImpl) UpdateMemberRole(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID, role string) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Update("role", role).Error
+}
+
+// RemoveMember 移除成员
+func (d *workspaceRepoImpl) RemoveMember(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&model.WorkspaceMember{}).Error
+}
+
+// CountOwners 工作区 owner 数量（至少保留一个）
+func (d *workspaceRepoImpl) CountOwners(ctx context.Context, tx *gorm.DB, workspaceID int64) (int64, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var count int64
+	err := tx.WithContext(ctx).Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// ==========================================
+// WorkspaceInvitation 相关操作
+// ==========================================
+
+// CreateInvitation 创建邀请
+func (d *workspaceRepoImpl) CreateInvitation(ctx context.Context, tx *gorm.DB, invitation *model.WorkspaceInvitation) error {
+	if tx == nil {
+		tx = d.db
+	}
+	return tx.WithContext(ctx).Create(invitation).Error
+}
+
+// GetInvitation 根据ID查询邀请
+func (d *workspaceRepoImpl) GetInvitation(ctx context.Context, tx *gorm.DB, invitationID int64) (*model.WorkspaceInvitation, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var invitation model.WorkspaceInvitation
+	if err := tx.WithContext(ctx).Where("id = ?", invitationID).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingInvitation 查询发给某用户且仍有效的邀请
+func (d *workspaceRepoImpl) GetPendingInvitation(ctx context.Context, tx *gorm.DB, workspaceID int64, inviteeID uuid.UUID, now time.Time) (*model.WorkspaceInvitation, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var invitation model.WorkspaceInvitation
+	err := tx.WithContext(ctx).
+		Where("workspace_id = ? AND invitee_id = ? AND status = ? AND expires_at > ?", workspaceID, inviteeID, model.InvitationPending, now).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// invitationInfoQuery 邀请连同工作区名称与双方用户名
+func invitationInfoQuery(tx *gorm.DB) *gorm.DB {
+	return tx.Table("workspace_invitations i").
+		Joins("JOIN workspaces w ON w.id = i.workspace_id").
+		Joins("LEFT JOIN users inviter ON inviter.id = i.inviter_id").
+		Joins("LEFT JOIN users invitee ON invitee.id = i.invitee_id").
+		Select(`i.*, w.name AS workspace_name,
+			COALESCE(inviter.username, '') AS inviter_username,
+			COALESCE(invitee.username, '') AS invitee_username`)
+}
+
+// ListPendingByWorkspace 工作区待处理的邀请
+func (d *workspaceRepoImpl) ListPendingByWorkspace(ctx context.Context, tx *gorm.DB, workspaceID int64, now time.Time) ([]repository.WorkspaceInvitationInfo, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var invitations []repository.WorkspaceInvitationInfo
+	err := invitationInfoQuery(tx.WithContext(ctx)).
+		Where("i.workspace_id = ? AND i.status = ? AND i.expires_at > ?", workspaceID, model.InvitationPending, now).
+		Order("i.created_at DESC").
+		Scan(&invitations).Error
+	return invitations, err
+}
+
+// ListPendingByInvitee 发给用户的待处理邀请
+func (d *workspaceRepoImpl) ListPendingByInvitee(ctx context.Context, tx *gorm.DB, inviteeID uuid.UUID, now time.Time) ([]repository.WorkspaceInvitationInfo, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	var invitations []repository.WorkspaceInvitationInfo
+	err := invitationInfoQuery(tx.WithContext(ctx)).
+		Where("i.invitee_id = ? AND i.status = ? AND i.expires_at > ?", inviteeID, model.InvitationPending, now).
+		Order("i.created_at DESC").
+		Scan(&invitations).Error
+	return invitations, err
+}
+
+// RespondInvitation 条件更新邀请状态（仅 pending 可更新）
+func (d *workspaceRepoImpl) RespondInvitation(ctx context.Context, tx *gorm.DB, invitationID int64, status string, at time.Time) (bool, error) {
+	if tx == nil {
+		tx = d.db
+	}
+	res := tx.WithContext(ctx).Model(&model.WorkspaceInvitation{}).
+		Where("id = ? AND status = ?", invitationID, model.InvitationPending).
+		Updates(map[string]any{"status": status, "responded_at": at})
+	return res.RowsAffected == 1, res.Error
+}
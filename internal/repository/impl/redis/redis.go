@@ -4,11 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"go-short/internal/repository"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -71,6 +76,311 @@ func (d *redisRepoImpl) DeleteLinkCache(ctx context.Context, code string) error
 	return d.rdb.Del(ctx, "short:"+code).Err()
 }
 
+// GetDashboard 读取仪表盘缓存（key 为个人或工作区范围）
+func (d *redisRepoImpl) GetDashboard(ctx context.Context, key string) ([]byte, error) {
+	return d.rdb.Get(ctx, "dashboard:"+key).Bytes()
+}
+
+// SetDashboard 写入仪表盘缓存
+func (d *redisRepoImpl) SetDashboard(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "dashboard:"+key, data, ttl).Err()
+}
+
+// SaveBulkJob 保存批量导入任务状态
+func (d *redisRepoImpl) SaveBulkJob(ctx context.Context, jobID string, data []byte, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "bulk_job:"+jobID, data, ttl).Err()
+}
+
+// GetBulkJob 读取批量导入任务状态
+func (d *redisRepoImpl) GetBulkJob(ctx context.Context, jobID string) ([]byte, error) {
+	return d.rdb.Get(ctx, "bulk_job:"+jobID).Bytes()
+}
+
+// GetQRCode 读取二维码图片缓存
+func (d *redisRepoImpl) GetQRCode(ctx context.Context, key string) ([]byte, error) {
+	return d.rdb.Get(ctx, "qr:"+key).Bytes()
+}
+
+// SetQRCode 写入二维码图片缓存
+func (d *redisRepoImpl) SetQRCode(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "qr:"+key, data, ttl).Err()
+}
+
+// RevokeToken 吊销单个 access token，ttl 取 token 剩余有效期（过期后无需再记录）
+func (d *redisRepoImpl) RevokeToken(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return d.rdb.Set(ctx, "revoked:jti:"+jti, 1, ttl).Err()
+}
+
+// RevokeSessionTokens 吊销会话已签发的全部 access token，ttl 取 access token 有效期
+func (d *redisRepoImpl) RevokeSessionTokens(ctx context.Context, sessionID uuid.UUID, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "revoked:sid:"+sessionID.String(), 1, ttl).Err()
+}
+
+// RevokeUserTokens 吊销用户在 before 之前签发的全部 access token（毫秒精度），ttl 应不短于 access token 有效期
+func (d *redisRepoImpl) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "revoked:user:"+userID.String(), before.UnixMilli(), ttl).Err()
+}
+
+// revokedSecondsLimit 小于该值的用户级吊销时间是旧格式（秒）
+const revokedSecondsLimit = 1e11
+
+// IsTokenRevoked 一次 MGET 同时检查 jti、用户级与会话级吊销；用户级按毫秒比较，吊销之后（含同一秒内）签发的 token 仍有效
+func (d *redisRepoImpl) IsTokenRevoked(ctx context.Context, jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	keys := []string{"revoked:user:" + userID.String(), "revoked:jti:" + jti}
+	if sessionID != "" {
+		keys = append(keys, "revoked:sid:"+sessionID)
+	}
+	vals, err := d.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, v := range vals[1:] {
+		if v != nil {
+			return true, nil
+		}
+	}
+	if s, ok := vals[0].(string); ok {
+		if before, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if before < revokedSecondsLimit {
+				before = (before + 1) * 1000 // 旧格式按秒记录，该秒内签发的都视为已吊销
+			}
+			if issuedAt.UnixMilli() < before {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// GetUserRole 读取缓存的用户当前角色
+func (d *redisRepoImpl) GetUserRole(ctx context.Context, userID uuid.UUID) (string, error) {
+	return d.rdb.Get(ctx, "perm:user:"+userID.String()).Result()
+}
+
+// SetUserRole 缓存用户当前角色
+func (d *redisRepoImpl) SetUserRole(ctx context.Context, userID uuid.UUID, role string, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "perm:user:"+userID.String(), role, ttl).Err()
+}
+
+// DeleteUserRole 角色变更、删除或禁用用户后删除缓存
+func (d *redisRepoImpl) DeleteUserRole(ctx context.Context, userID uuid.UUID) error {
+	return d.rdb.Del(ctx, "perm:user:"+userID.String()).Err()
+}
+
+// GetRolePermissions 读取缓存的角色权限（JSON 数组）
+func (d *redisRepoImpl) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	data, err := d.rdb.Get(ctx, "perm:role:"+role).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var permissions []string
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// SetRolePermissions 缓存角色权限
+func (d *redisRepoImpl) SetRolePermissions(ctx context.Context, role string, permissions []string, ttl time.Duration) error {
+	if permissions == nil {
+		permissions = []string{}
+	}
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	return d.rdb.Set(ctx, "perm:role:"+role, data, ttl).Err()
+}
+
+// DeleteRolePermissions 角色权限变更或删除后删除缓存
+func (d *redisRepoImpl) DeleteRolePermissions(ctx context.Context, role string) error {
+	return d.rdb.Del(ctx, "perm:role:"+role).Err()
+}
+
+// SaveOIDCState 保存 SSO 登录状态
+func (d *redisRepoImpl) SaveOIDCState(ctx context.Context, state string, data []byte, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "oidc:state:"+state, data, ttl).Err()
+}
+
+// TakeOIDCState 原子读取并删除 SSO 登录状态（GETDEL），防止回调重放
+func (d *redisRepoImpl) TakeOIDCState(ctx context.Context, state string) ([]byte, error) {
+	return d.rdb.GetDel(ctx, "oidc:state:"+state).Bytes()
+}
+
+// SaveMFAChallenge 保存两步验证挑战
+func (d *redisRepoImpl) SaveMFAChallenge(ctx context.Context, tokenHash string, data []byte, ttl time.Duration) error {
+	return d.rdb.Set(ctx, "mfa:challenge:"+tokenHash, data, ttl).Err()
+}
+
+// GetMFAChallenge 读取两步验证挑战
+func (d *redisRepoImpl) GetMFAChallenge(ctx context.Context, tokenHash string) ([]byte, error) {
+	return d.rdb.Get(ctx, "mfa:challenge:"+tokenHash).Bytes()
+}
+
+// IncrMFAChallengeAttempts 失败次数计数，首次计数时设置过期时间
+func (d *redisRepoImpl) IncrMFAChallengeAttempts(ctx context.Context, tokenHash string, ttl time.Duration) (int64, error) {
+	key := "mfa:attempts:" + tokenHash
+	n, err := d.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		d.rdb.Expire(ctx, key, ttl)
+	}
+	return n, nil
+}
+
+// DeleteMFAChallenge 验证成功或失败次数过多后删除挑战
+func (d *redisRepoImpl) DeleteMFAChallenge(ctx context.Context, tokenHash string) error {
+	return d.rdb.Del(ctx, "mfa:challenge:"+tokenHash, "mfa:attempts:"+tokenHash).Err()
+}
+
+// loginKey 登录防护相关的键：login:<kind>:<scope>:<key>
+func loginKey(kind, scope, key string) string {
+	return "login:" + kind + ":" + scope + ":" + key
+}
+
+// reserveLoginScript KEYS: lock, delay, fail；ARGV: 当前毫秒时间戳, 窗口起点, 窗口毫秒数, 上限, 尝试 id
+// 返回 {锁定剩余毫秒, 延迟剩余毫秒, 是否预占成功}
+var reserveLoginScript = redis.NewScript(`
+	local locked = redis.call("pttl", KEYS[1])
+	if locked > 0 then
+		return {locked, 0, 0}
+	end
+	local delayed = redis.call("pttl", KEYS[2])
+	if delayed > 0 then
+		return {0, delayed, 0}
+	end
+	redis.call("zremrangebyscore", KEYS[3], "-inf", ARGV[2])
+	if redis.call("zcard", KEYS[3]) >= tonumber(ARGV[4]) then
+		return {0, 0, 0}
+	end
+	redis.call("zadd", KEYS[3], ARGV[1], ARGV[5])
+	redis.call("pexpire", KEYS[3], ARGV[3])
+	return {0, 0, 1}
+`)
+
+// ReserveLoginAttempt 预占与失败记录存于同一有序集合（score 为毫秒时间戳），进行中的尝试同样占用失败名额，
+// 并发请求无法越过上限
+func (d *redisRepoImpl) ReserveLoginAttempt(ctx context.Context, scope, key, id string, at time.Time, window time.Duration, limit int64) (time.Duration, time.Duration, bool, error) {
+	keys := []string{loginKey("lock", scope, key), loginKey("delay", scope, key), loginKey("fail", scope, key)}
+	res, err := reserveLoginScript.Run(ctx, d.rdb, keys,
+		at.UnixMilli(), at.Add(-window).UnixMilli(), window.Milliseconds(), limit, id).Int64Slice()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(res) != 3 {
+		return 0, 0, false, fmt.Errorf("unexpected reserve result: %v", res)
+	}
+	return time.Duration(res[0]) * time.Millisecond, time.Duration(res[1]) * time.Millisecond, res[2] == 1, nil
+}
+
+// ReleaseLoginAttempt 移除预占记录
+func (d *redisRepoImpl) ReleaseLoginAttempt(ctx context.Context, scope, key, id string) error {
+	return d.rdb.ZRem(ctx, loginKey("fail", scope, key), id).Err()
+}
+
+// RecordLoginFailure 先移除窗口外的记录，再以 id 写入（已预占时只更新 score）并计数
+func (d *redisRepoImpl) RecordLoginFailure(ctx context.Context, scope, key, id string, at time.Time, window time.Duration) (int64, error) {
+	k := loginKey("fail", scope, key)
+	pipe := d.rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, k, "-inf", strconv.FormatInt(at.Add(-window).UnixMilli(), 10))
+	pipe.ZAdd(ctx, k, redis.Z{Score: float64(at.UnixMilli()), Member: id})
+	count := pipe.ZCard(ctx, k)
+	pipe.Expire(ctx, k, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// GetLoginBlock 读取锁定与延迟键的剩余时间
+func (d *redisRepoImpl) GetLoginBlock(ctx context.Context, scope, key string) (time.Duration, time.Duration, error) {
+	pipe := d.rdb.Pipeline()
+	locked := pipe.PTTL(ctx, loginKey("lock", scope, key))
+	delayed := pipe.PTTL(ctx, loginKey("delay", scope, key))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return max(locked.Val(), 0), max(delayed.Val(), 0), nil
+}
+
+// SetLoginDelay 下次尝试前需等待 delay
+func (d *redisRepoImpl) SetLoginDelay(ctx context.Context, scope, key string, delay time.Duration) error {
+	return d.rdb.Set(ctx, loginKey("delay", scope, key), 1, delay).Err()
+}
+
+// IncrLockoutCount 累计锁定次数，每次锁定时续期
+func (d *redisRepoImpl) IncrLockoutCount(ctx context.Context, scope, key string, ttl time.Duration) (int64, error) {
+	k := loginKey("lockcount", scope, key)
+	pipe := d.rdb.TxPipeline()
+	count := pipe.Incr(ctx, k)
+	pipe.Expire(ctx, k, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// LockLogin 写入锁定键并删除失败计数与延迟（解锁后重新计数）
+func (d *redisRepoImpl) LockLogin(ctx context.Context, scope, key string, duration time.Duration) error {
+	pipe := d.rdb.TxPipeline()
+	pipe.Set(ctx, loginKey("lock", scope, key), 1, duration)
+	pipe.Del(ctx, loginKey("fail", scope, key), loginKey("delay", scope, key))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ClearLoginFailures 删除失败计数与延迟
+func (d *redisRepoImpl) ClearLoginFailures(ctx context.Context, scope, key string) error {
+	return d.rdb.Del(ctx, loginKey("fail", scope, key), loginKey("delay", scope, key)).Err()
+}
+
+// ListLoginLockouts 扫描 login:lock:* 列出当前锁定
+func (d *redisRepoImpl) ListLoginLockouts(ctx context.Context) ([]repository.LoginLockout, error) {
+	var lockouts []repository.LoginLockout
+	iter := d.rdb.Scan(ctx, 0, "login:lock:*", 100).Iterator()
+	for iter.Next(ctx) {
+		scope, key, ok := strings.Cut(strings.TrimPrefix(iter.Val(), "login:lock:"), ":")
+		if !ok {
+			continue
+		}
+		ttl, err := d.rdb.PTTL(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			continue // 扫描期间已过期
+		}
+		lockouts = append(lockouts, repository.LoginLockout{Scope: scope, Key: key, ExpiresIn: ttl})
+	}
+	return lockouts, iter.Err()
+}
+
+// UnlockLogin 删除锁定键、失败计数、延迟与累计锁定次数
+func (d *redisRepoImpl) UnlockLogin(ctx context.Context, scope, key string) (bool, error) {
+	pipe := d.rdb.TxPipeline()
+	locked := pipe.Del(ctx, loginKey("lock", scope, key))
+	pipe.Del(ctx, loginKey("fail", scope, key), loginKey("delay", scope, key), loginKey("lockcount", scope, key))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return locked.Val() > 0, nil
+}
+
+// UseActionToken SETNX 标记邮件链接令牌已使用，保留到令牌过期
+func (d *redisRepoImpl) UseActionToken(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return d.rdb.SetNX(ctx, "action:used:"+nonce, 1, ttl).Result()
+}
+
+// AcquireMailCooldown SETNX 占用发信冷却期
+func (d *redisRepoImpl) AcquireMailCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return d.rdb.SetNX(ctx, "mail:cooldown:"+key, 1, ttl).Result()
+}
+
 // CacheInvalidateChannel Redirect 订阅此 channel 以删除本地缓存
 const CacheInvalidateChannel = "cache_invalidate"
 
@@ -79,6 +389,41 @@ func (d *redisRepoImpl) PublishCacheInvalidate(ctx context.Context, code string)
 	return d.rdb.Publish(ctx, CacheInvalidateChannel, code).Err()
 }
 
+// AccessEventChannel Redirect 发布访问事件，API 服务订阅后推送给实时点击流
+const AccessEventChannel = "access_events"
+
+// PublishAccessEvent 发布访问事件（JSON，与 Kafka 访问日志消息一致）
+func (d *redisRepoImpl) PublishAccessEvent(ctx context.Context, payload []byte) error {
+	return d.rdb.Publish(ctx, AccessEventChannel, payload).Err()
+}
+
+// SubscribeAccessEvents 订阅访问事件，返回的 channel 在 ctx 取消后关闭
+func (d *redisRepoImpl) SubscribeAccessEvents(ctx context.Context) <-chan []byte {
+	out := make(chan []byte, 1024)
+	go func() {
+		defer close(out)
+		pubsub := d.rdb.Subscribe(ctx, AccessEventChannel)
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				default:
+					// 下游处理不过来时丢弃，实时流允许有损
+				}
+			}
+		}
+	}()
+	return out
+}
+
 // doInvalidateLink 立即执行：删除 Redis 缓存并发布失效消息
 func (d *redisRepoImpl) doInvalidateLink(ctx context.Context, code string) error {
 	if err := d.DeleteLinkCache(ctx, code); err != nil {
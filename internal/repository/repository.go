@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"go-short/internal/model"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,37 +13,476 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, tx *gorm.DB, user *model.User) error
 	Update(ctx context.Context, tx *gorm.DB, user *model.User) error
-	DeleteUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error
+	DeleteUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) error
+	GetDeletedUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.User, error)
+	ListDeletedUsers(ctx context.Context, tx *gorm.DB, page, size int) ([]model.User, int64, error)
+	RestoreUserByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error
+	PurgeDeletedUsers(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
 	GetUserByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.User, error)
 	GetUserByUsername(ctx context.Context, tx *gorm.DB, username string) (*model.User, error)
+	GetUserByVerifiedEmail(ctx context.Context, tx *gorm.DB, email string) (*model.User, error)
 	CheckUsernameExists(ctx context.Context, tx *gorm.DB, username string) (bool, error)
 	GetAllUsers(ctx context.Context, tx *gorm.DB, page, size int) ([]model.User, int64, error)
+	ListUsers(ctx context.Context, tx *gorm.DB, filter UserListFilter) ([]model.User, int64, error)
 	UnactiveUserByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error
 	ActiveUserByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error
 	UpdatePasswordByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, password string) error
+	MarkEmailVerified(ctx context.Context, tx *gorm.DB, userID uuid.UUID, email string, at time.Time) (bool, error)
+	UpdateRoleByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, role string) error
+	IncrLinkCount(ctx context.Context, tx *gorm.DB, userID uuid.UUID, delta int64) error
+	ReconcileLinkCounts(ctx context.Context, tx *gorm.DB) (int64, error)
 }
 
 type LinkRepository interface {
 	Create(ctx context.Context, tx *gorm.DB, link *model.Link) error
 	Update(ctx context.Context, tx *gorm.DB, link *model.Link) error
-	GetLinkByCode(ctx context.Context, tx *gorm.DB, code string) (*model.Link, error)
-	CheckShortCodeExists(ctx context.Context, tx *gorm.DB, code string) (bool, error)
-	GetLinkByUserAndURL(ctx context.Context, tx *gorm.DB, userID uuid.UUID, originalURL string) (*model.Link, error)
-	GetLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, page, size int) ([]model.Link, int64, error)
-	GetLinksByUserAlias(ctx context.Context, tx *gorm.DB, userID uuid.UUID, alias string, page, size int) ([]model.Link, int64, error)
-	GetLinkIDByCode(ctx context.Context, tx *gorm.DB, code string) (int64, error)
+	GetLinkByCode(ctx context.Context, tx *gorm.DB, domain, code string) (*model.Link, error)
+	CheckShortCodeExists(ctx context.Context, tx *gorm.DB, domain, code string) (bool, error)
+	GetLinkByScopeAndURL(ctx context.Context, tx *gorm.DB, scope LinkScope, domain, originalURL string) (*model.Link, error)
+	GetLinkIDByCode(ctx context.Context, tx *gorm.DB, domain, code string) (int64, error)
 	ActiveLink(ctx context.Context, tx *gorm.DB, LinkID int64) error
 	UnactiveLink(ctx context.Context, tx *gorm.DB, LinkID int64) error
 	GetNumOfLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error)
-	CheckShortCodeDuplicate(ctx context.Context, tx *gorm.DB, short_code string) (bool, error)
-	DeleteLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) error
+	CheckShortCodeDuplicate(ctx context.Context, tx *gorm.DB, domain, short_code string) (bool, error)
+	DeleteLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) ([]model.Link, error)
+	RestoreLinksByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, at time.Time) ([]model.Link, error)
 	GetLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) (*model.Link, error)
 	DeleteLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) error
+	GetLinkStatusCounts(ctx context.Context, tx *gorm.DB, scope LinkScope, now time.Time) (*LinkStatusCounts, error)
+	UpdateWithVersion(ctx context.Context, tx *gorm.DB, link *model.Link, expectedVersion int64) (bool, error)
+	CreateBatch(ctx context.Context, tx *gorm.DB, links []model.Link) error
+	NextLinkIDs(ctx context.Context, tx *gorm.DB, n int) ([]int64, error)
+	GetLinksByScopeAndURLs(ctx context.Context, tx *gorm.DB, scope LinkScope, domain string, originalURLs []string) ([]model.Link, error)
+	GetExistingShortCodes(ctx context.Context, tx *gorm.DB, domain string, codes []string) ([]string, error)
+	ListLinks(ctx context.Context, tx *gorm.DB, filter LinkListFilter) ([]model.Link, int64, error)
+	SetLinkFolder(ctx context.Context, tx *gorm.DB, linkID int64, folderID *int64) error
+	CountLinksByDomain(ctx context.Context, tx *gorm.DB, domain string) (int64, error)
+	ListTrashedLinks(ctx context.Context, tx *gorm.DB, scope LinkScope, page, size int) ([]model.Link, int64, error)
+	GetTrashedLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) (*model.Link, error)
+	RestoreLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) error
+	PurgeLinkByID(ctx context.Context, tx *gorm.DB, linkID int64) error
+	PurgeDeletedLinks(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+	// CountWorkspaceLinks 工作区的链接数（含回收站）
+	CountWorkspaceLinks(ctx context.Context, tx *gorm.DB, workspaceID int64) (int64, error)
+}
+
+// LinkScope 链接归属范围：WorkspaceID 非空时为该工作区的链接，否则为 UserID 的个人链接
+type LinkScope struct {
+	UserID      uuid.UUID // 当前用户（个人范围的归属人）
+	WorkspaceID *int64
+}
+
+// Key 范围的唯一标识（用于缓存键）
+func (s LinkScope) Key() string {
+	if s.WorkspaceID != nil {
+		return "workspace:" + strconv.FormatInt(*s.WorkspaceID, 10)
+	}
+	return "user:" + s.UserID.String()
+}
+
+// 列表总数统计方式
+const (
+	CountExact     = "exact"     // COUNT(*)
+	CountEstimated = "estimated" // 查询计划估算值，不扫描数据
+	CountNone      = "none"      // 不统计，返回 -1
+)
+
+// UserListFilter 用户列表查询条件，After 非空时按 (created_at, id) keyset 翻页并忽略 Page
+type UserListFilter struct {
+	After *UserCursor
+	Page  int
+	Size  int
+	Count string
+}
+
+// UserCursor 用户列表游标（按 created_at DESC, id DESC）
+type UserCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// 链接列表排序字段
+const (
+	LinkSortCreated = "created"
+	LinkSortClicks  = "clicks"
+	LinkSortAlias   = "alias"
+)
+
+// LinkListFilter 链接列表筛选条件（指针/零值表示不过滤），总数按 Count 统计，none 时返回 -1
+type LinkListFilter struct {
+	Scope       LinkScope // 个人或工作区范围，Scope.UserID 同时用于标签筛选（标签属于用户）
+	Alias       string  // 精确匹配别名
+	Domain      *string // 非 nil 时按域名筛选，空串表示默认域名
+	Tag         string // 标签名
+	FolderID    *int64
+	NoFolder    bool // 仅未归档的链接
+	Status      *bool
+	Expired     *bool
+	Query       string // 别名 / 原始 URL 子串搜索（pg_trgm 索引）
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string // created | clicks | alias
+	Asc         bool
+	Page        int
+	Size        int
+	After       *LinkCursor // 非空时按排序字段 keyset 翻页并忽略 Page
+	Count       string      // exact | estimated | none
+}
+
+// LinkCursor 链接列表游标，取值字段与 LinkListFilter.Sort 对应，ID 作为次级排序保证唯一
+type LinkCursor struct {
+	CreatedAt  time.Time
+	VisitCount int64
+	Alias      string
+	ID         int64
+}
+
+type TagRepository interface {
+	GetTagsByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Tag, error)
+	GetTagByName(ctx context.Context, tx *gorm.DB, userID uuid.UUID, name string) (*model.Tag, error)
+	GetOrCreateTags(ctx context.Context, tx *gorm.DB, userID uuid.UUID, names []string) ([]model.Tag, error)
+	DeleteTag(ctx context.Context, tx *gorm.DB, userID uuid.UUID, tagID int64) (bool, error)
+	SetLinkTags(ctx context.Context, tx *gorm.DB, linkID int64, tagIDs []int64) error
+	GetTagsByLinkIDs(ctx context.Context, tx *gorm.DB, linkIDs []int64) (map[int64][]model.Tag, error)
+}
+
+type DomainRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, domain *model.Domain) error
+	GetDomainsByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Domain, error)
+	GetDomainByID(ctx context.Context, tx *gorm.DB, domainID int64) (*model.Domain, error)
+	GetDomainByHostname(ctx context.Context, tx *gorm.DB, hostname string) (*model.Domain, error)
+	MarkVerified(ctx context.Context, tx *gorm.DB, domainID int64, at time.Time) error
+	Delete(ctx context.Context, tx *gorm.DB, domainID int64) error
+	ListVerifiedHostnames(ctx context.Context, tx *gorm.DB) ([]string, error)
+}
+
+type LinkHealthRepository interface {
+	SaveHealth(ctx context.Context, tx *gorm.DB, health *model.LinkHealth) error
+	GetHealth(ctx context.Context, tx *gorm.DB, linkID int64) (*model.LinkHealth, error)
+	GetHealthByLinkIDs(ctx context.Context, tx *gorm.DB, linkIDs []int64) (map[int64]model.LinkHealth, error)
+	ListLinksDueForCheck(ctx context.Context, tx *gorm.DB, before time.Time, limit int) ([]model.Link, error)
+	PruneOrphans(ctx context.Context, tx *gorm.DB) (int64, error)
+}
+
+type LinkRevisionRepository interface {
+	CreateRevisions(ctx context.Context, tx *gorm.DB, revisions []model.LinkRevision) error
+	ListByLink(ctx context.Context, tx *gorm.DB, linkID int64, page, size int) ([]model.LinkRevision, int64, error)
+	GetRevision(ctx context.Context, tx *gorm.DB, linkID, revisionID int64) (*model.LinkRevision, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, token *model.RefreshToken) error
+	GetByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (*model.RefreshToken, error)
+	// MarkRotated 仅当令牌仍有效（未轮换、未吊销）时标记为已轮换，返回是否成功（并发刷新时只有一个请求成功）
+	MarkRotated(ctx context.Context, tx *gorm.DB, id, replacedBy uuid.UUID, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, tx *gorm.DB, familyID uuid.UUID, at time.Time) error
+	// RevokeByUser 吊销用户除 exceptFamily 外（uuid.Nil 表示全部）的令牌
+	RevokeByUser(ctx context.Context, tx *gorm.DB, userID, exceptFamily uuid.UUID, at time.Time) error
+	DeleteExpired(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, session *model.Session) error
+	// Touch 按 session.ID 更新客户端信息、LastSeenAt 与 ExpiresAt，会话不存在或已终止时返回 false
+	Touch(ctx context.Context, tx *gorm.DB, session *model.Session) (bool, error)
+	GetByID(ctx context.Context, tx *gorm.DB, id uuid.UUID) (*model.Session, error)
+	ListActiveByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID, now time.Time) ([]model.Session, error)
+	Revoke(ctx context.Context, tx *gorm.DB, id uuid.UUID, at time.Time) (bool, error)
+	// RevokeByUser 终止用户除 except 外（uuid.Nil 表示全部）的会话，返回被终止的会话 ID
+	RevokeByUser(ctx context.Context, tx *gorm.DB, userID, except uuid.UUID, at time.Time) ([]uuid.UUID, error)
+	DeleteExpired(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+}
+
+type WorkspaceRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, workspace *model.Workspace) error
+	GetByID(ctx context.Context, tx *gorm.DB, workspaceID int64) (*model.Workspace, error)
+	ListByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]UserWorkspace, error)
+	Rename(ctx context.Context, tx *gorm.DB, workspaceID int64, name string) error
+	// Delete 删除工作区及其成员和邀请
+	Delete(ctx context.Context, tx *gorm.DB, workspaceID int64) error
+
+	AddMember(ctx context.Context, tx *gorm.DB, member *model.WorkspaceMember) error
+	GetMember(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID) (*model.WorkspaceMember, error)
+	ListMembers(ctx context.Context, tx *gorm.DB, workspaceID int64) ([]WorkspaceMemberInfo, error)
+	UpdateMemberRole(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID, role string) error
+	RemoveMember(ctx context.Context, tx *gorm.DB, workspaceID int64, userID uuid.UUID) error
+	CountOwners(ctx context.Context, tx *gorm.DB, workspaceID int64) (int64, error)
+
+	CreateInvitation(ctx context.Context, tx *gorm.DB, invitation *model.WorkspaceInvitation) error
+	GetInvitation(ctx context.Context, tx *gorm.DB, invitationID int64) (*model.WorkspaceInvitation, error)
+	GetPendingInvitation(ctx context.Context, tx *gorm.DB, workspaceID int64, inviteeID uuid.UUID, now time.Time) (*model.WorkspaceInvitation, error)
+	ListPendingByWorkspace(ctx context.Context, tx *gorm.DB, workspaceID int64, now time.Time) ([]WorkspaceInvitationInfo, error)
+	ListPendingByInvitee(ctx context.Context, tx *gorm.DB, inviteeID uuid.UUID, now time.Time) ([]WorkspaceInvitationInfo, error)
+	// RespondInvitation 仅当邀请仍为 pending 时更新状态，返回是否成功（防止重复接受）
+	RespondInvitation(ctx context.Context, tx *gorm.DB, invitationID int64, status string, at time.Time) (bool, error)
+}
+
+// UserWorkspace 用户所在的工作区及其角色
+type UserWorkspace struct {
+	model.Workspace
+	Role string
+}
+
+// WorkspaceMemberInfo 成员及其用户信息
+type WorkspaceMemberInfo struct {
+	UserID    uuid.UUID
+	Username  string
+	Email     *string
+	Role      string
+	CreatedAt time.Time
+}
+
+// WorkspaceInvitationInfo 邀请及工作区名称、双方用户名
+type WorkspaceInvitationInfo struct {
+	model.WorkspaceInvitation
+	WorkspaceName   string
+	InviterUsername string
+	InviteeUsername string
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, key *model.APIKey) error
+	ListByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.APIKey, error)
+	GetByID(ctx context.Context, tx *gorm.DB, userID, keyID uuid.UUID) (*model.APIKey, error)
+	GetByPrefix(ctx context.Context, tx *gorm.DB, prefix string) (*model.APIKey, error)
+	Update(ctx context.Context, tx *gorm.DB, key *model.APIKey) error
+	Delete(ctx context.Context, tx *gorm.DB, userID, keyID uuid.UUID) (bool, error)
+	// TouchLastUsed 更新最近使用时间，距上次记录不足 interval 时跳过，避免每个请求都写库
+	TouchLastUsed(ctx context.Context, tx *gorm.DB, keyID uuid.UUID, at time.Time, interval time.Duration) error
+}
+
+// RoleRepository 角色与权限
+type RoleRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, role *model.Role) error
+	GetByName(ctx context.Context, tx *gorm.DB, name string) (*model.Role, error)
+	List(ctx context.Context, tx *gorm.DB) ([]model.Role, error)
+	Update(ctx context.Context, tx *gorm.DB, role *model.Role) error
+	Delete(ctx context.Context, tx *gorm.DB, name string) (bool, error)
+	// CountUsers 使用该角色的用户数（含软删除的用户，恢复后仍需要角色存在）
+	CountUsers(ctx context.Context, tx *gorm.DB, name string) (int64, error)
+}
+
+// UserIdentityRepository 外部 IdP 账号关联
+type UserIdentityRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, identity *model.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, tx *gorm.DB, provider, subject string) (*model.UserIdentity, error)
+	UpdateLogin(ctx context.Context, tx *gorm.DB, id int64, email string, at time.Time) error
+}
+
+// MFARepository TOTP 两步验证与恢复码
+type MFARepository interface {
+	GetByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.UserMFA, error)
+	// SaveSecret 写入（或覆盖未启用的）密钥，已启用时不覆盖并返回 false
+	SaveSecret(ctx context.Context, tx *gorm.DB, userID uuid.UUID, secret string) (bool, error)
+	Enable(ctx context.Context, tx *gorm.DB, userID uuid.UUID, counter int64, at time.Time) error
+	// UseCounter 记录已使用的时间步，counter 不大于上次使用的时间步时返回 false（重放）
+	UseCounter(ctx context.Context, tx *gorm.DB, userID uuid.UUID, counter int64) (bool, error)
+	// Delete 删除密钥与恢复码，返回是否存在
+	Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hashes []string) error
+	// UseRecoveryCode 标记恢复码已使用，不存在或已使用返回 false
+	UseRecoveryCode(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hash string, at time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error)
+}
+
+// AuthEventFilter 认证审计事件筛选条件，空字段不过滤
+type AuthEventFilter struct {
+	Event    string
+	Username string
+	IP       string
+}
+
+// AuthEventRepository 认证审计事件
+type AuthEventRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, event *model.AuthEvent) error
+	// List 按时间倒序分页
+	List(ctx context.Context, tx *gorm.DB, filter AuthEventFilter, page, size int) ([]model.AuthEvent, int64, error)
+	DeleteBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+}
+
+type URLRuleRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, rule *model.URLRule) error
+	ListRules(ctx context.Context, tx *gorm.DB) ([]model.URLRule, error)
+	Delete(ctx context.Context, tx *gorm.DB, ruleID int64) (bool, error)
+}
+
+type FolderRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, folder *model.Folder) error
+	GetFoldersByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Folder, error)
+	GetFolderByID(ctx context.Context, tx *gorm.DB, folderID int64) (*model.Folder, error)
+	Rename(ctx context.Context, tx *gorm.DB, folderID int64, name string) error
+	Delete(ctx context.Context, tx *gorm.DB, folderID int64) error
+}
+
+// LinkStatusCounts 用户链接按状态计数（禁用优先于过期，互不重叠）
+type LinkStatusCounts struct {
+	Total    int64
+	Active   int64 // 启用且未过期
+	Expired  int64 // 启用但已过期
+	Disabled int64 // 已禁用
 }
 
 type AccessLogRepository interface {
 	SaveAccessLog(ctx context.Context, tx *gorm.DB, logEntry *model.AccessLog) error
 	GetRecentAccessLogs(ctx context.Context, tx *gorm.DB, limit int) ([]model.AccessLog, error)
+	GetAccessLogsAfter(ctx context.Context, tx *gorm.DB, filter AccessLogFilter, after *AccessLogCursor, limit int) ([]model.AccessLog, error)
+}
+
+type ConversionRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, conversion *model.Conversion) error
+	GetLinkConversionStats(ctx context.Context, tx *gorm.DB, linkID int64) (*ConversionStats, error)
+}
+
+// ConversionStats 链接转化统计
+type ConversionStats struct {
+	Conversions     int64   // 转化事件数
+	TrackedClicks   int64   // access_logs 中带点击 ID 的点击数（转化率的分母）
+	ConvertedClicks int64   // 能关联到 access_logs 的去重点击数（TrackedClicks 的子集）
+	Value           float64 // 转化金额合计
+}
+
+// AccessLogPartitionRepository access_logs 月分区管理
+type AccessLogPartitionRepository interface {
+	EnsureMonthlyPartitions(ctx context.Context, from, to time.Time) ([]string, error)
+	ListPartitions(ctx context.Context) ([]model.AccessLogPartition, error)
+	MarkRolledUp(ctx context.Context, name string) error
+	DropPartition(ctx context.Context, name string) error
+	ArchivePartition(ctx context.Context, name string) error
+}
+
+// LinkStatsRepository 链接访问统计汇总
+type LinkStatsRepository interface {
+	RollupDailyStats(ctx context.Context, tx *gorm.DB, from, to time.Time) error
+	// GetRollupCheckpoint 汇总进度，从未汇总过时返回 nil
+	GetRollupCheckpoint(ctx context.Context, tx *gorm.DB) (*time.Time, error)
+	SetRollupCheckpoint(ctx context.Context, tx *gorm.DB, rolledUpTo time.Time) error
+	// EarliestAccessLogDay 主表中最早一条访问日志所在的 UTC 日期，没有日志时返回 nil
+	EarliestAccessLogDay(ctx context.Context, tx *gorm.DB) (*time.Time, error)
+	GetTotalClicks(ctx context.Context, tx *gorm.DB, linkID int64) (int64, error)
+	GetClickSummary(ctx context.Context, tx *gorm.DB, scope LinkScope, since7d, since30d time.Time) (*UserClickSummary, error)
+	GetTopLinksByScope(ctx context.Context, tx *gorm.DB, scope LinkScope, since time.Time, limit int) ([]LinkClicks, error)
+	SyncVisitCounts(ctx context.Context, tx *gorm.DB, since time.Time) (int64, error)
+}
+
+// UserClickSummary 用户所有链接的点击汇总
+type UserClickSummary struct {
+	Total      int64
+	Last7Days  int64
+	Last30Days int64
+}
+
+// LinkClicks 链接在某时间段内的点击数
+type LinkClicks struct {
+	LinkID      int64
+	ShortCode   string
+	Alias       string
+	OriginalURL string
+	Clicks      int64
+}
+
+// AccessLogFilter 访问日志查询条件（时间范围为左闭右开）
+type AccessLogFilter struct {
+	LinkID *int64
+	From   time.Time
+	To     time.Time
+}
+
+// AccessLogCursor 访问日志游标（visited_at, id），用于按时间顺序的 keyset 分页
+type AccessLogCursor struct {
+	VisitedAt time.Time
+	ID        int64
+}
+
+// DashboardCache 仪表盘聚合结果缓存，key 为 LinkScope.Key()（未命中返回 error）
+type DashboardCache interface {
+	GetDashboard(ctx context.Context, key string) ([]byte, error)
+	SetDashboard(ctx context.Context, key string, data []byte, ttl time.Duration) error
+}
+
+// BulkJobStore 批量导入任务状态存储（多实例共享，未找到返回 error）
+type BulkJobStore interface {
+	SaveBulkJob(ctx context.Context, jobID string, data []byte, ttl time.Duration) error
+	GetBulkJob(ctx context.Context, jobID string) ([]byte, error)
+}
+
+// QRCodeCache 二维码图片缓存（键为内容与渲染参数的哈希，未找到返回 error）
+type QRCodeCache interface {
+	GetQRCode(ctx context.Context, key string) ([]byte, error)
+	SetQRCode(ctx context.Context, key string, data []byte, ttl time.Duration) error
+}
+
+// TokenRevocationStore access token 吊销列表（多实例共享）：按 jti 吊销单个 token，按会话吊销该会话的全部 token，
+// 或吊销用户在某时刻之前签发的全部 token
+type TokenRevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, ttl time.Duration) error
+	RevokeSessionTokens(ctx context.Context, sessionID uuid.UUID, ttl time.Duration) error
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time, ttl time.Duration) error
+	// IsTokenRevoked sessionID 为空（会话管理上线前签发的 token）时不检查会话
+	IsTokenRevoked(ctx context.Context, jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+}
+
+// PermissionCache 用户角色与角色权限缓存（多实例共享，未找到返回 error），变更时删除对应键
+type PermissionCache interface {
+	GetUserRole(ctx context.Context, userID uuid.UUID) (string, error)
+	SetUserRole(ctx context.Context, userID uuid.UUID, role string, ttl time.Duration) error
+	DeleteUserRole(ctx context.Context, userID uuid.UUID) error
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+	SetRolePermissions(ctx context.Context, role string, permissions []string, ttl time.Duration) error
+	DeleteRolePermissions(ctx context.Context, role string) error
+}
+
+// OIDCStateStore SSO 登录过程中的 state（含 nonce 与 PKCE verifier），一次性使用
+type OIDCStateStore interface {
+	SaveOIDCState(ctx context.Context, state string, data []byte, ttl time.Duration) error
+	// TakeOIDCState 读取并删除，不存在或已过期返回 error
+	TakeOIDCState(ctx context.Context, state string) ([]byte, error)
+}
+
+// MFAChallengeStore 登录第二步的挑战令牌（按哈希保存），过期或失败次数过多后失效
+type MFAChallengeStore interface {
+	SaveMFAChallenge(ctx context.Context, tokenHash string, data []byte, ttl time.Duration) error
+	GetMFAChallenge(ctx context.Context, tokenHash string) ([]byte, error)
+	// IncrMFAChallengeAttempts 验证失败次数加一并返回当前次数
+	IncrMFAChallengeAttempts(ctx context.Context, tokenHash string, ttl time.Duration) (int64, error)
+	DeleteMFAChallenge(ctx context.Context, tokenHash string) error
+}
+
+// LoginLockout 被临时锁定的用户名或 IP
+type LoginLockout struct {
+	Scope     string // user | ip
+	Key       string // 用户名或 IP
+	ExpiresIn time.Duration
+}
+
+// LoginAttemptStore 登录失败的滑动窗口计数、渐进延迟与临时锁定（多实例共享），scope 为 user 或 ip
+type LoginAttemptStore interface {
+	// ReserveLoginAttempt 原子地检查锁定与延迟，并在 window 内的记录数未达到 limit 时以 id 预占一次尝试；
+	// 被锁定或处于延迟期时返回剩余时间，预占名额已满时 reserved 为 false
+	ReserveLoginAttempt(ctx context.Context, scope, key, id string, at time.Time, window time.Duration, limit int64) (locked, delayed time.Duration, reserved bool, err error)
+	// ReleaseLoginAttempt 撤销预占（尝试未失败）
+	ReleaseLoginAttempt(ctx context.Context, scope, key, id string) error
+	// RecordLoginFailure 记录一次失败（同一 id 只计一次，预占的尝试直接转为失败），返回 window 内的失败次数
+	RecordLoginFailure(ctx context.Context, scope, key, id string, at time.Time, window time.Duration) (int64, error)
+	// GetLoginBlock 剩余的锁定时间与延迟时间，没有时为 0（只读，不预占）
+	GetLoginBlock(ctx context.Context, scope, key string) (locked, delayed time.Duration, err error)
+	SetLoginDelay(ctx context.Context, scope, key string, delay time.Duration) error
+	// IncrLockoutCount 累计锁定次数加一（每次续期 ttl），用于递增锁定时长
+	IncrLockoutCount(ctx context.Context, scope, key string, ttl time.Duration) (int64, error)
+	// LockLogin 锁定 duration，同时清除失败计数与延迟
+	LockLogin(ctx context.Context, scope, key string, duration time.Duration) error
+	// ClearLoginFailures 登录成功后清除失败计数与延迟（不解除锁定）
+	ClearLoginFailures(ctx context.Context, scope, key string) error
+	ListLoginLockouts(ctx context.Context) ([]LoginLockout, error)
+	// UnlockLogin 解除锁定并清除失败计数，返回是否曾被锁定
+	UnlockLogin(ctx context.Context, scope, key string) (bool, error)
+}
+
+// ActionTokenStore 邮件链接令牌的一次性使用标记与发信冷却（多实例共享）
+type ActionTokenStore interface {
+	// UseActionToken 标记令牌已使用，ttl 取令牌剩余有效期；已使用过返回 false
+	UseActionToken(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+	// AcquireMailCooldown 冷却期内对同一 key 只允许发送一次，返回是否允许
+	AcquireMailCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error)
 }
 
 // CacheInvalidator 缓存失效接口（删除/禁用链接时调用，保证 Redis + 本地缓存一致性）
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-short/internal/model"
+	"go-short/internal/repository"
 	"go-short/internal/util"
 	"time"
 
@@ -17,6 +19,7 @@ type CreateUserCommand struct {
 	Password string
 	Role     string
 	Status   string
+	ActorID  uuid.UUID // 操作的管理员，只能授予不超出自己权限的角色
 }
 
 func (s *AdminService) CreateUser(ctx context.Context, cmd CreateUserCommand) (*model.User, error) {
@@ -29,19 +32,35 @@ func (s *AdminService) CreateUser(ctx context.Context, cmd CreateUserCommand) (*
 		return nil, ErrUserExists
 	}
 
-	// 2. 加密密码
+	// 2. 检查角色是否存在（内置或自定义角色），且不超出操作者的权限
+	role, err := s.roleRepository.GetByName(ctx, s.db, cmd.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	actor, err := actorRole(ctx, s.db, s.userRepository, s.roleRepository, cmd.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleGrant(actor, role); err != nil {
+		return nil, err
+	}
+
+	// 3. 加密密码
 	hashedPassword, err := util.HashPassword(cmd.Password)
 	if err != nil {
 		return nil, fmt.Errorf("密码加密失败: %w", err)
 	}
 
-	// 3. 生成用户ID
+	// 4. 生成用户ID
 	id, err := uuid.NewV7()
 	if err != nil {
 		return nil, fmt.Errorf("生成用户ID失败: %w", err)
 	}
 
-	// 4. 创建用户对象
+	// 5. 创建用户对象
 	user := model.User{
 		ID:           id,
 		Username:     cmd.Username,
@@ -54,7 +73,7 @@ func (s *AdminService) CreateUser(ctx context.Context, cmd CreateUserCommand) (*
 		LinkCount:    0,
 	}
 
-	// 5. 保存到数据库
+	// 6. 保存到数据库
 	if err := s.userRepository.Create(ctx, s.db, &user); err != nil {
 		return nil, fmt.Errorf("创建用户失败: %w", err)
 	}
@@ -62,69 +81,217 @@ func (s *AdminService) CreateUser(ctx context.Context, cmd CreateUserCommand) (*
 	return &user, nil
 }
 
-// DeleteUser 删除用户（仅删除用户记录）
-func (s *AdminService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	return s.userRepository.DeleteUserByID(ctx, s.db, userID)
-}
-
-// DeleteUserAndData 删除用户及其所有关联数据（使用事务保证原子性）
-func (s *AdminService) DeleteUserAndData(ctx context.Context, userID uuid.UUID) error {
-	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// DeleteUserAndData 删除用户及其所有链接（使用事务保证原子性），actorID 为操作的管理员。
+// 用户与链接使用同一删除时间，恢复用户时据此只恢复随用户一起删除的链接；保留期满后由维护任务彻底清理。
+func (s *AdminService) DeleteUserAndData(ctx context.Context, userID, actorID uuid.UUID) error {
+	if err := s.AuthorizeUserAction(ctx, actorID, userID); err != nil {
+		return err
+	}
+	at := time.Now().Truncate(time.Microsecond) // 与 PostgreSQL 时间精度一致，便于按删除时间匹配
+	var links []model.Link
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 1. 删除用户的所有链接
-		if err := s.linkRepository.DeleteLinksByUser(ctx, tx, userID); err != nil {
+		var err error
+		if links, err = s.linkRepository.DeleteLinksByUser(ctx, tx, userID, at); err != nil {
 			return err
 		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, userID, -int64(len(links))); err != nil {
+			return fmt.Errorf("更新链接计数失败: %w", err)
+		}
 
 		// 2. 删除用户记录
-		if err := s.userRepository.DeleteUserByID(ctx, tx, userID); err != nil {
+		if err := s.userRepository.DeleteUserByID(ctx, tx, userID, at); err != nil {
 			return err
 		}
 
-		return nil
+		return recordLifecycleRevisions(ctx, tx, s.revisionRepository, model.RevisionDelete, actorID, links)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateLinks(ctx, links)
+	return nil
+}
+
+// ListDeletedUsers 已删除（保留期内可恢复）的用户
+func (s *AdminService) ListDeletedUsers(ctx context.Context, page, size int) ([]model.User, int64, error) {
+	return s.userRepository.ListDeletedUsers(ctx, s.db, page, size)
+}
+
+// RestoreUser 恢复已删除的用户及随其一起删除的链接（用户自己移入回收站的链接仍留在回收站）
+func (s *AdminService) RestoreUser(ctx context.Context, userID, actorID uuid.UUID) error {
+	user, err := s.userRepository.GetDeletedUserByID(ctx, s.db, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.authorizeUser(ctx, actorID, user); err != nil {
+		return err
+	}
+	var links []model.Link
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepository.RestoreUserByID(ctx, tx, userID); err != nil {
+			return fmt.Errorf("恢复用户失败: %w", err)
+		}
+		var err error
+		if links, err = s.linkRepository.RestoreLinksByUser(ctx, tx, userID, user.DeletedAt.Time); err != nil {
+			return fmt.Errorf("恢复链接失败: %w", err)
+		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, userID, int64(len(links))); err != nil {
+			return fmt.Errorf("更新链接计数失败: %w", err)
+		}
+		return recordLifecycleRevisions(ctx, tx, s.revisionRepository, model.RevisionRestore, actorID, links)
 	})
+	if err != nil {
+		return err
+	}
+	// 清除删除期间可能写入的"不存在"缓存
+	s.invalidateLinks(ctx, links)
+	return nil
+}
+
+func (s *AdminService) invalidateLinks(ctx context.Context, links []model.Link) {
+	if s.cacheInvalidator == nil {
+		return
+	}
+	for _, l := range links {
+		_ = s.cacheInvalidator.InvalidateLink(ctx, model.LinkKey(l.Domain, l.ShortCode))
+	}
 }
 
 // UnactiveUserByUserID 禁用用户
-func (s *AdminService) UnactiveUserByUserID(ctx context.Context, userID uuid.UUID) error {
+func (s *AdminService) UnactiveUserByUserID(ctx context.Context, userID, actorID uuid.UUID) error {
+	if err := s.AuthorizeUserAction(ctx, actorID, userID); err != nil {
+		return err
+	}
 	return s.userRepository.UnactiveUserByUserID(ctx, s.db, userID)
 }
 
 // ActiveUserByUserID 激活用户
-func (s *AdminService) ActiveUserByUserID(ctx context.Context, userID uuid.UUID) error {
+func (s *AdminService) ActiveUserByUserID(ctx context.Context, userID, actorID uuid.UUID) error {
+	if err := s.AuthorizeUserAction(ctx, actorID, userID); err != nil {
+		return err
+	}
 	return s.userRepository.ActiveUserByUserID(ctx, s.db, userID)
 }
 
-// GetAllUsers 获取所有用户列表（分页）
-func (s *AdminService) GetAllUsers(ctx context.Context, page, size int) ([]model.User, int64, error) {
-	return s.userRepository.GetAllUsers(ctx, s.db, page, size)
+// AuthorizeUserAction 删除、禁用、重置两步验证、终止会话等管理操作前调用：
+// 与修改用户角色一样，目标用户当前角色的权限不能超出操作者的权限（admin 只能由 admin 管理）
+func (s *AdminService) AuthorizeUserAction(ctx context.Context, actorID, userID uuid.UUID) error {
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+	return s.authorizeUser(ctx, actorID, user)
 }
 
-// ActiveLink 激活链接（需失效旧缓存，下次访问会从 DB 回源并回填）
-func (s *AdminService) ActiveLink(ctx context.Context, linkID int64) error {
-	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+func (s *AdminService) authorizeUser(ctx context.Context, actorID uuid.UUID, user *model.User) error {
+	actor, err := actorRole(ctx, s.db, s.userRepository, s.roleRepository, actorID)
 	if err != nil {
 		return err
 	}
-	if err := s.linkRepository.ActiveLink(ctx, s.db, linkID); err != nil {
+	target, err := roleOrEmpty(ctx, s.db, s.roleRepository, user.Role)
+	if err != nil {
 		return err
 	}
-	if s.cacheInvalidator != nil {
-		_ = s.cacheInvalidator.InvalidateLink(ctx, link.ShortCode)
+	return checkRoleGrant(actor, target)
+}
+
+// GetAllUsers 获取所有用户列表（分页）
+func (s *AdminService) GetAllUsers(ctx context.Context, page, size int) ([]model.User, int64, error) {
+	return s.userRepository.GetAllUsers(ctx, s.db, page, size)
+}
+
+type ListUsersQuery struct {
+	Page   int
+	Size   int
+	Cursor *string // 非 nil 时使用游标分页（空串表示第一页），忽略 Page
+	Count  string  // exact | estimated | none
+}
+
+// UserPage 用户列表分页结果
+type UserPage struct {
+	Users      []model.User
+	Total      int64 // -1 表示未统计
+	Estimated  bool
+	NextCursor string
+}
+
+// ListUsers 用户列表，支持 offset 或按 (created_at, id) 的游标分页
+func (s *AdminService) ListUsers(ctx context.Context, q ListUsersQuery) (*UserPage, error) {
+	if q.Page < 1 {
+		q.Page = 1
 	}
-	return nil
+	filter := repository.UserListFilter{
+		Page:  q.Page,
+		Size:  q.Size,
+		Count: resolveCountMode(q.Count, q.Cursor != nil),
+	}
+	if q.Cursor != nil {
+		filter.Page = 1
+		filter.Size = q.Size + 1
+		if *q.Cursor != "" {
+			c, err := util.DecodeCursor(*q.Cursor)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			id, err := uuid.Parse(c.ID)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			filter.After = &repository.UserCursor{CreatedAt: c.CreatedAt, ID: id}
+		}
+	}
+
+	users, total, err := s.userRepository.ListUsers(ctx, s.db, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &UserPage{Users: users, Total: total, Estimated: filter.Count == repository.CountEstimated}
+	if q.Cursor != nil && len(users) > q.Size {
+		page.Users = users[:q.Size]
+		last := page.Users[q.Size-1]
+		page.NextCursor = util.EncodeCursor(util.Cursor{CreatedAt: last.CreatedAt, ID: last.ID.String()})
+	}
+	return page, nil
+}
+
+// ActiveLink 激活链接（需失效旧缓存，下次访问会从 DB 回源并回填）
+func (s *AdminService) ActiveLink(ctx context.Context, linkID int64, actorID uuid.UUID) error {
+	return s.setLinkStatus(ctx, linkID, actorID, true)
 }
 
 // UnactiveLink 禁用链接
-func (s *AdminService) UnactiveLink(ctx context.Context, linkID int64) error {
+func (s *AdminService) UnactiveLink(ctx context.Context, linkID int64, actorID uuid.UUID) error {
+	return s.setLinkStatus(ctx, linkID, actorID, false)
+}
+
+// setLinkStatus 启停链接并记录变更（操作人为管理员）
+func (s *AdminService) setLinkStatus(ctx context.Context, linkID int64, actorID uuid.UUID, status bool) error {
 	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
 	if err != nil {
 		return err
 	}
-	if err := s.linkRepository.UnactiveLink(ctx, s.db, linkID); err != nil {
+	before := *link
+	link.Status = status
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if status {
+			err = s.linkRepository.ActiveLink(ctx, tx, linkID)
+		} else {
+			err = s.linkRepository.UnactiveLink(ctx, tx, linkID)
+		}
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, s.revisionRepository, model.RevisionUpdate, actorID, &before, link)
+	})
+	if err != nil {
 		return err
 	}
 	if s.cacheInvalidator != nil {
-		_ = s.cacheInvalidator.InvalidateLink(ctx, link.ShortCode)
+		_ = s.cacheInvalidator.InvalidateLink(ctx, model.LinkKey(link.Domain, link.ShortCode))
 	}
 	return nil
 }
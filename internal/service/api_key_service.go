@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/util"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAPIKeysPerUser     = 20
+	maxAPIKeyAllowedIPs   = 20
+	apiKeyLastUsedRefresh = time.Minute // 最近使用时间的写库间隔
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API Key 不存在")
+	ErrAPIKeyLimit         = errors.New("API Key 数量已达上限")
+	ErrInvalidAPIKeyScope  = errors.New("权限范围无效")
+	ErrInvalidAPIKeyIP     = errors.New("IP 白名单格式无效")
+	ErrInvalidAPIKeyExpiry = errors.New("过期时间必须晚于当前时间")
+	ErrInvalidAPIKey       = errors.New("API Key 无效或已过期")
+	ErrAPIKeyIPNotAllowed  = errors.New("当前 IP 不在 API Key 白名单中")
+)
+
+// normalizeScopes 校验并去重权限范围（至少一个）
+func normalizeScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.TrimSpace(s)
+		if !slices.Contains(model.APIKeyScopes, s) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidAPIKeyScope
+	}
+	return out, nil
+}
+
+// normalizeAllowedIPs 校验 IP / CIDR 白名单，统一为规范写法（单个 IP 不带掩码）
+func normalizeAllowedIPs(ips []string) ([]string, error) {
+	if len(ips) > maxAPIKeyAllowedIPs {
+		return nil, ErrInvalidAPIKeyIP
+	}
+	out := make([]string, 0, len(ips))
+	for _, raw := range ips {
+		raw = strings.TrimSpace(raw)
+		var entry string
+		if strings.Contains(raw, "/") {
+			_, ipNet, err := net.ParseCIDR(raw)
+			if err != nil {
+				return nil, ErrInvalidAPIKeyIP
+			}
+			entry = ipNet.String()
+		} else {
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return nil, ErrInvalidAPIKeyIP
+			}
+			entry = ip.String()
+		}
+		if !slices.Contains(out, entry) {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+// ipAllowed 白名单为空时不限制
+func ipAllowed(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListAPIKeys 获取用户的 API Key（不含密钥）
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	return s.apiKeyRepository.ListByUser(ctx, s.db, userID)
+}
+
+// GetAPIKey 获取用户的某个 API Key
+func (s *APIKeyService) GetAPIKey(ctx context.Context, userID, keyID uuid.UUID) (*model.APIKey, error) {
+	key, err := s.apiKeyRepository.GetByID(ctx, s.db, userID, keyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("查询 API Key 失败: %w", err)
+	}
+	return key, nil
+}
+
+type CreateAPIKeyCommand struct {
+	UserID     uuid.UUID
+	Name       string
+	Scopes     []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+}
+
+// CreateAPIKey 创建 API Key，返回的完整密钥只在此时可见
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, cmd CreateAPIKeyCommand) (*model.APIKey, string, error) {
+	scopes, err := normalizeScopes(cmd.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	allowedIPs, err := normalizeAllowedIPs(cmd.AllowedIPs)
+	if err != nil {
+		return nil, "", err
+	}
+	if cmd.ExpiresAt != nil && cmd.ExpiresAt.Before(time.Now()) {
+		return nil, "", ErrInvalidAPIKeyExpiry
+	}
+
+	existing, err := s.apiKeyRepository.ListByUser(ctx, s.db, cmd.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("查询 API Key 失败: %w", err)
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, "", ErrAPIKeyLimit
+	}
+
+	raw, prefix, hash, err := util.NewAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+	key := &model.APIKey{
+		ID:         uuid.New(),
+		UserID:     cmd.UserID,
+		Name:       strings.TrimSpace(cmd.Name),
+		Prefix:     prefix,
+		SecretHash: hash,
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  cmd.ExpiresAt,
+	}
+	if err := s.apiKeyRepository.Create(ctx, s.db, key); err != nil {
+		return nil, "", fmt.Errorf("保存 API Key 失败: %w", err)
+	}
+	return key, raw, nil
+}
+
+type UpdateAPIKeyCommand struct {
+	UserID         uuid.UUID
+	KeyID          uuid.UUID
+	Name           *string
+	Scopes         []string // nil 表示不修改
+	AllowedIPs     []string // nil 表示不修改，空数组表示取消限制
+	ExpiresAt      *time.Time
+	ClearExpiresAt bool
+}
+
+// UpdateAPIKey 修改名称、权限范围、IP 白名单或过期时间（密钥本身不变）
+func (s *APIKeyService) UpdateAPIKey(ctx context.Context, cmd UpdateAPIKeyCommand) (*model.APIKey, error) {
+	key, err := s.GetAPIKey(ctx, cmd.UserID, cmd.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Name != nil {
+		key.Name = strings.TrimSpace(*cmd.Name)
+	}
+	if cmd.Scopes != nil {
+		if key.Scopes, err = normalizeScopes(cmd.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.AllowedIPs != nil {
+		if key.AllowedIPs, err = normalizeAllowedIPs(cmd.AllowedIPs); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.ClearExpiresAt {
+		key.ExpiresAt = nil
+	} else if cmd.ExpiresAt != nil {
+		if cmd.ExpiresAt.Before(time.Now()) {
+			return nil, ErrInvalidAPIKeyExpiry
+		}
+		key.ExpiresAt = cmd.ExpiresAt
+	}
+
+	if err := s.apiKeyRepository.Update(ctx, s.db, key); err != nil {
+		return nil, fmt.Errorf("更新 API Key 失败: %w", err)
+	}
+	return key, nil
+}
+
+// DeleteAPIKey 删除 API Key，立即失效
+func (s *APIKeyService) DeleteAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	ok, err := s.apiKeyRepository.Delete(ctx, s.db, userID, keyID)
+	if err != nil {
+		return fmt.Errorf("删除 API Key 失败: %w", err)
+	}
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey 校验 API Key 及其过期时间、IP 白名单和所属用户状态，成功时记录最近使用时间
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey, clientIP string) (*model.APIKey, *model.User, error) {
+	prefix, secret, ok := util.ParseAPIKey(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	key, err := s.apiKeyRepository.GetByPrefix(ctx, s.db, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, fmt.Errorf("查询 API Key 失败: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(util.HashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if !ipAllowed(key.AllowedIPs, clientIP) {
+		return nil, nil, ErrAPIKeyIPNotAllowed
+	}
+
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, key.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey // 用户已删除
+		}
+		return nil, nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user.Status != "active" {
+		return nil, nil, ErrUserDisabled
+	}
+
+	if err := s.apiKeyRepository.TouchLastUsed(ctx, s.db, key.ID, now, apiKeyLastUsedRefresh); err != nil {
+		log.Printf("⚠️ Update API key last used failed: %v", err)
+	}
+	return key, user, nil
+}
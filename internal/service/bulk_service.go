@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"go-short/internal/util"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	bulkBatchSize   = 500             // 每个事务插入的行数
+	bulkJobTTL      = 24 * time.Hour  // 任务状态保留时间
+	bulkJobStale    = 5 * time.Minute // 运行中的任务超过该时间未更新进度，视为已中断（实例重启或崩溃）
+	BulkSyncLimit   = 1000            // 不超过该行数时同步处理并直接返回结果
+	BulkMaxRows     = 100000          // 单次导入上限
+	bulkStatusRun   = "running"
+	bulkStatusDone  = "completed"
+	bulkStatusError = "failed"
+)
+
+const (
+	BulkResultCreated = "created"
+	BulkResultExists  = "exists"
+	BulkResultError   = "error"
+)
+
+var (
+	ErrBulkEmpty       = errors.New("没有可导入的链接")
+	ErrBulkTooLarge    = errors.New("导入行数超过上限")
+	ErrBulkJobNotFound = errors.New("导入任务不存在")
+	errBulkJobAborted  = errors.New("任务已中断（服务重启或异常退出），已创建的链接会保留，重新导入时记为已存在")
+	errBulkRowSkipped  = errors.New("系统错误，未创建")
+)
+
+// BulkLinkItem 批量创建中的一行（Invalid 非空表示校验未通过，直接记为失败）
+type BulkLinkItem struct {
+	Command CreateLinkCommand
+	Invalid error
+}
+
+// BulkLinkResult 单行导入结果，Row 从 1 开始
+type BulkLinkResult struct {
+	Row       int    `json:"row"`
+	Status    string `json:"status"` // created | exists | error
+	LinkID    int64  `json:"link_id,omitempty"`
+	ShortCode string `json:"short_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkJob 后台导入任务状态（存于 Redis，支持多实例轮询）
+type BulkJob struct {
+	ID          string           `json:"id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	WorkspaceID *int64           `json:"workspace_id,omitempty"` // 导入到的工作区，空表示个人链接
+	Domain      string           `json:"domain,omitempty"`       // 所有行使用的自定义域名
+	Status      string           `json:"status"`                 // running | completed | failed
+	Total       int              `json:"total"`
+	Processed   int              `json:"processed"`
+	Created     int              `json:"created"`
+	Exists      int              `json:"exists"`
+	Failed      int              `json:"failed"`
+	Error       string           `json:"error,omitempty"`
+	Results     []BulkLinkResult `json:"results,omitempty"` // 任务完成后写入
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"` // 最近一次保存进度的时间，用于识别已中断的任务
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+}
+
+// stale 运行中但长时间未更新进度：处理任务的实例已退出
+func (j *BulkJob) stale(now time.Time) bool {
+	last := j.UpdatedAt
+	if last.IsZero() {
+		last = j.CreatedAt
+	}
+	return j.Status == bulkStatusRun && now.Sub(last) > bulkJobStale
+}
+
+// count 按结果累计计数
+func (j *BulkJob) count(results []BulkLinkResult) {
+	for _, r := range results {
+		switch r.Status {
+		case BulkResultCreated:
+			j.Created++
+		case BulkResultExists:
+			j.Exists++
+		default:
+			j.Failed++
+		}
+	}
+	j.Processed += len(results)
+}
+
+// BulkCreateLinks 同步批量创建（行数不超过 BulkSyncLimit），domain 为空表示默认域名，workspaceID 为空表示个人链接
+func (s *BulkService) BulkCreateLinks(ctx context.Context, userID uuid.UUID, workspaceID *int64, domain string, items []BulkLinkItem) ([]BulkLinkResult, error) {
+	if len(items) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(items) > BulkSyncLimit {
+		return nil, ErrBulkTooLarge
+	}
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, userID, workspaceID, model.WorkspaceRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	domain, err = resolveLinkDomain(ctx, s.domainRepository, userID, domain)
+	if err != nil {
+		return nil, err
+	}
+	return s.createLinks(ctx, scope, domain, items, nil)
+}
+
+// StartBulkJob 创建后台导入任务并立即返回，进度通过 GetBulkJob 轮询
+func (s *BulkService) StartBulkJob(ctx context.Context, userID uuid.UUID, workspaceID *int64, domain string, items []BulkLinkItem) (*BulkJob, error) {
+	if len(items) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(items) > BulkMaxRows {
+		return nil, ErrBulkTooLarge
+	}
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, userID, workspaceID, model.WorkspaceRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	domain, err = resolveLinkDomain(ctx, s.domainRepository, userID, domain)
+	if err != nil {
+		return nil, err
+	}
+	job := &BulkJob{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Domain:      domain,
+		Status:      bulkStatusRun,
+		Total:       len(items),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("保存导入任务失败: %w", err)
+	}
+
+	// 任务与请求生命周期无关，使用独立 context；每批完成后保存进度，实例退出导致的中断由 GetBulkJob 识别
+	go func(job BulkJob) {
+		bgCtx := context.Background()
+		var results []BulkLinkResult
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Bulk job panicked: job=%s, err=%v", job.ID, r)
+				err = fmt.Errorf("内部错误: %v", r)
+			}
+			s.finishJob(bgCtx, &job, results, err)
+		}()
+		results, err = s.createLinks(bgCtx, scope, domain, items, func(batch []BulkLinkResult) {
+			job.count(batch)
+			if err := s.saveJob(bgCtx, &job); err != nil {
+				log.Printf("Save bulk job progress failed: job=%s, err=%v", job.ID, err)
+			}
+		})
+	}(*job)
+
+	return job, nil
+}
+
+// finishJob 保存任务结果；计数按最终结果重算（失败的那一批不会经过进度回调）
+func (s *BulkService) finishJob(ctx context.Context, job *BulkJob, results []BulkLinkResult, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Results = results
+	job.Processed, job.Created, job.Exists, job.Failed = 0, 0, 0, 0
+	job.count(results)
+	job.Status = bulkStatusDone
+	if err != nil {
+		job.Status = bulkStatusError
+		job.Error = err.Error()
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		log.Printf("Save bulk job result failed: job=%s, err=%v", job.ID, err)
+	}
+}
+
+// GetBulkJob 查询导入任务（只能查询自己的任务）
+func (s *BulkService) GetBulkJob(ctx context.Context, jobID string, userID uuid.UUID) (*BulkJob, error) {
+	data, err := s.jobStore.GetBulkJob(ctx, jobID)
+	if err != nil {
+		return nil, ErrBulkJobNotFound
+	}
+	var job BulkJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("解析导入任务失败: %w", err)
+	}
+	if job.UserID != userID {
+		return nil, ErrBulkJobNotFound
+	}
+	if now := time.Now(); job.stale(now) {
+		job.Status = bulkStatusError
+		job.Error = errBulkJobAborted.Error()
+		job.FinishedAt = &now
+		if err := s.saveJob(ctx, &job); err != nil {
+			log.Printf("Save aborted bulk job failed: job=%s, err=%v", job.ID, err)
+		}
+	}
+	return &job, nil
+}
+
+func (s *BulkService) saveJob(ctx context.Context, job *BulkJob) error {
+	job.UpdatedAt = time.Now()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.jobStore.SaveBulkJob(ctx, job.ID, data, bulkJobTTL)
+}
+
+// createLinks 按批处理所有行，每批完成后回调 onBatch；返回与 items 一一对应的结果
+func (s *BulkService) createLinks(ctx context.Context, scope repository.LinkScope, domain string, items []BulkLinkItem, onBatch func([]BulkLinkResult)) ([]BulkLinkResult, error) {
+	// 默认别名沿用单条创建的「短链接N」规则，只查询一次链接数
+	var count int64
+	var err error
+	if scope.WorkspaceID != nil {
+		count, err = s.linkRepository.CountWorkspaceLinks(ctx, s.db, *scope.WorkspaceID)
+	} else {
+		count, err = s.linkRepository.GetNumOfLinksByUser(ctx, s.db, scope.UserID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取链接数失败: %w", err)
+	}
+
+	results := make([]BulkLinkResult, 0, len(items))
+	seenURLs := make(map[string]*BulkLinkResult) // 本次导入内重复的 URL 视为已存在，指向首次出现的结果
+	seenCodes := make(map[string]bool)           // 本次导入内重复的自定义短码
+	for start := 0; start < len(items); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(items))
+		batch, err := s.createBatch(ctx, scope, domain, items[start:end], start, &count, seenURLs, seenCodes)
+		results = append(results, batch...)
+		if err != nil {
+			return results, err
+		}
+		if onBatch != nil {
+			onBatch(batch)
+		}
+	}
+	return results, nil
+}
+
+// createBatch 处理一批：校验与批量查重，再插入需要新建的行；插入出错时仍返回本批结果（已创建的行如实标记）
+func (s *BulkService) createBatch(ctx context.Context, scope repository.LinkScope, domain string, items []BulkLinkItem, offset int, count *int64, seenURLs map[string]*BulkLinkResult, seenCodes map[string]bool) ([]BulkLinkResult, error) {
+	results := make([]BulkLinkResult, len(items))
+	urls := make([]string, len(items))
+	var lookupURLs, lookupCodes []string
+	for i, item := range items {
+		results[i].Row = offset + i + 1
+		if item.Invalid != nil {
+			results[i].Status = BulkResultError
+			results[i].Error = item.Invalid.Error()
+			continue
+		}
+		normalizedURL, err := normalizeURL(item.Command.OriginalURL)
+		if err == nil {
+			err = checkDestination(ctx, s.urlChecker, normalizedURL)
+		}
+		if err != nil {
+			results[i].Status = BulkResultError
+			results[i].Error = err.Error()
+			continue
+		}
+		urls[i] = normalizedURL
+		lookupURLs = append(lookupURLs, normalizedURL)
+		if code := item.Command.ShortCode; code != nil && *code != "" {
+			lookupCodes = append(lookupCodes, *code)
+		}
+	}
+
+	existingLinks, err := s.linkRepository.GetLinksByScopeAndURLs(ctx, s.db, scope, domain, lookupURLs)
+	if err != nil {
+		return nil, fmt.Errorf("检查已有链接失败: %w", err)
+	}
+	existingByURL := make(map[string]model.Link, len(existingLinks))
+	for _, l := range existingLinks {
+		existingByURL[l.OriginalURL] = l
+	}
+	takenCodes, err := s.linkRepository.GetExistingShortCodes(ctx, s.db, domain, lookupCodes)
+	if err != nil {
+		return nil, fmt.Errorf("检查短码失败: %w", err)
+	}
+	for _, code := range takenCodes {
+		seenCodes[code] = true
+	}
+
+	// 筛出需要新建的行
+	var pending, duplicates []int
+	dupOf := make(map[int]*BulkLinkResult)
+	for i, item := range items {
+		if results[i].Status != "" {
+			continue
+		}
+		if l, ok := existingByURL[urls[i]]; ok {
+			results[i].Status = BulkResultExists
+			results[i].LinkID = l.ID
+			results[i].ShortCode = l.ShortCode
+			continue
+		}
+		if prev, ok := seenURLs[urls[i]]; ok {
+			duplicates = append(duplicates, i)
+			dupOf[i] = prev
+			continue
+		}
+		if code := item.Command.ShortCode; code != nil && *code != "" {
+			if seenCodes[*code] {
+				results[i].Status = BulkResultError
+				results[i].Error = ErrShortCodeExists.Error()
+				continue
+			}
+			seenCodes[*code] = true
+		}
+		seenURLs[urls[i]] = &results[i]
+		pending = append(pending, i)
+	}
+	if len(pending) > 0 {
+		if err := s.insertPending(ctx, scope, domain, items, urls, pending, results, count); err != nil {
+			return results, err
+		}
+	}
+
+	// 重复行引用首次出现的结果（首次出现可能在本批，需等插入完成后回填）
+	for _, i := range duplicates {
+		prev := dupOf[i]
+		results[i].LinkID = prev.LinkID
+		results[i].ShortCode = prev.ShortCode
+		results[i].Status = BulkResultExists
+		if prev.Status == BulkResultError {
+			results[i].Status = BulkResultError
+			results[i].Error = prev.Error
+		}
+	}
+	return results, nil
+}
+
+// insertPending 预分配 ID 生成短码后单事务插入，并更新链接计数。
+// 整批因唯一约束失败时逐行重试定位冲突的行；其他数据库错误直接返回，未创建的行标记为失败
+func (s *BulkService) insertPending(ctx context.Context, scope repository.LinkScope, domain string, items []BulkLinkItem, urls []string, pending []int, results []BulkLinkResult, count *int64) error {
+	ids, err := s.linkRepository.NextLinkIDs(ctx, s.db, len(pending))
+	if err != nil {
+		return fmt.Errorf("分配链接ID失败: %w", err)
+	}
+	links := make([]model.Link, len(pending))
+	for j, i := range pending {
+		cmd := items[i].Command
+		*count++
+		links[j] = newBulkLink(ids[j], scope, domain, urls[i], cmd, *count)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.linkRepository.CreateBatch(ctx, tx, links); err != nil {
+			return err
+		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, scope.UserID, int64(len(links))); err != nil {
+			return err
+		}
+		return recordLifecycleRevisions(ctx, tx, s.revisionRepository, model.RevisionCreate, scope.UserID, links)
+	})
+	if err != nil {
+		if !isDuplicateKeyError(err) {
+			markSkipped(results, pending)
+			return fmt.Errorf("批量创建链接失败: %w", err)
+		}
+		// 并发抢占了短码，逐行重试以定位冲突的行
+		log.Printf("Bulk insert batch conflicted, retrying row by row: %v", err)
+		for j, i := range pending {
+			if err := s.createOne(ctx, &links[j]); err != nil {
+				if !isDuplicateKeyError(err) {
+					markSkipped(results, pending[j:])
+					return fmt.Errorf("创建链接失败: %w", err)
+				}
+				results[i].Status = BulkResultError
+				results[i].Error = ErrShortCodeExists.Error()
+				continue
+			}
+			markCreated(&results[i], &links[j])
+		}
+		return nil
+	}
+
+	for j, i := range pending {
+		markCreated(&results[i], &links[j])
+	}
+	return nil
+}
+
+// createOne 单行插入（批量失败后的降级路径）
+func (s *BulkService) createOne(ctx context.Context, link *model.Link) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.linkRepository.Create(ctx, tx, link); err != nil {
+			return err
+		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, link.UserID, 1); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, s.revisionRepository, model.RevisionCreate, link.UserID, nil, link)
+	})
+}
+
+// markSkipped 因数据库错误未能创建的行
+func markSkipped(results []BulkLinkResult, rows []int) {
+	for _, i := range rows {
+		results[i].Status = BulkResultError
+		results[i].Error = errBulkRowSkipped.Error()
+	}
+}
+
+func markCreated(result *BulkLinkResult, link *model.Link) {
+	result.Status = BulkResultCreated
+	result.LinkID = link.ID
+	result.ShortCode = link.ShortCode
+}
+
+// newBulkLink 按单条创建的规则构造链接，ID 预先分配，未指定短码时直接由 ID 生成
+func newBulkLink(id int64, scope repository.LinkScope, domain, normalizedURL string, cmd CreateLinkCommand, seq int64) model.Link {
+	link := model.Link{
+		ID:               id,
+		Domain:           domain,
+		OriginalURL:      normalizedURL,
+		UserID:           scope.UserID,
+		WorkspaceID:      scope.WorkspaceID,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        cmd.ExpiresAt,
+		Status:           true,
+		Alias:            fmt.Sprintf("短链接%d", seq),
+		ShortCode:        util.Encode(id),
+		TrackConversions: cmd.TrackConversions,
+		Version:          1,
+	}
+	if cmd.Status != nil {
+		link.Status = *cmd.Status
+	}
+	if cmd.Alias != nil && *cmd.Alias != "" {
+		link.Alias = *cmd.Alias
+	}
+	if cmd.ShortCode != nil && *cmd.ShortCode != "" {
+		link.ShortCode = *cmd.ShortCode
+		link.IsCustom = true
+	}
+	return link
+}
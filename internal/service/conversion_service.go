@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/util"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidClickID      = errors.New("点击 ID 无效")
+	ErrConversionDuplicate = errors.New("转化事件已记录")
+)
+
+type RecordConversionCommand struct {
+	UserID     uuid.UUID // 上报者，须能编辑点击所属的链接
+	IsAdmin    bool
+	ClickToken string
+	Event      string
+	Value      float64
+	Currency   string
+}
+
+// RecordConversion 校验点击 ID 签名与上报者对链接的权限，并记录转化事件（同一点击的同名事件只记录一次）
+func (s *ConversionService) RecordConversion(ctx context.Context, cmd RecordConversionCommand) (*model.Conversion, error) {
+	token, err := util.ParseClickToken(cmd.ClickToken)
+	if err != nil {
+		return nil, ErrInvalidClickID
+	}
+
+	domain, code := model.ParseLinkKey(token.ShortCode) // 自定义域名的链接签名时使用 "域名/短码"
+	link, err := s.linkRepository.GetLinkByCode(ctx, s.db, domain, code)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, cmd.UserID, cmd.IsAdmin, model.WorkspaceRoleEditor); err != nil {
+		return nil, err
+	}
+
+	event := strings.TrimSpace(cmd.Event)
+	if event == "" {
+		event = "conversion"
+	}
+	conversion := &model.Conversion{
+		ClickID:   token.ClickID,
+		Event:     event,
+		LinkID:    link.ID,
+		ShortCode: token.ShortCode,
+		Value:     cmd.Value,
+		Currency:  strings.ToUpper(cmd.Currency),
+		ClickedAt: token.ClickedAt(),
+	}
+	if err := s.conversionRepository.Create(ctx, s.db, conversion); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrConversionDuplicate
+		}
+		return nil, fmt.Errorf("记录转化失败: %w", err)
+	}
+	return conversion, nil
+}
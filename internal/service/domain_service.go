@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"go-short/internal/util"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 域名所有权验证：在 _goshort.<域名> 添加 TXT 记录，值为 goshort-verify=<token>
+const (
+	domainVerifyPrefix = "_goshort."
+	domainVerifyValue  = "goshort-verify="
+)
+
+var (
+	ErrDomainNotFound     = errors.New("域名不存在")
+	ErrDomainExists       = errors.New("域名已被绑定")
+	ErrInvalidDomain      = errors.New("域名格式无效")
+	ErrDomainNotVerified  = errors.New("域名尚未验证")
+	ErrDomainVerifyFailed = errors.New("未找到匹配的 TXT 验证记录")
+	ErrDomainInUse        = errors.New("域名下仍有短链接，无法删除")
+)
+
+// TXTResolver DNS TXT 查询，生产环境使用 net.DefaultResolver，测试可替换为假实现
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainVerification 用户需要配置的 TXT 记录
+type DomainVerification struct {
+	RecordName  string
+	RecordValue string
+}
+
+// VerificationRecord 返回域名对应的 TXT 验证记录
+func VerificationRecord(domain *model.Domain) DomainVerification {
+	return DomainVerification{
+		RecordName:  domainVerifyPrefix + domain.Hostname,
+		RecordValue: domainVerifyValue + domain.VerifyToken,
+	}
+}
+
+// ListDomains 获取用户的自定义域名
+func (s *DomainService) ListDomains(ctx context.Context, userID uuid.UUID) ([]model.Domain, error) {
+	return s.domainRepository.GetDomainsByUser(ctx, s.db, userID)
+}
+
+// AddDomain 添加自定义域名（未验证状态）
+// 已被他人添加但尚未验证的域名可以重新认领，所有权以 DNS 验证为准
+func (s *DomainService) AddDomain(ctx context.Context, userID uuid.UUID, hostname string) (*model.Domain, error) {
+	hostname = util.NormalizeHost(hostname)
+	if !util.IsValidHostname(hostname) {
+		return nil, ErrInvalidDomain
+	}
+
+	if existing, err := s.domainRepository.GetDomainByHostname(ctx, s.db, hostname); err == nil {
+		if existing.UserID == userID {
+			return existing, nil
+		}
+		if existing.VerifiedAt != nil {
+			return nil, ErrDomainExists
+		}
+		if err := s.domainRepository.Delete(ctx, s.db, existing.ID); err != nil {
+			return nil, fmt.Errorf("删除未验证域名失败: %w", err)
+		}
+	}
+
+	token, err := newVerifyToken()
+	if err != nil {
+		return nil, err
+	}
+	domain := &model.Domain{UserID: userID, Hostname: hostname, VerifyToken: token}
+	if err := s.domainRepository.Create(ctx, s.db, domain); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrDomainExists
+		}
+		return nil, fmt.Errorf("添加域名失败: %w", err)
+	}
+	return domain, nil
+}
+
+func newVerifyToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成验证码失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// getOwnedDomain 获取域名并校验归属
+func (s *DomainService) getOwnedDomain(ctx context.Context, domainID int64, userID uuid.UUID) (*model.Domain, error) {
+	domain, err := s.domainRepository.GetDomainByID(ctx, s.db, domainID)
+	if err != nil || domain.UserID != userID {
+		return nil, ErrDomainNotFound
+	}
+	return domain, nil
+}
+
+// VerifyDomain 查询 TXT 记录验证域名所有权，已验证的域名直接返回
+func (s *DomainService) VerifyDomain(ctx context.Context, domainID int64, userID uuid.UUID) (*model.Domain, error) {
+	domain, err := s.getOwnedDomain(ctx, domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerifiedAt != nil {
+		return domain, nil
+	}
+
+	record := VerificationRecord(domain)
+	values, err := s.resolver.LookupTXT(ctx, record.RecordName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDomainVerifyFailed, err)
+	}
+	matched := false
+	for _, v := range values {
+		if strings.TrimSpace(v) == record.RecordValue {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, ErrDomainVerifyFailed
+	}
+
+	now := time.Now()
+	if err := s.domainRepository.MarkVerified(ctx, s.db, domain.ID, now); err != nil {
+		return nil, fmt.Errorf("更新验证状态失败: %w", err)
+	}
+	domain.VerifiedAt = &now
+	return domain, nil
+}
+
+// DeleteDomain 删除域名，域名下仍有链接时拒绝
+func (s *DomainService) DeleteDomain(ctx context.Context, domainID int64, userID uuid.UUID) error {
+	domain, err := s.getOwnedDomain(ctx, domainID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := s.linkRepository.CountLinksByDomain(ctx, s.db, domain.Hostname)
+	if err != nil {
+		return fmt.Errorf("统计域名链接失败: %w", err)
+	}
+	if n > 0 {
+		return ErrDomainInUse
+	}
+	return s.domainRepository.Delete(ctx, s.db, domain.ID)
+}
+
+// ListVerifiedHostnames 所有已验证的域名（Redirect 按 Host 路由使用）
+func (s *DomainService) ListVerifiedHostnames(ctx context.Context) ([]string, error) {
+	return s.domainRepository.ListVerifiedHostnames(ctx, s.db)
+}
+
+// IsVerifiedHostname 是否为已验证的自定义域名（URL 检查识别指向本服务的链接）
+func (s *DomainService) IsVerifiedHostname(ctx context.Context, hostname string) bool {
+	domain, err := s.domainRepository.GetDomainByHostname(ctx, s.db, hostname)
+	return err == nil && domain.VerifiedAt != nil
+}
+
+// resolveLinkDomain 校验创建链接时指定的域名：空表示默认域名，否则必须属于该用户且已验证
+func resolveLinkDomain(ctx context.Context, domainRepository repository.DomainRepository, userID uuid.UUID, hostname string) (string, error) {
+	hostname = util.NormalizeHost(hostname)
+	if hostname == "" {
+		return "", nil
+	}
+	domain, err := domainRepository.GetDomainByHostname(ctx, nil, hostname)
+	if err != nil || domain.UserID != userID {
+		return "", ErrDomainNotFound
+	}
+	if domain.VerifiedAt == nil {
+		return "", ErrDomainNotVerified
+	}
+	return domain.Hostname, nil
+}
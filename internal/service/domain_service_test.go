@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeDomainRepo 内存实现的 DomainRepository，hostname 唯一
+type fakeDomainRepo struct {
+	nextID  int64
+	domains map[int64]*model.Domain
+}
+
+func newFakeDomainRepo() *fakeDomainRepo {
+	return &fakeDomainRepo{domains: map[int64]*model.Domain{}}
+}
+
+func (r *fakeDomainRepo) Create(ctx context.Context, tx *gorm.DB, domain *model.Domain) error {
+	for _, d := range r.domains {
+		if d.Hostname == domain.Hostname {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	r.nextID++
+	domain.ID = r.nextID
+	d := *domain
+	r.domains[d.ID] = &d
+	return nil
+}
+
+func (r *fakeDomainRepo) GetDomainsByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]model.Domain, error) {
+	var out []model.Domain
+	for _, d := range r.domains {
+		if d.UserID == userID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDomainRepo) GetDomainByID(ctx context.Context, tx *gorm.DB, domainID int64) (*model.Domain, error) {
+	d, ok := r.domains[domainID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (r *fakeDomainRepo) GetDomainByHostname(ctx context.Context, tx *gorm.DB, hostname string) (*model.Domain, error) {
+	for _, d := range r.domains {
+		if d.Hostname == hostname {
+			cp := *d
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeDomainRepo) MarkVerified(ctx context.Context, tx *gorm.DB, domainID int64, at time.Time) error {
+	d, ok := r.domains[domainID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	d.VerifiedAt = &at
+	return nil
+}
+
+func (r *fakeDomainRepo) Delete(ctx context.Context, tx *gorm.DB, domainID int64) error {
+	delete(r.domains, domainID)
+	return nil
+}
+
+func (r *fakeDomainRepo) ListVerifiedHostnames(ctx context.Context, tx *gorm.DB) ([]string, error) {
+	var out []string
+	for _, d := range r.domains {
+		if d.VerifiedAt != nil {
+			out = append(out, d.Hostname)
+		}
+	}
+	return out, nil
+}
+
+// fakeResolver 按记录名返回预设的 TXT 值，并记录查询过的名称
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+	queried []string
+}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.queried = append(r.queried, name)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.records[name], nil
+}
+
+func newTestDomainService() (*DomainService, *fakeDomainRepo, *fakeResolver) {
+	repo := newFakeDomainRepo()
+	resolver := &fakeResolver{records: map[string][]string{}}
+	return NewDomainService(nil, nil, repo, resolver), repo, resolver
+}
+
+func TestAddDomain(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestDomainService()
+	user := uuid.New()
+
+	domain, err := svc.AddDomain(ctx, user, " Go.Example.COM. ")
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if domain.Hostname != "go.example.com" || domain.UserID != user || domain.VerifyToken == "" || domain.VerifiedAt != nil {
+		t.Fatalf("unexpected domain: %+v", domain)
+	}
+
+	again, err := svc.AddDomain(ctx, user, "go.example.com")
+	if err != nil || again.ID != domain.ID || again.VerifyToken != domain.VerifyToken {
+		t.Fatalf("re-adding own domain should return the existing one, got %+v, %v", again, err)
+	}
+
+	for _, host := range []string{"", "localhost", "127.0.0.1", "-bad.example.com", "a..example.com"} {
+		if _, err := svc.AddDomain(ctx, user, host); !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("AddDomain(%q) = %v, want ErrInvalidDomain", host, err)
+		}
+	}
+}
+
+func TestAddDomainVerifiedByOther(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestDomainService()
+	owner, other := uuid.New(), uuid.New()
+
+	domain, err := svc.AddDomain(ctx, owner, "go.example.com")
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if err := repo.MarkVerified(ctx, nil, domain.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.AddDomain(ctx, other, "go.example.com"); !errors.Is(err, ErrDomainExists) {
+		t.Fatalf("AddDomain by other user = %v, want ErrDomainExists", err)
+	}
+	if d, _ := repo.GetDomainByID(ctx, nil, domain.ID); d == nil || d.UserID != owner {
+		t.Fatalf("verified domain must stay with its owner, got %+v", d)
+	}
+}
+
+func TestAddDomainReclaimsUnverified(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, resolver := newTestDomainService()
+	squatter, owner := uuid.New(), uuid.New()
+
+	stale, err := svc.AddDomain(ctx, squatter, "go.example.com")
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	claimed, err := svc.AddDomain(ctx, owner, "go.example.com")
+	if err != nil {
+		t.Fatalf("reclaim: %v", err)
+	}
+	if claimed.UserID != owner || claimed.ID == stale.ID || claimed.VerifyToken == stale.VerifyToken {
+		t.Fatalf("reclaimed domain should be a new record with a new token, got %+v (old %+v)", claimed, stale)
+	}
+	if _, err := repo.GetDomainByID(ctx, nil, stale.ID); err == nil {
+		t.Fatal("unverified domain of the previous user should be removed")
+	}
+
+	// 原用户已失去该域名，即使按旧 token 配置了 TXT 记录也无法验证
+	rec := VerificationRecord(stale)
+	resolver.records[rec.RecordName] = []string{rec.RecordValue}
+	if _, err := svc.VerifyDomain(ctx, stale.ID, squatter); !errors.Is(err, ErrDomainNotFound) {
+		t.Fatalf("VerifyDomain by previous user = %v, want ErrDomainNotFound", err)
+	}
+	if _, err := svc.VerifyDomain(ctx, claimed.ID, owner); !errors.Is(err, ErrDomainVerifyFailed) {
+		t.Fatalf("old token must not verify the reclaimed domain, got %v", err)
+	}
+}
+
+func TestVerifyDomain(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, resolver := newTestDomainService()
+	user := uuid.New()
+
+	domain, err := svc.AddDomain(ctx, user, "go.example.com")
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	rec := VerificationRecord(domain)
+	if rec.RecordName != "_goshort.go.example.com" || rec.RecordValue != "goshort-verify="+domain.VerifyToken {
+		t.Fatalf("unexpected verification record: %+v", rec)
+	}
+
+	// 没有记录
+	if _, err := svc.VerifyDomain(ctx, domain.ID, user); !errors.Is(err, ErrDomainVerifyFailed) {
+		t.Fatalf("VerifyDomain without record = %v, want ErrDomainVerifyFailed", err)
+	}
+	// 记录值不匹配
+	resolver.records[rec.RecordName] = []string{"goshort-verify=other", "v=spf1 -all"}
+	if _, err := svc.VerifyDomain(ctx, domain.ID, user); !errors.Is(err, ErrDomainVerifyFailed) {
+		t.Fatalf("VerifyDomain with wrong value = %v, want ErrDomainVerifyFailed", err)
+	}
+	// 非所有者
+	if _, err := svc.VerifyDomain(ctx, domain.ID, uuid.New()); !errors.Is(err, ErrDomainNotFound) {
+		t.Fatalf("VerifyDomain by other user = %v, want ErrDomainNotFound", err)
+	}
+
+	resolver.records[rec.RecordName] = []string{"v=spf1 -all", " " + rec.RecordValue + " "}
+	verified, err := svc.VerifyDomain(ctx, domain.ID, user)
+	if err != nil {
+		t.Fatalf("VerifyDomain: %v", err)
+	}
+	if verified.VerifiedAt == nil {
+		t.Fatal("VerifiedAt should be set")
+	}
+	if hosts, _ := repo.ListVerifiedHostnames(ctx, nil); len(hosts) != 1 || hosts[0] != "go.example.com" {
+		t.Fatalf("ListVerifiedHostnames = %v", hosts)
+	}
+	for _, name := range resolver.queried {
+		if name != rec.RecordName {
+			t.Fatalf("queried %q, want %q", name, rec.RecordName)
+		}
+	}
+
+	// 已验证的域名不再查询 DNS
+	queried := len(resolver.queried)
+	delete(resolver.records, rec.RecordName)
+	if _, err := svc.VerifyDomain(ctx, domain.ID, user); err != nil {
+		t.Fatalf("VerifyDomain on verified domain: %v", err)
+	}
+	if len(resolver.queried) != queried {
+		t.Fatal("verified domain should not be looked up again")
+	}
+}
+
+func TestVerifyDomainResolverError(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, resolver := newTestDomainService()
+	user := uuid.New()
+
+	domain, err := svc.AddDomain(ctx, user, "go.example.com")
+	if err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	resolver.err = errors.New("no such host")
+	if _, err := svc.VerifyDomain(ctx, domain.ID, user); !errors.Is(err, ErrDomainVerifyFailed) {
+		t.Fatalf("VerifyDomain with DNS error = %v, want ErrDomainVerifyFailed", err)
+	}
+	if d, _ := repo.GetDomainByID(ctx, nil, domain.ID); d.VerifiedAt != nil {
+		t.Fatal("domain must stay unverified")
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/mail"
+	"go-short/internal/util"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailNotSet          = errors.New("未设置邮箱")
+	ErrEmailAlreadyVerified = errors.New("邮箱已验证")
+	ErrMailTooFrequent      = errors.New("邮件发送过于频繁，请稍后再试")
+	ErrInvalidEmailToken    = errors.New("链接无效或已使用")
+	ErrEmailTokenExpired    = errors.New("链接已过期")
+)
+
+const (
+	mailCooldown      = time.Minute      // 同一用户同类邮件的最短发送间隔
+	mailSendTimeout   = 30 * time.Second // 后台发送单封邮件的超时
+	verifyEmailPath   = "/verify-email"  // 前端页面路径，链接携带 token 参数
+	resetPasswordPath = "/reset-password"
+)
+
+// EmailConfig 邮件验证与找回密码配置
+type EmailConfig struct {
+	AppURL          string        // 邮件中链接指向的前端地址
+	DefaultLanguage string        // Accept-Language 无匹配时使用的语言
+	VerifyTTL       time.Duration // 验证邮箱链接有效期
+	ResetTTL        time.Duration // 重置密码链接有效期
+}
+
+// LoadEmailConfigFromEnv APP_URL（默认 BASE_URL）、MAIL_DEFAULT_LANG（zh / en，默认 zh）、
+// EMAIL_VERIFY_TTL（默认 48h）、PASSWORD_RESET_TTL（默认 1h）
+func LoadEmailConfigFromEnv() EmailConfig {
+	cfg := EmailConfig{
+		AppURL:          os.Getenv("APP_URL"),
+		DefaultLanguage: mail.Languages[0],
+		VerifyTTL:       48 * time.Hour,
+		ResetTTL:        time.Hour,
+	}
+	if cfg.AppURL == "" {
+		cfg.AppURL = os.Getenv("BASE_URL")
+	}
+	if cfg.AppURL == "" {
+		cfg.AppURL = "http://localhost:8080"
+	}
+	cfg.AppURL = strings.TrimRight(cfg.AppURL, "/")
+	if v := os.Getenv("MAIL_DEFAULT_LANG"); slices.Contains(mail.Languages, v) {
+		cfg.DefaultLanguage = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("EMAIL_VERIFY_TTL")); err == nil && d > 0 {
+		cfg.VerifyTTL = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL")); err == nil && d > 0 {
+		cfg.ResetTTL = d
+	}
+	return cfg
+}
+
+// Language 按 Accept-Language 选择邮件语言
+func (s *EmailService) Language(acceptLanguage string) string {
+	return mail.MatchLanguage(acceptLanguage, s.config.DefaultLanguage)
+}
+
+// SendVerification 向用户当前邮箱发送验证链接；链接绑定该邮箱，邮箱变更后失效
+func (s *EmailService) SendVerification(ctx context.Context, userID uuid.UUID, lang string) error {
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, userID)
+	if err != nil {
+		return fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	if user.Email == nil || *user.Email == "" {
+		return ErrEmailNotSet
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	ok, err := s.tokenStore.AcquireMailCooldown(ctx, "verify:"+user.ID.String(), mailCooldown)
+	if err != nil {
+		return fmt.Errorf("检查发送频率失败: %w", err)
+	}
+	if !ok {
+		return ErrMailTooFrequent
+	}
+	token, err := util.NewActionToken(util.ActionVerifyEmail, user.ID, strings.ToLower(*user.Email), s.config.VerifyTTL)
+	if err != nil {
+		return fmt.Errorf("生成验证链接失败: %w", err)
+	}
+	msg, err := mail.Render(mail.TemplateVerifyEmail, lang, *user.Email, mail.TemplateData{
+		Username:   user.Username,
+		Link:       s.link(verifyEmailPath, token),
+		ValidHours: validHours(s.config.VerifyTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("生成邮件失败: %w", err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// QueueVerification 注册或修改邮箱后在后台发送验证邮件，失败只记录日志
+func (s *EmailService) QueueVerification(userID uuid.UUID, lang string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		err := s.SendVerification(ctx, userID, lang)
+		if err != nil && !errors.Is(err, ErrEmailNotSet) && !errors.Is(err, ErrEmailAlreadyVerified) {
+			log.Printf("⚠️ Send verification email to user %s failed: %v", userID, err)
+		}
+	}()
+}
+
+// VerifyEmail 校验链接并标记邮箱已验证，每个链接只能使用一次
+func (s *EmailService) VerifyEmail(ctx context.Context, token string) error {
+	t, err := parseEmailToken(token, util.ActionVerifyEmail)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, t.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	if user.Email == nil || util.ActionBinding(strings.ToLower(*user.Email)) != t.Binding {
+		return ErrInvalidEmailToken // 发送后邮箱已变更
+	}
+	if err := s.useToken(ctx, t); err != nil {
+		return err
+	}
+	updated, err := s.userRepository.MarkEmailVerified(ctx, s.db, user.ID, *user.Email, time.Now())
+	if err != nil {
+		return fmt.Errorf("更新验证状态失败: %w", err)
+	}
+	if !updated {
+		return ErrInvalidEmailToken
+	}
+	return nil
+}
+
+// ForgotPassword 向已验证该邮箱的用户发送重置密码链接（未验证的邮箱可能被他人冒填，不发送）。
+// 无论邮箱是否存在都返回 nil 且在后台发送，避免通过响应内容或耗时枚举邮箱
+func (s *EmailService) ForgotPassword(ctx context.Context, email, lang string) error {
+	user, err := s.userRepository.GetUserByVerifiedEmail(ctx, s.db, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user.Status != "active" || user.Email == nil {
+		return nil
+	}
+	ok, err := s.tokenStore.AcquireMailCooldown(ctx, "reset:"+user.ID.String(), mailCooldown)
+	if err != nil {
+		return fmt.Errorf("检查发送频率失败: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	// 链接绑定当前密码哈希：密码一经修改（含通过该链接重置），其他未使用的链接随之失效
+	token, err := util.NewActionToken(util.ActionResetPassword, user.ID, user.PasswordHash, s.config.ResetTTL)
+	if err != nil {
+		return fmt.Errorf("生成重置链接失败: %w", err)
+	}
+	msg, err := mail.Render(mail.TemplateResetPassword, lang, *user.Email, mail.TemplateData{
+		Username:   user.Username,
+		Link:       s.link(resetPasswordPath, token),
+		ValidHours: validHours(s.config.ResetTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("生成邮件失败: %w", err)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("⚠️ Send password reset email to user %s failed: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword 校验链接并设置新密码，返回用户 ID（调用方据此吊销该用户的全部令牌）
+func (s *EmailService) ResetPassword(ctx context.Context, token, newPassword string) (uuid.UUID, error) {
+	t, err := parseEmailToken(token, util.ActionResetPassword)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, t.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrInvalidEmailToken
+		}
+		return uuid.Nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	if util.ActionBinding(user.PasswordHash) != t.Binding {
+		return uuid.Nil, ErrInvalidEmailToken // 密码已修改
+	}
+	if user.Status != "active" {
+		return uuid.Nil, ErrUserDisabled
+	}
+	if err := s.useToken(ctx, t); err != nil {
+		return uuid.Nil, err
+	}
+	hashedPassword, err := util.HashPassword(newPassword)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("密码加密失败: %w", err)
+	}
+	if err := s.userRepository.UpdatePasswordByUserID(ctx, s.db, user.ID, hashedPassword); err != nil {
+		return uuid.Nil, fmt.Errorf("更新密码失败: %w", err)
+	}
+	return user.ID, nil
+}
+
+// useToken 标记令牌已使用，已使用过的返回 ErrInvalidEmailToken
+func (s *EmailService) useToken(ctx context.Context, t *util.ActionToken) error {
+	ok, err := s.tokenStore.UseActionToken(ctx, t.Nonce, max(time.Until(t.ExpiresAt), time.Second))
+	if err != nil {
+		return fmt.Errorf("校验链接失败: %w", err)
+	}
+	if !ok {
+		return ErrInvalidEmailToken
+	}
+	return nil
+}
+
+func (s *EmailService) link(path, token string) string {
+	return s.config.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func parseEmailToken(token, purpose string) (*util.ActionToken, error) {
+	t, err := util.ParseActionToken(token, purpose)
+	if err != nil {
+		if errors.Is(err, util.ErrActionTokenExpired) {
+			return nil, ErrEmailTokenExpired
+		}
+		return nil, ErrInvalidEmailToken
+	}
+	return t, nil
+}
+
+// validHours 邮件中展示的有效小时数，不足 1 小时按 1 小时
+func validHours(ttl time.Duration) int {
+	return max(int(ttl.Hours()), 1)
+}
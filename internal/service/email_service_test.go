@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/mail"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo 只实现邮件流程用到的方法，其余方法调用时 panic
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uuid.UUID]*model.User
+}
+
+func (r *fakeUserRepo) GetUserByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *fakeUserRepo) GetUserByVerifiedEmail(ctx context.Context, tx *gorm.DB, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *model.User
+	for _, u := range r.users {
+		if u.Email != nil && strings.EqualFold(*u.Email, email) && u.EmailVerifiedAt != nil &&
+			(found == nil || u.CreatedAt.Before(found.CreatedAt)) {
+			found = u
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *found
+	return &cp, nil
+}
+
+func (r *fakeUserRepo) MarkEmailVerified(ctx context.Context, tx *gorm.DB, userID uuid.UUID, email string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || u.Email == nil || *u.Email != email || u.EmailVerifiedAt != nil {
+		return false, nil
+	}
+	u.EmailVerifiedAt = &at
+	return true, nil
+}
+
+func (r *fakeUserRepo) UpdatePasswordByUserID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	u.PasswordHash = password
+	return nil
+}
+
+func (r *fakeUserRepo) setEmail(userID uuid.UUID, email string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[userID].Email = &email
+	r.users[userID].EmailVerifiedAt = nil
+}
+
+func (r *fakeUserRepo) verifyEmail(userID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.users[userID].EmailVerifiedAt = &now
+}
+
+// fakeActionTokenStore 内存实现的 ActionTokenStore；cooldown 为 false 时不限制发信频率
+type fakeActionTokenStore struct {
+	mu       sync.Mutex
+	used     map[string]bool
+	cooldown bool
+	sent     map[string]bool
+}
+
+func (s *fakeActionTokenStore) UseActionToken(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used[nonce] {
+		return false, nil
+	}
+	s.used[nonce] = true
+	return true, nil
+}
+
+func (s *fakeActionTokenStore) AcquireMailCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cooldown && s.sent[key] {
+		return false, nil
+	}
+	s.sent[key] = true
+	return true, nil
+}
+
+const testPasswordHash = "$2a$10$original"
+
+func newTestEmailService(t *testing.T, cfg EmailConfig) (*EmailService, *fakeUserRepo, *fakeActionTokenStore, *mail.MemoryMailer, *model.User) {
+	t.Helper()
+	email := "Alice@Example.com"
+	user := &model.User{ID: uuid.New(), Username: "alice", Email: &email, PasswordHash: testPasswordHash, Status: "active"}
+	users := &fakeUserRepo{users: map[uuid.UUID]*model.User{user.ID: user}}
+	store := &fakeActionTokenStore{used: map[string]bool{}, sent: map[string]bool{}}
+	mailer := mail.NewMemoryMailer()
+	if cfg.AppURL == "" {
+		cfg.AppURL = "https://app.example.com"
+	}
+	if cfg.DefaultLanguage == "" {
+		cfg.DefaultLanguage = mail.Languages[0]
+	}
+	if cfg.VerifyTTL == 0 {
+		cfg.VerifyTTL = time.Hour
+	}
+	if cfg.ResetTTL == 0 {
+		cfg.ResetTTL = time.Hour
+	}
+	return NewEmailService(nil, users, store, mailer, cfg), users, store, mailer, user
+}
+
+var tokenParam = regexp.MustCompile(`[?&]token=([^\s"&<]+)`)
+
+// waitForToken 等待第 n 封邮件（找回密码在后台发送）并取出链接中的 token
+func waitForToken(t *testing.T, mailer *mail.MemoryMailer, n int) string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(mailer.Messages()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d messages, got %d", n, len(mailer.Messages()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	msg := mailer.Messages()[n-1]
+	m := tokenParam.FindStringSubmatch(msg.Text)
+	if m == nil {
+		t.Fatalf("no token link in message: %q", msg.Text)
+	}
+	token, err := url.QueryUnescape(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, mailer, user := newTestEmailService(t, EmailConfig{})
+
+	if err := svc.SendVerification(ctx, user.ID, "en"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	if msgs := mailer.Messages(); len(msgs) != 1 || msgs[0].To != *user.Email {
+		t.Fatalf("messages = %+v", msgs)
+	}
+	token := waitForToken(t, mailer, 1)
+
+	// 不同用途的令牌不能混用
+	if _, err := svc.ResetPassword(ctx, token, "new-password"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("ResetPassword with verify token = %v, want ErrInvalidEmailToken", err)
+	}
+	if err := svc.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if u, _ := users.GetUserByUserID(ctx, nil, user.ID); u.EmailVerifiedAt == nil {
+		t.Fatal("email should be verified")
+	}
+	// 只能使用一次
+	if err := svc.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("reusing token = %v, want ErrInvalidEmailToken", err)
+	}
+	if err := svc.SendVerification(ctx, user.ID, "en"); !errors.Is(err, ErrEmailAlreadyVerified) {
+		t.Fatalf("SendVerification after verify = %v, want ErrEmailAlreadyVerified", err)
+	}
+}
+
+func TestVerifyEmailBoundToAddress(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, mailer, user := newTestEmailService(t, EmailConfig{})
+
+	if err := svc.SendVerification(ctx, user.ID, "zh"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	token := waitForToken(t, mailer, 1)
+
+	// 发送后修改邮箱，旧链接不能验证新邮箱
+	users.setEmail(user.ID, "mallory@example.com")
+	if err := svc.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("VerifyEmail after email change = %v, want ErrInvalidEmailToken", err)
+	}
+	if u, _ := users.GetUserByUserID(ctx, nil, user.ID); u.EmailVerifiedAt != nil {
+		t.Fatal("changed email must stay unverified")
+	}
+}
+
+func TestVerifyEmailExpired(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, mailer, user := newTestEmailService(t, EmailConfig{VerifyTTL: -time.Minute})
+
+	if err := svc.SendVerification(ctx, user.ID, "en"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	if err := svc.VerifyEmail(ctx, waitForToken(t, mailer, 1)); !errors.Is(err, ErrEmailTokenExpired) {
+		t.Fatalf("VerifyEmail with expired token = %v, want ErrEmailTokenExpired", err)
+	}
+	if err := svc.VerifyEmail(ctx, "not-a-token"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("VerifyEmail with garbage = %v, want ErrInvalidEmailToken", err)
+	}
+}
+
+func TestSendVerificationCooldown(t *testing.T) {
+	ctx := context.Background()
+	svc, _, store, _, user := newTestEmailService(t, EmailConfig{})
+	store.cooldown = true
+
+	if err := svc.SendVerification(ctx, user.ID, "en"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	if err := svc.SendVerification(ctx, user.ID, "en"); !errors.Is(err, ErrMailTooFrequent) {
+		t.Fatalf("second SendVerification = %v, want ErrMailTooFrequent", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, mailer, user := newTestEmailService(t, EmailConfig{})
+	users.verifyEmail(user.ID)
+
+	// 未注册的邮箱同样返回 nil 且不发信
+	if err := svc.ForgotPassword(ctx, "nobody@example.com", "en"); err != nil {
+		t.Fatalf("ForgotPassword for unknown email: %v", err)
+	}
+	if err := svc.ForgotPassword(ctx, *user.Email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	first := waitForToken(t, mailer, 1)
+	if err := svc.ForgotPassword(ctx, *user.Email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	second := waitForToken(t, mailer, 2)
+	if n := len(mailer.Messages()); n != 2 {
+		t.Fatalf("unknown email should not be mailed, got %d messages", n)
+	}
+
+	// 不同用途的令牌不能混用
+	if err := svc.VerifyEmail(ctx, first); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("VerifyEmail with reset token = %v, want ErrInvalidEmailToken", err)
+	}
+	id, err := svc.ResetPassword(ctx, first, "new-password")
+	if err != nil || id != user.ID {
+		t.Fatalf("ResetPassword = %v, %v", id, err)
+	}
+	if u, _ := users.GetUserByUserID(ctx, nil, user.ID); u.PasswordHash == testPasswordHash {
+		t.Fatal("password should be updated")
+	}
+	// 只能使用一次
+	if _, err := svc.ResetPassword(ctx, first, "another-password"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("reusing token = %v, want ErrInvalidEmailToken", err)
+	}
+	// 链接绑定密码哈希：密码修改后，其他未使用的链接失效
+	if _, err := svc.ResetPassword(ctx, second, "another-password"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("ResetPassword after password change = %v, want ErrInvalidEmailToken", err)
+	}
+}
+
+func TestResetPasswordExpiredAndDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, mailer, user := newTestEmailService(t, EmailConfig{ResetTTL: -time.Minute})
+	users.verifyEmail(user.ID)
+
+	if err := svc.ForgotPassword(ctx, *user.Email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	if _, err := svc.ResetPassword(ctx, waitForToken(t, mailer, 1), "new-password"); !errors.Is(err, ErrEmailTokenExpired) {
+		t.Fatalf("ResetPassword with expired token = %v, want ErrEmailTokenExpired", err)
+	}
+
+	svc.config.ResetTTL = time.Hour
+	if err := svc.ForgotPassword(ctx, *user.Email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := waitForToken(t, mailer, 2)
+	users.mu.Lock()
+	users.users[user.ID].Status = "inactive"
+	users.mu.Unlock()
+	if _, err := svc.ResetPassword(ctx, token, "new-password"); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("ResetPassword for disabled user = %v, want ErrUserDisabled", err)
+	}
+	if u, _ := users.GetUserByUserID(ctx, nil, user.ID); u.PasswordHash != testPasswordHash {
+		t.Fatal("disabled user's password must not change")
+	}
+}
+
+func TestForgotPasswordRequiresVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, mailer, victim := newTestEmailService(t, EmailConfig{})
+	victim.CreatedAt = time.Now()
+
+	// 更早注册的账号冒填了同一邮箱但从未验证
+	email := "alice@example.com"
+	squatter := &model.User{ID: uuid.New(), Username: "mallory", Email: &email, PasswordHash: testPasswordHash,
+		Status: "active", CreatedAt: victim.CreatedAt.Add(-time.Hour)}
+	users.users[squatter.ID] = squatter
+
+	// 双方都未验证时不发送
+	if err := svc.ForgotPassword(ctx, *victim.Email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(mailer.Messages()); n != 0 {
+		t.Fatalf("unverified email must not receive reset links, got %d messages", n)
+	}
+
+	// 只有受害者验证过邮箱时，链接重置的是受害者的账号
+	users.verifyEmail(victim.ID)
+	if err := svc.ForgotPassword(ctx, email, "en"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	id, err := svc.ResetPassword(ctx, waitForToken(t, mailer, 1), "new-password")
+	if err != nil || id != victim.ID {
+		t.Fatalf("ResetPassword = %v, %v, want victim %v", id, err, victim.ID)
+	}
+	if u, _ := users.GetUserByUserID(ctx, nil, squatter.ID); u.PasswordHash != testPasswordHash {
+		t.Fatal("squatter's password must not change")
+	}
+}
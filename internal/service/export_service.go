@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize 导出时每批读取的行数
+const exportBatchSize = 5000
+
+// maxExportRange 单次导出允许的最大时间跨度
+const maxExportRange = 366 * 24 * time.Hour
+
+var ErrInvalidTimeRange = errors.New("时间范围无效")
+
+type ExportAccessLogsQuery struct {
+	LinkID *int64
+	From   time.Time
+	To     time.Time
+}
+
+// validate 校验时间范围
+func (q ExportAccessLogsQuery) validate() error {
+	if q.From.IsZero() || q.To.IsZero() || !q.From.Before(q.To) || q.To.Sub(q.From) > maxExportRange {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// streamAccessLogs 按 keyset 分页逐批读取访问日志并回调，内存中最多只保留一批
+func streamAccessLogs(ctx context.Context, db *gorm.DB, repo repository.AccessLogRepository, q ExportAccessLogsQuery, fn func([]model.AccessLog) error) error {
+	filter := repository.AccessLogFilter{LinkID: q.LinkID, From: q.From, To: q.To}
+	var cursor *repository.AccessLogCursor
+	for {
+		logs, err := repo.GetAccessLogsAfter(ctx, db, filter, cursor, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+		if len(logs) < exportBatchSize {
+			return nil
+		}
+		last := logs[len(logs)-1]
+		cursor = &repository.AccessLogCursor{VisitedAt: last.VisitedAt, ID: last.ID}
+	}
+}
+
+// CheckExportLinkAccessLogs 导出前校验链接归属与时间范围（在写响应头之前调用）
+func (s *LinkService) CheckExportLinkAccessLogs(ctx context.Context, userID uuid.UUID, isAdmin bool, q ExportAccessLogsQuery) error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+	if q.LinkID == nil {
+		return ErrLinkNotFound
+	}
+	_, err := s.GetOwnedLink(ctx, *q.LinkID, userID, isAdmin)
+	return err
+}
+
+// ExportLinkAccessLogs 流式导出单个链接的访问日志（调用方需先通过 CheckExportLinkAccessLogs）
+func (s *LinkService) ExportLinkAccessLogs(ctx context.Context, q ExportAccessLogsQuery, fn func([]model.AccessLog) error) error {
+	if q.LinkID == nil {
+		return ErrLinkNotFound
+	}
+	return streamAccessLogs(ctx, s.db, s.accessLogRepository, q, fn)
+}
+
+// CheckExportAccessLogs 校验全站导出参数
+func (s *AdminService) CheckExportAccessLogs(q ExportAccessLogsQuery) error {
+	return q.validate()
+}
+
+// ExportAccessLogs 流式导出全站访问日志（可按链接过滤）
+func (s *AdminService) ExportAccessLogs(ctx context.Context, q ExportAccessLogsQuery, fn func([]model.AccessLog) error) error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+	return streamAccessLogs(ctx, s.db, s.accessLogRepository, q, fn)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxFolderNameLength = 100
+
+var (
+	ErrFolderNotFound    = errors.New("文件夹不存在")
+	ErrFolderExists      = errors.New("文件夹已存在")
+	ErrInvalidFolderName = errors.New("文件夹名无效")
+)
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxFolderNameLength {
+		return "", ErrInvalidFolderName
+	}
+	return name, nil
+}
+
+// isDuplicateKeyError 判断是否违反唯一约束
+func isDuplicateKeyError(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+}
+
+// ListFolders 获取用户的全部文件夹
+func (s *FolderService) ListFolders(ctx context.Context, userID uuid.UUID) ([]model.Folder, error) {
+	return s.folderRepository.GetFoldersByUser(ctx, s.db, userID)
+}
+
+// CreateFolder 创建文件夹（同一用户下名称唯一）
+func (s *FolderService) CreateFolder(ctx context.Context, userID uuid.UUID, name string) (*model.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	folder := &model.Folder{UserID: userID, Name: name}
+	if err := s.folderRepository.Create(ctx, s.db, folder); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrFolderExists
+		}
+		return nil, fmt.Errorf("创建文件夹失败: %w", err)
+	}
+	return folder, nil
+}
+
+// getOwnedFolder 获取文件夹并校验归属
+func (s *FolderService) getOwnedFolder(ctx context.Context, folderID int64, userID uuid.UUID) (*model.Folder, error) {
+	folder, err := s.folderRepository.GetFolderByID(ctx, s.db, folderID)
+	if err != nil || folder.UserID != userID {
+		return nil, ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+// RenameFolder 重命名文件夹
+func (s *FolderService) RenameFolder(ctx context.Context, folderID int64, userID uuid.UUID, name string) (*model.Folder, error) {
+	folder, err := s.getOwnedFolder(ctx, folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	name, err = normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.folderRepository.Rename(ctx, s.db, folderID, name); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrFolderExists
+		}
+		return nil, fmt.Errorf("重命名文件夹失败: %w", err)
+	}
+	folder.Name = name
+	return folder, nil
+}
+
+// DeleteFolder 删除文件夹，其中的链接保留并移出文件夹
+func (s *FolderService) DeleteFolder(ctx context.Context, folderID int64, userID uuid.UUID) error {
+	if _, err := s.getOwnedFolder(ctx, folderID, userID); err != nil {
+		return err
+	}
+	if err := s.folderRepository.Delete(ctx, s.db, folderID); err != nil {
+		return fmt.Errorf("删除文件夹失败: %w", err)
+	}
+	return nil
+}
+
+// MoveLink 将链接移入文件夹（folderID 为 nil 表示移出），文件夹必须属于链接所有者
+func (s *FolderService) MoveLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool, folderID *int64) error {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleEditor); err != nil {
+		return err
+	}
+	if folderID != nil {
+		if _, err := s.getOwnedFolder(ctx, *folderID, link.UserID); err != nil {
+			return err
+		}
+	}
+	if err := s.linkRepository.SetLinkFolder(ctx, s.db, linkID, folderID); err != nil {
+		return fmt.Errorf("移动链接失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-short/internal/healthcheck"
+	"go-short/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// manualCheckCooldown 手动重新检查的最小间隔，期间直接返回上次结果
+const manualCheckCooldown = time.Minute
+
+// RunHealthChecks 检查一批到期的链接并保存结果，返回本轮检查的数量（等于 BatchSize 说明可能还有积压）
+func (s *HealthService) RunHealthChecks(ctx context.Context) (int, error) {
+	if _, err := s.linkHealthRepository.PruneOrphans(ctx, s.db); err != nil {
+		return 0, fmt.Errorf("清理健康检查结果失败: %w", err)
+	}
+	links, err := s.linkHealthRepository.ListLinksDueForCheck(ctx, s.db, time.Now().Add(-s.config.Interval), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("查询待检查链接失败: %w", err)
+	}
+	if len(links) == 0 {
+		return 0, nil
+	}
+
+	urls := make([]string, len(links))
+	for i, l := range links {
+		urls[i] = l.OriginalURL
+	}
+	results := s.prober.ProbeAll(ctx, urls, s.config.Concurrency)
+	for i, l := range links {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		if err := s.linkHealthRepository.SaveHealth(ctx, s.db, newLinkHealth(l.ID, l.OriginalURL, results[i])); err != nil {
+			return i, fmt.Errorf("保存健康检查结果失败: %w", err)
+		}
+	}
+	return len(links), nil
+}
+
+// CheckLink 立即重新检查链接（所有者、工作区 editor 以上或管理员），一分钟内重复调用返回上次结果
+func (s *HealthService) CheckLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.LinkHealth, error) {
+	link, err := s.ownedLink(ctx, linkID, userID, isAdmin, model.WorkspaceRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if last, err := s.linkHealthRepository.GetHealth(ctx, s.db, link.ID); err == nil &&
+		last.CheckedURL == link.OriginalURL && time.Since(last.CheckedAt) < manualCheckCooldown {
+		return last, nil
+	}
+
+	health := newLinkHealth(link.ID, link.OriginalURL, s.prober.Probe(ctx, link.OriginalURL))
+	if err := s.linkHealthRepository.SaveHealth(ctx, s.db, health); err != nil {
+		return nil, fmt.Errorf("保存健康检查结果失败: %w", err)
+	}
+	// 连续失败次数在库内累加，重新读取
+	if saved, err := s.linkHealthRepository.GetHealth(ctx, s.db, link.ID); err == nil {
+		return saved, nil
+	}
+	return health, nil
+}
+
+// GetLinkHealth 链接最近一次检查结果，未检查过（或目标地址已变更）返回 nil
+func (s *HealthService) GetLinkHealth(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.LinkHealth, error) {
+	link, err := s.ownedLink(ctx, linkID, userID, isAdmin, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	health, err := s.linkHealthRepository.GetHealth(ctx, s.db, link.ID)
+	if err != nil || health.CheckedURL != link.OriginalURL {
+		return nil, nil
+	}
+	return health, nil
+}
+
+// GetHealthByLinks 批量获取链接的检查结果（用于列表标记失效链接），目标地址已变更的结果不返回
+func (s *HealthService) GetHealthByLinks(ctx context.Context, links []model.Link) (map[int64]model.LinkHealth, error) {
+	linkIDs := make([]int64, len(links))
+	for i, l := range links {
+		linkIDs[i] = l.ID
+	}
+	health, err := s.linkHealthRepository.GetHealthByLinkIDs(ctx, s.db, linkIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		if h, ok := health[l.ID]; ok && h.CheckedURL != l.OriginalURL {
+			delete(health, l.ID)
+		}
+	}
+	return health, nil
+}
+
+// ownedLink 查询链接并校验权限，need 为工作区链接所需的最低角色
+func (s *HealthService) ownedLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool, need string) (*model.Link, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, need); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func newLinkHealth(linkID int64, checkedURL string, r healthcheck.Result) *model.LinkHealth {
+	h := &model.LinkHealth{
+		LinkID:     linkID,
+		CheckedURL: checkedURL,
+		StatusCode: r.StatusCode,
+		FinalURL:   r.FinalURL,
+		LatencyMs:  r.Latency.Milliseconds(),
+		Broken:     r.Broken(),
+		CheckedAt:  time.Now(),
+	}
+	if r.Broken() {
+		h.ConsecutiveFailures = 1
+	}
+	if r.Err != nil {
+		h.Error = truncate(r.Err.Error(), 255)
+	}
+	return h
+}
+
+// truncate 按字节截断，保证不截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
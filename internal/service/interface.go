@@ -1,7 +1,12 @@
 package service
 
 import (
+	"go-short/internal/healthcheck"
+	"go-short/internal/mail"
+	"go-short/internal/oidc"
 	"go-short/internal/repository"
+	"go-short/internal/urlcheck"
+	"image"
 
 	"gorm.io/gorm"
 )
@@ -11,16 +16,25 @@ type LinkService struct {
 	linkRepository      repository.LinkRepository
 	userRepository      repository.UserRepository
 	accessLogRepository repository.AccessLogRepository
+	domainRepository    repository.DomainRepository
+	revisionRepository  repository.LinkRevisionRepository
+	workspaceRepository repository.WorkspaceRepository
 	cacheInvalidator    repository.CacheInvalidator
+	urlChecker          urlcheck.URLChecker
 }
 
-func NewLinkService(db *gorm.DB, linkRepository repository.LinkRepository, userRepository repository.UserRepository, accessLogRepository repository.AccessLogRepository, cacheInvalidator repository.CacheInvalidator) *LinkService {
+// NewLinkService urlChecker 为 nil 时不检查目标地址（如只读的 Redirect 服务）
+func NewLinkService(db *gorm.DB, linkRepository repository.LinkRepository, userRepository repository.UserRepository, accessLogRepository repository.AccessLogRepository, domainRepository repository.DomainRepository, revisionRepository repository.LinkRevisionRepository, workspaceRepository repository.WorkspaceRepository, cacheInvalidator repository.CacheInvalidator, urlChecker urlcheck.URLChecker) *LinkService {
 	return &LinkService{
 		db:                  db,
 		linkRepository:      linkRepository,
 		userRepository:      userRepository,
 		accessLogRepository: accessLogRepository,
+		domainRepository:    domainRepository,
+		revisionRepository:  revisionRepository,
+		workspaceRepository: workspaceRepository,
 		cacheInvalidator:    cacheInvalidator,
+		urlChecker:          urlChecker,
 	}
 }
 
@@ -29,27 +43,372 @@ type AdminService struct {
 	linkRepository      repository.LinkRepository
 	userRepository      repository.UserRepository
 	accessLogRepository repository.AccessLogRepository
+	revisionRepository  repository.LinkRevisionRepository
+	roleRepository      repository.RoleRepository
 	cacheInvalidator    repository.CacheInvalidator
 }
 
-func NewAdminService(db *gorm.DB, linkRepository repository.LinkRepository, userRepository repository.UserRepository, accessLogRepository repository.AccessLogRepository, cacheInvalidator repository.CacheInvalidator) *AdminService {
+func NewAdminService(db *gorm.DB, linkRepository repository.LinkRepository, userRepository repository.UserRepository, accessLogRepository repository.AccessLogRepository, revisionRepository repository.LinkRevisionRepository, roleRepository repository.RoleRepository, cacheInvalidator repository.CacheInvalidator) *AdminService {
 	return &AdminService{
 		db:                  db,
 		linkRepository:      linkRepository,
 		userRepository:      userRepository,
 		accessLogRepository: accessLogRepository,
+		revisionRepository:  revisionRepository,
+		roleRepository:      roleRepository,
 		cacheInvalidator:    cacheInvalidator,
 	}
 }
 
+type RoleService struct {
+	db              *gorm.DB
+	roleRepository  repository.RoleRepository
+	userRepository  repository.UserRepository
+	permissionCache repository.PermissionCache
+}
+
+// NewRoleService permissionCache 为 nil 时每次都查数据库
+func NewRoleService(db *gorm.DB, roleRepository repository.RoleRepository, userRepository repository.UserRepository, permissionCache repository.PermissionCache) *RoleService {
+	return &RoleService{
+		db:              db,
+		roleRepository:  roleRepository,
+		userRepository:  userRepository,
+		permissionCache: permissionCache,
+	}
+}
+
 type UserService struct {
 	db             *gorm.DB
 	userRepository repository.UserRepository
+	sessions       sessionRevoker
 }
 
-func NewUserService(db *gorm.DB, userRepository repository.UserRepository) *UserService {
+// NewUserService 修改密码时终止其他会话，需要会话与令牌相关的存储及 access token 有效期
+func NewUserService(db *gorm.DB, userRepository repository.UserRepository, sessionRepository repository.SessionRepository, refreshTokenRepository repository.RefreshTokenRepository, revocationStore repository.TokenRevocationStore, tokenConfig TokenConfig) *UserService {
 	return &UserService{
 		db:             db,
 		userRepository: userRepository,
+		sessions:       newSessionRevoker(db, sessionRepository, refreshTokenRepository, revocationStore, tokenConfig.AccessTTL),
+	}
+}
+
+type TokenService struct {
+	db                     *gorm.DB
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	sessionRepository      repository.SessionRepository
+	revocationStore        repository.TokenRevocationStore
+	sessions               sessionRevoker
+	config                 TokenConfig
+}
+
+func NewTokenService(db *gorm.DB, userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, sessionRepository repository.SessionRepository, revocationStore repository.TokenRevocationStore, config TokenConfig) *TokenService {
+	return &TokenService{
+		db:                     db,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		sessionRepository:      sessionRepository,
+		revocationStore:        revocationStore,
+		sessions:               newSessionRevoker(db, sessionRepository, refreshTokenRepository, revocationStore, config.AccessTTL),
+		config:                 config,
+	}
+}
+
+type OIDCService struct {
+	db                 *gorm.DB
+	userRepository     repository.UserRepository
+	roleRepository     repository.RoleRepository
+	identityRepository repository.UserIdentityRepository
+	permissionCache    repository.PermissionCache
+	stateStore         repository.OIDCStateStore
+	providers          map[string]*oidc.Provider
+}
+
+// NewOIDCService providers 为空时不提供 SSO 登录；permissionCache 可为 nil
+func NewOIDCService(db *gorm.DB, userRepository repository.UserRepository, roleRepository repository.RoleRepository, identityRepository repository.UserIdentityRepository, permissionCache repository.PermissionCache, stateStore repository.OIDCStateStore, providers []*oidc.Provider) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Config().Name] = p
+	}
+	return &OIDCService{
+		db:                 db,
+		userRepository:     userRepository,
+		roleRepository:     roleRepository,
+		identityRepository: identityRepository,
+		permissionCache:    permissionCache,
+		stateStore:         stateStore,
+		providers:          byName,
+	}
+}
+
+type MFAService struct {
+	db             *gorm.DB
+	userRepository repository.UserRepository
+	mfaRepository  repository.MFARepository
+	challengeStore repository.MFAChallengeStore
+	config         MFAConfig
+}
+
+func NewMFAService(db *gorm.DB, userRepository repository.UserRepository, mfaRepository repository.MFARepository, challengeStore repository.MFAChallengeStore, config MFAConfig) *MFAService {
+	return &MFAService{
+		db:             db,
+		userRepository: userRepository,
+		mfaRepository:  mfaRepository,
+		challengeStore: challengeStore,
+		config:         config,
+	}
+}
+
+type LoginGuardService struct {
+	db                  *gorm.DB
+	store               repository.LoginAttemptStore
+	authEventRepository repository.AuthEventRepository
+	config              LoginGuardConfig
+}
+
+func NewLoginGuardService(db *gorm.DB, store repository.LoginAttemptStore, authEventRepository repository.AuthEventRepository, config LoginGuardConfig) *LoginGuardService {
+	return &LoginGuardService{
+		db:                  db,
+		store:               store,
+		authEventRepository: authEventRepository,
+		config:              config,
+	}
+}
+
+type APIKeyService struct {
+	db               *gorm.DB
+	userRepository   repository.UserRepository
+	apiKeyRepository repository.APIKeyRepository
+}
+
+func NewAPIKeyService(db *gorm.DB, userRepository repository.UserRepository, apiKeyRepository repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		db:               db,
+		userRepository:   userRepository,
+		apiKeyRepository: apiKeyRepository,
+	}
+}
+
+type WorkspaceService struct {
+	db                  *gorm.DB
+	workspaceRepository repository.WorkspaceRepository
+	userRepository      repository.UserRepository
+	linkRepository      repository.LinkRepository
+}
+
+func NewWorkspaceService(db *gorm.DB, workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository, linkRepository repository.LinkRepository) *WorkspaceService {
+	return &WorkspaceService{
+		db:                  db,
+		workspaceRepository: workspaceRepository,
+		userRepository:      userRepository,
+		linkRepository:      linkRepository,
+	}
+}
+
+type MaintenanceService struct {
+	db                     *gorm.DB
+	partitionRepository    repository.AccessLogPartitionRepository
+	linkStatsRepository    repository.LinkStatsRepository
+	linkRepository         repository.LinkRepository
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	sessionRepository      repository.SessionRepository
+	authEventRepository    repository.AuthEventRepository
+	config                 RetentionConfig
+}
+
+func NewMaintenanceService(db *gorm.DB, partitionRepository repository.AccessLogPartitionRepository, linkStatsRepository repository.LinkStatsRepository, linkRepository repository.LinkRepository, userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, sessionRepository repository.SessionRepository, authEventRepository repository.AuthEventRepository, config RetentionConfig) *MaintenanceService {
+	return &MaintenanceService{
+		db:                     db,
+		partitionRepository:    partitionRepository,
+		linkStatsRepository:    linkStatsRepository,
+		linkRepository:         linkRepository,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		sessionRepository:      sessionRepository,
+		authEventRepository:    authEventRepository,
+		config:                 config,
+	}
+}
+
+type ConversionService struct {
+	db                   *gorm.DB
+	linkRepository       repository.LinkRepository
+	workspaceRepository  repository.WorkspaceRepository
+	conversionRepository repository.ConversionRepository
+}
+
+func NewConversionService(db *gorm.DB, linkRepository repository.LinkRepository, workspaceRepository repository.WorkspaceRepository, conversionRepository repository.ConversionRepository) *ConversionService {
+	return &ConversionService{
+		db:                   db,
+		linkRepository:       linkRepository,
+		workspaceRepository:  workspaceRepository,
+		conversionRepository: conversionRepository,
+	}
+}
+
+type StatsService struct {
+	db                   *gorm.DB
+	linkRepository       repository.LinkRepository
+	linkStatsRepository  repository.LinkStatsRepository
+	conversionRepository repository.ConversionRepository
+	workspaceRepository  repository.WorkspaceRepository
+	dashboardCache       repository.DashboardCache
+}
+
+func NewStatsService(db *gorm.DB, linkRepository repository.LinkRepository, linkStatsRepository repository.LinkStatsRepository, conversionRepository repository.ConversionRepository, workspaceRepository repository.WorkspaceRepository, dashboardCache repository.DashboardCache) *StatsService {
+	return &StatsService{
+		db:                   db,
+		linkRepository:       linkRepository,
+		linkStatsRepository:  linkStatsRepository,
+		conversionRepository: conversionRepository,
+		workspaceRepository:  workspaceRepository,
+		dashboardCache:       dashboardCache,
+	}
+}
+
+type BulkService struct {
+	db                  *gorm.DB
+	linkRepository      repository.LinkRepository
+	userRepository      repository.UserRepository
+	domainRepository    repository.DomainRepository
+	revisionRepository  repository.LinkRevisionRepository
+	workspaceRepository repository.WorkspaceRepository
+	jobStore            repository.BulkJobStore
+	urlChecker          urlcheck.URLChecker
+}
+
+func NewBulkService(db *gorm.DB, linkRepository repository.LinkRepository, userRepository repository.UserRepository, domainRepository repository.DomainRepository, revisionRepository repository.LinkRevisionRepository, workspaceRepository repository.WorkspaceRepository, jobStore repository.BulkJobStore, urlChecker urlcheck.URLChecker) *BulkService {
+	return &BulkService{
+		db:                  db,
+		linkRepository:      linkRepository,
+		userRepository:      userRepository,
+		domainRepository:    domainRepository,
+		revisionRepository:  revisionRepository,
+		workspaceRepository: workspaceRepository,
+		jobStore:            jobStore,
+		urlChecker:          urlChecker,
+	}
+}
+
+type TagService struct {
+	db                  *gorm.DB
+	linkRepository      repository.LinkRepository
+	tagRepository       repository.TagRepository
+	workspaceRepository repository.WorkspaceRepository
+}
+
+func NewTagService(db *gorm.DB, linkRepository repository.LinkRepository, tagRepository repository.TagRepository, workspaceRepository repository.WorkspaceRepository) *TagService {
+	return &TagService{
+		db:                  db,
+		linkRepository:      linkRepository,
+		tagRepository:       tagRepository,
+		workspaceRepository: workspaceRepository,
+	}
+}
+
+type FolderService struct {
+	db                  *gorm.DB
+	linkRepository      repository.LinkRepository
+	folderRepository    repository.FolderRepository
+	workspaceRepository repository.WorkspaceRepository
+}
+
+func NewFolderService(db *gorm.DB, linkRepository repository.LinkRepository, folderRepository repository.FolderRepository, workspaceRepository repository.WorkspaceRepository) *FolderService {
+	return &FolderService{
+		db:                  db,
+		linkRepository:      linkRepository,
+		folderRepository:    folderRepository,
+		workspaceRepository: workspaceRepository,
+	}
+}
+
+type QRService struct {
+	db                  *gorm.DB
+	linkRepository      repository.LinkRepository
+	workspaceRepository repository.WorkspaceRepository
+	qrCache             repository.QRCodeCache
+	logo                image.Image
+}
+
+// NewQRService qrCache 可为 nil（不使用 Redis 缓存），logo 为 nil 时不支持嵌入 Logo
+// workspaceRepository 仅用于 GetLinkQRCode 的权限校验，只按短码生成时（Redirect 服务）可为 nil
+func NewQRService(db *gorm.DB, linkRepository repository.LinkRepository, workspaceRepository repository.WorkspaceRepository, qrCache repository.QRCodeCache, logo image.Image) *QRService {
+	return &QRService{
+		db:                  db,
+		linkRepository:      linkRepository,
+		workspaceRepository: workspaceRepository,
+		qrCache:             qrCache,
+		logo:                logo,
+	}
+}
+
+type DomainService struct {
+	db               *gorm.DB
+	linkRepository   repository.LinkRepository
+	domainRepository repository.DomainRepository
+	resolver         TXTResolver
+}
+
+// NewDomainService resolver 通常为 net.DefaultResolver
+func NewDomainService(db *gorm.DB, linkRepository repository.LinkRepository, domainRepository repository.DomainRepository, resolver TXTResolver) *DomainService {
+	return &DomainService{
+		db:               db,
+		linkRepository:   linkRepository,
+		domainRepository: domainRepository,
+		resolver:         resolver,
+	}
+}
+
+type URLRuleService struct {
+	db                *gorm.DB
+	urlRuleRepository repository.URLRuleRepository
+	ruleSet           *urlcheck.RuleSet
+}
+
+// NewURLRuleService ruleSet 为本实例 URL 检查流水线使用的黑白名单快照，规则变更后立即刷新
+func NewURLRuleService(db *gorm.DB, urlRuleRepository repository.URLRuleRepository, ruleSet *urlcheck.RuleSet) *URLRuleService {
+	return &URLRuleService{
+		db:                db,
+		urlRuleRepository: urlRuleRepository,
+		ruleSet:           ruleSet,
+	}
+}
+
+type HealthService struct {
+	db                   *gorm.DB
+	linkRepository       repository.LinkRepository
+	linkHealthRepository repository.LinkHealthRepository
+	workspaceRepository  repository.WorkspaceRepository
+	prober               *healthcheck.Prober
+	config               healthcheck.Config
+}
+
+func NewHealthService(db *gorm.DB, linkRepository repository.LinkRepository, linkHealthRepository repository.LinkHealthRepository, workspaceRepository repository.WorkspaceRepository, prober *healthcheck.Prober, config healthcheck.Config) *HealthService {
+	return &HealthService{
+		db:                   db,
+		linkRepository:       linkRepository,
+		linkHealthRepository: linkHealthRepository,
+		workspaceRepository:  workspaceRepository,
+		prober:               prober,
+		config:               config,
+	}
+}
+
+type EmailService struct {
+	db             *gorm.DB
+	userRepository repository.UserRepository
+	tokenStore     repository.ActionTokenStore
+	mailer         mail.Mailer
+	config         EmailConfig
+}
+
+func NewEmailService(db *gorm.DB, userRepository repository.UserRepository, tokenStore repository.ActionTokenStore, mailer mail.Mailer, config EmailConfig) *EmailService {
+	return &EmailService{
+		db:             db,
+		userRepository: userRepository,
+		tokenStore:     tokenStore,
+		mailer:         mailer,
+		config:         config,
 	}
 }
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = errors.New("版本不存在")
+
+// FieldChange 单个字段的变更，创建时 From 为 null
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// linkFields 参与记录和回滚的链接字段（JSON 键与 API 字段名一致）
+func linkFields(l *model.Link) map[string]any {
+	var expiresAt any
+	if l.ExpiresAt != nil {
+		expiresAt = l.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return map[string]any{
+		"original_url":      l.OriginalURL,
+		"alias":             l.Alias,
+		"short_code":        l.ShortCode,
+		"status":            l.Status,
+		"expires_at":        expiresAt,
+		"track_conversions": l.TrackConversions,
+	}
+}
+
+// diffLinks 比较变更前后的字段，before 为 nil 表示新建
+func diffLinks(before, after *model.Link) map[string]FieldChange {
+	to := linkFields(after)
+	changes := make(map[string]FieldChange)
+	if before == nil {
+		for k, v := range to {
+			changes[k] = FieldChange{To: v}
+		}
+		return changes
+	}
+	from := linkFields(before)
+	for k, v := range to {
+		if from[k] != v {
+			changes[k] = FieldChange{From: from[k], To: v}
+		}
+	}
+	return changes
+}
+
+// newRevision 生成变更记录；编辑类操作没有字段变化时返回 false（不记录）
+func newRevision(action string, actorID uuid.UUID, before, after *model.Link) (model.LinkRevision, bool) {
+	changes := diffLinks(before, after)
+	if len(changes) == 0 && (action == model.RevisionUpdate || action == model.RevisionRollback) {
+		return model.LinkRevision{}, false
+	}
+	raw, _ := json.Marshal(changes)
+	return model.LinkRevision{
+		LinkID:           after.ID,
+		Action:           action,
+		ActorID:          actorID,
+		Changes:          raw,
+		OriginalURL:      after.OriginalURL,
+		Alias:            after.Alias,
+		ShortCode:        after.ShortCode,
+		Status:           after.Status,
+		ExpiresAt:        after.ExpiresAt,
+		TrackConversions: after.TrackConversions,
+		CreatedAt:        time.Now(),
+	}, true
+}
+
+// recordRevision 在事务内写入一条变更记录
+func recordRevision(ctx context.Context, tx *gorm.DB, repo repository.LinkRevisionRepository, action string, actorID uuid.UUID, before, after *model.Link) error {
+	rev, ok := newRevision(action, actorID, before, after)
+	if !ok {
+		return nil
+	}
+	if err := repo.CreateRevisions(ctx, tx, []model.LinkRevision{rev}); err != nil {
+		return fmt.Errorf("记录变更失败: %w", err)
+	}
+	return nil
+}
+
+// recordLifecycleRevisions 批量记录同一类生命周期变更（创建、删除、恢复），字段差异为空
+func recordLifecycleRevisions(ctx context.Context, tx *gorm.DB, repo repository.LinkRevisionRepository, action string, actorID uuid.UUID, links []model.Link) error {
+	revisions := make([]model.LinkRevision, 0, len(links))
+	for i := range links {
+		var before *model.Link
+		if action != model.RevisionCreate {
+			before = &links[i] // 删除/恢复不改变字段，差异为空
+		}
+		rev, _ := newRevision(action, actorID, before, &links[i])
+		revisions = append(revisions, rev)
+	}
+	if err := repo.CreateRevisions(ctx, tx, revisions); err != nil {
+		return fmt.Errorf("记录变更失败: %w", err)
+	}
+	return nil
+}
+
+// ParseRevisionChanges 解析变更记录中的字段差异
+func ParseRevisionChanges(rev *model.LinkRevision) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	_ = json.Unmarshal(rev.Changes, &changes)
+	return changes
+}
+
+// ownedLinkIncludingTrash 查询链接（含回收站）并校验归属
+func (s *LinkService) ownedLinkIncludingTrash(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.Link, error) {
+	link, err := s.GetOwnedLink(ctx, linkID, userID, isAdmin)
+	if errors.Is(err, ErrLinkNotFound) {
+		return s.ownedTrashedLink(ctx, linkID, userID, isAdmin)
+	}
+	return link, err
+}
+
+// GetLinkHistory 链接的变更记录（按时间倒序，回收站中的链接同样可查）
+func (s *LinkService) GetLinkHistory(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool, page, size int) ([]model.LinkRevision, int64, error) {
+	if _, err := s.ownedLinkIncludingTrash(ctx, linkID, userID, isAdmin); err != nil {
+		return nil, 0, err
+	}
+	return s.revisionRepository.ListByLink(ctx, s.db, linkID, page, size)
+}
+
+type RollbackLinkCommand struct {
+	LinkID     int64
+	RevisionID int64
+	UserID     uuid.UUID
+	IsAdmin    bool
+	Version    *int64 // 可选的乐观锁版本号，nil 时以当前版本为准
+}
+
+// RollbackLink 将链接的目标地址、别名、启停、过期时间和转化追踪恢复为指定版本的快照。
+// 短码不回滚（旧短码可能已被其他链接占用）；目标地址同样需要通过安全检查。
+func (s *LinkService) RollbackLink(ctx context.Context, cmd RollbackLinkCommand) (*model.Link, error) {
+	link, err := s.GetOwnedLink(ctx, cmd.LinkID, cmd.UserID, cmd.IsAdmin)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.revisionRepository.GetRevision(ctx, s.db, cmd.LinkID, cmd.RevisionID)
+	if err != nil {
+		return nil, ErrRevisionNotFound
+	}
+
+	version := link.Version
+	if cmd.Version != nil {
+		version = *cmd.Version
+	}
+	update := UpdateLinkCommand{
+		LinkID:           cmd.LinkID,
+		UserID:           cmd.UserID,
+		IsAdmin:          cmd.IsAdmin,
+		Version:          version,
+		OriginalURL:      &rev.OriginalURL,
+		Alias:            &rev.Alias,
+		Status:           &rev.Status,
+		ExpiresAt:        rev.ExpiresAt,
+		ClearExpiresAt:   rev.ExpiresAt == nil,
+		TrackConversions: &rev.TrackConversions,
+	}
+	return s.updateLink(ctx, update, model.RevisionRollback, &rev.ID)
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"go-short/internal/model"
+	"go-short/internal/repository"
 	"go-short/internal/util"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 var (
@@ -18,31 +21,46 @@ var (
 	ErrLinkNotFound      = errors.New("链接不存在")
 	ErrForbidden         = errors.New("没有操作权限")
 	ErrUserNotFound      = errors.New("用户不存在")
+	ErrInvalidURL        = errors.New("URL不能为空")
+	ErrVersionConflict   = errors.New("链接已被修改，请刷新后重试")
 )
 
 type CreateLinkCommand struct {
-	OriginalURL string
-	Alias       *string
-	ShortCode   *string
-	Status      *bool
-	ExpiresAt   *time.Time
-	UserID      uuid.UUID
+	OriginalURL      string
+	Alias            *string
+	ShortCode        *string
+	Status           *bool
+	ExpiresAt        *time.Time
+	UserID           uuid.UUID
+	TrackConversions bool   // 开启转化追踪
+	Domain           string // 自定义域名（需已验证），空表示默认域名
+	WorkspaceID      *int64 // 所属工作区（需为 editor 以上），空表示个人链接
 }
 
 // CreateLink 创建短链接（包含所有业务逻辑）
 func (s *LinkService) CreateLink(ctx context.Context, cmd CreateLinkCommand) (*model.Link, error) {
-	// 1. 规范化 URL
-	normalizedURL := strings.TrimSpace(cmd.OriginalURL)
-	if normalizedURL == "" {
-		return nil, fmt.Errorf("URL不能为空")
+	// 1. 规范化 URL 并做安全检查
+	normalizedURL, err := normalizeURL(cmd.OriginalURL)
+	if err != nil {
+		return nil, err
 	}
-	if !strings.HasPrefix(normalizedURL, "http://") && !strings.HasPrefix(normalizedURL, "https://") {
-		normalizedURL = "https://" + normalizedURL
+	if err := checkDestination(ctx, s.urlChecker, normalizedURL); err != nil {
+		return nil, err
+	}
+
+	// 2. 校验工作区权限与域名归属，短码唯一性以 (域名, 短码) 为范围
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, cmd.UserID, cmd.WorkspaceID, model.WorkspaceRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := resolveLinkDomain(ctx, s.domainRepository, cmd.UserID, cmd.Domain)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. 检查自定义短码是否已被占用
+	// 3. 检查自定义短码是否已被占用
 	if cmd.ShortCode != nil && *cmd.ShortCode != "" {
-		exists, err := s.linkRepository.CheckShortCodeDuplicate(ctx, s.db, *cmd.ShortCode)
+		exists, err := s.linkRepository.CheckShortCodeDuplicate(ctx, s.db, domain, *cmd.ShortCode)
 		if err != nil {
 			return nil, fmt.Errorf("检查短码失败: %w", err)
 		}
@@ -51,59 +69,212 @@ func (s *LinkService) CreateLink(ctx context.Context, cmd CreateLinkCommand) (*m
 		}
 	}
 
-	// 3. 检查用户是否已经创建过相同的链接
-	existingLink, err := s.linkRepository.GetLinkByUserAndURL(ctx, s.db, cmd.UserID, normalizedURL)
+	// 4. 检查用户（或工作区）是否已经在该域名下创建过相同的链接
+	existingLink, err := s.linkRepository.GetLinkByScopeAndURL(ctx, s.db, scope, domain, normalizedURL)
 	if err == nil && existingLink != nil {
 		// 返回已存在的链接和特殊错误
 		return existingLink, ErrLinkAlreadyExists
 	}
 
-	// 4. 处理别名
+	// 5. 处理别名
 	alias := ""
 	if cmd.Alias != nil && *cmd.Alias != "" {
 		alias = *cmd.Alias
 	} else {
-		// 查询用户已有链接数，生成默认别名
-		count, err := s.linkRepository.GetNumOfLinksByUser(ctx, s.db, cmd.UserID)
+		// 查询用户（或工作区）已有链接数，生成默认别名
+		var count int64
+		if cmd.WorkspaceID != nil {
+			count, err = s.linkRepository.CountWorkspaceLinks(ctx, s.db, *cmd.WorkspaceID)
+		} else {
+			count, err = s.linkRepository.GetNumOfLinksByUser(ctx, s.db, cmd.UserID)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("获取链接数失败: %w", err)
 		}
 		alias = fmt.Sprintf("短链接%d", count+1)
 	}
 
-	// 5. 创建链接对象
+	// 6. 创建链接对象
 	link := &model.Link{
-		OriginalURL: normalizedURL,
-		UserID:      cmd.UserID,
-		CreatedAt:   time.Now(),
-		ExpiresAt:   cmd.ExpiresAt,
-		Status:      *cmd.Status,
-		Alias:       alias,
+		Domain:           domain,
+		OriginalURL:      normalizedURL,
+		UserID:           cmd.UserID,
+		WorkspaceID:      cmd.WorkspaceID,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        cmd.ExpiresAt,
+		Status:           true,
+		Alias:            alias,
+		TrackConversions: cmd.TrackConversions,
 	}
 
 	if cmd.Status != nil {
 		link.Status = *cmd.Status
 	}
 
-	// 6. 处理自定义短码
+	// 7. 处理自定义短码
 	if cmd.ShortCode != nil && *cmd.ShortCode != "" {
 		link.ShortCode = *cmd.ShortCode
 		link.IsCustom = true
 	}
 
-	// 7. 存入数据库
-	if err := s.linkRepository.Create(ctx, s.db, link); err != nil {
-		return nil, fmt.Errorf("创建链接失败: %w", err)
+	// 8. 存入数据库（同一事务内生成短码并维护用户链接计数）
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.linkRepository.Create(ctx, tx, link); err != nil {
+			return fmt.Errorf("创建链接失败: %w", err)
+		}
+
+		// 9. 如果短码未设置，使用 ID 生成短码并更新
+		if link.ShortCode == "" && link.ID > 0 {
+			link.ShortCode = util.Encode(link.ID)
+			if err := s.linkRepository.Update(ctx, tx, link); err != nil {
+				return fmt.Errorf("更新短码失败: %w", err)
+			}
+		}
+
+		if err := s.userRepository.IncrLinkCount(ctx, tx, cmd.UserID, 1); err != nil {
+			return fmt.Errorf("更新链接计数失败: %w", err)
+		}
+		return recordRevision(ctx, tx, s.revisionRepository, model.RevisionCreate, cmd.UserID, nil, link)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 8. 如果短码未设置，使用 ID 生成短码并更新
-	if link.ShortCode == "" && link.ID > 0 {
-		link.ShortCode = util.Encode(link.ID)
-		if err := s.linkRepository.Update(ctx, s.db, link); err != nil {
-			return nil, fmt.Errorf("更新短码失败: %w", err)
+	return link, nil
+}
+
+// normalizeURL 去除首尾空白，缺少协议时补全 https://
+// 已带其他协议（如 javascript:、ftp://）的保持原样，交由 URL 检查拒绝，避免被拼成 https://javascript:...
+func normalizeURL(rawURL string) (string, error) {
+	normalizedURL := strings.TrimSpace(rawURL)
+	if normalizedURL == "" {
+		return "", ErrInvalidURL
+	}
+	if !strings.HasPrefix(normalizedURL, "http://") && !strings.HasPrefix(normalizedURL, "https://") && !hasScheme(normalizedURL) {
+		normalizedURL = "https://" + normalizedURL
+	}
+	return normalizedURL, nil
+}
+
+// hasScheme 以 "协议:" 开头且冒号后不是端口号（example.com:8080 视为缺少协议）
+func hasScheme(rawURL string) bool {
+	i := strings.IndexByte(rawURL, ':')
+	if i <= 0 {
+		return false
+	}
+	for j, r := range rawURL[:i] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || j > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.')) {
+			return false
 		}
 	}
+	rest := rawURL[i+1:]
+	return rest == "" || rest[0] < '0' || rest[0] > '9'
+}
+
+type UpdateLinkCommand struct {
+	LinkID           int64
+	UserID           uuid.UUID
+	IsAdmin          bool
+	Version          int64 // 客户端持有的版本号（If-Match 或请求体 version）
+	OriginalURL      *string
+	Alias            *string
+	ShortCode        *string
+	Status           *bool
+	ExpiresAt        *time.Time
+	ClearExpiresAt   bool // 取消过期时间（永久有效）
+	TrackConversions *bool
+}
+
+// UpdateLink 编辑短链接（乐观锁：版本号不一致返回 ErrVersionConflict）
+func (s *LinkService) UpdateLink(ctx context.Context, cmd UpdateLinkCommand) (*model.Link, error) {
+	return s.updateLink(ctx, cmd, model.RevisionUpdate, nil)
+}
 
+// updateLink 编辑并在同一事务内记录变更，rollbackOf 为回滚的目标版本
+func (s *LinkService) updateLink(ctx context.Context, cmd UpdateLinkCommand, action string, rollbackOf *int64) (*model.Link, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, cmd.LinkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, cmd.UserID, cmd.IsAdmin, model.WorkspaceRoleEditor); err != nil {
+		return nil, err
+	}
+	if link.Version != cmd.Version {
+		return nil, ErrVersionConflict
+	}
+
+	before := *link
+	oldCode := link.ShortCode
+	if cmd.OriginalURL != nil {
+		normalizedURL, err := normalizeURL(*cmd.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkDestination(ctx, s.urlChecker, normalizedURL); err != nil {
+			return nil, err
+		}
+		link.OriginalURL = normalizedURL
+	}
+	if cmd.Alias != nil && strings.TrimSpace(*cmd.Alias) != "" {
+		link.Alias = strings.TrimSpace(*cmd.Alias)
+	}
+	if cmd.Status != nil {
+		link.Status = *cmd.Status
+	}
+	if cmd.ClearExpiresAt {
+		link.ExpiresAt = nil
+	} else if cmd.ExpiresAt != nil {
+		link.ExpiresAt = cmd.ExpiresAt
+	}
+	if cmd.TrackConversions != nil {
+		link.TrackConversions = *cmd.TrackConversions
+	}
+	if cmd.ShortCode != nil && *cmd.ShortCode != "" && *cmd.ShortCode != oldCode {
+		exists, err := s.linkRepository.CheckShortCodeDuplicate(ctx, s.db, link.Domain, *cmd.ShortCode)
+		if err != nil {
+			return nil, fmt.Errorf("检查短码失败: %w", err)
+		}
+		if exists {
+			return nil, ErrShortCodeExists
+		}
+		link.ShortCode = *cmd.ShortCode
+		link.IsCustom = true
+	}
+
+	link.Version = cmd.Version + 1
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updated, err := s.linkRepository.UpdateWithVersion(ctx, tx, link, cmd.Version)
+		if err != nil {
+			// 并发下两个请求抢同一个短码，由唯一索引兜底
+			if isDuplicateKeyError(err) {
+				return ErrShortCodeExists
+			}
+			return fmt.Errorf("更新链接失败: %w", err)
+		}
+		if !updated {
+			return ErrVersionConflict
+		}
+		rev, ok := newRevision(action, cmd.UserID, &before, link)
+		if !ok {
+			return nil
+		}
+		rev.RollbackOf = rollbackOf
+		if err := s.revisionRepository.CreateRevisions(ctx, tx, []model.LinkRevision{rev}); err != nil {
+			return fmt.Errorf("记录变更失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 旧短码和新短码都要通知：旧短码删缓存，新短码让 Redirect 加入布隆过滤器，避免被误判为不存在
+	if s.cacheInvalidator != nil {
+		_ = s.cacheInvalidator.InvalidateLink(ctx, model.LinkKey(link.Domain, oldCode))
+		if link.ShortCode != oldCode {
+			_ = s.cacheInvalidator.InvalidateLink(ctx, model.LinkKey(link.Domain, link.ShortCode))
+		}
+	}
 	return link, nil
 }
 
@@ -115,13 +286,13 @@ func (s *LinkService) Update(ctx context.Context, link *model.Link) error {
 	return s.linkRepository.Update(ctx, s.db, link)
 }
 
-func (s *LinkService) GetLinkByCode(ctx context.Context, code string) (*model.Link, error) {
-	return s.linkRepository.GetLinkByCode(ctx, s.db, code)
+func (s *LinkService) GetLinkByCode(ctx context.Context, domain, code string) (*model.Link, error) {
+	return s.linkRepository.GetLinkByCode(ctx, s.db, domain, code)
 }
 
-// GetLinkByCodeForRedirect 根据短码获取链接（用于重定向服务，包含过期时间检查）
-func (s *LinkService) GetLinkByCodeForRedirect(ctx context.Context, code string) (*model.Link, error) {
-	link, err := s.linkRepository.GetLinkByCode(ctx, s.db, code)
+// GetLinkByCodeForRedirect 根据域名和短码获取链接（用于重定向服务，包含过期时间检查）
+func (s *LinkService) GetLinkByCodeForRedirect(ctx context.Context, domain, code string) (*model.Link, error) {
+	link, err := s.linkRepository.GetLinkByCode(ctx, s.db, domain, code)
 	if err != nil {
 		return nil, ErrLinkNotFound
 	}
@@ -134,20 +305,146 @@ func (s *LinkService) GetLinkByCodeForRedirect(ctx context.Context, code string)
 	return link, nil
 }
 
-func (s *LinkService) GetLinkByUserAndURL(ctx context.Context, userID uuid.UUID, originalURL string) (*model.Link, error) {
-	return s.linkRepository.GetLinkByUserAndURL(ctx, s.db, userID, originalURL)
+func (s *LinkService) GetLinkByUserAndURL(ctx context.Context, userID uuid.UUID, domain, originalURL string) (*model.Link, error) {
+	return s.linkRepository.GetLinkByScopeAndURL(ctx, s.db, repository.LinkScope{UserID: userID}, domain, originalURL)
 }
 
-func (s *LinkService) GetLinksByUser(ctx context.Context, userID uuid.UUID, page, size int) ([]model.Link, int64, error) {
-	return s.linkRepository.GetLinksByUser(ctx, s.db, userID, page, size)
+type ListLinksQuery struct {
+	UserID      uuid.UUID
+	WorkspaceID *int64 // 当前工作区，空表示个人链接
+	Alias       string
+	Domain      *string // 非 nil 时按域名筛选，空串表示默认域名
+	Tag         string
+	FolderID    *int64
+	NoFolder    bool
+	Status      *bool
+	Expired     *bool
+	Query       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string // created | clicks | alias
+	Asc         bool
+	Page        int
+	Size        int
+	Cursor      *string // 非 nil 时使用游标分页（空串表示第一页），忽略 Page
+	Count       string  // exact | estimated | none，空值时 offset 模式为 exact、游标模式为 none
+}
+
+// LinkPage 链接列表分页结果
+type LinkPage struct {
+	Links      []model.Link
+	Total      int64 // -1 表示未统计
+	Estimated  bool
+	NextCursor string // 游标模式下还有下一页时非空
+}
+
+// 列表分页大小
+const (
+	DefaultLinkPageSize = 10
+	MaxLinkPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("分页游标无效")
+
+// ListLinks 按标签/文件夹/状态/过期/创建时间/关键字筛选用户（或工作区）链接，支持排序、offset 或游标分页
+func (s *LinkService) ListLinks(ctx context.Context, q ListLinksQuery) (*LinkPage, error) {
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, q.UserID, q.WorkspaceID, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Size < 1 {
+		q.Size = DefaultLinkPageSize
+	}
+	if q.Size > MaxLinkPageSize {
+		q.Size = MaxLinkPageSize
+	}
+	if q.Sort == "" {
+		q.Sort = repository.LinkSortCreated
+	}
+	filter := repository.LinkListFilter{
+		Scope:       scope,
+		Alias:       q.Alias,
+		Domain:      q.Domain,
+		Tag:         q.Tag,
+		FolderID:    q.FolderID,
+		NoFolder:    q.NoFolder,
+		Status:      q.Status,
+		Expired:     q.Expired,
+		Query:       strings.TrimSpace(q.Query),
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+		Sort:        q.Sort,
+		Asc:         q.Asc,
+		Page:        q.Page,
+		Size:        q.Size,
+		Count:       resolveCountMode(q.Count, q.Cursor != nil),
+	}
+	if q.Cursor != nil {
+		filter.Page = 1
+		filter.Size = q.Size + 1 // 多取一条判断是否还有下一页
+		if *q.Cursor != "" {
+			after, err := decodeLinkCursor(*q.Cursor, q.Sort, q.Asc)
+			if err != nil {
+				return nil, err
+			}
+			filter.After = after
+		}
+	}
+
+	links, total, err := s.linkRepository.ListLinks(ctx, s.db, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &LinkPage{Links: links, Total: total, Estimated: filter.Count == repository.CountEstimated}
+	if q.Cursor != nil && len(links) > q.Size {
+		page.Links = links[:q.Size]
+		last := page.Links[q.Size-1]
+		page.NextCursor = util.EncodeCursor(util.Cursor{
+			Sort:      q.Sort,
+			Asc:       q.Asc,
+			CreatedAt: last.CreatedAt,
+			Clicks:    last.VisitCount,
+			Alias:     last.Alias,
+			ID:        strconv.FormatInt(last.ID, 10),
+		})
+	}
+	return page, nil
+}
+
+// resolveCountMode 总数统计方式默认值：offset 模式保持原有的精确总数，游标模式默认不统计
+func resolveCountMode(mode string, cursorMode bool) string {
+	switch mode {
+	case repository.CountExact, repository.CountEstimated, repository.CountNone:
+		return mode
+	}
+	if cursorMode {
+		return repository.CountNone
+	}
+	return repository.CountExact
+}
+
+// decodeLinkCursor 解析链接列表游标，排序方式必须与本次请求一致
+func decodeLinkCursor(s, sort string, asc bool) (*repository.LinkCursor, error) {
+	c, err := util.DecodeCursor(s)
+	if err != nil || c.Sort != sort || c.Asc != asc {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(c.ID, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.LinkCursor{CreatedAt: c.CreatedAt, VisitCount: c.Clicks, Alias: c.Alias, ID: id}, nil
 }
 
 func (s *LinkService) GetNumOfLinksByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
 	return s.linkRepository.GetNumOfLinksByUser(ctx, s.db, userID)
 }
 
-func (s *LinkService) CheckShortCodeDuplicate(ctx context.Context, short_code string) (bool, error) {
-	return s.linkRepository.CheckShortCodeDuplicate(ctx, s.db, short_code)
+func (s *LinkService) CheckShortCodeDuplicate(ctx context.Context, domain, short_code string) (bool, error) {
+	return s.linkRepository.CheckShortCodeDuplicate(ctx, s.db, domain, short_code)
 }
 
 func (s *LinkService) ActiveLink(ctx context.Context, linkID int64) error {
@@ -158,28 +455,108 @@ func (s *LinkService) UnactiveLink(ctx context.Context, linkID int64) error {
 	return s.linkRepository.UnactiveLink(ctx, s.db, linkID)
 }
 
-func (s *LinkService) GetLinksByUserAlias(ctx context.Context, userID uuid.UUID, alias string, page, size int) ([]model.Link, int64, error) {
-	return s.linkRepository.GetLinksByUserAlias(ctx, s.db, userID, alias, page, size)
+// GetOwnedLink 根据ID获取链接并校验访问权限（个人链接需为创建者，工作区链接需为成员，管理员可访问任意链接）
+func (s *LinkService) GetOwnedLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.Link, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("获取链接失败: %w", err)
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	return link, nil
 }
 
-// DeleteLink 根据ID删除短链接（需要验证用户权限）
+// DeleteLink 根据ID删除短链接（需要验证用户权限）：移入回收站，保留期内可恢复，短码仍被占用
 func (s *LinkService) DeleteLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) error {
 	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
 	if err != nil {
 		return ErrLinkNotFound
 	}
 
-	if link.UserID != userID && !isAdmin {
-		return ErrForbidden
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleEditor); err != nil {
+		return err
+	}
+
+	linkKey := model.LinkKey(link.Domain, link.ShortCode)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.linkRepository.DeleteLinkByID(ctx, tx, linkID); err != nil {
+			return fmt.Errorf("删除链接失败: %w", err)
+		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, link.UserID, -1); err != nil {
+			return fmt.Errorf("更新链接计数失败: %w", err)
+		}
+		return recordRevision(ctx, tx, s.revisionRepository, model.RevisionDelete, userID, link, link)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.cacheInvalidator != nil {
+		_ = s.cacheInvalidator.InvalidateLink(ctx, linkKey)
+	}
+	return nil
+}
+
+// ListTrash 用户（或工作区）回收站中的链接
+func (s *LinkService) ListTrash(ctx context.Context, userID uuid.UUID, workspaceID *int64, page, size int) ([]model.Link, int64, error) {
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, userID, workspaceID, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.linkRepository.ListTrashedLinks(ctx, s.db, scope, page, size)
+}
+
+// ownedTrashedLink 查询回收站中的链接并校验权限（恢复和彻底删除需要 editor 以上）
+func (s *LinkService) ownedTrashedLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.Link, error) {
+	link, err := s.linkRepository.GetTrashedLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
 	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleEditor); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
 
-	shortCode := link.ShortCode
-	if err := s.linkRepository.DeleteLinkByID(ctx, s.db, linkID); err != nil {
-		return fmt.Errorf("删除链接失败: %w", err)
+// RestoreLink 从回收站恢复链接
+func (s *LinkService) RestoreLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*model.Link, error) {
+	link, err := s.ownedTrashedLink(ctx, linkID, userID, isAdmin)
+	if err != nil {
+		return nil, err
 	}
 
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.linkRepository.RestoreLinkByID(ctx, tx, linkID); err != nil {
+			return fmt.Errorf("恢复链接失败: %w", err)
+		}
+		if err := s.userRepository.IncrLinkCount(ctx, tx, link.UserID, 1); err != nil {
+			return fmt.Errorf("更新链接计数失败: %w", err)
+		}
+		return recordRevision(ctx, tx, s.revisionRepository, model.RevisionRestore, userID, link, link)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 清除删除期间跳转服务写入的"不存在"缓存
 	if s.cacheInvalidator != nil {
-		_ = s.cacheInvalidator.InvalidateLink(ctx, shortCode)
+		_ = s.cacheInvalidator.InvalidateLink(ctx, model.LinkKey(link.Domain, link.ShortCode))
+	}
+	link.DeletedAt = gorm.DeletedAt{}
+	return link, nil
+}
+
+// PurgeLink 彻底删除回收站中的链接，短码随之释放
+func (s *LinkService) PurgeLink(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) error {
+	if _, err := s.ownedTrashedLink(ctx, linkID, userID, isAdmin); err != nil {
+		return err
+	}
+	if err := s.linkRepository.PurgeLinkByID(ctx, s.db, linkID); err != nil {
+		return fmt.Errorf("彻底删除链接失败: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLoginLocked         = errors.New("登录失败次数过多，已被临时锁定")
+	ErrLoginThrottled      = errors.New("登录尝试过于频繁，请稍后再试")
+	ErrLockoutNotFound     = errors.New("锁定不存在")
+	ErrInvalidLockoutScope = errors.New("锁定类型无效")
+)
+
+// 锁定对象
+const (
+	LockoutScopeUser = "user"
+	LockoutScopeIP   = "ip"
+)
+
+const (
+	loginDelayAfter    = 3                // 窗口内失败次数达到后开始渐进延迟
+	loginBaseDelay     = time.Second      // 首次延迟，之后每次失败翻倍
+	loginMaxDelay      = 30 * time.Second // 延迟上限
+	loginLockoutMemory = 24 * time.Hour   // 统计累计锁定次数的时长，期间再次锁定时长翻倍
+)
+
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	Window          time.Duration // 失败次数滑动窗口
+	UserMaxFailures int64         // 窗口内同一用户名失败次数达到后锁定该用户名
+	IPMaxFailures   int64         // 窗口内同一 IP 失败次数达到后锁定该 IP
+	Lockout         time.Duration // 首次锁定时长
+	MaxLockout      time.Duration // 递增后的锁定时长上限
+}
+
+// LoadLoginGuardConfigFromEnv LOGIN_FAILURE_WINDOW（默认 15m）、LOGIN_MAX_FAILURES（默认 5）、
+// LOGIN_IP_MAX_FAILURES（默认 20）、LOGIN_LOCKOUT（默认 15m）、LOGIN_MAX_LOCKOUT（默认 24h）
+func LoadLoginGuardConfigFromEnv() LoginGuardConfig {
+	cfg := LoginGuardConfig{
+		Window:          15 * time.Minute,
+		UserMaxFailures: 5,
+		IPMaxFailures:   20,
+		Lockout:         15 * time.Minute,
+		MaxLockout:      24 * time.Hour,
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_FAILURE_WINDOW")); err == nil && d > 0 {
+		cfg.Window = d
+	}
+	if n, err := strconv.ParseInt(os.Getenv("LOGIN_MAX_FAILURES"), 10, 64); err == nil && n > 0 {
+		cfg.UserMaxFailures = n
+	}
+	if n, err := strconv.ParseInt(os.Getenv("LOGIN_IP_MAX_FAILURES"), 10, 64); err == nil && n > 0 {
+		cfg.IPMaxFailures = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT")); err == nil && d > 0 {
+		cfg.Lockout = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_MAX_LOCKOUT")); err == nil && d >= cfg.Lockout {
+		cfg.MaxLockout = d
+	}
+	return cfg
+}
+
+// LoginAttempt 一次失败的登录尝试
+type LoginAttempt struct {
+	Username      string
+	UserID        *uuid.UUID // 用户存在时
+	IP            string
+	UserAgent     string
+	Reason        string // invalid_password / unknown_user / user_disabled / invalid_mfa_code
+	ReservationID string // Check 返回的预占 id，失败时直接计入；为空时（如两步验证失败）新增一条记录
+}
+
+// Check 登录前调用：用户名或 IP 已锁定返回 ErrLoginLocked，处于渐进延迟中返回 ErrLoginThrottled，并返回需等待的时间。
+// 通过时为用户名与 IP 原子地预占一次尝试并返回预占 id：进行中的尝试占用失败名额，并发请求无法越过上限。
+// 调用方须以该 id 调用 RecordFailure 或 Release 结束本次尝试
+func (s *LoginGuardService) Check(ctx context.Context, username, ip string) (string, time.Duration, error) {
+	id := uuid.NewString()
+	now := time.Now()
+	var reserved []loginTarget
+	for _, t := range s.targets(username, ip) {
+		locked, delayed, ok, err := s.store.ReserveLoginAttempt(ctx, t.scope, t.key, id, now, s.config.Window, t.limit)
+		if err != nil {
+			s.release(ctx, reserved, id)
+			return "", 0, fmt.Errorf("查询登录限制失败: %w", err)
+		}
+		if ok {
+			reserved = append(reserved, t)
+			continue
+		}
+		s.release(ctx, reserved, id)
+		switch {
+		case locked > 0:
+			return "", locked, ErrLoginLocked
+		case delayed > 0:
+			return "", delayed, ErrLoginThrottled
+		default:
+			// 名额已被进行中的尝试占满，稍后重试
+			return "", loginBaseDelay, ErrLoginThrottled
+		}
+	}
+	return id, 0, nil
+}
+
+// CheckBlocked 只检查不预占：用户名或 IP 已锁定返回 ErrLoginLocked，处于渐进延迟中返回 ErrLoginThrottled。
+// 用于两步验证等后续步骤，失败仍由 RecordFailure 计入
+func (s *LoginGuardService) CheckBlocked(ctx context.Context, username, ip string) (time.Duration, error) {
+	var wait time.Duration
+	throttled := false
+	for _, t := range s.targets(username, ip) {
+		locked, delayed, err := s.store.GetLoginBlock(ctx, t.scope, t.key)
+		if err != nil {
+			return 0, fmt.Errorf("查询登录限制失败: %w", err)
+		}
+		if locked > 0 {
+			return locked, ErrLoginLocked
+		}
+		if delayed > 0 {
+			throttled = true
+			wait = max(wait, delayed)
+		}
+	}
+	if throttled {
+		return wait, ErrLoginThrottled
+	}
+	return 0, nil
+}
+
+// Release 撤销 Check 的预占：密码校验通过（或因内部错误中止）的尝试不计入失败
+func (s *LoginGuardService) Release(ctx context.Context, username, ip, reservationID string) {
+	if reservationID == "" {
+		return
+	}
+	s.release(ctx, s.targets(username, ip), reservationID)
+}
+
+func (s *LoginGuardService) release(ctx context.Context, targets []loginTarget, id string) {
+	for _, t := range targets {
+		if err := s.store.ReleaseLoginAttempt(ctx, t.scope, t.key, id); err != nil {
+			log.Printf("⚠️ Release login attempt for %s %s failed: %v", t.scope, t.key, err)
+		}
+	}
+}
+
+// RecordFailure 记录失败并写入审计事件；用户名与 IP 分别按滑动窗口计数，连续失败 3 次起每次延迟翻倍，达到上限后锁定
+func (s *LoginGuardService) RecordFailure(ctx context.Context, attempt LoginAttempt) {
+	s.audit(ctx, &model.AuthEvent{
+		Event:     model.AuthEventLoginFailed,
+		Username:  attempt.Username,
+		UserID:    attempt.UserID,
+		IP:        attempt.IP,
+		UserAgent: truncate(attempt.UserAgent, 255),
+		Reason:    attempt.Reason,
+	})
+
+	id := attempt.ReservationID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	now := time.Now()
+	for _, t := range s.targets(attempt.Username, attempt.IP) {
+		failures, err := s.store.RecordLoginFailure(ctx, t.scope, t.key, id, now, s.config.Window)
+		if err != nil {
+			log.Printf("⚠️ Record login failure for %s %s failed: %v", t.scope, t.key, err)
+			continue
+		}
+		switch {
+		case failures >= t.limit:
+			s.lock(ctx, t, failures, attempt)
+		case failures >= loginDelayAfter:
+			delay := min(loginBaseDelay<<min(failures-loginDelayAfter, 5), loginMaxDelay)
+			if err := s.store.SetLoginDelay(ctx, t.scope, t.key, delay); err != nil {
+				log.Printf("⚠️ Set login delay for %s %s failed: %v", t.scope, t.key, err)
+			}
+		}
+	}
+}
+
+// RecordSuccess 登录成功（已签发令牌）后清除该用户名的失败计数；IP 的计数保留，避免用一个有效账号重置 IP 限制
+func (s *LoginGuardService) RecordSuccess(ctx context.Context, username string) {
+	if err := s.store.ClearLoginFailures(ctx, LockoutScopeUser, username); err != nil {
+		log.Printf("⚠️ Clear login failures for %s failed: %v", username, err)
+	}
+}
+
+// ListLockouts 当前被锁定的用户名与 IP
+func (s *LoginGuardService) ListLockouts(ctx context.Context) ([]repository.LoginLockout, error) {
+	lockouts, err := s.store.ListLoginLockouts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询锁定失败: %w", err)
+	}
+	return lockouts, nil
+}
+
+// ClearLockout 管理员解除锁定并记录审计事件
+func (s *LoginGuardService) ClearLockout(ctx context.Context, scope, key string, actorID uuid.UUID) error {
+	if scope != LockoutScopeUser && scope != LockoutScopeIP {
+		return ErrInvalidLockoutScope
+	}
+	unlocked, err := s.store.UnlockLogin(ctx, scope, key)
+	if err != nil {
+		return fmt.Errorf("解除锁定失败: %w", err)
+	}
+	if !unlocked {
+		return ErrLockoutNotFound
+	}
+	event := &model.AuthEvent{Event: model.AuthEventLockoutCleared, Reason: scope, ActorID: &actorID}
+	if scope == LockoutScopeUser {
+		event.Username = key
+	} else {
+		event.IP = key
+	}
+	s.audit(ctx, event)
+	return nil
+}
+
+// ListEvents 认证审计事件
+func (s *LoginGuardService) ListEvents(ctx context.Context, filter repository.AuthEventFilter, page, size int) ([]model.AuthEvent, int64, error) {
+	events, total, err := s.authEventRepository.List(ctx, s.db, filter, page, size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询审计事件失败: %w", err)
+	}
+	return events, total, nil
+}
+
+type loginTarget struct {
+	scope string
+	key   string
+	limit int64
+}
+
+func (s *LoginGuardService) targets(username, ip string) []loginTarget {
+	targets := []loginTarget{{scope: LockoutScopeUser, key: username, limit: s.config.UserMaxFailures}}
+	if ip != "" {
+		targets = append(targets, loginTarget{scope: LockoutScopeIP, key: ip, limit: s.config.IPMaxFailures})
+	}
+	return targets
+}
+
+// lock 锁定时长按 24 小时内的累计锁定次数翻倍，不超过 MaxLockout
+func (s *LoginGuardService) lock(ctx context.Context, t loginTarget, failures int64, attempt LoginAttempt) {
+	count, err := s.store.IncrLockoutCount(ctx, t.scope, t.key, loginLockoutMemory)
+	if err != nil {
+		log.Printf("⚠️ Count login lockouts for %s %s failed: %v", t.scope, t.key, err)
+		count = 1
+	}
+	duration := s.config.Lockout
+	for i := int64(1); i < count && duration < s.config.MaxLockout; i++ {
+		duration *= 2
+	}
+	duration = min(duration, s.config.MaxLockout)
+	if err := s.store.LockLogin(ctx, t.scope, t.key, duration); err != nil {
+		log.Printf("⚠️ Lock login for %s %s failed: %v", t.scope, t.key, err)
+		return
+	}
+	log.Printf("🔒 Login locked for %s %s (%d failures, %s)", t.scope, t.key, failures, duration)
+
+	event := &model.AuthEvent{
+		Event:     model.AuthEventLockout,
+		IP:        attempt.IP,
+		UserAgent: truncate(attempt.UserAgent, 255),
+		Reason:    fmt.Sprintf("%s: %d failures, locked %s", t.scope, failures, duration),
+	}
+	if t.scope == LockoutScopeUser {
+		event.Username = attempt.Username
+		event.UserID = attempt.UserID
+	}
+	s.audit(ctx, event)
+}
+
+// audit 审计事件写入失败不影响登录流程
+func (s *LoginGuardService) audit(ctx context.Context, event *model.AuthEvent) {
+	if err := s.authEventRepository.Create(ctx, s.db, event); err != nil {
+		log.Printf("⚠️ Write auth event %s failed: %v", event.Event, err)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeLoginStore 内存实现的 LoginAttemptStore，以互斥锁模拟 Redis 脚本的原子性
+type fakeLoginStore struct {
+	mu     sync.Mutex
+	fails  map[string]map[string]time.Time
+	delays map[string]time.Time
+	locks  map[string]time.Time
+	counts map[string]int64
+}
+
+func newFakeLoginStore() *fakeLoginStore {
+	return &fakeLoginStore{
+		fails:  map[string]map[string]time.Time{},
+		delays: map[string]time.Time{},
+		locks:  map[string]time.Time{},
+		counts: map[string]int64{},
+	}
+}
+
+func (s *fakeLoginStore) trim(k string, at time.Time, window time.Duration) map[string]time.Time {
+	set := s.fails[k]
+	if set == nil {
+		set = map[string]time.Time{}
+		s.fails[k] = set
+	}
+	for id, t := range set {
+		if !t.After(at.Add(-window)) {
+			delete(set, id)
+		}
+	}
+	return set
+}
+
+func (s *fakeLoginStore) ReserveLoginAttempt(ctx context.Context, scope, key, id string, at time.Time, window time.Duration, limit int64) (time.Duration, time.Duration, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := scope + ":" + key
+	if until, ok := s.locks[k]; ok && time.Until(until) > 0 {
+		return time.Until(until), 0, false, nil
+	}
+	if until, ok := s.delays[k]; ok && time.Until(until) > 0 {
+		return 0, time.Until(until), false, nil
+	}
+	set := s.trim(k, at, window)
+	if int64(len(set)) >= limit {
+		return 0, 0, false, nil
+	}
+	set[id] = at
+	return 0, 0, true, nil
+}
+
+func (s *fakeLoginStore) ReleaseLoginAttempt(ctx context.Context, scope, key, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fails[scope+":"+key], id)
+	return nil
+}
+
+func (s *fakeLoginStore) RecordLoginFailure(ctx context.Context, scope, key, id string, at time.Time, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set := s.trim(scope+":"+key, at, window)
+	set[id] = at
+	return int64(len(set)), nil
+}
+
+func (s *fakeLoginStore) GetLoginBlock(ctx context.Context, scope, key string) (time.Duration, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := scope + ":" + key
+	return max(time.Until(s.locks[k]), 0), max(time.Until(s.delays[k]), 0), nil
+}
+
+func (s *fakeLoginStore) SetLoginDelay(ctx context.Context, scope, key string, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays[scope+":"+key] = time.Now().Add(delay)
+	return nil
+}
+
+func (s *fakeLoginStore) IncrLockoutCount(ctx context.Context, scope, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[scope+":"+key]++
+	return s.counts[scope+":"+key], nil
+}
+
+func (s *fakeLoginStore) LockLogin(ctx context.Context, scope, key string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := scope + ":" + key
+	s.locks[k] = time.Now().Add(duration)
+	delete(s.fails, k)
+	delete(s.delays, k)
+	return nil
+}
+
+func (s *fakeLoginStore) ClearLoginFailures(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fails, scope+":"+key)
+	delete(s.delays, scope+":"+key)
+	return nil
+}
+
+func (s *fakeLoginStore) ListLoginLockouts(ctx context.Context) ([]repository.LoginLockout, error) {
+	return nil, nil
+}
+
+func (s *fakeLoginStore) UnlockLogin(ctx context.Context, scope, key string) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeLoginStore) pending(scope, key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.fails[scope+":"+key])
+}
+
+// fakeAuthEventRepo 丢弃审计事件
+type fakeAuthEventRepo struct{}
+
+func (fakeAuthEventRepo) Create(ctx context.Context, tx *gorm.DB, event *model.AuthEvent) error {
+	return nil
+}
+
+func (fakeAuthEventRepo) List(ctx context.Context, tx *gorm.DB, filter repository.AuthEventFilter, page, size int) ([]model.AuthEvent, int64, error) {
+	return nil, 0, nil
+}
+
+func (fakeAuthEventRepo) DeleteBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestLoginGuard() (*LoginGuardService, *fakeLoginStore) {
+	store := newFakeLoginStore()
+	cfg := LoginGuardConfig{
+		Window:          15 * time.Minute,
+		UserMaxFailures: 5,
+		IPMaxFailures:   20,
+		Lockout:         15 * time.Minute,
+		MaxLockout:      24 * time.Hour,
+	}
+	return NewLoginGuardService(nil, store, fakeAuthEventRepo{}, cfg), store
+}
+
+func TestLoginGuardConcurrentBurst(t *testing.T) {
+	ctx := context.Background()
+	guard, _ := newTestLoginGuard()
+
+	const burst = 50
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		admitted []string
+	)
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, _, err := guard.Check(ctx, "alice", "203.0.113.1")
+			if err == nil {
+				mu.Lock()
+				admitted = append(admitted, id)
+				mu.Unlock()
+			} else if !errors.Is(err, ErrLoginThrottled) {
+				t.Errorf("Check = %v, want nil or ErrLoginThrottled", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(admitted) != 5 {
+		t.Fatalf("%d of %d parallel attempts admitted, want 5", len(admitted), burst)
+	}
+
+	for _, id := range admitted {
+		guard.RecordFailure(ctx, LoginAttempt{Username: "alice", IP: "203.0.113.1", Reason: "invalid_password", ReservationID: id})
+	}
+	if _, _, err := guard.Check(ctx, "alice", "203.0.113.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check after %d failures = %v, want ErrLoginLocked", len(admitted), err)
+	}
+}
+
+func TestLoginGuardReleaseAndFailure(t *testing.T) {
+	ctx := context.Background()
+	guard, store := newTestLoginGuard()
+	ip := "203.0.113.2"
+
+	// 预占的尝试失败时只计一次
+	id, _, err := guard.Check(ctx, "bob", ip)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	guard.RecordFailure(ctx, LoginAttempt{Username: "bob", IP: ip, ReservationID: id})
+	if n := store.pending(LockoutScopeUser, "bob"); n != 1 {
+		t.Fatalf("user failures = %d, want 1", n)
+	}
+
+	// 密码正确时撤销预占，不计入用户名与 IP 的失败
+	id, _, err = guard.Check(ctx, "bob", ip)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	guard.Release(ctx, "bob", ip, id)
+	if n := store.pending(LockoutScopeUser, "bob"); n != 1 {
+		t.Fatalf("user failures after release = %d, want 1", n)
+	}
+	if n := store.pending(LockoutScopeIP, ip); n != 1 {
+		t.Fatalf("ip failures after release = %d, want 1", n)
+	}
+
+	// IP 名额已满时撤销已为用户名预占的名额
+	for i := 0; i < 19; i++ {
+		guard.RecordFailure(ctx, LoginAttempt{Username: "other", IP: ip})
+	}
+	if _, _, err := guard.Check(ctx, "carol", ip); err == nil {
+		t.Fatal("Check should fail once the ip limit is reached")
+	}
+	if n := store.pending(LockoutScopeUser, "carol"); n != 0 {
+		t.Fatalf("user reservation left behind: %d", n)
+	}
+}
+
+func TestLoginGuardCheckBlocked(t *testing.T) {
+	ctx := context.Background()
+	guard, store := newTestLoginGuard()
+	ip := "203.0.113.3"
+
+	if _, err := guard.CheckBlocked(ctx, "dave", ip); err != nil {
+		t.Fatalf("CheckBlocked = %v, want nil", err)
+	}
+	if n := store.pending(LockoutScopeUser, "dave"); n != 0 {
+		t.Fatalf("CheckBlocked reserved %d attempts, want 0", n)
+	}
+
+	for i := 0; i < 5; i++ {
+		guard.RecordFailure(ctx, LoginAttempt{Username: "dave", IP: ip, Reason: "invalid_mfa_code"})
+	}
+	if wait, err := guard.CheckBlocked(ctx, "dave", "198.51.100.1"); !errors.Is(err, ErrLoginLocked) || wait <= 0 {
+		t.Fatalf("CheckBlocked for locked user = %v, %v, want ErrLoginLocked", wait, err)
+	}
+
+	// IP 被锁定时换用户名同样拒绝
+	store.LockLogin(ctx, LockoutScopeIP, ip, time.Minute)
+	if _, err := guard.CheckBlocked(ctx, "erin", ip); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("CheckBlocked for locked ip = %v, want ErrLoginLocked", err)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	RetentionModeArchive = "archive" // 摘下分区并移入 archive schema
+	RetentionModeDrop    = "drop"    // 直接删除分区
+)
+
+// rollupChunkDays 补汇总历史日期时每条 SQL 覆盖的天数，避免一次扫描过多日志
+const rollupChunkDays = 31
+
+// RetentionConfig access_logs 分区与保留策略配置
+type RetentionConfig struct {
+	PartitionsAhead    int    // 提前创建未来几个月的分区
+	RetentionMonths    int    // 原始日志保留月数，0 表示永久保留
+	Mode               string // archive | drop
+	TrashRetentionDays int    // 回收站（已删除的链接和用户）保留天数，0 表示永久保留
+	AuthEventDays      int    // 认证审计事件保留天数，0 表示永久保留
+}
+
+// LoadRetentionConfigFromEnv 从环境变量读取保留策略
+// ACCESS_LOG_PARTITIONS_AHEAD（默认 3）、ACCESS_LOG_RETENTION_MONTHS（默认 0）、ACCESS_LOG_RETENTION_MODE（默认 archive）、
+// TRASH_RETENTION_DAYS（默认 30）、AUTH_EVENT_RETENTION_DAYS（默认 90）
+func LoadRetentionConfigFromEnv() RetentionConfig {
+	cfg := RetentionConfig{PartitionsAhead: 3, Mode: RetentionModeArchive, TrashRetentionDays: 30, AuthEventDays: 90}
+	if n, err := strconv.Atoi(os.Getenv("ACCESS_LOG_PARTITIONS_AHEAD")); err == nil && n > 0 {
+		cfg.PartitionsAhead = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("ACCESS_LOG_RETENTION_MONTHS")); err == nil && n > 0 {
+		cfg.RetentionMonths = n
+	}
+	if os.Getenv("ACCESS_LOG_RETENTION_MODE") == RetentionModeDrop {
+		cfg.Mode = RetentionModeDrop
+	}
+	if n, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && n >= 0 {
+		cfg.TrashRetentionDays = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("AUTH_EVENT_RETENTION_DAYS")); err == nil && n >= 0 {
+		cfg.AuthEventDays = n
+	}
+	return cfg
+}
+
+// RunAccessLogMaintenance 执行一轮维护：预建分区 -> 汇总未完成的日期并同步点击数 -> 执行保留策略 -> 清理回收站 -> 校准链接计数
+func (s *MaintenanceService) RunAccessLogMaintenance(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	created, err := s.partitionRepository.EnsureMonthlyPartitions(ctx, now, now.AddDate(0, s.config.PartitionsAhead, 0))
+	if err != nil {
+		return fmt.Errorf("创建分区失败: %w", err)
+	}
+	for _, name := range created {
+		log.Printf("🧱 Created access_logs partition %s", name)
+	}
+
+	if err := s.rollupDailyStats(ctx, utcDay(now)); err != nil {
+		return err
+	}
+
+	if err := s.applyRetention(ctx, now); err != nil {
+		return err
+	}
+	if err := s.purgeTrash(ctx, now); err != nil {
+		return err
+	}
+	// 过期的 refresh token 已无法使用，也不再需要用于重放检测
+	if n, err := s.refreshTokenRepository.DeleteExpired(ctx, s.db, now); err != nil {
+		return fmt.Errorf("清理过期刷新令牌失败: %w", err)
+	} else if n > 0 {
+		log.Printf("🗑️ Purged %d expired refresh tokens", n)
+	}
+	if n, err := s.sessionRepository.DeleteExpired(ctx, s.db, now); err != nil {
+		return fmt.Errorf("清理过期会话失败: %w", err)
+	} else if n > 0 {
+		log.Printf("🗑️ Purged %d expired sessions", n)
+	}
+	if s.config.AuthEventDays > 0 {
+		if n, err := s.authEventRepository.DeleteBefore(ctx, s.db, now.AddDate(0, 0, -s.config.AuthEventDays)); err != nil {
+			return fmt.Errorf("清理认证审计事件失败: %w", err)
+		} else if n > 0 {
+			log.Printf("🗑️ Purged %d auth events", n)
+		}
+	}
+
+	// 校准 users.link_count（正常由创建/删除链接增量维护，这里兜底修正历史数据和偏差）
+	n, err := s.userRepository.ReconcileLinkCounts(ctx, s.db)
+	if err != nil {
+		return fmt.Errorf("校准用户链接数失败: %w", err)
+	}
+	if n > 0 {
+		log.Printf("🔧 Reconciled link_count for %d users", n)
+	}
+	return nil
+}
+
+// rollupDailyStats 从上次汇总完成的日期汇总到今天：最后一个已完成的日期重新汇总（可能有延迟到达的日志），
+// Worker 停机期间错过的日期一并补上；从未汇总过时从最早的访问日志开始回填
+func (s *MaintenanceService) rollupDailyStats(ctx context.Context, today time.Time) error {
+	checkpoint, err := s.linkStatsRepository.GetRollupCheckpoint(ctx, s.db)
+	if err != nil {
+		return fmt.Errorf("获取汇总进度失败: %w", err)
+	}
+	from := today.AddDate(0, 0, -1)
+	if checkpoint != nil {
+		if c := checkpoint.AddDate(0, 0, -1); c.Before(from) {
+			from = c
+		}
+	} else {
+		earliest, err := s.linkStatsRepository.EarliestAccessLogDay(ctx, s.db)
+		if err != nil {
+			return fmt.Errorf("获取最早访问日志失败: %w", err)
+		}
+		if earliest != nil && earliest.Before(from) {
+			from = *earliest
+		}
+	}
+	if today.Sub(from) > 48*time.Hour {
+		log.Printf("📊 Rolling up daily stats since %s", from.Format(time.DateOnly))
+	}
+	return s.rollupRange(ctx, from, today)
+}
+
+// BackfillDailyStats 按主表中的全部访问日志重新汇总 link_daily_stats（worker -backfill-stats）；
+// 已归档或删除的分区在移出前已汇总，不受影响
+func (s *MaintenanceService) BackfillDailyStats(ctx context.Context) error {
+	earliest, err := s.linkStatsRepository.EarliestAccessLogDay(ctx, s.db)
+	if err != nil {
+		return fmt.Errorf("获取最早访问日志失败: %w", err)
+	}
+	if earliest == nil {
+		return nil
+	}
+	log.Printf("📊 Backfilling daily stats since %s", earliest.Format(time.DateOnly))
+	return s.rollupRange(ctx, *earliest, utcDay(time.Now()))
+}
+
+// rollupRange 按 rollupChunkDays 分段汇总 [from, today]，每段完成后推进汇总进度（今天尚未结束，不计入进度），
+// 最后同步 from 之后有点击的链接的 visit_count
+func (s *MaintenanceService) rollupRange(ctx context.Context, from, today time.Time) error {
+	end := today.AddDate(0, 0, 1)
+	for start := from; start.Before(end); {
+		stop := start.AddDate(0, 0, rollupChunkDays)
+		if stop.After(end) {
+			stop = end
+		}
+		if err := s.linkStatsRepository.RollupDailyStats(ctx, s.db, start, stop); err != nil {
+			return fmt.Errorf("汇总访问统计失败: %w", err)
+		}
+		done := stop
+		if done.After(today) {
+			done = today
+		}
+		if err := s.linkStatsRepository.SetRollupCheckpoint(ctx, s.db, done); err != nil {
+			return fmt.Errorf("保存汇总进度失败: %w", err)
+		}
+		start = stop
+	}
+	if _, err := s.linkStatsRepository.SyncVisitCounts(ctx, s.db, from); err != nil {
+		return fmt.Errorf("同步链接点击数失败: %w", err)
+	}
+	return nil
+}
+
+// utcDay t 所在日期的 UTC 零点
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// applyRetention 对超过保留期的分区：先确保已汇总，再按配置删除或归档
+func (s *MaintenanceService) applyRetention(ctx context.Context, now time.Time) error {
+	if s.config.RetentionMonths <= 0 {
+		return nil
+	}
+	cutoff := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -s.config.RetentionMonths, 0)
+
+	partitions, err := s.partitionRepository.ListPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("获取分区列表失败: %w", err)
+	}
+	for _, p := range partitions {
+		if p.RangeEnd.After(cutoff) {
+			break // 按时间升序，后面的都还在保留期内
+		}
+		if p.RolledUpAt == nil {
+			if err := s.linkStatsRepository.RollupDailyStats(ctx, s.db, p.RangeStart, p.RangeEnd); err != nil {
+				return fmt.Errorf("汇总分区 %s 失败: %w", p.Name, err)
+			}
+			if err := s.partitionRepository.MarkRolledUp(ctx, p.Name); err != nil {
+				return err
+			}
+			if _, err := s.linkStatsRepository.SyncVisitCounts(ctx, s.db, p.RangeStart); err != nil {
+				return fmt.Errorf("同步链接点击数失败: %w", err)
+			}
+		}
+
+		if s.config.Mode == RetentionModeDrop {
+			err = s.partitionRepository.DropPartition(ctx, p.Name)
+		} else {
+			err = s.partitionRepository.ArchivePartition(ctx, p.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("处理过期分区 %s 失败: %w", p.Name, err)
+		}
+		log.Printf("🗄️ Applied retention (%s) to access_logs partition %s", s.config.Mode, p.Name)
+	}
+	return nil
+}
+
+// purgeTrash 彻底删除超过保留期的回收站链接，以及链接已清空的已删除用户
+func (s *MaintenanceService) purgeTrash(ctx context.Context, now time.Time) error {
+	if s.config.TrashRetentionDays <= 0 {
+		return nil
+	}
+	before := now.AddDate(0, 0, -s.config.TrashRetentionDays)
+
+	links, err := s.linkRepository.PurgeDeletedLinks(ctx, s.db, before)
+	if err != nil {
+		return fmt.Errorf("清理回收站链接失败: %w", err)
+	}
+	users, err := s.userRepository.PurgeDeletedUsers(ctx, s.db, before)
+	if err != nil {
+		return fmt.Errorf("清理已删除用户失败: %w", err)
+	}
+	if links > 0 || users > 0 {
+		log.Printf("🗑️ Purged %d trashed links and %d deleted users", links, users)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/qrcode"
+	"go-short/internal/util"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMFAInvalidCode       = errors.New("验证码错误")
+	ErrMFAInvalidChallenge  = errors.New("两步验证已过期，请重新登录")
+	ErrMFANotEnabled        = errors.New("未启用两步验证")
+	ErrMFAAlreadyEnabled    = errors.New("已启用两步验证")
+	ErrMFANotEnrolled       = errors.New("请先生成两步验证密钥")
+	ErrMFARequired          = errors.New("当前角色必须启用两步验证")
+	ErrMFAEnrollmentPending = errors.New("需要先完成两步验证绑定")
+)
+
+const (
+	mfaChallengeTTL         = 5 * time.Minute
+	mfaChallengeMaxAttempts = 5
+	mfaRecoveryCodeCount    = 10
+)
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer        string   // 验证器 App 中显示的名称
+	RequiredRoles []string // 必须启用两步验证的角色
+}
+
+// LoadMFAConfigFromEnv MFA_ISSUER（默认 GoShort）、MFA_REQUIRED_ROLES（逗号分隔，默认 admin，设为 none 不强制）
+func LoadMFAConfigFromEnv() MFAConfig {
+	cfg := MFAConfig{Issuer: "GoShort", RequiredRoles: []string{model.RoleAdmin}}
+	if v := os.Getenv("MFA_ISSUER"); v != "" {
+		cfg.Issuer = v
+	}
+	if v := os.Getenv("MFA_REQUIRED_ROLES"); v != "" {
+		cfg.RequiredRoles = nil
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.TrimSpace(role); role != "" && role != "none" {
+				cfg.RequiredRoles = append(cfg.RequiredRoles, role)
+			}
+		}
+	}
+	return cfg
+}
+
+// MFAChallenge 密码验证通过后返回给客户端的挑战，凭 Token 完成第二步
+type MFAChallenge struct {
+	Token              string
+	EnrollmentRequired bool // 角色要求两步验证但尚未绑定：先用 Token 绑定，再确认
+	ExpiresIn          int  // 秒
+}
+
+// MFAEnrollment 绑定信息，密钥只在绑定时返回
+type MFAEnrollment struct {
+	Secret     string
+	OTPAuthURI string
+	QRCode     []byte // otpauth 地址的二维码（PNG）
+}
+
+// MFAStatus 两步验证状态
+type MFAStatus struct {
+	Enabled                bool
+	Required               bool
+	RecoveryCodesRemaining int64
+}
+
+type mfaChallengeData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Enroll bool      `json:"enroll"`
+}
+
+// Required 角色是否必须启用两步验证
+func (s *MFAService) Required(role string) bool {
+	return slices.Contains(s.config.RequiredRoles, role)
+}
+
+// BeginLogin 密码验证通过后调用：未启用且角色不要求两步验证时返回 nil（直接签发令牌），否则返回挑战
+func (s *MFAService) BeginLogin(ctx context.Context, user *model.User) (*MFAChallenge, error) {
+	if user.Status != "active" {
+		return nil, ErrUserDisabled
+	}
+	enabled, err := s.enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !s.Required(user.Role) {
+		return nil, nil
+	}
+	raw, hash, err := util.NewRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成挑战令牌失败: %w", err)
+	}
+	data, _ := json.Marshal(mfaChallengeData{UserID: user.ID, Enroll: !enabled})
+	if err := s.challengeStore.SaveMFAChallenge(ctx, hash, data, mfaChallengeTTL); err != nil {
+		return nil, fmt.Errorf("保存挑战失败: %w", err)
+	}
+	return &MFAChallenge{Token: raw, EnrollmentRequired: !enabled, ExpiresIn: int(mfaChallengeTTL.Seconds())}, nil
+}
+
+// VerifyLogin 登录第二步：校验 TOTP 验证码或恢复码，成功后挑战失效，返回用户供签发令牌。
+// 验证码错误时同时返回用户，供调用方计入该用户名的登录失败次数
+func (s *MFAService) VerifyLogin(ctx context.Context, challengeToken, code string) (*model.User, error) {
+	challenge, hash, err := s.loadChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Enroll {
+		return nil, ErrMFAEnrollmentPending
+	}
+	user, err := s.activeUser(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, challenge.UserID, code, true); err != nil {
+		return user, s.challengeFailed(ctx, hash, err)
+	}
+	_ = s.challengeStore.DeleteMFAChallenge(ctx, hash)
+	return user, nil
+}
+
+// EnrollWithChallenge 角色强制两步验证但未绑定的用户，在登录过程中凭挑战令牌生成密钥
+func (s *MFAService) EnrollWithChallenge(ctx context.Context, challengeToken string) (*MFAEnrollment, error) {
+	challenge, _, err := s.loadChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enroll {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return s.Enroll(ctx, challenge.UserID)
+}
+
+// ConfirmWithChallenge 登录过程中确认绑定：启用两步验证并返回恢复码与用户（随后签发令牌）
+func (s *MFAService) ConfirmWithChallenge(ctx context.Context, challengeToken, code string) (*model.User, []string, error) {
+	challenge, hash, err := s.loadChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !challenge.Enroll {
+		return nil, nil, ErrMFAAlreadyEnabled
+	}
+	codes, err := s.Confirm(ctx, challenge.UserID, code)
+	if err != nil {
+		if errors.Is(err, ErrMFAInvalidCode) {
+			return nil, nil, s.challengeFailed(ctx, hash, err)
+		}
+		return nil, nil, err
+	}
+	_ = s.challengeStore.DeleteMFAChallenge(ctx, hash)
+	user, err := s.activeUser(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, codes, nil
+}
+
+// Status 两步验证状态
+func (s *MFAService) Status(ctx context.Context, userID uuid.UUID, role string) (*MFAStatus, error) {
+	status := &MFAStatus{Required: s.Required(role)}
+	enabled, err := s.enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		status.Enabled = true
+		if status.RecoveryCodesRemaining, err = s.mfaRepository.CountRecoveryCodes(ctx, s.db, userID); err != nil {
+			return nil, fmt.Errorf("统计恢复码失败: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// Enroll 生成新密钥（覆盖未确认的密钥），确认前不生效
+func (s *MFAService) Enroll(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	saved, err := s.mfaRepository.SaveSecret(ctx, s.db, userID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("保存密钥失败: %w", err)
+	}
+	if !saved {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	uri := util.TOTPURI(s.config.Issuer, user.Username, secret)
+	png, err := qrcode.Render(uri, qrcode.DefaultOptions(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败: %w", err)
+	}
+	return &MFAEnrollment{Secret: secret, OTPAuthURI: uri, QRCode: png}, nil
+}
+
+// Confirm 用验证器 App 生成的验证码确认绑定，启用后返回一组恢复码（只返回这一次）
+func (s *MFAService) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepository.GetByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("查询两步验证失败: %w", err)
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	counter, ok := util.VerifyTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+	var codes []string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.mfaRepository.Enable(ctx, tx, userID, counter, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		codes, err = s.replaceRecoveryCodes(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("启用两步验证失败: %w", err)
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes 凭当前验证码重新生成恢复码，旧恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.verifyCode(ctx, userID, code, false); err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, s.db, userID)
+	if err != nil {
+		return nil, fmt.Errorf("生成恢复码失败: %w", err)
+	}
+	return codes, nil
+}
+
+// Disable 凭验证码或恢复码关闭两步验证；角色要求两步验证时不允许关闭
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, role, code string) error {
+	if s.Required(role) {
+		return ErrMFARequired
+	}
+	if err := s.verifyCode(ctx, userID, code, true); err != nil {
+		return err
+	}
+	if _, err := s.mfaRepository.Delete(ctx, s.db, userID); err != nil {
+		return fmt.Errorf("关闭两步验证失败: %w", err)
+	}
+	return nil
+}
+
+// Reset 管理员重置用户的两步验证（如丢失设备），用户下次登录时按角色要求重新绑定
+func (s *MFAService) Reset(ctx context.Context, userID uuid.UUID) error {
+	deleted, err := s.mfaRepository.Delete(ctx, s.db, userID)
+	if err != nil {
+		return fmt.Errorf("重置两步验证失败: %w", err)
+	}
+	if !deleted {
+		return ErrMFANotEnabled
+	}
+	return nil
+}
+
+// verifyCode 校验已启用的两步验证：6 位验证码（拒绝重放），allowRecovery 时也接受恢复码（一次性）
+func (s *MFAService) verifyCode(ctx context.Context, userID uuid.UUID, code string, allowRecovery bool) error {
+	mfa, err := s.mfaRepository.GetByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMFANotEnabled
+		}
+		return fmt.Errorf("查询两步验证失败: %w", err)
+	}
+	if !mfa.Enabled {
+		return ErrMFANotEnabled
+	}
+	if counter, ok := util.VerifyTOTP(mfa.Secret, code, time.Now()); ok {
+		used, err := s.mfaRepository.UseCounter(ctx, s.db, userID, counter)
+		if err != nil {
+			return fmt.Errorf("记录验证码失败: %w", err)
+		}
+		if !used {
+			return ErrMFAInvalidCode // 同一验证码已使用过
+		}
+		return nil
+	}
+	if !allowRecovery {
+		return ErrMFAInvalidCode
+	}
+	used, err := s.mfaRepository.UseRecoveryCode(ctx, s.db, userID, recoveryCodeHash(userID, code), time.Now())
+	if err != nil {
+		return fmt.Errorf("校验恢复码失败: %w", err)
+	}
+	if !used {
+		return ErrMFAInvalidCode
+	}
+	return nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+	for range mfaRecoveryCodeCount {
+		code, err := util.NewRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, recoveryCodeHash(userID, code))
+	}
+	if err := s.mfaRepository.ReplaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// recoveryCodeHash 以用户 ID 加盐，不同用户的相同恢复码哈希不同
+func recoveryCodeHash(userID uuid.UUID, code string) string {
+	return util.HashToken(userID.String() + ":" + util.NormalizeRecoveryCode(code))
+}
+
+func (s *MFAService) enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := s.mfaRepository.GetByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("查询两步验证失败: %w", err)
+	}
+	return mfa.Enabled, nil
+}
+
+// ChallengeUser 登录挑战对应的用户（不消耗挑战），供验证前检查该用户名是否已被锁定
+func (s *MFAService) ChallengeUser(ctx context.Context, challengeToken string) (*model.User, error) {
+	challenge, _, err := s.loadChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.activeUser(ctx, challenge.UserID)
+}
+
+func (s *MFAService) loadChallenge(ctx context.Context, challengeToken string) (*mfaChallengeData, string, error) {
+	if challengeToken == "" {
+		return nil, "", ErrMFAInvalidChallenge
+	}
+	hash := util.HashToken(challengeToken)
+	data, err := s.challengeStore.GetMFAChallenge(ctx, hash)
+	if err != nil {
+		return nil, "", ErrMFAInvalidChallenge
+	}
+	var challenge mfaChallengeData
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, "", ErrMFAInvalidChallenge
+	}
+	return &challenge, hash, nil
+}
+
+// challengeFailed 验证失败计数，达到上限后挑战失效（需重新输入密码）
+func (s *MFAService) challengeFailed(ctx context.Context, hash string, cause error) error {
+	if !errors.Is(cause, ErrMFAInvalidCode) {
+		return cause
+	}
+	n, err := s.challengeStore.IncrMFAChallengeAttempts(ctx, hash, mfaChallengeTTL)
+	if err == nil && n >= mfaChallengeMaxAttempts {
+		_ = s.challengeStore.DeleteMFAChallenge(ctx, hash)
+		return ErrMFAInvalidChallenge
+	}
+	return cause
+}
+
+func (s *MFAService) activeUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user.Status != "active" {
+		return nil, ErrUserDisabled
+	}
+	return user, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/oidc"
+	"go-short/internal/util"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("SSO 提供方不存在")
+	ErrOIDCInvalidState     = errors.New("SSO 登录状态无效或已过期")
+	ErrOIDCLoginFailed      = errors.New("SSO 登录失败")
+)
+
+// OIDCStateTTL 从跳转 IdP 到回调的最长时间（也是 state Cookie 的有效期）
+const OIDCStateTTL = 10 * time.Minute
+
+// usernameInvalidChars 生成用户名时去掉的字符（与注册规则一致：字母、数字、下划线、汉字）
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_\p{Han}]+`)
+
+// oidcLoginState 跳转 IdP 前保存，回调时取回（一次性）
+type oidcLoginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// ListProviders 已配置的 SSO 提供方名称
+func (s *OIDCService) ListProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// StartLogin 生成 state、nonce 与 PKCE verifier 并保存，返回 IdP 授权地址和 state；
+// 调用方需把 state 写入浏览器 Cookie，回调时原样传给 CompleteLogin
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (authURL, state string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrOIDCProviderNotFound
+	}
+	state, err = oidc.RandomString()
+	if err != nil {
+		return "", "", fmt.Errorf("生成 state 失败: %w", err)
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", "", fmt.Errorf("生成 PKCE 失败: %w", err)
+	}
+	authURL, err = provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+	}
+	data, _ := json.Marshal(oidcLoginState{Provider: providerName, Nonce: nonce, CodeVerifier: verifier})
+	if err := s.stateStore.SaveOIDCState(ctx, state, data, OIDCStateTTL); err != nil {
+		return "", "", fmt.Errorf("保存登录状态失败: %w", err)
+	}
+	return authURL, state, nil
+}
+
+// CompleteLogin 处理回调：校验 state（须与发起登录的浏览器 Cookie 中的 cookieState 一致，防止登录 CSRF）、
+// 用授权码换取并校验 ID Token，找到或创建（JIT）对应的本地用户。
+// 配置了 RoleClaim 的提供方每次登录按 IdP 声明同步用户角色
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, state, cookieState, code string) (*model.User, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return nil, ErrOIDCInvalidState
+	}
+	data, err := s.stateStore.TakeOIDCState(ctx, state)
+	if err != nil {
+		return nil, ErrOIDCInvalidState
+	}
+	var saved oidcLoginState
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Provider != providerName {
+		return nil, ErrOIDCInvalidState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, saved.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+	}
+
+	user, identity, err := s.findOrProvisionUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.identityRepository.UpdateLogin(ctx, s.db, identity.ID, claims.Email, time.Now()); err != nil {
+		log.Printf("⚠️ Update identity login for user %s failed: %v", user.ID, err)
+	}
+	if provider.Config().RoleClaim != "" {
+		if err := s.syncRole(ctx, user, s.mappedRole(ctx, provider, claims)); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// findOrProvisionUser 按 (提供方, sub) 查找已关联的用户；未关联时按配置用已验证邮箱关联已有用户
+// （本地账号的邮箱也必须已验证，防止抢注他人邮箱的未验证账号被关联），否则创建新用户
+func (s *OIDCService) findOrProvisionUser(ctx context.Context, provider *oidc.Provider, claims *oidc.Claims) (*model.User, *model.UserIdentity, error) {
+	cfg := provider.Config()
+	identity, err := s.identityRepository.GetByProviderSubject(ctx, s.db, cfg.Name, claims.Subject)
+	if err == nil {
+		user, err := s.userRepository.GetUserByUserID(ctx, s.db, identity.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrUserDisabled // 本地用户已删除
+			}
+			return nil, nil, fmt.Errorf("查询用户失败: %w", err)
+		}
+		return user, identity, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("查询外部账号失败: %w", err)
+	}
+
+	identity = &model.UserIdentity{
+		Provider:    cfg.Name,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: time.Now(),
+	}
+
+	if cfg.LinkByEmail && claims.EmailVerified && claims.Email != "" {
+		user, err := s.userRepository.GetUserByVerifiedEmail(ctx, s.db, claims.Email)
+		if err == nil {
+			identity.UserID = user.ID
+			if err := s.identityRepository.Create(ctx, s.db, identity); err != nil {
+				return nil, nil, fmt.Errorf("关联外部账号失败: %w", err)
+			}
+			return user, identity, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("查询用户失败: %w", err)
+		}
+	}
+
+	user, err := s.provisionUser(ctx, provider, claims, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, identity, nil
+}
+
+// provisionUser 首次 SSO 登录时创建本地用户（随机密码，只能通过 SSO 登录）并关联外部账号
+func (s *OIDCService) provisionUser(ctx context.Context, provider *oidc.Provider, claims *oidc.Claims, identity *model.UserIdentity) (*model.User, error) {
+	username, err := s.uniqueUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	password, err := oidc.RandomString()
+	if err != nil {
+		return nil, fmt.Errorf("生成密码失败: %w", err)
+	}
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("密码加密失败: %w", err)
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("生成用户ID失败: %w", err)
+	}
+	user := &model.User{
+		ID:           id,
+		Username:     username,
+		Role:         s.mappedRole(ctx, provider, claims),
+		PasswordHash: hashedPassword,
+		Status:       "active",
+	}
+	if claims.Email != "" {
+		email := claims.Email
+		user.Email = &email
+	}
+	identity.UserID = id
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepository.Create(ctx, tx, user); err != nil {
+			return fmt.Errorf("创建用户失败: %w", err)
+		}
+		if err := s.identityRepository.Create(ctx, tx, identity); err != nil {
+			return fmt.Errorf("关联外部账号失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// uniqueUsername 由 preferred_username、邮箱前缀或 sub 生成符合注册规则的用户名，重名时追加随机后缀
+func (s *OIDCService) uniqueUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameInvalidChars.ReplaceAllString(base, "_")
+	base = strings.Trim(base, "_")
+	if len([]rune(base)) < 2 {
+		base = "sso_" + util.HashToken(claims.Subject)[:8]
+	}
+	if r := []rune(base); len(r) > 15 {
+		base = string(r[:15])
+	}
+
+	candidate := base
+	for range 5 {
+		exists, err := s.userRepository.CheckUsernameExists(ctx, s.db, candidate)
+		if err != nil {
+			return "", fmt.Errorf("检查用户名失败: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+		suffix, err := oidc.RandomString()
+		if err != nil {
+			return "", fmt.Errorf("生成用户名失败: %w", err)
+		}
+		candidate = base + "_" + util.HashToken(suffix)[:4]
+	}
+	return "", fmt.Errorf("%w: 无法生成唯一用户名", ErrOIDCLoginFailed)
+}
+
+// mappedRole 按提供方映射规则得到角色，角色不存在时退回普通用户
+func (s *OIDCService) mappedRole(ctx context.Context, provider *oidc.Provider, claims *oidc.Claims) string {
+	role := provider.MapRole(claims)
+	if role == "" || role == model.RoleUser {
+		return model.RoleUser
+	}
+	if _, err := s.roleRepository.GetByName(ctx, s.db, role); err != nil {
+		log.Printf("⚠️ OIDC provider %s maps to unknown role %q: %v", provider.Config().Name, role, err)
+		return model.RoleUser
+	}
+	return role
+}
+
+// syncRole 角色与 IdP 声明不一致时更新，并删除权限缓存使其立即生效
+func (s *OIDCService) syncRole(ctx context.Context, user *model.User, role string) error {
+	if user.Role == role {
+		return nil
+	}
+	if err := s.userRepository.UpdateRoleByUserID(ctx, s.db, user.ID, role); err != nil {
+		return fmt.Errorf("同步用户角色失败: %w", err)
+	}
+	user.Role = role
+	if s.permissionCache != nil {
+		_ = s.permissionCache.DeleteUserRole(ctx, user.ID)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/qrcode"
+	"go-short/internal/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// qrCacheTTL 二维码只编码短链接地址，内容稳定，缓存时间可以较长
+const qrCacheTTL = 24 * time.Hour
+
+var ErrQRLogoUnavailable = errors.New("未配置二维码 Logo")
+
+// GetLinkQRCode 生成链接的二维码（需要验证用户权限）
+func (s *QRService) GetLinkQRCode(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool, baseURL string, opts qrcode.Options) ([]byte, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.render(ctx, util.ShortURL(baseURL, link.Domain, link.ShortCode), opts)
+}
+
+// GetShortURLQRCode 生成短链接地址的二维码，不检查链接状态；调用方需先确认链接可用（Redirect 服务复用跳转缓存校验）
+func (s *QRService) GetShortURLQRCode(ctx context.Context, domain, code, baseURL string, opts qrcode.Options) ([]byte, error) {
+	return s.render(ctx, util.ShortURL(baseURL, domain, code), opts)
+}
+
+// render 按内容与参数哈希读写 Redis 缓存
+func (s *QRService) render(ctx context.Context, content string, opts qrcode.Options) ([]byte, error) {
+	if opts.Logo && s.logo == nil {
+		return nil, ErrQRLogoUnavailable
+	}
+	key := opts.CacheKey(content)
+	if data, ok := s.getCached(ctx, key); ok {
+		return data, nil
+	}
+	data, err := qrcode.Render(content, opts, s.logo)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败: %w", err)
+	}
+	if s.qrCache != nil {
+		_ = s.qrCache.SetQRCode(ctx, key, data, qrCacheTTL)
+	}
+	return data, nil
+}
+
+func (s *QRService) getCached(ctx context.Context, key string) ([]byte, bool) {
+	if s.qrCache == nil {
+		return nil, false
+	}
+	data, err := s.qrCache.GetQRCode(ctx, key)
+	return data, err == nil && len(data) > 0
+}
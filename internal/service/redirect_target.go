@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/json"
+	"go-short/internal/model"
+	"strings"
+	"time"
+)
+
+// RedirectTarget 跳转缓存（本地缓存 / Redis）中保存的内容
+// 无附加选项时直接存原始 URL，与旧缓存格式兼容；有选项时存 JSON（URL 不会以 "{" 开头）
+type RedirectTarget struct {
+	URL              string `json:"u"`
+	TrackConversions bool   `json:"c,omitempty"`
+}
+
+// NewRedirectTarget 由链接构造跳转目标
+func NewRedirectTarget(link *model.Link) RedirectTarget {
+	return RedirectTarget{
+		URL:              link.OriginalURL,
+		TrackConversions: link.TrackConversions,
+	}
+}
+
+// Encode 序列化为缓存值
+func (t RedirectTarget) Encode() string {
+	if !t.TrackConversions {
+		return t.URL
+	}
+	data, _ := json.Marshal(t)
+	return string(data)
+}
+
+// DecodeRedirectTarget 解析缓存值
+func DecodeRedirectTarget(s string) RedirectTarget {
+	if strings.HasPrefix(s, "{") {
+		var t RedirectTarget
+		if err := json.Unmarshal([]byte(s), &t); err == nil {
+			return t
+		}
+	}
+	return RedirectTarget{URL: s}
+}
+
+// RedirectCacheTTL 跳转缓存时长：不超过 limit，且不晚于链接的过期时间
+func RedirectCacheTTL(link *model.Link, limit time.Duration) time.Duration {
+	if link.ExpiresAt != nil {
+		if ttl := time.Until(*link.ExpiresAt); ttl < limit {
+			return max(ttl, time.Second)
+		}
+	}
+	return limit
+}
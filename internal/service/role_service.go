@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("角色不存在")
+	ErrRoleExists        = errors.New("角色已存在")
+	ErrInvalidRole       = errors.New("角色名无效")
+	ErrBuiltinRole       = errors.New("内置角色不能修改或删除")
+	ErrRoleInUse         = errors.New("角色仍有用户使用")
+	ErrInvalidPermission = errors.New("权限无效")
+	ErrChangeOwnRole     = errors.New("不能修改自己的角色")
+	ErrRoleEscalation    = errors.New("不能授予或修改超出自己权限的角色")
+)
+
+// permissionCacheTTL 用户角色与角色权限的缓存时间；变更时主动删除缓存，TTL 只是兜底
+const permissionCacheTTL = time.Minute
+
+type CreateRoleCommand struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// UpdateRoleCommand 字段为 nil 表示不修改
+type UpdateRoleCommand struct {
+	Description *string
+	Permissions []string // nil 不修改，空切片清空权限
+}
+
+// normalizePermissions 校验权限并去重排序
+func normalizePermissions(perms []string) ([]string, error) {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		if !model.IsPermission(p) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPermission, p)
+		}
+		out = append(out, p)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// ListRoles 全部角色
+func (s *RoleService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	return s.roleRepository.List(ctx, s.db)
+}
+
+// CreateRole 创建自定义角色，权限不能超出操作者的权限
+func (s *RoleService) CreateRole(ctx context.Context, actorID uuid.UUID, cmd CreateRoleCommand) (*model.Role, error) {
+	name := strings.TrimSpace(cmd.Name)
+	if !model.ValidRoleName(name) {
+		return nil, ErrInvalidRole
+	}
+	perms, err := normalizePermissions(cmd.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role := &model.Role{
+		Name:        name,
+		Description: strings.TrimSpace(cmd.Description),
+		Permissions: perms,
+	}
+	actor, err := actorRole(ctx, s.db, s.userRepository, s.roleRepository, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleGrant(actor, role); err != nil {
+		return nil, err
+	}
+	if err := s.roleRepository.Create(ctx, s.db, role); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrRoleExists
+		}
+		return nil, fmt.Errorf("创建角色失败: %w", err)
+	}
+	return role, nil
+}
+
+// UpdateRole 修改自定义角色的描述或权限，立即对持有该角色的用户生效；
+// 非 admin 不能修改自己的角色，角色修改前后的权限都不能超出操作者的权限
+func (s *RoleService) UpdateRole(ctx context.Context, name string, actorID uuid.UUID, cmd UpdateRoleCommand) (*model.Role, error) {
+	if model.IsBuiltinRole(name) {
+		return nil, ErrBuiltinRole
+	}
+	role, err := s.roleRepository.GetByName(ctx, s.db, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	actor, err := actorRole(ctx, s.db, s.userRepository, s.roleRepository, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Name == role.Name && actor.Name != model.RoleAdmin {
+		return nil, ErrChangeOwnRole
+	}
+	if err := checkRoleGrant(actor, role); err != nil {
+		return nil, err
+	}
+	if cmd.Description != nil {
+		role.Description = strings.TrimSpace(*cmd.Description)
+	}
+	if cmd.Permissions != nil {
+		perms, err := normalizePermissions(cmd.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRoleGrant(actor, &model.Role{Name: role.Name, Permissions: perms}); err != nil {
+			return nil, err
+		}
+		role.Permissions = perms
+	}
+	if err := s.roleRepository.Update(ctx, s.db, role); err != nil {
+		return nil, fmt.Errorf("更新角色失败: %w", err)
+	}
+	s.invalidateRole(ctx, name)
+	return role, nil
+}
+
+// DeleteRole 删除自定义角色，仍有用户（含回收站中的用户）使用时不能删除
+func (s *RoleService) DeleteRole(ctx context.Context, name string) error {
+	if model.IsBuiltinRole(name) {
+		return ErrBuiltinRole
+	}
+	count, err := s.roleRepository.CountUsers(ctx, s.db, name)
+	if err != nil {
+		return fmt.Errorf("统计角色用户失败: %w", err)
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+	deleted, err := s.roleRepository.Delete(ctx, s.db, name)
+	if err != nil {
+		return fmt.Errorf("删除角色失败: %w", err)
+	}
+	if !deleted {
+		return ErrRoleNotFound
+	}
+	s.invalidateRole(ctx, name)
+	return nil
+}
+
+// AssignUserRole 修改用户角色，下一次请求即按新角色鉴权（无需重新登录）；
+// 新角色和用户当前角色的权限都不能超出操作者的权限
+func (s *RoleService) AssignUserRole(ctx context.Context, userID, actorID uuid.UUID, roleName string) error {
+	if userID == actorID {
+		return ErrChangeOwnRole
+	}
+	role, err := s.roleRepository.GetByName(ctx, s.db, roleName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("查询角色失败: %w", err)
+	}
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("查询用户失败: %w", err)
+	}
+	actor, err := actorRole(ctx, s.db, s.userRepository, s.roleRepository, actorID)
+	if err != nil {
+		return err
+	}
+	current, err := roleOrEmpty(ctx, s.db, s.roleRepository, user.Role)
+	if err != nil {
+		return err
+	}
+	if err := checkRoleGrant(actor, role); err != nil {
+		return err
+	}
+	if err := checkRoleGrant(actor, current); err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdateRoleByUserID(ctx, s.db, userID, roleName); err != nil {
+		return fmt.Errorf("修改用户角色失败: %w", err)
+	}
+	if s.permissionCache != nil {
+		_ = s.permissionCache.DeleteUserRole(ctx, userID)
+	}
+	return nil
+}
+
+// ResolvePermissions 查询用户当前角色及权限（先读缓存），供认证中间件每次请求时刷新，
+// 不信任 token 中签发时的角色；用户已删除时返回 ErrUserNotFound
+func (s *RoleService) ResolvePermissions(ctx context.Context, userID uuid.UUID) (string, []string, error) {
+	roleName, err := s.userRole(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if roleName == model.RoleAdmin {
+		return roleName, slices.Clone(model.Permissions), nil
+	}
+	perms, err := s.rolePermissions(ctx, roleName)
+	if err != nil {
+		return "", nil, err
+	}
+	return roleName, perms, nil
+}
+
+func (s *RoleService) userRole(ctx context.Context, userID uuid.UUID) (string, error) {
+	if s.permissionCache != nil {
+		if role, err := s.permissionCache.GetUserRole(ctx, userID); err == nil {
+			return role, nil
+		}
+	}
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("查询用户失败: %w", err)
+	}
+	if s.permissionCache != nil {
+		_ = s.permissionCache.SetUserRole(ctx, userID, user.Role, permissionCacheTTL)
+	}
+	return user.Role, nil
+}
+
+// rolePermissions 角色不存在时视为没有任何权限
+func (s *RoleService) rolePermissions(ctx context.Context, roleName string) ([]string, error) {
+	if s.permissionCache != nil {
+		if perms, err := s.permissionCache.GetRolePermissions(ctx, roleName); err == nil {
+			return perms, nil
+		}
+	}
+	var perms []string
+	role, err := s.roleRepository.GetByName(ctx, s.db, roleName)
+	switch {
+	case err == nil:
+		perms = role.EffectivePermissions()
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	if s.permissionCache != nil {
+		_ = s.permissionCache.SetRolePermissions(ctx, roleName, perms, permissionCacheTTL)
+	}
+	return perms, nil
+}
+
+// checkRoleGrant 操作者只能授予不超出自己权限的角色：admin 角色（始终拥有全部权限）只能由 admin 授予，
+// 其他角色的权限须是操作者权限的子集
+func checkRoleGrant(actor, role *model.Role) error {
+	if actor.Name == model.RoleAdmin {
+		return nil
+	}
+	if role.Name == model.RoleAdmin {
+		return ErrRoleEscalation
+	}
+	perms := actor.EffectivePermissions()
+	for _, p := range role.EffectivePermissions() {
+		if !slices.Contains(perms, p) {
+			return ErrRoleEscalation
+		}
+	}
+	return nil
+}
+
+// actorRole 操作者当前的角色（直接查库，不读缓存）
+func actorRole(ctx context.Context, db *gorm.DB, userRepository repository.UserRepository, roleRepository repository.RoleRepository, actorID uuid.UUID) (*model.Role, error) {
+	actor, err := userRepository.GetUserByUserID(ctx, db, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("查询操作者失败: %w", err)
+	}
+	return roleOrEmpty(ctx, db, roleRepository, actor.Role)
+}
+
+// roleOrEmpty 查询角色，角色已不存在时视为没有任何权限
+func roleOrEmpty(ctx context.Context, db *gorm.DB, roleRepository repository.RoleRepository, name string) (*model.Role, error) {
+	role, err := roleRepository.GetByName(ctx, db, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.Role{Name: name}, nil
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	return role, nil
+}
+
+func (s *RoleService) invalidateRole(ctx context.Context, name string) {
+	if s.permissionCache != nil {
+		_ = s.permissionCache.DeleteRolePermissions(ctx, name)
+	}
+}
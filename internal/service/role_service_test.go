@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-short/internal/model"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestCheckRoleGrant(t *testing.T) {
+	admin := &model.Role{Name: model.RoleAdmin}
+	user := &model.Role{Name: model.RoleUser}
+	userManager := &model.Role{Name: "user-manager", Permissions: []string{model.PermUsersManage}}
+	auditor := &model.Role{Name: "auditor", Permissions: []string{model.PermLogsRead}}
+	allPerms := &model.Role{Name: "super", Permissions: model.Permissions}
+
+	cases := []struct {
+		name        string
+		actor, role *model.Role
+		wantErr     bool
+	}{
+		{"admin grants admin", admin, admin, false},
+		{"admin grants custom role", admin, auditor, false},
+		{"manager grants user", userManager, user, false},
+		{"manager grants own role", userManager, userManager, false},
+		{"manager grants admin", userManager, admin, true},
+		{"manager grants role with extra permission", userManager, auditor, true},
+		{"custom role with every permission grants admin", allPerms, admin, true},
+		{"custom role with every permission grants custom role", allPerms, auditor, false},
+	}
+	for _, c := range cases {
+		err := checkRoleGrant(c.actor, c.role)
+		if c.wantErr != errors.Is(err, ErrRoleEscalation) || (!c.wantErr && err != nil) {
+			t.Errorf("%s: checkRoleGrant = %v, wantErr %v", c.name, err, c.wantErr)
+		}
+	}
+}
+
+// fakeRoleRepo 内存实现的 RoleRepository，admin 与 user 为内置角色
+type fakeRoleRepo struct {
+	roles map[string]*model.Role
+}
+
+func newFakeRoleRepo(roles ...*model.Role) *fakeRoleRepo {
+	r := &fakeRoleRepo{roles: map[string]*model.Role{
+		model.RoleAdmin: {Name: model.RoleAdmin, BuiltIn: true},
+		model.RoleUser:  {Name: model.RoleUser, BuiltIn: true},
+	}}
+	for _, role := range roles {
+		r.roles[role.Name] = role
+	}
+	return r
+}
+
+func (r *fakeRoleRepo) Create(ctx context.Context, tx *gorm.DB, role *model.Role) error {
+	if _, ok := r.roles[role.Name]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	cp := *role
+	r.roles[role.Name] = &cp
+	return nil
+}
+
+func (r *fakeRoleRepo) GetByName(ctx context.Context, tx *gorm.DB, name string) (*model.Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *role
+	return &cp, nil
+}
+
+func (r *fakeRoleRepo) List(ctx context.Context, tx *gorm.DB) ([]model.Role, error) {
+	var out []model.Role
+	for _, role := range r.roles {
+		out = append(out, *role)
+	}
+	return out, nil
+}
+
+func (r *fakeRoleRepo) Update(ctx context.Context, tx *gorm.DB, role *model.Role) error {
+	cp := *role
+	r.roles[role.Name] = &cp
+	return nil
+}
+
+func (r *fakeRoleRepo) Delete(ctx context.Context, tx *gorm.DB, name string) (bool, error) {
+	_, ok := r.roles[name]
+	delete(r.roles, name)
+	return ok, nil
+}
+
+func (r *fakeRoleRepo) CountUsers(ctx context.Context, tx *gorm.DB, name string) (int64, error) {
+	return 0, nil
+}
+
+// newTestRoleService 返回角色服务与持有 admin、roles.manage 角色的两个用户
+func newTestRoleService() (*RoleService, *fakeRoleRepo, uuid.UUID, uuid.UUID) {
+	roles := newFakeRoleRepo(
+		&model.Role{Name: "role-manager", Permissions: []string{model.PermRolesManage}},
+		&model.Role{Name: "auditor", Permissions: []string{model.PermLogsRead}},
+		&model.Role{Name: "helper", Permissions: []string{model.PermRolesManage}},
+	)
+	admin := &model.User{ID: uuid.New(), Username: "root", Role: model.RoleAdmin}
+	manager := &model.User{ID: uuid.New(), Username: "mallory", Role: "role-manager"}
+	users := &fakeUserRepo{users: map[uuid.UUID]*model.User{admin.ID: admin, manager.ID: manager}}
+	return NewRoleService(nil, roles, users, nil), roles, admin.ID, manager.ID
+}
+
+func TestCreateRoleEscalation(t *testing.T) {
+	ctx := context.Background()
+	svc, _, adminID, managerID := newTestRoleService()
+
+	if _, err := svc.CreateRole(ctx, managerID, CreateRoleCommand{Name: "everything", Permissions: model.Permissions}); !errors.Is(err, ErrRoleEscalation) {
+		t.Fatalf("CreateRole with extra permissions = %v, want ErrRoleEscalation", err)
+	}
+	if _, err := svc.CreateRole(ctx, managerID, CreateRoleCommand{Name: "junior", Permissions: []string{model.PermRolesManage}}); err != nil {
+		t.Fatalf("CreateRole within own permissions: %v", err)
+	}
+	if _, err := svc.CreateRole(ctx, adminID, CreateRoleCommand{Name: "everything", Permissions: model.Permissions}); err != nil {
+		t.Fatalf("CreateRole by admin: %v", err)
+	}
+}
+
+func TestUpdateRoleEscalation(t *testing.T) {
+	ctx := context.Background()
+	svc, roles, adminID, managerID := newTestRoleService()
+	all := slices.Clone(model.Permissions)
+
+	// 修改自己持有的角色
+	if _, err := svc.UpdateRole(ctx, "role-manager", managerID, UpdateRoleCommand{Permissions: all}); !errors.Is(err, ErrChangeOwnRole) {
+		t.Fatalf("UpdateRole on own role = %v, want ErrChangeOwnRole", err)
+	}
+	desc := "mine"
+	if _, err := svc.UpdateRole(ctx, "role-manager", managerID, UpdateRoleCommand{Description: &desc}); !errors.Is(err, ErrChangeOwnRole) {
+		t.Fatalf("UpdateRole description on own role = %v, want ErrChangeOwnRole", err)
+	}
+	// 给其他角色加上自己没有的权限
+	if _, err := svc.UpdateRole(ctx, "helper", managerID, UpdateRoleCommand{Permissions: all}); !errors.Is(err, ErrRoleEscalation) {
+		t.Fatalf("UpdateRole with extra permissions = %v, want ErrRoleEscalation", err)
+	}
+	// 修改权限已超出自己的角色（即使是收回权限）
+	if _, err := svc.UpdateRole(ctx, "auditor", managerID, UpdateRoleCommand{Permissions: []string{}}); !errors.Is(err, ErrRoleEscalation) {
+		t.Fatalf("UpdateRole on stronger role = %v, want ErrRoleEscalation", err)
+	}
+	if role, _ := roles.GetByName(ctx, nil, "role-manager"); !slices.Equal(role.Permissions, []string{model.PermRolesManage}) || role.Description != "" {
+		t.Fatalf("own role must not change, got %+v", role)
+	}
+	if role, _ := roles.GetByName(ctx, nil, "helper"); !slices.Equal(role.Permissions, []string{model.PermRolesManage}) {
+		t.Fatalf("helper role must not change, got %+v", role)
+	}
+
+	// 权限范围内的修改
+	if _, err := svc.UpdateRole(ctx, "helper", managerID, UpdateRoleCommand{Permissions: []string{}}); err != nil {
+		t.Fatalf("UpdateRole within own permissions: %v", err)
+	}
+	// admin 不受限制
+	if _, err := svc.UpdateRole(ctx, "role-manager", adminID, UpdateRoleCommand{Permissions: all}); err != nil {
+		t.Fatalf("UpdateRole by admin: %v", err)
+	}
+}
+
+func TestAuthorizeUserAction(t *testing.T) {
+	ctx := context.Background()
+	roles := newFakeRoleRepo(
+		&model.Role{Name: "user-manager", Permissions: []string{model.PermUsersManage}},
+		&model.Role{Name: "auditor", Permissions: []string{model.PermLogsRead}},
+	)
+	newUser := func(role string) *model.User {
+		return &model.User{ID: uuid.New(), Username: role, Role: role}
+	}
+	admin, manager, peer := newUser(model.RoleAdmin), newUser("user-manager"), newUser("user-manager")
+	auditor, plain, orphan := newUser("auditor"), newUser(model.RoleUser), newUser("removed-role")
+	users := &fakeUserRepo{users: map[uuid.UUID]*model.User{}}
+	for _, u := range []*model.User{admin, manager, peer, auditor, plain, orphan} {
+		users.users[u.ID] = u
+	}
+	svc := NewAdminService(nil, nil, users, nil, nil, roles, nil)
+
+	cases := []struct {
+		name    string
+		actor   *model.User
+		target  uuid.UUID
+		wantErr error
+	}{
+		{"manager on admin", manager, admin.ID, ErrRoleEscalation},
+		{"manager on stronger role", manager, auditor.ID, ErrRoleEscalation},
+		{"manager on user", manager, plain.ID, nil},
+		{"manager on same role", manager, peer.ID, nil},
+		{"manager on deleted role", manager, orphan.ID, nil},
+		{"admin on admin", admin, admin.ID, nil},
+		{"admin on stronger role", admin, auditor.ID, nil},
+		{"unknown target", admin, uuid.New(), ErrUserNotFound},
+	}
+	for _, c := range cases {
+		err := svc.AuthorizeUserAction(ctx, c.actor.ID, c.target)
+		if !errors.Is(err, c.wantErr) || (c.wantErr == nil && err != nil) {
+			t.Errorf("%s: AuthorizeUserAction = %v, want %v", c.name, err, c.wantErr)
+		}
+	}
+
+	// 禁用、启用、删除在改动前校验
+	if err := svc.UnactiveUserByUserID(ctx, admin.ID, manager.ID); !errors.Is(err, ErrRoleEscalation) {
+		t.Errorf("UnactiveUserByUserID on admin = %v, want ErrRoleEscalation", err)
+	}
+	if err := svc.ActiveUserByUserID(ctx, auditor.ID, manager.ID); !errors.Is(err, ErrRoleEscalation) {
+		t.Errorf("ActiveUserByUserID on stronger role = %v, want ErrRoleEscalation", err)
+	}
+	if err := svc.DeleteUserAndData(ctx, admin.ID, manager.ID); !errors.Is(err, ErrRoleEscalation) {
+		t.Errorf("DeleteUserAndData on admin = %v, want ErrRoleEscalation", err)
+	}
+}
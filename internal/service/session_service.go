@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("会话不存在")
+
+// sessionRevoker 终止登录会话：会话记录、对应的 refresh token 家族及该会话已签发的 access token 一并失效。
+// TokenService（登出、终止会话）与 UserService（修改密码）共用
+type sessionRevoker struct {
+	db                     *gorm.DB
+	sessionRepository      repository.SessionRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	revocationStore        repository.TokenRevocationStore
+	accessTTL              time.Duration
+}
+
+func newSessionRevoker(db *gorm.DB, sessionRepository repository.SessionRepository, refreshTokenRepository repository.RefreshTokenRepository, revocationStore repository.TokenRevocationStore, accessTTL time.Duration) sessionRevoker {
+	return sessionRevoker{
+		db:                     db,
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		revocationStore:        revocationStore,
+		accessTTL:              accessTTL,
+	}
+}
+
+// revoke 终止单个会话
+func (r sessionRevoker) revoke(ctx context.Context, sessionID uuid.UUID) error {
+	now := time.Now()
+	if _, err := r.sessionRepository.Revoke(ctx, r.db, sessionID, now); err != nil {
+		return fmt.Errorf("终止会话失败: %w", err)
+	}
+	if err := r.refreshTokenRepository.RevokeFamily(ctx, r.db, sessionID, now); err != nil {
+		return fmt.Errorf("吊销刷新令牌失败: %w", err)
+	}
+	if err := r.revocationStore.RevokeSessionTokens(ctx, sessionID, r.accessTTL); err != nil {
+		return fmt.Errorf("吊销访问令牌失败: %w", err)
+	}
+	return nil
+}
+
+// revokeAll 终止用户除 except 外的全部会话；except 为 uuid.Nil 时全部终止，
+// 并按签发时间吊销该用户的全部 access token（包括会话管理上线前签发、不带 sid 的 token）
+func (r sessionRevoker) revokeAll(ctx context.Context, userID, except uuid.UUID) error {
+	now := time.Now()
+	ids, err := r.sessionRepository.RevokeByUser(ctx, r.db, userID, except, now)
+	if err != nil {
+		return fmt.Errorf("终止会话失败: %w", err)
+	}
+	if err := r.refreshTokenRepository.RevokeByUser(ctx, r.db, userID, except, now); err != nil {
+		return fmt.Errorf("吊销刷新令牌失败: %w", err)
+	}
+	if except == uuid.Nil {
+		if err := r.revocationStore.RevokeUserTokens(ctx, userID, now, r.accessTTL); err != nil {
+			return fmt.Errorf("吊销访问令牌失败: %w", err)
+		}
+		return nil
+	}
+	for _, id := range ids {
+		if err := r.revocationStore.RevokeSessionTokens(ctx, id, r.accessTTL); err != nil {
+			return fmt.Errorf("吊销访问令牌失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListSessions 用户当前有效的登录会话，按最近活跃时间倒序
+func (s *TokenService) ListSessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	sessions, err := s.sessionRepository.ListActiveByUser(ctx, s.db, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("获取会话列表失败: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession 终止用户的某个会话；会话不属于该用户、已终止或已过期时返回 ErrSessionNotFound
+func (s *TokenService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepository.GetByID(ctx, s.db, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrSessionNotFound
+	}
+	return s.sessions.revoke(ctx, sessionID)
+}
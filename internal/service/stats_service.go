@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	dashboardCacheTTL  = time.Minute // 仪表盘聚合缓存时间，新建链接最多延迟 1 分钟出现
+	dashboardTopLinks  = 5           // 热门链接数
+	dashboardNewLinks  = 5           // 最新创建链接数
+	dashboardTopWindow = 30          // 热门链接统计窗口（天）
+)
+
+// LinkStats 单个链接的统计数据
+type LinkStats struct {
+	LinkID          int64
+	Clicks          int64 // 累计点击（来自 link_daily_stats）
+	Conversions     int64
+	TrackedClicks   int64 // 仍保留在 access_logs 中、带点击 ID 的点击
+	ConvertedClicks int64
+	ConversionValue float64
+	ConversionRate  float64 // ConvertedClicks / TrackedClicks，分子分母取自同一时间范围
+}
+
+// GetLinkStats 获取链接点击与转化统计（需要验证用户权限）
+func (s *StatsService) GetLinkStats(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool) (*LinkStats, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+
+	clicks, err := s.linkStatsRepository.GetTotalClicks(ctx, s.db, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("获取点击数失败: %w", err)
+	}
+	conv, err := s.conversionRepository.GetLinkConversionStats(ctx, s.db, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("获取转化统计失败: %w", err)
+	}
+
+	stats := &LinkStats{
+		LinkID:          linkID,
+		Clicks:          clicks,
+		Conversions:     conv.Conversions,
+		TrackedClicks:   conv.TrackedClicks,
+		ConvertedClicks: conv.ConvertedClicks,
+		ConversionValue: conv.Value,
+	}
+	if conv.TrackedClicks > 0 {
+		stats.ConversionRate = float64(conv.ConvertedClicks) / float64(conv.TrackedClicks)
+	}
+	return stats, nil
+}
+
+// Dashboard 用户仪表盘聚合数据
+type Dashboard struct {
+	Links       repository.LinkStatusCounts
+	Clicks      repository.UserClickSummary
+	TopLinks    []repository.LinkClicks // 近 30 天点击最多的链接
+	RecentLinks []model.Link            // 最新创建的链接
+	GeneratedAt time.Time
+}
+
+// GetUserDashboard 获取用户（或当前工作区）仪表盘（点击数来自 link_daily_stats 汇总表，结果缓存 1 分钟）
+func (s *StatsService) GetUserDashboard(ctx context.Context, userID uuid.UUID, workspaceID *int64) (*Dashboard, error) {
+	scope, err := resolveLinkScope(ctx, s.db, s.workspaceRepository, userID, workspaceID, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := scope.Key()
+	if s.dashboardCache != nil {
+		if data, err := s.dashboardCache.GetDashboard(ctx, cacheKey); err == nil {
+			var cached Dashboard
+			if json.Unmarshal(data, &cached) == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since7d := today.AddDate(0, 0, -6) // 含今天共 7 天
+	since30d := today.AddDate(0, 0, -(dashboardTopWindow - 1))
+
+	counts, err := s.linkRepository.GetLinkStatusCounts(ctx, s.db, scope, now)
+	if err != nil {
+		return nil, fmt.Errorf("获取链接数失败: %w", err)
+	}
+	clicks, err := s.linkStatsRepository.GetClickSummary(ctx, s.db, scope, since7d, since30d)
+	if err != nil {
+		return nil, fmt.Errorf("获取点击数失败: %w", err)
+	}
+	topLinks, err := s.linkStatsRepository.GetTopLinksByScope(ctx, s.db, scope, since30d, dashboardTopLinks)
+	if err != nil {
+		return nil, fmt.Errorf("获取热门链接失败: %w", err)
+	}
+	recentLinks, _, err := s.linkRepository.ListLinks(ctx, s.db, repository.LinkListFilter{
+		Scope: scope,
+		Sort:  repository.LinkSortCreated,
+		Page:  1,
+		Size:  dashboardNewLinks,
+		Count: repository.CountNone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取最新链接失败: %w", err)
+	}
+
+	dashboard := &Dashboard{
+		Links:       *counts,
+		Clicks:      *clicks,
+		TopLinks:    topLinks,
+		RecentLinks: recentLinks,
+		GeneratedAt: now,
+	}
+
+	if s.dashboardCache != nil {
+		if data, err := json.Marshal(dashboard); err == nil {
+			_ = s.dashboardCache.SetDashboard(ctx, cacheKey, data, dashboardCacheTTL)
+		}
+	}
+	return dashboard, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxTagNameLength = 50
+	maxTagsPerLink   = 20
+)
+
+var (
+	ErrTagNotFound    = errors.New("标签不存在")
+	ErrInvalidTagName = errors.New("标签名无效")
+	ErrTooManyTags    = errors.New("标签数量超过上限")
+)
+
+// normalizeTagNames 去除首尾空白并去重（保持原有顺序）
+func normalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil, ErrInvalidTagName
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	if len(result) > maxTagsPerLink {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
+
+// ListTags 获取用户的全部标签
+func (s *TagService) ListTags(ctx context.Context, userID uuid.UUID) ([]model.Tag, error) {
+	return s.tagRepository.GetTagsByUser(ctx, s.db, userID)
+}
+
+// CreateTag 创建标签（同名标签已存在时直接返回）
+func (s *TagService) CreateTag(ctx context.Context, userID uuid.UUID, name string) (*model.Tag, error) {
+	names, err := normalizeTagNames([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tagRepository.GetOrCreateTags(ctx, s.db, userID, names)
+	if err != nil {
+		return nil, fmt.Errorf("创建标签失败: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, ErrTagNotFound
+	}
+	return &tags[0], nil
+}
+
+// DeleteTag 删除标签（同时解除与链接的关联）
+func (s *TagService) DeleteTag(ctx context.Context, userID uuid.UUID, tagID int64) error {
+	deleted, err := s.tagRepository.DeleteTag(ctx, s.db, userID, tagID)
+	if err != nil {
+		return fmt.Errorf("删除标签失败: %w", err)
+	}
+	if !deleted {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// SetLinkTags 按名称替换链接的标签，不存在的标签自动创建在链接所有者名下
+func (s *TagService) SetLinkTags(ctx context.Context, linkID int64, userID uuid.UUID, isAdmin bool, names []string) ([]model.Tag, error) {
+	link, err := s.linkRepository.GetLinkByID(ctx, s.db, linkID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+	if err := checkLinkAccess(ctx, s.db, s.workspaceRepository, link, userID, isAdmin, model.WorkspaceRoleEditor); err != nil {
+		return nil, err
+	}
+	names, err = normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepository.GetOrCreateTags(ctx, s.db, link.UserID, names)
+	if err != nil {
+		return nil, fmt.Errorf("创建标签失败: %w", err)
+	}
+	tagIDs := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := s.tagRepository.SetLinkTags(ctx, s.db, linkID, tagIDs); err != nil {
+		return nil, fmt.Errorf("设置链接标签失败: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagsByLinks 批量获取链接的标签（列表展示用）
+func (s *TagService) GetTagsByLinks(ctx context.Context, links []model.Link) (map[int64][]model.Tag, error) {
+	linkIDs := make([]int64, 0, len(links))
+	for _, link := range links {
+		linkIDs = append(linkIDs, link.ID)
+	}
+	return s.tagRepository.GetTagsByLinkIDs(ctx, s.db, linkIDs)
+}
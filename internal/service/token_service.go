@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/util"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，该登录会话已全部失效")
+	ErrUserDisabled        = errors.New("用户已被禁用")
+)
+
+// TokenConfig 令牌有效期配置
+type TokenConfig struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// LoadTokenConfigFromEnv ACCESS_TOKEN_TTL（默认 15m）、REFRESH_TOKEN_TTL（默认 720h）
+func LoadTokenConfigFromEnv() TokenConfig {
+	cfg := TokenConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 30 * 24 * time.Hour}
+	if d, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil && d > 0 {
+		cfg.AccessTTL = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && d > 0 {
+		cfg.RefreshTTL = d
+	}
+	return cfg
+}
+
+// ClientInfo 发起登录 / 刷新的客户端信息
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair 登录或刷新后返回给客户端的令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // access token 有效秒数
+}
+
+// IssueTokens 登录成功后签发 access token 与新家族的 refresh token
+func (s *TokenService) IssueTokens(ctx context.Context, user *model.User, client ClientInfo) (*TokenPair, error) {
+	if user.Status != "active" {
+		return nil, ErrUserDisabled
+	}
+	return s.issue(ctx, user, uuid.New(), client, nil)
+}
+
+// issue 签发一对令牌；rotate 非 nil 时在同一事务内将旧令牌标记为已轮换
+func (s *TokenService) issue(ctx context.Context, user *model.User, familyID uuid.UUID, client ClientInfo, rotate *model.RefreshToken) (*TokenPair, error) {
+	raw, hash, err := util.NewRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	now := time.Now()
+	refresh := &model.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.config.RefreshTTL),
+		UserAgent: truncate(client.UserAgent, 255),
+		IP:        truncate(client.IP, 45),
+	}
+	// 会话 ID 即家族 ID：登录时创建，刷新时更新最近活跃时间与客户端信息
+	session := &model.Session{
+		ID:         familyID,
+		UserID:     user.ID,
+		UserAgent:  refresh.UserAgent,
+		IP:         refresh.IP,
+		LastSeenAt: now,
+		ExpiresAt:  refresh.ExpiresAt,
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if rotate != nil {
+			ok, err := s.refreshTokenRepository.MarkRotated(ctx, tx, rotate.ID, refresh.ID, now)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrRefreshTokenReused // 并发请求已抢先轮换
+			}
+		}
+		if err := s.refreshTokenRepository.Create(ctx, tx, refresh); err != nil {
+			return err
+		}
+		if rotate != nil {
+			touched, err := s.sessionRepository.Touch(ctx, tx, session)
+			if err != nil || touched {
+				return err
+			}
+			// 会话管理上线前登录的家族没有会话记录，首次刷新时补建
+		}
+		return s.sessionRepository.Create(ctx, tx, session)
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
+
+	access, err := util.GenerateToken(user.ID, user.Username, user.Role, familyID, s.config.AccessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: raw, ExpiresIn: int(s.config.AccessTTL.Seconds())}, nil
+}
+
+// Refresh 用 refresh token 换发新的一对令牌（旧 refresh token 随即失效）。
+// 已轮换的令牌再次出现视为泄露：吊销整个家族，并吊销该用户已签发的 access token
+func (s *TokenService) Refresh(ctx context.Context, rawRefreshToken string, client ClientInfo) (*TokenPair, error) {
+	token, err := s.refreshTokenRepository.GetByHash(ctx, s.db, util.HashToken(rawRefreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.RotatedAt != nil {
+		s.handleReuse(ctx, token)
+		return nil, ErrRefreshTokenReused
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepository.GetUserByUserID(ctx, s.db, token.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken // 用户已删除
+	}
+	if user.Status != "active" {
+		return nil, ErrUserDisabled
+	}
+
+	pair, err := s.issue(ctx, user, token.FamilyID, client, token)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		s.handleReuse(ctx, token)
+	}
+	return pair, err
+}
+
+// handleReuse 检测到重放：终止该会话（吊销整个家族），并让该用户此前签发的 access token 全部失效
+func (s *TokenService) handleReuse(ctx context.Context, token *model.RefreshToken) {
+	now := time.Now()
+	log.Printf("⚠️ Refresh token reuse detected: user=%s family=%s", token.UserID, token.FamilyID)
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, s.db, token.FamilyID, now); err != nil {
+		log.Printf("⚠️ Revoke refresh token family failed: %v", err)
+	}
+	if _, err := s.sessionRepository.Revoke(ctx, s.db, token.FamilyID, now); err != nil {
+		log.Printf("⚠️ Revoke session failed: %v", err)
+	}
+	if err := s.revocationStore.RevokeUserTokens(ctx, token.UserID, now, s.config.AccessTTL); err != nil {
+		log.Printf("⚠️ Revoke access tokens failed: %v", err)
+	}
+}
+
+type LogoutCommand struct {
+	UserID       uuid.UUID
+	JTI          string    // 当前 access token 的 jti
+	ExpiresAt    time.Time // 当前 access token 的过期时间
+	SessionID    string    // 当前 access token 的 sid，可为空
+	RefreshToken string    // 可选，同时吊销该 refresh token 所在的登录会话
+	All          bool      // 退出全部设备
+}
+
+// Logout 终止当前会话（refresh token 所在的会话，未提供时取 access token 的 sid）并吊销当前 access token；
+// All 时吊销该用户的全部令牌
+func (s *TokenService) Logout(ctx context.Context, cmd LogoutCommand) error {
+	if cmd.All {
+		return s.RevokeUserTokens(ctx, cmd.UserID)
+	}
+	sessionID, _ := uuid.Parse(cmd.SessionID)
+	if cmd.RefreshToken != "" {
+		token, err := s.refreshTokenRepository.GetByHash(ctx, s.db, util.HashToken(cmd.RefreshToken))
+		if err == nil && token.UserID == cmd.UserID {
+			sessionID = token.FamilyID
+		}
+	}
+	if sessionID != uuid.Nil {
+		if err := s.sessions.revoke(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	if cmd.JTI != "" {
+		if err := s.revocationStore.RevokeToken(ctx, cmd.JTI, time.Until(cmd.ExpiresAt)); err != nil {
+			return fmt.Errorf("吊销访问令牌失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// RevokeUserTokens 终止用户的全部会话，吊销全部 refresh token 和已签发的 access token（禁用、删除用户或退出全部设备时调用）
+func (s *TokenService) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	return s.sessions.revokeAll(ctx, userID, uuid.Nil)
+}
+
+// JWKS 当前可验签 access token 的公钥（签发密钥及轮换中的其他密钥）
+func (s *TokenService) JWKS() util.JWKSet {
+	return util.CurrentJWKS()
+}
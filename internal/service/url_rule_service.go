@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/urlcheck"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsafeURL       = errors.New("目标地址不安全")
+	ErrURLRuleExists   = errors.New("规则已存在")
+	ErrURLRuleNotFound = errors.New("规则不存在")
+	ErrInvalidURLRule  = errors.New("规则格式无效")
+)
+
+// checkDestination 对规范化后的目标地址执行安全检查，checker 为 nil 时不检查
+// 拒绝时同时包装 ErrUnsafeURL 和 *urlcheck.BlockedError，调用方可取出拒绝原因
+func checkDestination(ctx context.Context, checker urlcheck.URLChecker, rawURL string) error {
+	if checker == nil {
+		return nil
+	}
+	if err := urlcheck.CheckString(ctx, checker, rawURL); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsafeURL, err)
+	}
+	return nil
+}
+
+type CreateURLRuleCommand struct {
+	Pattern   string
+	Action    string // block | allow
+	Reason    string
+	CreatedBy uuid.UUID
+}
+
+// ListRules 全部黑白名单规则
+func (s *URLRuleService) ListRules(ctx context.Context) ([]model.URLRule, error) {
+	return s.urlRuleRepository.ListRules(ctx, s.db)
+}
+
+// CreateRule 添加规则并立即刷新本实例的规则快照（其他实例定时刷新）
+func (s *URLRuleService) CreateRule(ctx context.Context, cmd CreateURLRuleCommand) (*model.URLRule, error) {
+	pattern := urlcheck.NormalizeHost(strings.TrimSpace(cmd.Pattern))
+	if !urlcheck.ValidPattern(pattern) {
+		return nil, ErrInvalidURLRule
+	}
+	if cmd.Action != model.URLRuleBlock && cmd.Action != model.URLRuleAllow {
+		return nil, ErrInvalidURLRule
+	}
+	rule := &model.URLRule{
+		Pattern:   pattern,
+		Action:    cmd.Action,
+		Reason:    strings.TrimSpace(cmd.Reason),
+		CreatedBy: cmd.CreatedBy,
+	}
+	if err := s.urlRuleRepository.Create(ctx, s.db, rule); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrURLRuleExists
+		}
+		return nil, fmt.Errorf("创建规则失败: %w", err)
+	}
+	if err := s.ReloadRules(ctx); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除规则
+func (s *URLRuleService) DeleteRule(ctx context.Context, ruleID int64) error {
+	deleted, err := s.urlRuleRepository.Delete(ctx, s.db, ruleID)
+	if err != nil {
+		return fmt.Errorf("删除规则失败: %w", err)
+	}
+	if !deleted {
+		return ErrURLRuleNotFound
+	}
+	return s.ReloadRules(ctx)
+}
+
+// ReloadRules 从数据库加载规则到内存快照
+func (s *URLRuleService) ReloadRules(ctx context.Context) error {
+	rules, err := s.urlRuleRepository.ListRules(ctx, s.db)
+	if err != nil {
+		return fmt.Errorf("加载规则失败: %w", err)
+	}
+	s.ruleSet.Replace(ToCheckRules(rules))
+	return nil
+}
+
+// ToCheckRules 数据库规则转换为检查用的规则
+func ToCheckRules(rules []model.URLRule) []urlcheck.Rule {
+	out := make([]urlcheck.Rule, len(rules))
+	for i, r := range rules {
+		out[i] = urlcheck.Rule{Pattern: r.Pattern, Allow: r.Action == model.URLRuleAllow, Reason: r.Reason}
+	}
+	return out
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"go-short/internal/model"
 	"go-short/internal/util"
+	"log"
 	"strings"
 	"time"
 
@@ -88,8 +89,11 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, cmd UpdateUserProfi
 		return nil, fmt.Errorf("获取用户信息失败: %w", err)
 	}
 
-	// 更新邮箱（如果提供）
+	// 更新邮箱（如果提供），邮箱变更后需重新验证
 	if cmd.Email != nil {
+		if user.Email == nil || !strings.EqualFold(*user.Email, *cmd.Email) {
+			user.EmailVerifiedAt = nil
+		}
 		user.Email = cmd.Email
 	}
 
@@ -101,8 +105,8 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, cmd UpdateUserProfi
 	return user, nil
 }
 
-// UpdatePassword 修改密码
-func (s *UserService) UpdatePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+// UpdatePassword 修改密码，成功后终止该用户除当前会话（currentSessionID，可为空）外的全部会话
+func (s *UserService) UpdatePassword(ctx context.Context, username, oldPassword, newPassword, currentSessionID string) error {
 	// 先获取用户信息
 	user, err := s.userRepository.GetUserByUsername(ctx, s.db, username)
 	if err != nil {
@@ -126,5 +130,11 @@ func (s *UserService) UpdatePassword(ctx context.Context, username, oldPassword,
 		return fmt.Errorf("更新密码失败: %w", err)
 	}
 
+	// 其他设备上的登录随之失效；密码已修改成功，失败只记录日志
+	currentSession, _ := uuid.Parse(currentSessionID)
+	if err := s.sessions.revokeAll(ctx, user.ID, currentSession); err != nil {
+		log.Printf("⚠️ Revoke other sessions after password change for user %s failed: %v", user.ID, err)
+	}
+
 	return nil
 }
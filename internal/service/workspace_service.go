@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-short/internal/model"
+	"go-short/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const workspaceInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrWorkspaceNotFound    = errors.New("工作区不存在")
+	ErrWorkspaceNotEmpty    = errors.New("工作区下仍有链接，无法删除")
+	ErrInvalidWorkspaceRole = errors.New("工作区角色无效")
+	ErrLastWorkspaceOwner   = errors.New("工作区至少需要保留一位所有者")
+	ErrAlreadyMember        = errors.New("该用户已是工作区成员")
+	ErrInvitationExists     = errors.New("已向该用户发出邀请")
+	ErrInvitationNotFound   = errors.New("邀请不存在或已失效")
+)
+
+// workspaceRole 查询用户在工作区中的角色，非成员返回 ErrWorkspaceNotFound（不暴露工作区是否存在）
+func workspaceRole(ctx context.Context, db *gorm.DB, repo repository.WorkspaceRepository, workspaceID int64, userID uuid.UUID) (string, error) {
+	member, err := repo.GetMember(ctx, db, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrWorkspaceNotFound
+		}
+		return "", fmt.Errorf("查询工作区成员失败: %w", err)
+	}
+	return member.Role, nil
+}
+
+// resolveLinkScope 解析当前操作的链接范围：workspaceID 为空时为个人链接，否则校验成员角色不低于 need
+func resolveLinkScope(ctx context.Context, db *gorm.DB, repo repository.WorkspaceRepository, userID uuid.UUID, workspaceID *int64, need string) (repository.LinkScope, error) {
+	scope := repository.LinkScope{UserID: userID, WorkspaceID: workspaceID}
+	if workspaceID == nil {
+		return scope, nil
+	}
+	role, err := workspaceRole(ctx, db, repo, *workspaceID, userID)
+	if err != nil {
+		return scope, err
+	}
+	if !model.WorkspaceRoleAllows(role, need) {
+		return scope, ErrForbidden
+	}
+	return scope, nil
+}
+
+// checkLinkAccess 校验用户对链接的权限：个人链接只有创建者可访问，工作区链接按成员角色判断，管理员不受限
+func checkLinkAccess(ctx context.Context, db *gorm.DB, repo repository.WorkspaceRepository, link *model.Link, userID uuid.UUID, isAdmin bool, need string) error {
+	if isAdmin {
+		return nil
+	}
+	if link.WorkspaceID == nil {
+		if link.UserID != userID {
+			return ErrForbidden
+		}
+		return nil
+	}
+	role, err := workspaceRole(ctx, db, repo, *link.WorkspaceID, userID)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !model.WorkspaceRoleAllows(role, need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireWorkspaceRole 校验用户在工作区中的角色不低于 need，返回实际角色
+func (s *WorkspaceService) requireWorkspaceRole(ctx context.Context, workspaceID int64, userID uuid.UUID, need string) (string, error) {
+	role, err := workspaceRole(ctx, s.db, s.workspaceRepository, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+	if !model.WorkspaceRoleAllows(role, need) {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// ListWorkspaces 用户所在的工作区
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context, userID uuid.UUID) ([]repository.UserWorkspace, error) {
+	return s.workspaceRepository.ListByUser(ctx, s.db, userID)
+}
+
+// CreateWorkspace 创建工作区，创建者成为所有者
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, userID uuid.UUID, name string) (*repository.UserWorkspace, error) {
+	workspace := &model.Workspace{Name: strings.TrimSpace(name), CreatedBy: userID}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.workspaceRepository.Create(ctx, tx, workspace); err != nil {
+			return err
+		}
+		return s.workspaceRepository.AddMember(ctx, tx, &model.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      userID,
+			Role:        model.WorkspaceRoleOwner,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建工作区失败: %w", err)
+	}
+	return &repository.UserWorkspace{Workspace: *workspace, Role: model.WorkspaceRoleOwner}, nil
+}
+
+// GetWorkspace 获取工作区（需为成员）
+func (s *WorkspaceService) GetWorkspace(ctx context.Context, workspaceID int64, userID uuid.UUID) (*repository.UserWorkspace, error) {
+	role, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaceRepository.GetByID(ctx, s.db, workspaceID)
+	if err != nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	return &repository.UserWorkspace{Workspace: *workspace, Role: role}, nil
+}
+
+// RenameWorkspace 修改工作区名称（所有者）
+func (s *WorkspaceService) RenameWorkspace(ctx context.Context, workspaceID int64, userID uuid.UUID, name string) (*repository.UserWorkspace, error) {
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+	if err := s.workspaceRepository.Rename(ctx, s.db, workspaceID, strings.TrimSpace(name)); err != nil {
+		return nil, fmt.Errorf("修改工作区失败: %w", err)
+	}
+	return s.GetWorkspace(ctx, workspaceID, userID)
+}
+
+// DeleteWorkspace 删除工作区（所有者；工作区下仍有链接，包括回收站中的链接时拒绝）
+func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, workspaceID int64, userID uuid.UUID) error {
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+	count, err := s.linkRepository.CountWorkspaceLinks(ctx, s.db, workspaceID)
+	if err != nil {
+		return fmt.Errorf("统计工作区链接失败: %w", err)
+	}
+	if count > 0 {
+		return ErrWorkspaceNotEmpty
+	}
+	if err := s.workspaceRepository.Delete(ctx, s.db, workspaceID); err != nil {
+		return fmt.Errorf("删除工作区失败: %w", err)
+	}
+	return nil
+}
+
+// ListMembers 工作区成员（需为成员）
+func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID int64, userID uuid.UUID) ([]repository.WorkspaceMemberInfo, error) {
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepository.ListMembers(ctx, s.db, workspaceID)
+}
+
+// UpdateMemberRole 修改成员角色（所有者；不能降级最后一位所有者）
+func (s *WorkspaceService) UpdateMemberRole(ctx context.Context, workspaceID int64, actorID, memberID uuid.UUID, role string) error {
+	if !model.IsValidWorkspaceRole(role) {
+		return ErrInvalidWorkspaceRole
+	}
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, actorID, model.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+	current, err := workspaceRole(ctx, s.db, s.workspaceRepository, workspaceID, memberID)
+	if err != nil {
+		if errors.Is(err, ErrWorkspaceNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if current == role {
+		return nil
+	}
+	if current == model.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+	if err := s.workspaceRepository.UpdateMemberRole(ctx, s.db, workspaceID, memberID, role); err != nil {
+		return fmt.Errorf("修改成员角色失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember 移除成员（所有者可移除任何人，成员可以退出；不能移除最后一位所有者）
+func (s *WorkspaceService) RemoveMember(ctx context.Context, workspaceID int64, actorID, memberID uuid.UUID) error {
+	need := model.WorkspaceRoleOwner
+	if actorID == memberID {
+		need = model.WorkspaceRoleViewer
+	}
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, actorID, need); err != nil {
+		return err
+	}
+	current, err := workspaceRole(ctx, s.db, s.workspaceRepository, workspaceID, memberID)
+	if err != nil {
+		if errors.Is(err, ErrWorkspaceNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if current == model.WorkspaceRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+	if err := s.workspaceRepository.RemoveMember(ctx, s.db, workspaceID, memberID); err != nil {
+		return fmt.Errorf("移除成员失败: %w", err)
+	}
+	return nil
+}
+
+// ensureAnotherOwner 降级或移除一位所有者前，确认还有其他所有者
+func (s *WorkspaceService) ensureAnotherOwner(ctx context.Context, workspaceID int64) error {
+	owners, err := s.workspaceRepository.CountOwners(ctx, s.db, workspaceID)
+	if err != nil {
+		return fmt.Errorf("统计工作区所有者失败: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+type InviteMemberCommand struct {
+	WorkspaceID int64
+	InviterID   uuid.UUID
+	Username    string // 用户名与邮箱二选一
+	Email       string
+	Role        string
+}
+
+// InviteMember 按用户名或邮箱邀请已注册用户加入工作区（所有者），邀请 7 天内有效；
+// 按邮箱邀请时只匹配已验证该邮箱的用户，避免邀请到冒填邮箱的账号
+func (s *WorkspaceService) InviteMember(ctx context.Context, cmd InviteMemberCommand) (*model.WorkspaceInvitation, error) {
+	if !model.IsValidWorkspaceRole(cmd.Role) {
+		return nil, ErrInvalidWorkspaceRole
+	}
+	if _, err := s.requireWorkspaceRole(ctx, cmd.WorkspaceID, cmd.InviterID, model.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+
+	var invitee *model.User
+	var err error
+	if cmd.Username != "" {
+		invitee, err = s.userRepository.GetUserByUsername(ctx, s.db, strings.TrimSpace(cmd.Username))
+	} else {
+		invitee, err = s.userRepository.GetUserByVerifiedEmail(ctx, s.db, strings.TrimSpace(cmd.Email))
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	if _, err := workspaceRole(ctx, s.db, s.workspaceRepository, cmd.WorkspaceID, invitee.ID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrWorkspaceNotFound) {
+		return nil, err
+	}
+	now := time.Now()
+	if _, err := s.workspaceRepository.GetPendingInvitation(ctx, s.db, cmd.WorkspaceID, invitee.ID, now); err == nil {
+		return nil, ErrInvitationExists
+	}
+
+	invitation := &model.WorkspaceInvitation{
+		WorkspaceID: cmd.WorkspaceID,
+		InviterID:   cmd.InviterID,
+		InviteeID:   invitee.ID,
+		Role:        cmd.Role,
+		Status:      model.InvitationPending,
+		ExpiresAt:   now.Add(workspaceInvitationTTL),
+	}
+	if err := s.workspaceRepository.CreateInvitation(ctx, s.db, invitation); err != nil {
+		return nil, fmt.Errorf("创建邀请失败: %w", err)
+	}
+	return invitation, nil
+}
+
+// ListWorkspaceInvitations 工作区待处理的邀请（所有者）
+func (s *WorkspaceService) ListWorkspaceInvitations(ctx context.Context, workspaceID int64, userID uuid.UUID) ([]repository.WorkspaceInvitationInfo, error) {
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepository.ListPendingByWorkspace(ctx, s.db, workspaceID, time.Now())
+}
+
+// RevokeInvitation 撤回邀请（所有者）
+func (s *WorkspaceService) RevokeInvitation(ctx context.Context, workspaceID, invitationID int64, userID uuid.UUID) error {
+	if _, err := s.requireWorkspaceRole(ctx, workspaceID, userID, model.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+	invitation, err := s.workspaceRepository.GetInvitation(ctx, s.db, invitationID)
+	if err != nil || invitation.WorkspaceID != workspaceID {
+		return ErrInvitationNotFound
+	}
+	ok, err := s.workspaceRepository.RespondInvitation(ctx, s.db, invitationID, model.InvitationRevoked, time.Now())
+	if err != nil {
+		return fmt.Errorf("撤回邀请失败: %w", err)
+	}
+	if !ok {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// ListMyInvitations 发给当前用户的待处理邀请
+func (s *WorkspaceService) ListMyInvitations(ctx context.Context, userID uuid.UUID) ([]repository.WorkspaceInvitationInfo, error) {
+	return s.workspaceRepository.ListPendingByInvitee(ctx, s.db, userID, time.Now())
+}
+
+// AcceptInvitation 接受邀请并加入工作区
+func (s *WorkspaceService) AcceptInvitation(ctx context.Context, invitationID int64, userID uuid.UUID) (*repository.UserWorkspace, error) {
+	invitation, err := s.pendingInvitationFor(ctx, invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ok, err := s.workspaceRepository.RespondInvitation(ctx, tx, invitationID, model.InvitationAccepted, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvitationNotFound
+		}
+		if _, err := s.workspaceRepository.GetMember(ctx, tx, invitation.WorkspaceID, userID); err == nil {
+			return nil // 已通过其他邀请加入
+		}
+		return s.workspaceRepository.AddMember(ctx, tx, &model.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("接受邀请失败: %w", err)
+	}
+	return s.GetWorkspace(ctx, invitation.WorkspaceID, userID)
+}
+
+// DeclineInvitation 拒绝邀请
+func (s *WorkspaceService) DeclineInvitation(ctx context.Context, invitationID int64, userID uuid.UUID) error {
+	if _, err := s.pendingInvitationFor(ctx, invitationID, userID); err != nil {
+		return err
+	}
+	ok, err := s.workspaceRepository.RespondInvitation(ctx, s.db, invitationID, model.InvitationDeclined, time.Now())
+	if err != nil {
+		return fmt.Errorf("拒绝邀请失败: %w", err)
+	}
+	if !ok {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// pendingInvitationFor 查询发给该用户且仍有效的邀请
+func (s *WorkspaceService) pendingInvitationFor(ctx context.Context, invitationID int64, userID uuid.UUID) (*model.WorkspaceInvitation, error) {
+	invitation, err := s.workspaceRepository.GetInvitation(ctx, s.db, invitationID)
+	if err != nil || invitation.InviteeID != userID || invitation.Status != model.InvitationPending || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
@@ -0,0 +1,172 @@
+package urlcheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// HashPrefixList 本地恶意网址库，格式与 Google Safe Browsing 哈希前缀一致：
+// 每行一个十六进制 SHA-256 前缀（4-32 字节），# 开头为注释。
+// 本地没有全哈希校验，命中前缀即拒绝，因此建议使用较长前缀或完整哈希。
+type HashPrefixList struct {
+	prefixes map[string]struct{}
+	lengths  []int // 出现过的前缀字节长度
+}
+
+// LoadHashPrefixFile 读取哈希前缀文件
+func LoadHashPrefixFile(path string) (*HashPrefixList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l := &HashPrefixList{prefixes: make(map[string]struct{})}
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) < 4 || len(b) > sha256.Size {
+			return nil, fmt.Errorf("%s:%d: invalid hash prefix", path, line)
+		}
+		l.prefixes[string(b)] = struct{}{}
+		if !seen[len(b)] {
+			seen[len(b)] = true
+			l.lengths = append(l.lengths, len(b))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LoadHashPrefixFileFromEnv 读取 URL_HASH_PREFIX_FILE，未配置返回 nil
+func LoadHashPrefixFileFromEnv() (*HashPrefixList, error) {
+	path := os.Getenv("URL_HASH_PREFIX_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	return LoadHashPrefixFile(path)
+}
+
+// Len 前缀数量
+func (l *HashPrefixList) Len() int {
+	return len(l.prefixes)
+}
+
+func (l *HashPrefixList) Check(_ context.Context, u *url.URL) error {
+	for _, expr := range Expressions(u) {
+		sum := sha256.Sum256([]byte(expr))
+		for _, n := range l.lengths {
+			if _, ok := l.prefixes[string(sum[:n])]; ok {
+				return blocked("命中恶意网址库")
+			}
+		}
+	}
+	return nil
+}
+
+// Expressions 按 Safe Browsing 规则生成待查询的 "主机/路径" 表达式：
+// 主机取完整主机名及最后 5 级起逐级去掉首段的后缀（最多 5 个，不含顶级域），
+// 路径取带查询串的完整路径、完整路径、以及根路径起最多 4 个前缀目录
+func Expressions(u *url.URL) []string {
+	host := canonicalHost(u.Hostname())
+	if host == "" {
+		return nil
+	}
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		labels := strings.Split(host, ".")
+		if len(labels) > 5 {
+			labels = labels[len(labels)-5:]
+		}
+		for i := 0; i < len(labels)-1 && len(hosts) < 5; i++ {
+			h := strings.Join(labels[i:], ".")
+			if h != host {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+
+	path := canonicalPath(u)
+	paths := make([]string, 0, 6)
+	addPath := func(p string) {
+		for _, existing := range paths {
+			if existing == p {
+				return
+			}
+		}
+		paths = append(paths, p)
+	}
+	if u.RawQuery != "" {
+		addPath(path + "?" + u.RawQuery)
+	}
+	addPath(path)
+	addPath("/")
+	prefix := "/"
+	dirs := strings.Split(strings.Trim(path, "/"), "/")
+	if !strings.HasSuffix(path, "/") {
+		dirs = dirs[:len(dirs)-1] // 最后一段是文件名，不作为目录前缀
+	}
+	for i, seg := range dirs {
+		if seg == "" || i >= 3 {
+			break
+		}
+		prefix += seg + "/"
+		addPath(prefix)
+	}
+
+	exprs := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			exprs = append(exprs, h+p)
+		}
+	}
+	return exprs
+}
+
+// canonicalHost 小写、去掉首尾的点并合并连续的点
+func canonicalHost(host string) string {
+	host = strings.Trim(strings.ToLower(host), ".")
+	for strings.Contains(host, "..") {
+		host = strings.ReplaceAll(host, "..", ".")
+	}
+	return host
+}
+
+// canonicalPath 解析 "." / ".." 并合并连续斜杠，保留末尾斜杠
+func canonicalPath(u *url.URL) string {
+	p := u.Path
+	if p == "" {
+		return "/"
+	}
+	var out []string
+	for _, seg := range strings.Split(p, "/") {
+		switch seg {
+		case "", ".":
+		case "..":
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+	cleaned := "/" + strings.Join(out, "/")
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
@@ -0,0 +1,108 @@
+package urlcheck
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Rule 域名规则：Pattern 为 "example.com"（仅该域名）或 "*.example.com"（该域名及所有子域名）
+type Rule struct {
+	Pattern string
+	Allow   bool
+	Reason  string
+}
+
+// ValidPattern 校验规则模式：可选的 "*." 前缀加至少两级的域名
+func ValidPattern(pattern string) bool {
+	host := strings.TrimPrefix(pattern, "*.")
+	if host == "" || strings.Contains(host, "*") || !strings.Contains(host, ".") {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RuleSet 黑白名单的内存快照，通过 Replace 整体刷新；白名单优先于黑名单
+type RuleSet struct {
+	mu       sync.RWMutex
+	exact    map[string]Rule
+	wildcard map[string]Rule // 键为去掉 "*." 的域名
+}
+
+func NewRuleSet() *RuleSet {
+	return &RuleSet{exact: map[string]Rule{}, wildcard: map[string]Rule{}}
+}
+
+// Replace 用最新规则整体替换
+func (s *RuleSet) Replace(rules []Rule) {
+	exact := make(map[string]Rule)
+	wildcard := make(map[string]Rule)
+	for _, r := range rules {
+		p := NormalizeHost(r.Pattern)
+		if strings.HasPrefix(p, "*.") {
+			wildcard[p[2:]] = r
+		} else {
+			exact[p] = r
+		}
+	}
+	s.mu.Lock()
+	s.exact, s.wildcard = exact, wildcard
+	s.mu.Unlock()
+}
+
+// Match 返回主机名命中的规则：同时命中黑白名单时返回白名单规则
+func (s *RuleSet) Match(host string) (Rule, bool) {
+	host = NormalizeHost(host)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found Rule
+	ok := false
+	consider := func(r Rule) bool {
+		if !ok || r.Allow && !found.Allow {
+			found, ok = r, true
+		}
+		return found.Allow
+	}
+	if r, hit := s.exact[host]; hit && consider(r) {
+		return found, true
+	}
+	for h := host; h != ""; {
+		if r, hit := s.wildcard[h]; hit && consider(r) {
+			return found, true
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return found, ok
+}
+
+// Check 命中白名单返回 ErrAllowed，命中黑名单拒绝
+func (s *RuleSet) Check(_ context.Context, u *url.URL) error {
+	r, ok := s.Match(u.Hostname())
+	if !ok {
+		return nil
+	}
+	if r.Allow {
+		return ErrAllowed
+	}
+	reason := "域名已被列入黑名单"
+	if r.Reason != "" {
+		reason += "：" + r.Reason
+	}
+	return blocked(reason)
+}
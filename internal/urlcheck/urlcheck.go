@@ -0,0 +1,161 @@
+// Package urlcheck 目标地址安全检查：由多个 URLChecker 组成流水线，创建/编辑链接和跳转时依次执行。
+package urlcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URLChecker 单项检查，拒绝时返回 *BlockedError；返回 ErrAllowed 表示明确放行，流水线跳过后续检查
+type URLChecker interface {
+	Check(ctx context.Context, u *url.URL) error
+}
+
+// CheckerFunc 函数适配器
+type CheckerFunc func(ctx context.Context, u *url.URL) error
+
+func (f CheckerFunc) Check(ctx context.Context, u *url.URL) error {
+	return f(ctx, u)
+}
+
+// ErrAllowed 命中白名单，不再执行后续检查
+var ErrAllowed = errors.New("url explicitly allowed")
+
+// BlockedError 地址被拒绝，Error() 即拒绝原因
+type BlockedError struct {
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return e.Reason
+}
+
+func blocked(reason string) error {
+	return &BlockedError{Reason: reason}
+}
+
+// Pipeline 按顺序执行检查，遇到第一个拒绝即返回；本身也是 URLChecker，可嵌套组合
+type Pipeline struct {
+	checkers []URLChecker
+}
+
+// NewPipeline nil 检查项会被忽略
+func NewPipeline(checkers ...URLChecker) *Pipeline {
+	p := &Pipeline{}
+	for _, c := range checkers {
+		if c != nil {
+			p.checkers = append(p.checkers, c)
+		}
+	}
+	return p
+}
+
+func (p *Pipeline) Check(ctx context.Context, u *url.URL) error {
+	for _, c := range p.checkers {
+		if err := c.Check(ctx, u); err != nil {
+			if errors.Is(err, ErrAllowed) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckString 解析后检查，无法解析或缺少主机名视为拒绝（javascript: 等不透明地址交由 SchemeChecker 判断）
+func CheckString(ctx context.Context, c URLChecker, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" && u.Opaque == "" {
+		return blocked("无法解析的地址")
+	}
+	if err := c.Check(ctx, u); err != nil && !errors.Is(err, ErrAllowed) {
+		return err
+	}
+	return nil
+}
+
+// NormalizeHost 小写并去掉末尾的点
+func NormalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// SchemeChecker 只允许 http / https
+var SchemeChecker = CheckerFunc(func(_ context.Context, u *url.URL) error {
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return nil
+	}
+	return blocked("不支持的协议 " + u.Scheme)
+})
+
+// PrivateAddressChecker 拒绝指向本机、内网、链路本地地址的链接（含 localhost 与十进制/十六进制等非常规 IPv4 写法）
+var PrivateAddressChecker = CheckerFunc(func(_ context.Context, u *url.URL) error {
+	host := NormalizeHost(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || !strings.Contains(host, ".") && !strings.Contains(host, ":") {
+		return blocked("禁止指向本机或内网主机")
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = parseLooseIPv4(host)
+	}
+	if ip != nil && IsInternalIP(ip) {
+		return blocked("禁止指向本机或内网地址")
+	}
+	return nil
+})
+
+// IsInternalIP 回环、私有、未指定、链路本地及 CGNAT 地址
+func IsInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		cgnat.Contains(ip)
+}
+
+// 运营商级 NAT 地址段（100.64.0.0/10），云环境常用于内部服务
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// parseLooseIPv4 按浏览器（WHATWG URL）规则解析 IPv4：支持 1-4 段，每段可为十进制、0x 十六进制或 0 开头的八进制，
+// 如 2130706433、0x7f.1、127.1 都等价于 127.0.0.1；不是 IPv4 时返回 nil
+func parseLooseIPv4(host string) net.IP {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	nums := make([]uint64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 0, 32)
+		if err != nil {
+			return nil
+		}
+		nums[i] = n
+	}
+	var v uint64
+	for i, n := range nums[:len(nums)-1] {
+		if n > 255 {
+			return nil
+		}
+		v |= n << (8 * (3 - i))
+	}
+	last := nums[len(nums)-1]
+	if last >= 1<<(8*(5-len(nums))) {
+		return nil
+	}
+	v |= last
+	return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// SelfHostChecker 拒绝指向本服务域名的链接，避免短链互相跳转形成循环
+type SelfHostChecker struct {
+	IsSelf func(ctx context.Context, host string) bool
+}
+
+func (c SelfHostChecker) Check(ctx context.Context, u *url.URL) error {
+	if c.IsSelf != nil && c.IsSelf(ctx, NormalizeHost(u.Hostname())) {
+		return blocked("禁止指向本服务的短链接（跳转循环）")
+	}
+	return nil
+}
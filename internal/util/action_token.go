@@ -0,0 +1,112 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 邮件链接令牌的用途，不同用途的令牌不能混用
+const (
+	ActionVerifyEmail   = "verify_email"
+	ActionResetPassword = "reset_password"
+)
+
+// devActionTokenSecret 未配置 EMAIL_TOKEN_SECRET 时开发环境使用的密钥（生产环境启动失败）
+const devActionTokenSecret = "youwillneverknow-email"
+
+// actionTokenSecret 邮件链接令牌签名密钥，启动时由 LoadEmailTokenSecretFromEnv 设置
+var actionTokenSecret = []byte(devActionTokenSecret)
+
+// LoadEmailTokenSecretFromEnv 读取 EMAIL_TOKEN_SECRET；APP_ENV=production 时未配置或不足 32 字节返回错误
+func LoadEmailTokenSecretFromEnv() error {
+	secret, err := loadSecretFromEnv("EMAIL_TOKEN_SECRET", devActionTokenSecret)
+	if err != nil {
+		return err
+	}
+	actionTokenSecret = secret
+	return nil
+}
+
+var (
+	ErrInvalidActionToken = errors.New("invalid action token")
+	ErrActionTokenExpired = errors.New("action token expired")
+)
+
+// ActionToken 解析后的邮件链接令牌
+type ActionToken struct {
+	Purpose   string
+	UserID    uuid.UUID
+	Nonce     string // 一次性使用的标识
+	Binding   string // 绑定的用户状态摘要（邮箱或密码哈希），状态变化后令牌失效
+	ExpiresAt time.Time
+}
+
+type actionPayload struct {
+	P string    `json:"p"`
+	U uuid.UUID `json:"u"`
+	E int64     `json:"e"`
+	N string    `json:"n"`
+	H string    `json:"h"`
+}
+
+// NewActionToken 生成邮件链接令牌
+// token 格式：base64url(JSON 载荷) + "." + base64url(HMAC-SHA256)
+func NewActionToken(purpose string, userID uuid.UUID, binding string, ttl time.Duration) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(actionPayload{
+		P: purpose,
+		U: userID,
+		E: time.Now().Add(ttl).Unix(),
+		N: base64.RawURLEncoding.EncodeToString(nonce),
+		H: ActionBinding(binding),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + signActionPayload(enc), nil
+}
+
+// ParseActionToken 校验签名、用途与有效期
+func ParseActionToken(token, purpose string) (*ActionToken, error) {
+	enc, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signActionPayload(enc))) {
+		return nil, ErrInvalidActionToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	var p actionPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.P != purpose || p.N == "" {
+		return nil, ErrInvalidActionToken
+	}
+	expiresAt := time.Unix(p.E, 0)
+	if time.Now().After(expiresAt) {
+		return nil, ErrActionTokenExpired
+	}
+	return &ActionToken{Purpose: p.P, UserID: p.U, Nonce: p.N, Binding: p.H, ExpiresAt: expiresAt}, nil
+}
+
+// ActionBinding 用户状态的短摘要，令牌中不直接携带邮箱或密码哈希
+func ActionBinding(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func signActionPayload(enc string) string {
+	mac := hmac.New(sha256.New, actionTokenSecret)
+	mac.Write([]byte(enc))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClickIDParam 重定向时追加到目标 URL 的点击 ID 参数名
+const ClickIDParam = "gs_cid"
+
+// devClickIDSecret 未配置 CLICK_ID_SECRET 时开发环境使用的密钥（生产环境启动失败）
+const devClickIDSecret = "youwillneverknow-click"
+
+// clickIDSecret 点击 ID 签名密钥，启动时由 LoadClickIDSecretFromEnv 设置
+var clickIDSecret = []byte(devClickIDSecret)
+
+// LoadClickIDSecretFromEnv 读取 CLICK_ID_SECRET（签发点击 ID 的 Redirect 与校验转化的 API 必须一致）；
+// APP_ENV=production 时未配置或不足 32 字节返回错误
+func LoadClickIDSecretFromEnv() error {
+	secret, err := loadSecretFromEnv("CLICK_ID_SECRET", devClickIDSecret)
+	if err != nil {
+		return err
+	}
+	clickIDSecret = secret
+	return nil
+}
+
+var ErrInvalidClickToken = errors.New("invalid click token")
+
+// ClickToken 解析后的点击凭证
+type ClickToken struct {
+	ClickID   uuid.UUID // UUIDv7，自带毫秒时间戳
+	ShortCode string
+}
+
+// ClickedAt 从 UUIDv7 中还原点击时间
+func (t ClickToken) ClickedAt() time.Time {
+	sec, nsec := t.ClickID.Time().UnixTime()
+	return time.Unix(sec, nsec)
+}
+
+// NewClickToken 生成点击 ID 并签名，返回 (clickID, token)
+// token 格式：base64url(clickID 16 字节 + 短码) + "." + base64url(HMAC-SHA256 前 16 字节)
+func NewClickToken(shortCode string) (uuid.UUID, string, error) {
+	clickID, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	payload := append(clickID[:], shortCode...)
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return clickID, enc + "." + signClickPayload(enc), nil
+}
+
+// ParseClickToken 校验签名并解析点击凭证
+func ParseClickToken(token string) (*ClickToken, error) {
+	enc, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signClickPayload(enc))) {
+		return nil, ErrInvalidClickToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil || len(payload) <= 16 {
+		return nil, ErrInvalidClickToken
+	}
+	clickID, err := uuid.FromBytes(payload[:16])
+	if err != nil || clickID.Version() != 7 {
+		return nil, ErrInvalidClickToken
+	}
+	return &ClickToken{ClickID: clickID, ShortCode: string(payload[16:])}, nil
+}
+
+func signClickPayload(enc string) string {
+	mac := hmac.New(sha256.New, clickIDSecret)
+	mac.Write([]byte(enc))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// AppendQueryParam 向 URL 追加查询参数，原有参数顺序与编码、fragment 均保持不变；解析失败时原样返回
+func AppendQueryParam(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	param := url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	if u.RawQuery == "" {
+		u.RawQuery = param
+	} else {
+		u.RawQuery += "&" + param
+	}
+	return u.String()
+}
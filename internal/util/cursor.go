@@ -0,0 +1,40 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor keyset 分页游标，编码后对客户端不透明
+// Sort/Asc 记录生成游标时的排序方式，翻页时排序不一致的游标视为无效
+type Cursor struct {
+	Sort      string    `json:"s,omitempty"`
+	Asc       bool      `json:"a,omitempty"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	Clicks    int64     `json:"c,omitempty"`
+	Alias     string    `json:"l,omitempty"`
+	ID        string    `json:"i"`
+}
+
+// EncodeCursor 编码游标（base64url JSON）
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解码游标
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
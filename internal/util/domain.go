@@ -0,0 +1,59 @@
+package util
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeHost 规范化 Host 头 / 域名：去掉端口和末尾的点，转小写
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// IsValidHostname 校验自定义域名：至少两级、每级 1-63 个字母数字或连字符、不以连字符开头结尾、不能是 IP
+func IsValidHostname(host string) bool {
+	if len(host) == 0 || len(host) > 253 || net.ParseIP(host) != nil {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ShortURL 拼接短链接地址：默认域名使用 baseURL，自定义域名沿用 baseURL 的协议
+func ShortURL(baseURL, domain, code string) string {
+	if domain == "" {
+		return baseURL + "/code/" + code
+	}
+	scheme := "https"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return scheme + "://" + domain + "/code/" + code
+}
+
+// BaseHost baseURL 中的主机名（规范化后），用于识别默认域名
+func BaseHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return NormalizeHost(u.Host)
+}
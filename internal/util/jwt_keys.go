@@ -0,0 +1,245 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// devJWTSecret 未配置任何密钥时开发环境使用的 HS256 密钥（生产环境启动失败）
+const devJWTSecret = "youwillneverknow"
+
+const minRSABits = 2048
+
+var kidPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// signingKey 一个 JWT 密钥：非对称密钥按 kid 区分，仅用于验签的密钥 signKey 为 nil
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   any // *rsa.PrivateKey / ed25519.PrivateKey / []byte
+	verifyKey any // *rsa.PublicKey / ed25519.PublicKey / []byte
+}
+
+// KeySet 签发 access token 的当前密钥，以及验签时按 kid 查找的全部密钥（轮换期间旧密钥仍可验签）
+type KeySet struct {
+	signing *signingKey
+	keys    map[string]*signingKey
+}
+
+// keySet 当前使用的密钥，启动时由 SetKeySet 替换；默认为开发用 HS256 密钥
+var keySet atomic.Pointer[KeySet]
+
+func init() {
+	keySet.Store(newHMACKeySet([]byte(devJWTSecret)))
+}
+
+// SetKeySet 设置签发与验签使用的密钥
+func SetKeySet(ks *KeySet) {
+	keySet.Store(ks)
+}
+
+func newHMACKeySet(secret []byte) *KeySet {
+	k := &signingKey{method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	return &KeySet{signing: k, keys: map[string]*signingKey{"": k}}
+}
+
+// LoadKeySetFromEnv 读取 JWT 密钥：
+//   - JWT_SIGNING_KEYS：逗号分隔的 kid=PEM 私钥文件路径（RSA ≥ 2048 位用 RS256，Ed25519 用 EdDSA），第一个用于签发，其余只用于验签
+//   - JWT_VERIFY_KEYS：逗号分隔的 kid=PEM 公钥文件路径，只用于验签（轮换时提前发布的新密钥或已退役的旧密钥）
+//   - 未配置 JWT_SIGNING_KEYS 时退回 JWT_SECRET（HS256，不出现在 JWKS 中）
+//
+// APP_ENV=production 时既没有非对称密钥也没有 JWT_SECRET（或 JWT_SECRET 不足 32 字节）返回错误
+func LoadKeySetFromEnv() (*KeySet, error) {
+	production := os.Getenv("APP_ENV") == "production"
+	signing, err := parseKeyList(os.Getenv("JWT_SIGNING_KEYS"), true)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_SIGNING_KEYS: %w", err)
+	}
+	verifying, err := parseKeyList(os.Getenv("JWT_VERIFY_KEYS"), false)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_VERIFY_KEYS: %w", err)
+	}
+
+	if len(signing) == 0 {
+		if len(verifying) > 0 {
+			return nil, errors.New("JWT_VERIFY_KEYS requires JWT_SIGNING_KEYS")
+		}
+		secret := os.Getenv("JWT_SECRET")
+		switch {
+		case secret == "" && production:
+			return nil, errors.New("no JWT key configured: set JWT_SIGNING_KEYS (or JWT_SECRET) in production")
+		case secret == "":
+			secret = devJWTSecret
+		case production && len(secret) < 32:
+			return nil, errors.New("JWT_SECRET must be at least 32 bytes in production")
+		}
+		return newHMACKeySet([]byte(secret)), nil
+	}
+
+	ks := &KeySet{signing: signing[0], keys: make(map[string]*signingKey)}
+	for _, k := range append(signing, verifying...) {
+		if _, dup := ks.keys[k.kid]; dup {
+			return nil, fmt.Errorf("duplicate kid %q", k.kid)
+		}
+		ks.keys[k.kid] = k
+	}
+	return ks, nil
+}
+
+// Describe 启动日志中的密钥摘要，不含密钥内容
+func (ks *KeySet) Describe() string {
+	if ks.signing.kid == "" {
+		return "HS256 (JWT_SECRET)"
+	}
+	return fmt.Sprintf("%s kid=%s, %d key(s) for verification", ks.signing.method.Alg(), ks.signing.kid, len(ks.keys))
+}
+
+// parseKeyList 解析 kid=path 列表；private 为 true 时要求私钥
+func parseKeyList(value string, private bool) ([]*signingKey, error) {
+	var keys []*signingKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || !kidPattern.MatchString(kid) || path == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected kid=path", entry)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("kid %s: %w", kid, err)
+		}
+		k, err := parseKeyPEM(data, private)
+		if err != nil {
+			return nil, fmt.Errorf("kid %s: %w", kid, err)
+		}
+		k.kid = kid
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// parseKeyPEM 支持 PKCS#8 / PKCS#1 私钥与 PKIX / PKCS#1 公钥
+func parseKeyPEM(data []byte, private bool) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if signer, ok := key.(crypto.Signer); ok {
+		k, err := newKey(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		k.signKey = key
+		return k, nil
+	}
+	if private {
+		return nil, errors.New("a private key is required for signing")
+	}
+	return newKey(key)
+}
+
+func newKey(pub crypto.PublicKey) (*signingKey, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, verifyKey: pub}, nil
+	case ed25519.PublicKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, verifyKey: pub}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", pub)
+}
+
+// JWK 公钥（RFC 7517），RSA 使用 n/e，Ed25519 使用 crv/x
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json 的内容
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// CurrentJWKS 当前可验签的全部公钥（签发密钥在前）；HS256 密钥不公开，此时 keys 为空
+func CurrentJWKS() JWKSet {
+	ks := keySet.Load()
+	set := JWKSet{Keys: []JWK{}}
+	add := func(k *signingKey) {
+		jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			return
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	add(ks.signing)
+	for _, kid := range slices.Sorted(maps.Keys(ks.keys)) {
+		if kid != ks.signing.kid {
+			add(ks.keys[kid])
+		}
+	}
+	return set
+}
+
+// lookupKey 按 token 头部的 kid 查找验签密钥，并校验算法与密钥一致（防止算法混淆）
+func (ks *KeySet) lookupKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return k.verifyKey, nil
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"fmt"
+	"os"
+)
+
+// minProductionSecretLen 生产环境 HMAC 密钥的最小长度
+const minProductionSecretLen = 32
+
+// loadSecretFromEnv 读取 HMAC 签名密钥：未配置时开发环境使用 devDefault，
+// APP_ENV=production 时未配置或不足 32 字节返回错误（默认值是公开的，可被用来伪造签名）
+func loadSecretFromEnv(name, devDefault string) ([]byte, error) {
+	secret := os.Getenv(name)
+	if os.Getenv("APP_ENV") == "production" {
+		if secret == "" {
+			return nil, fmt.Errorf("%s must be set in production", name)
+		}
+		if len(secret) < minProductionSecretLen {
+			return nil, fmt.Errorf("%s must be at least %d bytes in production", name, minProductionSecretLen)
+		}
+	}
+	if secret == "" {
+		secret = devDefault
+	}
+	return []byte(secret), nil
+}
@@ -1,54 +1,69 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-// 定义 JWT 密钥，生产环境必须从环境变量读取
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-
-func init() {
-	// 如果环境变量没设置，为了开发方便给个默认值（生产环境应强制报错）
-	if len(jwtSecret) == 0 {
-		jwtSecret = []byte("youwillneverknow")
-	}
-}
-
 // UserClaims 自定义载荷，包含用户基础信息和标准 Claims
 type UserClaims struct {
-	UserID   uuid.UUID `json:"uid"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"` // "admin" or "user"
+	UserID     uuid.UUID `json:"uid"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`             // "admin" or "user"
+	SessionID  string    `json:"sid,omitempty"`    // 所属登录会话（refresh token 家族），终止会话时据此吊销
+	IssuedAtMs int64     `json:"iat_ms,omitempty"` // 毫秒级签发时间（iat 只有秒级），用户级吊销据此区分同一秒内吊销前后签发的 token
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT Token
-// duration: token 有效期，例如 time.Hour * 24
-func GenerateToken(userID uuid.UUID, username string, role string, duration time.Duration) (string, error) {
+// IssuedTime 签发时间，优先使用毫秒级的 iat_ms，旧 token 回退到 iat
+func (c *UserClaims) IssuedTime() time.Time {
+	if c.IssuedAtMs > 0 {
+		return time.UnixMilli(c.IssuedAtMs)
+	}
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.IssuedAt.Time
+}
+
+// GenerateToken 生成 JWT Token（access token），每个 token 带唯一 jti，用于服务端吊销
+// sessionID: 所属登录会话；duration: token 有效期，例如 time.Minute * 15
+func GenerateToken(userID uuid.UUID, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, error) {
 	// 设置过期时间
-	expirationTime := time.Now().Add(duration)
+	now := time.Now()
+	expirationTime := now.Add(duration)
 
 	claims := &UserClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:     userID,
+		Username:   username,
+		Role:       role,
+		SessionID:  sessionID.String(),
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "goshort-api",
+			ID:        uuid.NewString(),
 		},
 	}
 
-	// 使用 HS256 签名算法
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 使用当前签发密钥（RS256 / EdDSA，头部带 kid；未配置时为 HS256）
+	key := keySet.Load().signing
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.kid != "" {
+		token.Header["kid"] = key.kid
+	}
 
 	// 签名并生成字符串
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := token.SignedString(key.signKey)
 	if err != nil {
 		return "", err
 	}
@@ -62,14 +77,9 @@ func GenerateToken(userID uuid.UUID, username string, role string, duration time
 // - ExpiresAt 是否过期
 // - IssuedAt 是否有效
 func ParseToken(tokenString string) (*UserClaims, error) {
-	// 解析 token
-	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// 校验签名算法是否为预期算法 (这是防止 None 算法攻击的关键)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return jwtSecret, nil
-	})
+	// 解析 token：按 kid 查找密钥，算法必须与该密钥一致 (这是防止 None 算法与算法混淆攻击的关键)
+	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, keySet.Load().lookupKey,
+		jwt.WithValidMethods([]string{"RS256", "EdDSA", "HS256"}))
 
 	if err != nil {
 		// jwt.ParseWithClaims 会自动检查过期时间
@@ -84,3 +94,49 @@ func ParseToken(tokenString string) (*UserClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// NewRefreshToken 生成随机 refresh token，返回原文（只发给客户端一次）与用于存储的哈希
+func NewRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+	return raw, HashToken(raw), nil
+}
+
+// HashToken SHA-256 十六进制摘要（token 本身是高熵随机串，无需加盐慢哈希）
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix 个人 API Key 的固定前缀，便于识别和密钥扫描
+const APIKeyPrefix = "gsk_"
+
+// NewAPIKey 生成 API Key：gsk_<12 位十六进制前缀>_<随机串>，返回完整密钥（只发给用户一次）、前缀与密钥哈希
+func NewAPIKey() (key, prefix, hash string, err error) {
+	p := make([]byte, 6)
+	if _, err = rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	secret, hash, err := NewRefreshToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(p)
+	return APIKeyPrefix + prefix + "_" + secret, prefix, hash, nil
+}
+
+// ParseAPIKey 拆分 API Key 的前缀与随机串，格式不符时 ok 为 false
+func ParseAPIKey(key string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, APIKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	prefix, secret, found = strings.Cut(rest, "_")
+	if !found || len(prefix) != 12 || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}